@@ -15,6 +15,8 @@ import (
 	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
 
 	"github.com/liriquew/social-todo/api_service/internal/app"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
 func main() {
@@ -24,6 +26,11 @@ func main() {
 
 	log.Info("CONFIG", slog.Any("cfg", cfg))
 
+	// sso_service issues tokens with millisecond times and revocation
+	// cutoffs are compared with iat, with whole seconds tokens issued
+	// right after a cutoff would be revoked too
+	jwt.TimePrecision = time.Millisecond
+
 	r := app.New(log, *cfg)
 
 	srv := &http.Server{
@@ -49,6 +56,7 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Warn("Server forced to shutdown:", sl.Err(err))
 	}
+	r.Stop()
 
 	log.Info("Server exiting")
 }
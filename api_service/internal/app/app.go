@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"log/slog"
 
 	apiapp "github.com/liriquew/social-todo/api_service/internal/app/app"
@@ -8,16 +9,23 @@ import (
 	friends_grpc "github.com/liriquew/social-todo/api_service/internal/clients/friendsgrpc"
 	notes_grpc "github.com/liriquew/social-todo/api_service/internal/clients/notesgrpc"
 	"github.com/liriquew/social-todo/api_service/internal/lib/config"
+	"github.com/liriquew/social-todo/api_service/internal/lib/verifier"
+	"github.com/liriquew/social-todo/api_service/internal/rest/admin"
 	"github.com/liriquew/social-todo/api_service/internal/rest/auth"
 	"github.com/liriquew/social-todo/api_service/internal/rest/friends"
 	"github.com/liriquew/social-todo/api_service/internal/rest/notes"
+	"github.com/liriquew/social-todo/api_service/internal/rest/notifications"
+	"github.com/liriquew/social-todo/api_service/internal/rest/openapi"
 	handlers "github.com/liriquew/social-todo/api_service/internal/rest/other"
+	"github.com/liriquew/social-todo/api_service/internal/rest/users"
 
 	"github.com/gin-gonic/gin"
 )
 
 type App struct {
 	GinRouter *gin.Engine
+
+	stop context.CancelFunc
 }
 
 func New(log *slog.Logger, cfg config.Config) *App {
@@ -34,12 +42,33 @@ func New(log *slog.Logger, cfg config.Config) *App {
 		panic(err)
 	}
 
-	auth := auth.New(log, authClient)
+	ctx, stop := context.WithCancel(context.Background())
+
+	verifier := verifier.New(log, authClient, cfg.TokensConfig)
+	go verifier.Run(ctx)
+
+	auth := auth.New(log, authClient, verifier, cfg.EmailVerification, cfg.ExternalLogin)
 	notes := notes.New(log, notesClient)
-	friends := friends.New(log, friendsClient)
-	other := handlers.New(log, notesClient, friendsClient)
+	friends := friends.New(log, friendsClient, authClient)
+	notifications := notifications.New(log, friendsClient)
+	users := users.New(log, authClient)
+	other := handlers.New(log, notesClient, friendsClient, authClient)
+	admin := admin.New(log, authClient, notesClient)
 
-	r := apiapp.New(auth, notes, friends, other)
+	spec, err := openapi.New(log, cfg.OpenAPI)
+	if err != nil {
+		panic(err)
+	}
+
+	r := apiapp.New(spec, auth, notes, friends, notifications, users, other, admin)
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		panic(err)
+	}
+
+	return &App{GinRouter: r, stop: stop}
+}
 
-	return &App{r}
+// Stop stops background work, e.g. keys and revocations polling.
+func (a *App) Stop() {
+	a.stop()
 }
@@ -1,22 +1,102 @@
 package apiapp
 
 import (
+	"github.com/liriquew/social-todo/api_service/internal/rest/admin"
+	"github.com/liriquew/social-todo/api_service/internal/rest/apierr"
 	"github.com/liriquew/social-todo/api_service/internal/rest/auth"
 	"github.com/liriquew/social-todo/api_service/internal/rest/friends"
 	"github.com/liriquew/social-todo/api_service/internal/rest/notes"
+	"github.com/liriquew/social-todo/api_service/internal/rest/notifications"
+	"github.com/liriquew/social-todo/api_service/internal/rest/openapi"
 	handlers "github.com/liriquew/social-todo/api_service/internal/rest/other"
+	"github.com/liriquew/social-todo/api_service/internal/rest/users"
 
 	"github.com/gin-gonic/gin"
 )
 
-func New(auth auth.AuthAPI, notes notes.NotesAPI, friends friends.FriendsAPI, other handlers.GeneralAPI) *gin.Engine {
+func New(
+	spec *openapi.Spec,
+	auth auth.AuthAPI,
+	notes notes.NotesAPI,
+	friends friends.FriendsAPI,
+	notifications notifications.NotificationsAPI,
+	users users.UsersAPI,
+	other handlers.GeneralAPI,
+	admin admin.AdminAPI,
+) *gin.Engine {
 	r := gin.New()
+	r.Use(apierr.RequestID, gin.CustomRecovery(apierr.Recovery))
+	r.NoRoute(apierr.NotFound)
 
-	r.POST("/signin", auth.Login)
-	r.POST("/signup", auth.Register)
+	r.GET("/openapi.json", spec.Serve)
 
+	// requests are validated against the document once authenticated,
+	// so anonymous clients don't learn the shape of protected routes
+	public := r.Group("")
+	public.Use(spec.Validate)
+
+	public.POST("/signin", auth.Login)
+	public.POST("/signin/2fa", auth.CompleteLogin)
+	public.POST("/signup", auth.Register)
+	public.POST("/token/refresh", auth.Refresh)
+
+	public.GET("/signin/external", auth.ListConnectors)
+	public.GET("/signin/external/:connector", auth.StartExternalLogin)
+	public.POST("/signin/external/:connector", auth.ExternalLogin)
+
+	logoutAPI := r.Group("/logout")
+	logoutAPI.Use(auth.AuthRequired, spec.Validate)
+	{
+		logoutAPI.POST("", auth.Logout)
+		logoutAPI.POST("/all", auth.LogoutAll)
+	}
+
+	public.POST("/email/verify", auth.VerifyEmail)
+
+	public.POST("/password/reset/request", auth.RequestPasswordReset)
+	public.POST("/password/reset", auth.ResetPassword)
+
+	passwordAPI := r.Group("/password")
+	passwordAPI.Use(auth.AuthRequired, spec.Validate)
+	{
+		passwordAPI.POST("/change", auth.ChangePassword)
+	}
+
+	twoFactorAPI := r.Group("/2fa")
+	twoFactorAPI.Use(auth.AuthRequired, spec.Validate)
+	{
+		twoFactorAPI.POST("/enroll", auth.EnrollTOTP)
+		twoFactorAPI.POST("/confirm", auth.ConfirmTOTP)
+		twoFactorAPI.POST("/disable", auth.DisableTOTP)
+	}
+
+	sessionsAPI := r.Group("/sessions")
+	sessionsAPI.Use(auth.AuthRequired, spec.Validate)
+	{
+		sessionsAPI.GET("", auth.ListSessions)
+		sessionsAPI.DELETE("/:id", auth.RevokeSession)
+	}
+
+	identitiesAPI := r.Group("/identities")
+	identitiesAPI.Use(auth.AuthRequired, spec.Validate)
+	{
+		identitiesAPI.GET("", auth.ListIdentities)
+		identitiesAPI.POST("/:connector/start", auth.StartLinkIdentity)
+		identitiesAPI.POST("/:connector", auth.LinkIdentity)
+		identitiesAPI.DELETE("/:connector", auth.UnlinkIdentity)
+	}
+
+	tokensAPI := r.Group("/tokens")
+	tokensAPI.Use(auth.AuthRequired, auth.EmailVerifiedRequired("tokens"), spec.Validate)
+	{
+		tokensAPI.POST("", auth.CreateAccessToken)
+		tokensAPI.GET("", auth.ListAccessTokens)
+		tokensAPI.DELETE("/:id", auth.RevokeAccessToken)
+	}
+
+	// groups below also accept personal access tokens with matching scopes
 	notesAPI := r.Group("/note")
-	notesAPI.Use(auth.AuthRequired)
+	notesAPI.Use(auth.AllowAccessTokens("notes"), auth.AuthRequired, auth.EmailVerifiedRequired("notes"), spec.Validate)
 	{
 		notesAPI.GET("/listid", notes.ListIDs)
 		notesAPI.GET("/listnotes", notes.ListNotes)
@@ -27,18 +107,68 @@ func New(auth auth.AuthAPI, notes notes.NotesAPI, friends friends.FriendsAPI, ot
 	}
 
 	friendsAPI := r.Group("/friends")
-	friendsAPI.Use(auth.AuthRequired)
+	friendsAPI.Use(auth.AllowAccessTokens("friends"), auth.AuthRequired, auth.EmailVerifiedRequired("friends"), spec.Validate)
 	{
 		friendsAPI.POST("/add", friends.AddFriend)
 		friendsAPI.POST("/remove", friends.RemoveFriend)
 		friendsAPI.GET("/list", friends.ListFriend)
+		friendsAPI.POST("/follow", friends.Follow)
+		friendsAPI.POST("/unfollow", friends.Unfollow)
+		friendsAPI.GET("/followers", friends.ListFollowers)
+		friendsAPI.GET("/following", friends.ListFollowing)
+		friendsAPI.POST("/invite", friends.CreateInvite)
+		friendsAPI.GET("/invite", friends.ListInvites)
+		friendsAPI.GET("/invite/:token", friends.AcceptInvite)
+		friendsAPI.DELETE("/invite/:id", friends.RevokeInvite)
+	}
+
+	notificationsAPI := r.Group("/notifications")
+	notificationsAPI.Use(auth.AllowAccessTokens("notifications"), auth.AuthRequired, spec.Validate)
+	{
+		notificationsAPI.GET("", notifications.List)
+		notificationsAPI.POST("/read", notifications.MarkRead)
+	}
+
+	meAPI := r.Group("/me")
+	meAPI.Use(auth.AuthRequired, spec.Validate)
+	{
+		meAPI.GET("", users.Me)
+		meAPI.PATCH("", users.UpdateMe)
+		meAPI.DELETE("", users.DeleteMe)
+		meAPI.GET("/email", auth.GetEmail)
+		meAPI.POST("/email", auth.SetEmail)
+		meAPI.POST("/email/verification", auth.SendVerification)
+	}
+
+	// tokens are revoked once deletion starts, status is looked up by deletion ID
+	public.GET("/account/deletions/:id", users.Deletion)
+
+	usersAPI := r.Group("/users")
+	usersAPI.Use(auth.AuthRequired, spec.Validate)
+	{
+		usersAPI.GET("/search", users.Search)
+		usersAPI.GET("/:id", users.Get)
+		usersAPI.POST("/:id/block", users.Block)
+		usersAPI.DELETE("/:id/block", users.Unblock)
 	}
 
 	news := r.Group("/news")
-	news.Use(auth.AuthRequired)
+	news.Use(auth.AllowAccessTokens("notes"), auth.AuthRequired, auth.EmailVerifiedRequired("notes"), spec.Validate)
 	{
 		news.GET("", other.ListLastNotes)
 	}
 
+	adminAPI := r.Group("/admin")
+	adminAPI.Use(auth.AuthRequired, auth.AdminRequired, spec.Validate)
+	{
+		adminAPI.GET("/users", admin.ListUsers)
+		adminAPI.POST("/users/:id/disable", admin.DisableUser)
+		adminAPI.POST("/users/:id/enable", admin.EnableUser)
+		adminAPI.POST("/users/:id/logout", admin.ForceLogout)
+		adminAPI.PUT("/users/:id/role", admin.SetRole)
+		adminAPI.DELETE("/notes/:id", admin.DeleteNote)
+		adminAPI.GET("/audit", admin.AuditLog)
+	}
+
 	return r
 }
@@ -0,0 +1,87 @@
+package apiapp
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/liriquew/social-todo/api_service/internal/lib/config"
+	"github.com/liriquew/social-todo/api_service/internal/rest/admin"
+	"github.com/liriquew/social-todo/api_service/internal/rest/auth"
+	"github.com/liriquew/social-todo/api_service/internal/rest/friends"
+	"github.com/liriquew/social-todo/api_service/internal/rest/notes"
+	"github.com/liriquew/social-todo/api_service/internal/rest/notifications"
+	"github.com/liriquew/social-todo/api_service/internal/rest/openapi"
+	handlers "github.com/liriquew/social-todo/api_service/internal/rest/other"
+	"github.com/liriquew/social-todo/api_service/internal/rest/users"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_ValidateAfterAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	spec, err := openapi.New(log, config.OpenAPIConfig{})
+	require.NoError(t, err)
+
+	// requests below are rejected before any client is called
+	r := New(
+		spec,
+		auth.New(log, nil, nil, config.EmailVerificationConfig{}, config.ExternalLoginConfig{}),
+		notes.New(log, nil),
+		friends.New(log, nil, nil),
+		notifications.New(log, nil),
+		users.New(log, nil),
+		handlers.New(log, nil, nil, nil),
+		admin.New(log, nil, nil),
+	)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{
+			name:   "malformed body of protected route",
+			method: http.MethodPost,
+			target: "/friends/add",
+			body:   `{"friendID": "abc"`,
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "invalid path parameter of protected route",
+			method: http.MethodGet,
+			target: "/note/abc",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "admin route",
+			method: http.MethodDelete,
+			target: "/admin/notes/0",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "public route is validated",
+			method: http.MethodPost,
+			target: "/signup",
+			body:   `{}`,
+			status: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+}
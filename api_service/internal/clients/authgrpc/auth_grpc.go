@@ -4,12 +4,17 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/liriquew/social-todo/api_service/internal/lib/config"
+	"github.com/liriquew/social-todo/api_service/internal/models"
 	"github.com/liriquew/todoprotos/gen/go/sso"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	grpclog "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
@@ -22,14 +27,31 @@ type Client struct {
 }
 
 var (
-	ErrMissJWTToken = fmt.Errorf("miss JWT auth token")
+	ErrMissJWTToken    = fmt.Errorf("miss JWT auth token")
+	ErrUnauthenticated = fmt.Errorf("invalid or expired token")
+	ErrWrongPassword   = fmt.Errorf("wrong password")
+	ErrInvalidCreds    = fmt.Errorf("invalid username or password")
+	ErrInvalidCode     = fmt.Errorf("invalid code")
+	ErrTwoFactorState  = fmt.Errorf("two-factor authentication already enabled or not enrolled")
+	ErrInvalidReset    = fmt.Errorf("invalid or expired reset token")
+	ErrTooManyTokens   = fmt.Errorf("too many access tokens")
+	ErrForbidden       = fmt.Errorf("admin role required")
+	ErrUserDisabled    = fmt.Errorf("account disabled")
+	ErrSelfAction      = fmt.Errorf("can't apply to own account")
+	ErrExternalAuth    = fmt.Errorf("external authentication failed")
+	ErrIdentityExist   = fmt.Errorf("identity already linked")
+	ErrEmailState      = fmt.Errorf("email is not set or already verified")
+	ErrEmailTaken      = fmt.Errorf("email is used by another account")
+	ErrInvalidVerify   = fmt.Errorf("invalid or expired verification token")
+	ErrTooManyRequests = fmt.Errorf("verification was sent recently")
 )
 
 func New(log *slog.Logger, cfg config.ServiceConfig) (*Client, error) {
 	const op = "auth_grpc.New"
 
 	retryOpts := []grpcretry.CallOption{
-		grpcretry.WithCodes(codes.NotFound, codes.Aborted, codes.DeadlineExceeded),
+		// NotFound is not retried: a retried failed login would be counted twice by lockout
+		grpcretry.WithCodes(codes.Aborted, codes.DeadlineExceeded),
 		grpcretry.WithMax(uint(cfg.Retries)),
 		grpcretry.WithPerRetryTimeout(cfg.Timeout),
 	}
@@ -61,15 +83,107 @@ func InterceptorLogger(log *slog.Logger) grpclog.Logger {
 	})
 }
 
+// PasswordPolicyError lists password policy rules the password violates.
+type PasswordPolicyError struct {
+	Violations []models.PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "password does not satisfy policy"
+}
+
+// invalidArgument returns *PasswordPolicyError if status carries
+// policy violations and ErrInvalidArgument otherwise.
+func invalidArgument(st *status.Status) error {
+	var violations []models.PasswordViolation
+	for _, d := range st.Details() {
+		br, ok := d.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+		for _, v := range br.GetFieldViolations() {
+			if rule, ok := strings.CutPrefix(v.GetField(), "password."); ok {
+				violations = append(violations, models.PasswordViolation{
+					Rule:        rule,
+					Description: v.GetDescription(),
+				})
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return ErrInvalidArgument
+}
+
+// ProfileError tells which profile field sso_service rejected.
+type ProfileError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ProfileError) Error() string {
+	return fmt.Sprintf("invalid profile: %s %s", e.Field, e.Reason)
+}
+
+func profileError(st *status.Status) error {
+	for _, d := range st.Details() {
+		br, ok := d.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+		for _, v := range br.GetFieldViolations() {
+			return &ProfileError{Field: v.GetField(), Reason: v.GetDescription()}
+		}
+	}
+	return ErrInvalidArgument
+}
+
+// RateLimitError means login is locked after too many failed attempts.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, retry after %s", e.RetryAfter)
+}
+
+func rateLimited(st *status.Status) error {
+	for _, d := range st.Details() {
+		if ri, ok := d.(*errdetails.RetryInfo); ok {
+			return &RateLimitError{RetryAfter: ri.GetRetryDelay().AsDuration()}
+		}
+	}
+	return &RateLimitError{}
+}
+
+// Metadata keys end-user address and User-Agent are passed to sso_service in.
+const (
+	ClientIPKey  = "x-client-ip"
+	UserAgentKey = "x-client-user-agent"
+)
+
+// withClient passes end-user device to sso_service, it's used by login
+// lockout and shown in sessions list.
+func withClient(ctx context.Context, client models.Client) context.Context {
+	return metadata.AppendToOutgoingContext(ctx,
+		ClientIPKey, client.IP,
+		UserAgentKey, client.UserAgent,
+	)
+}
+
 var (
 	ErrNotFound        = fmt.Errorf("user not found")
 	ErrAlreadyExists   = fmt.Errorf("user already exists")
 	ErrInvalidArgument = fmt.Errorf("invalid argument")
 )
 
-func (c *Client) Login(ctx context.Context, username, password string) (string, error) {
+func (c *Client) Login(ctx context.Context, username, password string, client models.Client) (models.TokenPair, error) {
 	const op = "auth_grpc.Login"
 
+	ctx = withClient(ctx, client)
+
 	resp, err := c.api.Login(ctx, &sso.LoginRequest{
 		Username: username,
 		Password: password,
@@ -78,23 +192,168 @@ func (c *Client) Login(ctx context.Context, username, password string) (string,
 		if st, ok := status.FromError(err); ok {
 			switch st.Code() {
 			case codes.NotFound:
-				return "", ErrNotFound
+				return models.TokenPair{}, ErrNotFound
+			case codes.Unauthenticated:
+				return models.TokenPair{}, ErrInvalidCreds
+			case codes.ResourceExhausted:
+				return models.TokenPair{}, rateLimited(st)
+			case codes.InvalidArgument:
+				return models.TokenPair{}, ErrInvalidArgument
+			case codes.PermissionDenied:
+				return models.TokenPair{}, ErrUserDisabled
+			}
+		}
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return models.TokenPair{
+		Token:             resp.Token,
+		RefreshToken:      resp.RefreshToken,
+		TwoFactorRequired: resp.TwoFactorRequired,
+		ChallengeToken:    resp.ChallengeToken,
+	}, nil
+}
+
+func (c *Client) CompleteLogin(ctx context.Context, challengeToken, code string, client models.Client) (models.TokenPair, error) {
+	const op = "auth_grpc.CompleteLogin"
+
+	ctx = withClient(ctx, client)
+
+	resp, err := c.api.CompleteLogin(ctx, &sso.CompleteLoginRequest{
+		ChallengeToken: challengeToken,
+		Code:           code,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.Unauthenticated:
+				return models.TokenPair{}, ErrUnauthenticated
+			case codes.ResourceExhausted:
+				return models.TokenPair{}, rateLimited(st)
+			case codes.InvalidArgument:
+				return models.TokenPair{}, ErrInvalidArgument
+			case codes.PermissionDenied:
+				return models.TokenPair{}, ErrUserDisabled
+			}
+		}
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return models.TokenPair{
+		Token:        resp.Token,
+		RefreshToken: resp.RefreshToken,
+	}, nil
+}
+
+func (c *Client) EnrollTOTP(ctx context.Context, token string) (models.TOTPEnrollment, error) {
+	const op = "auth_grpc.EnrollTOTP"
+
+	resp, err := c.api.EnrollTOTP(ctx, &sso.EnrollTOTPRequest{
+		Token: token,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.Unauthenticated:
+				return models.TOTPEnrollment{}, ErrUnauthenticated
+			case codes.FailedPrecondition:
+				return models.TOTPEnrollment{}, ErrTwoFactorState
+			}
+		}
+		return models.TOTPEnrollment{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return models.TOTPEnrollment{
+		Secret: resp.Secret,
+		URI:    resp.Uri,
+	}, nil
+}
+
+func (c *Client) ConfirmTOTP(ctx context.Context, token, code string) ([]string, error) {
+	const op = "auth_grpc.ConfirmTOTP"
+
+	resp, err := c.api.ConfirmTOTP(ctx, &sso.ConfirmTOTPRequest{
+		Token: token,
+		Code:  code,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.Unauthenticated:
+				return nil, ErrUnauthenticated
+			case codes.InvalidArgument:
+				return nil, ErrInvalidCode
+			case codes.FailedPrecondition:
+				return nil, ErrTwoFactorState
+			}
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp.RecoveryCodes, nil
+}
+
+func (c *Client) DisableTOTP(ctx context.Context, token, password string) error {
+	const op = "auth_grpc.DisableTOTP"
+
+	_, err := c.api.DisableTOTP(ctx, &sso.DisableTOTPRequest{
+		Token:    token,
+		Password: password,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.Unauthenticated:
+				return ErrUnauthenticated
+			case codes.PermissionDenied:
+				return ErrWrongPassword
+			case codes.FailedPrecondition:
+				return ErrTwoFactorState
+			case codes.InvalidArgument:
+				return ErrInvalidArgument
+			}
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (c *Client) Refresh(ctx context.Context, refreshToken string, client models.Client) (models.TokenPair, error) {
+	const op = "auth_grpc.Refresh"
+
+	ctx = withClient(ctx, client)
+
+	resp, err := c.api.Refresh(ctx, &sso.RefreshRequest{
+		RefreshToken: refreshToken,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.Unauthenticated:
+				return models.TokenPair{}, ErrUnauthenticated
 			case codes.InvalidArgument:
-				return "", ErrInvalidArgument
+				return models.TokenPair{}, ErrInvalidArgument
+			case codes.PermissionDenied:
+				return models.TokenPair{}, ErrUserDisabled
 			}
 		}
-		return "", fmt.Errorf("%s: %w", op, err)
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return resp.Token, nil
+	return models.TokenPair{
+		Token:        resp.Token,
+		RefreshToken: resp.RefreshToken,
+	}, nil
 }
 
-func (c *Client) Register(ctx context.Context, username, password string) (int64, error) {
+func (c *Client) Register(ctx context.Context, username, password, email string) (int64, error) {
 	const op = "auth_grpc.Login"
 
 	resp, err := c.api.Register(ctx, &sso.RegisterRequest{
 		Username: username,
 		Password: password,
+		Email:    email,
 	})
 	if err != nil {
 		if st, ok := status.FromError(err); ok {
@@ -102,7 +361,7 @@ func (c *Client) Register(ctx context.Context, username, password string) (int64
 			case codes.AlreadyExists:
 				return 0, ErrAlreadyExists
 			case codes.InvalidArgument:
-				return 0, ErrInvalidArgument
+				return 0, invalidArgument(st)
 			}
 		}
 		return 0, fmt.Errorf("%s: %w", op, err)
@@ -111,18 +370,1001 @@ func (c *Client) Register(ctx context.Context, username, password string) (int64
 	return resp.Uid, nil
 }
 
-func (c *Client) Authorize(ctx context.Context, token string) (int64, error) {
+func (c *Client) Authorize(ctx context.Context, token string) (models.Principal, error) {
 	const op = "auth_grpc.Authorize"
 
 	resp, err := c.api.Authorize(ctx, &sso.AuthorizeRequest{
 		Token: token,
 	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.InvalidArgument:
+				return models.Principal{}, ErrMissJWTToken
+			case codes.Unauthenticated:
+				return models.Principal{}, ErrUnauthenticated
+			}
+		}
+		return models.Principal{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return models.Principal{
+		UID:           resp.Uid,
+		Role:          resp.Role,
+		EmailVerified: resp.EmailVerified,
+	}, nil
+}
+
+func (c *Client) Logout(ctx context.Context, token, refreshToken string) error {
+	const op = "auth_grpc.Logout"
+
+	_, err := c.api.Logout(ctx, &sso.LogoutRequest{
+		Token:        token,
+		RefreshToken: refreshToken,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.InvalidArgument:
+				return ErrMissJWTToken
+			case codes.Unauthenticated:
+				return ErrUnauthenticated
+			}
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (c *Client) LogoutAll(ctx context.Context, token string) error {
+	const op = "auth_grpc.LogoutAll"
+
+	_, err := c.api.LogoutAll(ctx, &sso.LogoutAllRequest{
+		Token: token,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.InvalidArgument:
+				return ErrMissJWTToken
+			case codes.Unauthenticated:
+				return ErrUnauthenticated
+			}
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (c *Client) ChangePassword(ctx context.Context, token, oldPassword, newPassword string) error {
+	const op = "auth_grpc.ChangePassword"
+
+	_, err := c.api.ChangePassword(ctx, &sso.ChangePasswordRequest{
+		Token:       token,
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.InvalidArgument:
+				return invalidArgument(st)
+			case codes.Unauthenticated:
+				return ErrUnauthenticated
+			case codes.PermissionDenied:
+				return ErrWrongPassword
+			}
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (c *Client) RequestPasswordReset(ctx context.Context, username string) error {
+	const op = "auth_grpc.RequestPasswordReset"
+
+	_, err := c.api.RequestPasswordReset(ctx, &sso.RequestPasswordResetRequest{
+		Username: username,
+	})
 	if err != nil {
 		if st, ok := status.FromError(err); ok && st.Code() == codes.InvalidArgument {
-			return 0, ErrMissJWTToken
+			return ErrInvalidArgument
 		}
-		return 0, fmt.Errorf("%s: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	return resp.Uid, nil
+	return nil
+}
+
+func (c *Client) ResetPassword(ctx context.Context, resetToken, newPassword string) error {
+	const op = "auth_grpc.ResetPassword"
+
+	_, err := c.api.ResetPassword(ctx, &sso.ResetPasswordRequest{
+		ResetToken:  resetToken,
+		NewPassword: newPassword,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.InvalidArgument:
+				return invalidArgument(st)
+			case codes.FailedPrecondition:
+				return ErrInvalidReset
+			}
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Revocations returns token revocations made after since (unix milli).
+func (c *Client) Revocations(ctx context.Context, since int64) (models.Revocations, error) {
+	const op = "auth_grpc.Revocations"
+
+	resp, err := c.api.ListRevocations(ctx, &sso.ListRevocationsRequest{
+		Since: since,
+	})
+	if err != nil {
+		return models.Revocations{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	revs := models.Revocations{
+		Tokens: make([]models.RevokedToken, 0, len(resp.Tokens)),
+		Users:  make([]models.UserRevocation, 0, len(resp.Users)),
+		Until:  resp.Until,
+	}
+	for _, token := range resp.Tokens {
+		revs.Tokens = append(revs.Tokens, models.RevokedToken{
+			JTI:       token.Jti,
+			ExpiresAt: token.ExpiresAt,
+		})
+	}
+	for _, user := range resp.Users {
+		revs.Users = append(revs.Users, models.UserRevocation{
+			UID:           user.Uid,
+			RevokedBefore: user.RevokedBefore,
+		})
+	}
+
+	return revs, nil
+}
+
+func (c *Client) Users(ctx context.Context, UIDs []int64) ([]*models.UserInfo, error) {
+	const op = "auth_grpc.Users"
+
+	resp, err := c.api.GetUsers(ctx, &sso.GetUsersRequest{
+		Uids: UIDs,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok && st.Code() == codes.InvalidArgument {
+			return nil, ErrInvalidArgument
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	users := make([]*models.UserInfo, 0, len(resp.Users))
+	for _, user := range resp.Users {
+		users = append(users, userFromProto(user))
+	}
+
+	return users, nil
+}
+
+func (c *Client) Profile(ctx context.Context, UID int64) (*models.Profile, error) {
+	const op = "auth_grpc.Profile"
+
+	resp, err := c.api.GetProfile(ctx, &sso.GetProfileRequest{
+		Uid: UID,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.NotFound:
+				return nil, ErrNotFound
+			case codes.InvalidArgument:
+				return nil, ErrInvalidArgument
+			}
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return profileFromProto(resp.Profile), nil
+}
+
+func (c *Client) Profiles(ctx context.Context, UIDs []int64) ([]*models.Profile, error) {
+	const op = "auth_grpc.Profiles"
+
+	resp, err := c.api.BatchGetProfiles(ctx, &sso.BatchGetProfilesRequest{
+		Uids: UIDs,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok && st.Code() == codes.InvalidArgument {
+			return nil, ErrInvalidArgument
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	profiles := make([]*models.Profile, 0, len(resp.Profiles))
+	for _, profile := range resp.Profiles {
+		profiles = append(profiles, profileFromProto(profile))
+	}
+
+	return profiles, nil
+}
+
+func (c *Client) UpdateProfile(ctx context.Context, token string, update models.ProfileUpdate) (*models.Profile, error) {
+	const op = "auth_grpc.UpdateProfile"
+
+	resp, err := c.api.UpdateProfile(ctx, &sso.UpdateProfileRequest{
+		Token:       token,
+		DisplayName: update.DisplayName,
+		Bio:         update.Bio,
+		AvatarUrl:   update.AvatarURL,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.InvalidArgument:
+				return nil, profileError(st)
+			case codes.Unauthenticated:
+				return nil, ErrUnauthenticated
+			case codes.NotFound:
+				return nil, ErrNotFound
+			}
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return profileFromProto(resp.Profile), nil
+}
+
+func profileFromProto(p *sso.Profile) *models.Profile {
+	profile := &models.Profile{
+		UID:         p.Uid,
+		Username:    p.Username,
+		DisplayName: p.DisplayName,
+		Bio:         p.Bio,
+		AvatarURL:   p.AvatarUrl,
+	}
+	if p.UpdatedAt > 0 {
+		updatedAt := time.Unix(p.UpdatedAt, 0).UTC()
+		profile.UpdatedAt = &updatedAt
+	}
+	return profile
+}
+
+// SearchUsers returns page of users matching query and cursor of the next one.
+func (c *Client) SearchUsers(ctx context.Context, token, query, cursor string, limit int64) (*models.UsersPage, error) {
+	const op = "auth_grpc.SearchUsers"
+
+	resp, err := c.api.SearchUsers(ctx, &sso.SearchUsersRequest{
+		Token:  token,
+		Query:  query,
+		Cursor: cursor,
+		Limit:  limit,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.InvalidArgument:
+				return nil, fmt.Errorf("%w: %s", ErrInvalidArgument, st.Message())
+			case codes.Unauthenticated:
+				return nil, ErrUnauthenticated
+			}
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	page := &models.UsersPage{
+		Users:      make([]*models.UserInfo, 0, len(resp.Users)),
+		NextCursor: resp.NextCursor,
+	}
+	for _, user := range resp.Users {
+		page.Users = append(page.Users, userFromProto(user))
+	}
+
+	return page, nil
+}
+
+// LookupUser resolves exact username, ErrNotFound is returned
+// for users blocked either way.
+func (c *Client) LookupUser(ctx context.Context, token, username string) (*models.UserInfo, error) {
+	const op = "auth_grpc.LookupUser"
+
+	resp, err := c.api.LookupUser(ctx, &sso.LookupUserRequest{
+		Token:    token,
+		Username: username,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.NotFound:
+				return nil, ErrNotFound
+			case codes.InvalidArgument:
+				return nil, ErrInvalidArgument
+			case codes.Unauthenticated:
+				return nil, ErrUnauthenticated
+			}
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userFromProto(resp.User), nil
+}
+
+func (c *Client) BlockUser(ctx context.Context, token string, UID int64) error {
+	const op = "auth_grpc.BlockUser"
+
+	_, err := c.api.BlockUser(ctx, &sso.BlockUserRequest{
+		Token: token,
+		Uid:   UID,
+	})
+	if err != nil {
+		return blockError(op, err)
+	}
+
+	return nil
+}
+
+func (c *Client) UnblockUser(ctx context.Context, token string, UID int64) error {
+	const op = "auth_grpc.UnblockUser"
+
+	_, err := c.api.UnblockUser(ctx, &sso.UnblockUserRequest{
+		Token: token,
+		Uid:   UID,
+	})
+	if err != nil {
+		return blockError(op, err)
+	}
+
+	return nil
+}
+
+func blockError(op string, err error) error {
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.NotFound:
+			return ErrNotFound
+		case codes.InvalidArgument:
+			return fmt.Errorf("%w: %s", ErrInvalidArgument, st.Message())
+		case codes.Unauthenticated:
+			return ErrUnauthenticated
+		}
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+// DeleteAccount starts account deletion, user's data is removed
+// in background.
+func (c *Client) DeleteAccount(ctx context.Context, token, password string) (*models.AccountDeletion, error) {
+	const op = "auth_grpc.DeleteAccount"
+
+	resp, err := c.api.DeleteAccount(ctx, &sso.DeleteAccountRequest{
+		Token:    token,
+		Password: password,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.Unauthenticated:
+				return nil, ErrUnauthenticated
+			case codes.PermissionDenied:
+				return nil, ErrWrongPassword
+			case codes.NotFound:
+				return nil, ErrNotFound
+			case codes.InvalidArgument:
+				return nil, ErrInvalidArgument
+			}
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deletionFromProto(resp), nil
+}
+
+func (c *Client) AccountDeletion(ctx context.Context, ID string) (*models.AccountDeletion, error) {
+	const op = "auth_grpc.AccountDeletion"
+
+	resp, err := c.api.GetAccountDeletion(ctx, &sso.GetAccountDeletionRequest{
+		DeletionId: ID,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.NotFound:
+				return nil, ErrNotFound
+			case codes.InvalidArgument:
+				return nil, ErrInvalidArgument
+			}
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deletionFromProto(resp), nil
+}
+
+func deletionFromProto(resp *sso.AccountDeletionResponse) *models.AccountDeletion {
+	d := &models.AccountDeletion{
+		ID:        resp.DeletionId,
+		Status:    resp.Status,
+		CreatedAt: time.Unix(resp.CreatedAt, 0).UTC(),
+	}
+	if resp.CompletedAt > 0 {
+		completedAt := time.Unix(resp.CompletedAt, 0).UTC()
+		d.CompletedAt = &completedAt
+	}
+	return d
+}
+
+func (c *Client) CreateAccessToken(ctx context.Context, token string, req models.CreateAccessTokenRequest) (*models.CreatedAccessToken, error) {
+	const op = "auth_grpc.CreateAccessToken"
+
+	resp, err := c.api.CreateAccessToken(ctx, &sso.CreateAccessTokenRequest{
+		Token:     token,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresIn: req.ExpiresIn,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.InvalidArgument:
+				return nil, fmt.Errorf("%w: %s", ErrInvalidArgument, st.Message())
+			case codes.Unauthenticated:
+				return nil, ErrUnauthenticated
+			case codes.ResourceExhausted:
+				return nil, ErrTooManyTokens
+			}
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &models.CreatedAccessToken{
+		AccessToken: resp.AccessToken,
+		Info:        accessTokenFromProto(resp.Info),
+	}, nil
+}
+
+func (c *Client) AccessTokens(ctx context.Context, token string) ([]*models.AccessToken, error) {
+	const op = "auth_grpc.AccessTokens"
+
+	resp, err := c.api.ListAccessTokens(ctx, &sso.ListAccessTokensRequest{
+		Token: token,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok && st.Code() == codes.Unauthenticated {
+			return nil, ErrUnauthenticated
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	list := make([]*models.AccessToken, 0, len(resp.Tokens))
+	for _, t := range resp.Tokens {
+		list = append(list, accessTokenFromProto(t))
+	}
+
+	return list, nil
+}
+
+func (c *Client) RevokeAccessToken(ctx context.Context, token string, ID int64) error {
+	const op = "auth_grpc.RevokeAccessToken"
+
+	_, err := c.api.RevokeAccessToken(ctx, &sso.RevokeAccessTokenRequest{
+		Token: token,
+		Id:    ID,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.NotFound:
+				return ErrNotFound
+			case codes.InvalidArgument:
+				return ErrInvalidArgument
+			case codes.Unauthenticated:
+				return ErrUnauthenticated
+			}
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// AuthorizeAccessToken returns owner and scopes of personal access token.
+// Access tokens carry no role.
+func (c *Client) AuthorizeAccessToken(ctx context.Context, token string) (models.Principal, []string, error) {
+	const op = "auth_grpc.AuthorizeAccessToken"
+
+	resp, err := c.api.AuthorizeAccessToken(ctx, &sso.AuthorizeAccessTokenRequest{
+		AccessToken: token,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.InvalidArgument:
+				return models.Principal{}, nil, ErrMissJWTToken
+			case codes.Unauthenticated:
+				return models.Principal{}, nil, ErrUnauthenticated
+			}
+		}
+		return models.Principal{}, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	principal := models.Principal{UID: resp.Uid, EmailVerified: resp.EmailVerified}
+	return principal, resp.Scopes, nil
+}
+
+func accessTokenFromProto(t *sso.AccessTokenInfo) *models.AccessToken {
+	token := &models.AccessToken{
+		ID:        t.Id,
+		Name:      t.Name,
+		Scopes:    t.Scopes,
+		CreatedAt: time.Unix(t.CreatedAt, 0).UTC(),
+	}
+	if t.ExpiresAt > 0 {
+		expiresAt := time.Unix(t.ExpiresAt, 0).UTC()
+		token.ExpiresAt = &expiresAt
+	}
+	if t.LastUsedAt > 0 {
+		lastUsedAt := time.Unix(t.LastUsedAt, 0).UTC()
+		token.LastUsedAt = &lastUsedAt
+	}
+	return token
+}
+
+func (c *Client) Sessions(ctx context.Context, token string) ([]*models.Session, error) {
+	const op = "auth_grpc.Sessions"
+
+	resp, err := c.api.ListSessions(ctx, &sso.ListSessionsRequest{
+		Token: token,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok && st.Code() == codes.Unauthenticated {
+			return nil, ErrUnauthenticated
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sessions := make([]*models.Session, 0, len(resp.Sessions))
+	for _, session := range resp.Sessions {
+		sessions = append(sessions, &models.Session{
+			ID:         session.Id,
+			UserAgent:  session.UserAgent,
+			IP:         session.Ip,
+			CreatedAt:  time.Unix(session.CreatedAt, 0).UTC(),
+			LastSeenAt: time.Unix(session.LastSeenAt, 0).UTC(),
+			Current:    session.Current,
+		})
+	}
+
+	return sessions, nil
+}
+
+func (c *Client) RevokeSession(ctx context.Context, token, ID string) error {
+	const op = "auth_grpc.RevokeSession"
+
+	_, err := c.api.RevokeSession(ctx, &sso.RevokeSessionRequest{
+		Token:     token,
+		SessionId: ID,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.NotFound:
+				return ErrNotFound
+			case codes.InvalidArgument:
+				return ErrInvalidArgument
+			case codes.Unauthenticated:
+				return ErrUnauthenticated
+			}
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func userFromProto(user *sso.UserInfo) *models.UserInfo {
+	return &models.UserInfo{
+		UID:         user.Uid,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+	}
+}
+
+// adminError maps status codes shared by admin RPCs.
+func adminError(op string, err error) error {
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.InvalidArgument:
+			return fmt.Errorf("%w: %s", ErrInvalidArgument, st.Message())
+		case codes.Unauthenticated:
+			return ErrUnauthenticated
+		case codes.PermissionDenied:
+			return ErrForbidden
+		case codes.FailedPrecondition:
+			return ErrSelfAction
+		case codes.NotFound:
+			return ErrNotFound
+		}
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+func (c *Client) ListUsers(ctx context.Context, token, cursor string, limit int64) (*models.AdminUsersPage, error) {
+	const op = "auth_grpc.ListUsers"
+
+	resp, err := c.api.ListUsers(ctx, &sso.ListUsersRequest{
+		Token:  token,
+		Cursor: cursor,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, adminError(op, err)
+	}
+
+	page := &models.AdminUsersPage{
+		Users:      make([]*models.AdminUser, 0, len(resp.Users)),
+		NextCursor: resp.NextCursor,
+	}
+	for _, user := range resp.Users {
+		info := &models.AdminUser{
+			UID:         user.Uid,
+			Username:    user.Username,
+			DisplayName: user.DisplayName,
+			Role:        user.Role,
+			CreatedAt:   time.Unix(user.CreatedAt, 0).UTC(),
+		}
+		if user.DisabledAt > 0 {
+			disabledAt := time.Unix(user.DisabledAt, 0).UTC()
+			info.DisabledAt = &disabledAt
+		}
+		page.Users = append(page.Users, info)
+	}
+
+	return page, nil
+}
+
+func (c *Client) SetUserDisabled(ctx context.Context, token string, UID int64, disabled bool) error {
+	const op = "auth_grpc.SetUserDisabled"
+
+	_, err := c.api.SetUserDisabled(ctx, &sso.SetUserDisabledRequest{
+		Token:    token,
+		Uid:      UID,
+		Disabled: disabled,
+	})
+	if err != nil {
+		return adminError(op, err)
+	}
+
+	return nil
+}
+
+func (c *Client) ForceLogout(ctx context.Context, token string, UID int64) error {
+	const op = "auth_grpc.ForceLogout"
+
+	_, err := c.api.ForceLogout(ctx, &sso.ForceLogoutRequest{
+		Token: token,
+		Uid:   UID,
+	})
+	if err != nil {
+		return adminError(op, err)
+	}
+
+	return nil
+}
+
+func (c *Client) SetUserRole(ctx context.Context, token string, UID int64, role string) error {
+	const op = "auth_grpc.SetUserRole"
+
+	_, err := c.api.SetUserRole(ctx, &sso.SetUserRoleRequest{
+		Token: token,
+		Uid:   UID,
+		Role:  role,
+	})
+	if err != nil {
+		return adminError(op, err)
+	}
+
+	return nil
+}
+
+// AuthorizeAdminAction checks admin role of the token owner before an
+// action outside of sso_service and records the request.
+func (c *Client) AuthorizeAdminAction(ctx context.Context, token, action, target, details string) error {
+	const op = "auth_grpc.AuthorizeAdminAction"
+
+	_, err := c.api.AuthorizeAdminAction(ctx, &sso.AuthorizeAdminActionRequest{
+		Token:   token,
+		Action:  action,
+		Target:  target,
+		Details: details,
+	})
+	if err != nil {
+		return adminError(op, err)
+	}
+
+	return nil
+}
+
+// RecordAdminAction audits admin action performed outside of sso_service
+// with its result, it's called after the action.
+func (c *Client) RecordAdminAction(ctx context.Context, token, action, target, details, result string) error {
+	const op = "auth_grpc.RecordAdminAction"
+
+	_, err := c.api.RecordAdminAction(ctx, &sso.RecordAdminActionRequest{
+		Token:   token,
+		Action:  action,
+		Target:  target,
+		Details: details,
+		Result:  result,
+	})
+	if err != nil {
+		return adminError(op, err)
+	}
+
+	return nil
+}
+
+func (c *Client) AuditLog(ctx context.Context, token, cursor string, limit int64) (*models.AuditLogPage, error) {
+	const op = "auth_grpc.AuditLog"
+
+	resp, err := c.api.ListAuditLog(ctx, &sso.ListAuditLogRequest{
+		Token:  token,
+		Cursor: cursor,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, adminError(op, err)
+	}
+
+	page := &models.AuditLogPage{
+		Entries:    make([]*models.AuditEntry, 0, len(resp.Entries)),
+		NextCursor: resp.NextCursor,
+	}
+	for _, entry := range resp.Entries {
+		page.Entries = append(page.Entries, &models.AuditEntry{
+			ID:        entry.Id,
+			ActorUID:  entry.ActorUid,
+			Action:    entry.Action,
+			Target:    entry.Target,
+			Details:   entry.Details,
+			Result:    entry.Result,
+			CreatedAt: time.Unix(entry.CreatedAt, 0).UTC(),
+		})
+	}
+
+	return page, nil
+}
+
+// externalError maps status codes shared by external login RPCs.
+// Unauthenticated means invalid token if the RPC takes one.
+func externalError(op string, err error) error {
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.InvalidArgument:
+			return fmt.Errorf("%w: %s", ErrInvalidArgument, st.Message())
+		case codes.Unauthenticated:
+			return ErrUnauthenticated
+		case codes.NotFound:
+			return ErrNotFound
+		case codes.AlreadyExists:
+			return ErrIdentityExist
+		case codes.PermissionDenied:
+			return ErrUserDisabled
+		}
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+func (c *Client) Connectors(ctx context.Context) ([]*models.Connector, error) {
+	const op = "auth_grpc.Connectors"
+
+	resp, err := c.api.ListConnectors(ctx, &sso.ListConnectorsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	connectors := make([]*models.Connector, 0, len(resp.Connectors))
+	for _, conn := range resp.Connectors {
+		connectors = append(connectors, &models.Connector{ID: conn.Id, Name: conn.Name})
+	}
+
+	return connectors, nil
+}
+
+func (c *Client) StartExternalLogin(ctx context.Context, connectorID string) (string, error) {
+	const op = "auth_grpc.StartExternalLogin"
+
+	resp, err := c.api.StartExternalLogin(ctx, &sso.StartExternalLoginRequest{
+		ConnectorId: connectorID,
+	})
+	if err != nil {
+		return "", externalError(op, err)
+	}
+
+	return resp.AuthUrl, nil
+}
+
+// ExternalLogin exchanges code and state provider redirected the user with
+// for token pair. ErrUnauthenticated here means provider rejected the code.
+func (c *Client) ExternalLogin(ctx context.Context, connectorID, state, code string, client models.Client) (models.TokenPair, error) {
+	const op = "auth_grpc.ExternalLogin"
+
+	ctx = withClient(ctx, client)
+
+	resp, err := c.api.ExternalLogin(ctx, &sso.ExternalLoginRequest{
+		ConnectorId: connectorID,
+		State:       state,
+		Code:        code,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok && st.Code() == codes.Unauthenticated {
+			return models.TokenPair{}, ErrExternalAuth
+		}
+		return models.TokenPair{}, externalError(op, err)
+	}
+
+	return models.TokenPair{
+		Token:             resp.Token,
+		RefreshToken:      resp.RefreshToken,
+		TwoFactorRequired: resp.TwoFactorRequired,
+		ChallengeToken:    resp.ChallengeToken,
+	}, nil
+}
+
+func (c *Client) StartLinkIdentity(ctx context.Context, token, connectorID string) (string, error) {
+	const op = "auth_grpc.StartLinkIdentity"
+
+	resp, err := c.api.StartLinkIdentity(ctx, &sso.StartLinkIdentityRequest{
+		Token:       token,
+		ConnectorId: connectorID,
+	})
+	if err != nil {
+		return "", externalError(op, err)
+	}
+
+	return resp.AuthUrl, nil
+}
+
+func (c *Client) LinkIdentity(ctx context.Context, token, connectorID, state, code string) (*models.Identity, error) {
+	const op = "auth_grpc.LinkIdentity"
+
+	resp, err := c.api.LinkIdentity(ctx, &sso.LinkIdentityRequest{
+		Token:       token,
+		ConnectorId: connectorID,
+		State:       state,
+		Code:        code,
+	})
+	if err != nil {
+		return nil, externalError(op, err)
+	}
+
+	return identityFromProto(resp.Identity), nil
+}
+
+func (c *Client) UnlinkIdentity(ctx context.Context, token, connectorID string) error {
+	const op = "auth_grpc.UnlinkIdentity"
+
+	_, err := c.api.UnlinkIdentity(ctx, &sso.UnlinkIdentityRequest{
+		Token:       token,
+		ConnectorId: connectorID,
+	})
+	if err != nil {
+		return externalError(op, err)
+	}
+
+	return nil
+}
+
+func (c *Client) Identities(ctx context.Context, token string) ([]*models.Identity, error) {
+	const op = "auth_grpc.Identities"
+
+	resp, err := c.api.ListIdentities(ctx, &sso.ListIdentitiesRequest{
+		Token: token,
+	})
+	if err != nil {
+		return nil, externalError(op, err)
+	}
+
+	identities := make([]*models.Identity, 0, len(resp.Identities))
+	for _, identity := range resp.Identities {
+		identities = append(identities, identityFromProto(identity))
+	}
+
+	return identities, nil
+}
+
+func identityFromProto(i *sso.Identity) *models.Identity {
+	identity := &models.Identity{
+		ConnectorID: i.ConnectorId,
+		Subject:     i.Subject,
+		Username:    i.Username,
+		Email:       i.Email,
+		CreatedAt:   time.Unix(i.CreatedAt, 0).UTC(),
+	}
+	if i.LastLoginAt != 0 {
+		lastLoginAt := time.Unix(i.LastLoginAt, 0).UTC()
+		identity.LastLoginAt = &lastLoginAt
+	}
+	return identity
+}
+
+func emailError(op string, err error) error {
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.InvalidArgument:
+			return fmt.Errorf("%w: %s", ErrInvalidArgument, st.Message())
+		case codes.Unauthenticated:
+			return ErrUnauthenticated
+		case codes.FailedPrecondition:
+			return ErrEmailState
+		case codes.AlreadyExists:
+			return ErrEmailTaken
+		case codes.ResourceExhausted:
+			return ErrTooManyRequests
+		}
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+func (c *Client) Email(ctx context.Context, token string) (*models.Email, error) {
+	const op = "auth_grpc.Email"
+
+	resp, err := c.api.GetEmail(ctx, &sso.GetEmailRequest{
+		Token: token,
+	})
+	if err != nil {
+		return nil, emailError(op, err)
+	}
+
+	return &models.Email{Email: resp.Email, Verified: resp.Verified}, nil
+}
+
+// SetEmail changes user's email, verification is mailed to it.
+func (c *Client) SetEmail(ctx context.Context, token, email string) error {
+	const op = "auth_grpc.SetEmail"
+
+	_, err := c.api.SetEmail(ctx, &sso.SetEmailRequest{
+		Token: token,
+		Email: email,
+	})
+	if err != nil {
+		return emailError(op, err)
+	}
+
+	return nil
+}
+
+func (c *Client) SendVerification(ctx context.Context, token string) error {
+	const op = "auth_grpc.SendVerification"
+
+	_, err := c.api.SendVerification(ctx, &sso.SendVerificationRequest{
+		Token: token,
+	})
+	if err != nil {
+		return emailError(op, err)
+	}
+
+	return nil
+}
+
+func (c *Client) VerifyEmail(ctx context.Context, verificationToken string) error {
+	const op = "auth_grpc.VerifyEmail"
+
+	_, err := c.api.VerifyEmail(ctx, &sso.VerifyEmailRequest{
+		VerificationToken: verificationToken,
+	})
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			return ErrInvalidVerify
+		}
+		return emailError(op, err)
+	}
+
+	return nil
 }
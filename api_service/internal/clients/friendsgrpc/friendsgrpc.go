@@ -6,10 +6,12 @@ import (
 	"log/slog"
 
 	"github.com/liriquew/social-todo/api_service/internal/lib/config"
+	"github.com/liriquew/social-todo/api_service/internal/models"
 	"github.com/liriquew/todoprotos/gen/go/friends"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 
 	grpclog "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
 	grpcretry "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/retry"
@@ -60,6 +62,7 @@ var (
 	ErrNotFound        = fmt.Errorf("note not found")
 	ErrAlreadyExists   = fmt.Errorf("note already exists")
 	ErrInvalidArgument = fmt.Errorf("invalid argument")
+	ErrInviteInvalid   = fmt.Errorf("invite is invalid, expired or used up")
 )
 
 func (c *Client) AddFriend(ctx context.Context, UID, FID int64) error {
@@ -87,6 +90,7 @@ func (c *Client) RemoveFriend(ctx context.Context, UID, FID int64) error {
 	return nil
 }
 
+// ListFriends returns IDs of all UID's friends.
 func (c *Client) ListFriends(ctx context.Context, UID int64) ([]int64, error) {
 	const op = "friends_grpc.Update"
 
@@ -99,3 +103,212 @@ func (c *Client) ListFriends(ctx context.Context, UID int64) ([]int64, error) {
 
 	return resp.FriendIDs, nil
 }
+
+// ListFriendsPage returns a page of UID's friends, newest friendships first,
+// and a cursor for the next page. limit <= 0 returns all friends.
+func (c *Client) ListFriendsPage(ctx context.Context, UID int64, cursor string, limit int64) ([]*models.Friend, string, error) {
+	const op = "friends_grpc.ListFriendsPage"
+
+	resp, err := c.api.ListFriends(ctx, &friends.ListFriendRequest{
+		UID:    UID,
+		Cursor: cursor,
+		Limit:  limit,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok && st.Code() == codes.InvalidArgument {
+			return nil, "", ErrInvalidArgument
+		}
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	friendsList := make([]*models.Friend, 0, len(resp.Friends))
+	for _, fr := range resp.Friends {
+		friendsList = append(friendsList, &models.Friend{
+			UserInfo: models.UserInfo{UID: fr.FriendID},
+			Since:    fr.Since.AsTime(),
+		})
+	}
+
+	return friendsList, resp.NextCursor, nil
+}
+
+func (c *Client) Follow(ctx context.Context, UID, FID int64) error {
+	const op = "friends_grpc.Follow"
+
+	_, err := c.api.Follow(ctx, &friends.FollowRequest{UID: UID, FollowID: FID})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (c *Client) Unfollow(ctx context.Context, UID, FID int64) error {
+	const op = "friends_grpc.Unfollow"
+
+	_, err := c.api.Unfollow(ctx, &friends.FollowRequest{UID: UID, FollowID: FID})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (c *Client) ListFollowers(ctx context.Context, UID int64) ([]int64, error) {
+	const op = "friends_grpc.ListFollowers"
+
+	resp, err := c.api.ListFollowers(ctx, &friends.ListFollowRequest{
+		UID: UID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp.UIDs, nil
+}
+
+func (c *Client) ListFollowing(ctx context.Context, UID int64) ([]int64, error) {
+	const op = "friends_grpc.ListFollowing"
+
+	resp, err := c.api.ListFollowing(ctx, &friends.ListFollowRequest{
+		UID: UID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp.UIDs, nil
+}
+
+func (c *Client) CreateInvite(ctx context.Context, UID, maxUses int64) (*models.Invite, error) {
+	const op = "friends_grpc.CreateInvite"
+
+	resp, err := c.api.CreateInvite(ctx, &friends.CreateInviteRequest{
+		UID:     UID,
+		MaxUses: maxUses,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok && st.Code() == codes.InvalidArgument {
+			return nil, ErrInvalidArgument
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &models.Invite{
+		InviteID:  resp.InviteID,
+		Token:     resp.Token,
+		ExpiresAt: resp.ExpiresAt.AsTime(),
+	}, nil
+}
+
+// AcceptInvite makes UID a friend of the invite's author and returns author ID.
+func (c *Client) AcceptInvite(ctx context.Context, UID int64, token string) (int64, error) {
+	const op = "friends_grpc.AcceptInvite"
+
+	resp, err := c.api.AcceptInvite(ctx, &friends.AcceptInviteRequest{
+		UID:   UID,
+		Token: token,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.InvalidArgument:
+				return 0, ErrInvalidArgument
+			case codes.FailedPrecondition:
+				return 0, ErrInviteInvalid
+			}
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp.InviterID, nil
+}
+
+func (c *Client) RevokeInvite(ctx context.Context, UID int64, inviteID string) error {
+	const op = "friends_grpc.RevokeInvite"
+
+	_, err := c.api.RevokeInvite(ctx, &friends.RevokeInviteRequest{
+		UID:      UID,
+		InviteID: inviteID,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			switch st.Code() {
+			case codes.InvalidArgument:
+				return ErrInvalidArgument
+			case codes.NotFound:
+				return ErrNotFound
+			}
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (c *Client) ListInvites(ctx context.Context, UID int64) ([]*models.Invite, error) {
+	const op = "friends_grpc.ListInvites"
+
+	resp, err := c.api.ListInvites(ctx, &friends.ListInvitesRequest{
+		UID: UID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	invites := make([]*models.Invite, 0, len(resp.Invites))
+	for _, invite := range resp.Invites {
+		invites = append(invites, &models.Invite{
+			InviteID:  invite.InviteID,
+			MaxUses:   invite.MaxUses,
+			Uses:      invite.Uses,
+			CreatedAt: invite.CreatedAt.AsTime(),
+			ExpiresAt: invite.ExpiresAt.AsTime(),
+		})
+	}
+
+	return invites, nil
+}
+
+func (c *Client) ListNotifications(ctx context.Context, UID int64, unreadOnly bool) (*models.NotificationsList, error) {
+	const op = "friends_grpc.ListNotifications"
+
+	resp, err := c.api.ListNotifications(ctx, &friends.ListNotificationsRequest{
+		UID:        UID,
+		UnreadOnly: unreadOnly,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	list := &models.NotificationsList{
+		Notifications: make([]*models.Notification, 0, len(resp.Notifications)),
+		Unread:        resp.Unread,
+	}
+	for _, n := range resp.Notifications {
+		list.Notifications = append(list.Notifications, &models.Notification{
+			ID:        n.ID,
+			Type:      n.Type,
+			ActorID:   n.ActorID,
+			Read:      n.Read,
+			CreatedAt: n.CreatedAt.AsTime(),
+		})
+	}
+
+	return list, nil
+}
+
+// MarkNotificationsRead marks given notifications as read, all of them if IDs is empty.
+func (c *Client) MarkNotificationsRead(ctx context.Context, UID int64, IDs []int64) error {
+	const op = "friends_grpc.MarkNotificationsRead"
+
+	_, err := c.api.MarkNotificationsRead(ctx, &friends.MarkNotificationsReadRequest{
+		UID: UID,
+		IDs: IDs,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -142,6 +142,25 @@ func (c *Client) Delete(ctx context.Context, UID, NID int64) error {
 	return nil
 }
 
+// AdminDeleteNote removes note of any user, it returns UID of the owner.
+// Caller must check the actor is admin and audit the action.
+func (c *Client) AdminDeleteNote(ctx context.Context, actorUID, NID int64) (int64, error) {
+	const op = "notes_grpc.AdminDeleteNote"
+
+	resp, err := c.api.AdminDeleteNote(ctx, &notes.AdminDeleteNoteRequest{
+		NID:     NID,
+		ActorID: actorUID,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok && st.Code() == codes.NotFound {
+			return 0, ErrNotFound
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp.UID, nil
+}
+
 func (c *Client) ListUserIDs(ctx context.Context, UID int64) ([]int64, error) {
 	const op = "notes_grpc.ListUserIDs"
 
@@ -190,13 +209,15 @@ func (c *Client) ListUserNotes(ctx context.Context, UID int64, NIDs []int64) ([]
 	return notes, nil
 }
 
-func (c *Client) ListUsersNotes(ctx context.Context, UIDs []int64, offset, limit int64) ([]*models.Note, error) {
+// ListUsersNotes returns all notes of UIDs and only public notes of publicUIDs.
+func (c *Client) ListUsersNotes(ctx context.Context, UIDs, publicUIDs []int64, offset, limit int64) ([]*models.Note, error) {
 	const op = "notes_grpc.ListUserNotes"
 
 	resp, err := c.api.ListUsersNotes(ctx, &notes.UsersNotesRequest{
-		UIDs:   UIDs,
-		Offset: offset,
-		Limit:  limit,
+		UIDs:       UIDs,
+		PublicUIDs: publicUIDs,
+		Offset:     offset,
+		Limit:      limit,
 	})
 	if err != nil {
 		if st, ok := status.FromError(err); ok {
@@ -12,6 +12,45 @@ type Config struct {
 	AuthConfig    ServiceConfig `yaml:"auth_client" env-required:"true"`
 	NoteConfig    ServiceConfig `yaml:"note_client" env-required:"true"`
 	FriendsConfig ServiceConfig `yaml:"friends_client" env-required:"true"`
+	TokensConfig  TokensConfig  `yaml:"tokens"`
+	// features unverified accounts can't use
+	EmailVerification EmailVerificationConfig `yaml:"email_verification"`
+	// proxies allowed to set X-Forwarded-For, client IP is used by login lockout
+	TrustedProxies []string            `yaml:"trusted_proxies"`
+	OpenAPI        OpenAPIConfig       `yaml:"openapi"`
+	ExternalLogin  ExternalLoginConfig `yaml:"external_login"`
+}
+
+// TokensConfig configures local verification of sso access tokens.
+type TokensConfig struct {
+	JWKSURL         string        `yaml:"jwks_url" env-default:"http://localhost:4044/.well-known/jwks.json"`
+	Issuer          string        `yaml:"issuer" env-default:"social-todo-sso"`
+	KeysRefresh     time.Duration `yaml:"keys_refresh" env-default:"5m"`
+	RevocationsPoll time.Duration `yaml:"revocations_poll" env-default:"5s"`
+	// if revocations were not synced for this long, tokens are checked by sso_service
+	MaxStaleness time.Duration `yaml:"max_staleness" env-default:"30s"`
+}
+
+// EmailVerificationConfig lists features, e.g. "notes", that require
+// verified email. Route groups check it with EmailVerifiedRequired.
+type EmailVerificationConfig struct {
+	RequiredFor []string `yaml:"required_for"`
+}
+
+// OpenAPIConfig configures validation against the OpenAPI document.
+// Requests are always validated, mismatching responses are only logged.
+type OpenAPIConfig struct {
+	ValidateResponses bool `yaml:"validate_responses" env-default:"false"`
+}
+
+// ExternalLoginConfig configures sign in with external providers. Starts
+// are unauthenticated and each one saves state in sso_service, so only
+// StartsPerIP of them are allowed per Window. StateTTL is how long the
+// state cookie lives, it should match state_timeout of sso_service.
+type ExternalLoginConfig struct {
+	StartsPerIP int           `yaml:"starts_per_ip" env-default:"10"`
+	Window      time.Duration `yaml:"window" env-default:"1m"`
+	StateTTL    time.Duration `yaml:"state_timeout" env-default:"10m"`
 }
 
 type ServiceConfig struct {
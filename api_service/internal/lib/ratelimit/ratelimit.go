@@ -0,0 +1,56 @@
+// Package ratelimit limits requests of unauthenticated endpoints per key,
+// e.g. client IP.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter allows limit requests per key in fixed windows. Counters
+// are dropped all at once when window ends, at most size keys are
+// counted in a window, keys above it are not limited.
+type Limiter struct {
+	limit  int
+	window time.Duration
+	size   int
+
+	mu     sync.Mutex
+	start  time.Time
+	counts map[string]int
+	now    func() time.Time
+}
+
+func New(limit int, window time.Duration, size int) *Limiter {
+	return &Limiter{
+		limit:  limit,
+		window: window,
+		size:   size,
+		counts: make(map[string]int),
+		now:    time.Now,
+	}
+}
+
+// Allow counts request of key. If it's over the limit, false is returned
+// with time left until the window ends.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.start) >= l.window {
+		l.start = now
+		clear(l.counts)
+	}
+
+	count, ok := l.counts[key]
+	if !ok && len(l.counts) >= l.size {
+		return true, 0
+	}
+	if count >= l.limit {
+		return false, l.start.Add(l.window).Sub(now)
+	}
+
+	l.counts[key] = count + 1
+	return true, 0
+}
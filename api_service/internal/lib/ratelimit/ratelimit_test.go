@@ -0,0 +1,39 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := New(2, time.Minute, 2)
+	l.now = func() time.Time { return now }
+
+	for range 2 {
+		ok, _ := l.Allow("a")
+		assert.True(t, ok)
+	}
+
+	now = now.Add(20 * time.Second)
+	ok, retryAfter := l.Allow("a")
+	assert.False(t, ok)
+	assert.Equal(t, 40*time.Second, retryAfter)
+
+	// other keys are counted apart
+	ok, _ = l.Allow("b")
+	assert.True(t, ok)
+
+	// keys above size are not limited
+	for range 3 {
+		ok, _ = l.Allow("c")
+		assert.True(t, ok)
+	}
+
+	// counters are dropped when window ends
+	now = now.Add(40 * time.Second)
+	ok, _ = l.Allow("a")
+	assert.True(t, ok)
+}
@@ -0,0 +1,336 @@
+package verifier
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/liriquew/social-todo/api_service/internal/lib/config"
+	"github.com/liriquew/social-todo/api_service/internal/models"
+	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AuthClient is used to fetch revocations and to check tokens
+// that can't be verified locally.
+type AuthClient interface {
+	Authorize(ctx context.Context, token string) (models.Principal, error)
+	Revocations(ctx context.Context, since int64) (models.Revocations, error)
+}
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenRevoked = errors.New("token revoked")
+
+	errUnknownKey = errors.New("unknown key")
+)
+
+// revocations may be committed in sso_service a bit later
+// than their revoked_at, so each poll looks back a little
+const pollOverlap = 30 * time.Second
+
+type claims struct {
+	jwt.RegisteredClaims
+	UID  int64  `json:"uid"`
+	Role string `json:"role"`
+	// email verified at the time token was issued
+	EmailVerified bool `json:"ev"`
+	// set only on tokens that are not access tokens, e.g. 2FA challenges
+	Purpose string `json:"pur"`
+}
+
+// Verifier verifies sso access tokens locally with public keys from JWKS.
+// Tokens signed with unknown key, or checked while revocation list is
+// stale, are verified by sso_service. jwt.TimePrecision must be set to
+// milliseconds by main, iat is compared with user revocation cutoffs.
+type Verifier struct {
+	log    *slog.Logger
+	auth   AuthClient
+	cfg    config.TokensConfig
+	client *http.Client
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	keysFetched time.Time
+
+	revMu    sync.RWMutex
+	tokens   map[string]int64 // jti -> exp
+	users    map[int64]int64  // uid -> revoked before
+	since    int64
+	syncedAt time.Time
+
+	refresh chan struct{}
+}
+
+func New(log *slog.Logger, auth AuthClient, cfg config.TokensConfig) *Verifier {
+	return &Verifier{
+		log:     log,
+		auth:    auth,
+		cfg:     cfg,
+		client:  &http.Client{Timeout: 5 * time.Second},
+		keys:    make(map[string]crypto.PublicKey),
+		tokens:  make(map[string]int64),
+		users:   make(map[int64]int64),
+		refresh: make(chan struct{}, 1),
+	}
+}
+
+// Run keeps keys and revocations up to date until ctx is done.
+func (v *Verifier) Run(ctx context.Context) {
+	v.refreshKeys(ctx)
+	v.pollRevocations(ctx)
+
+	keysTicker := time.NewTicker(v.cfg.KeysRefresh)
+	defer keysTicker.Stop()
+	revTicker := time.NewTicker(v.cfg.RevocationsPoll)
+	defer revTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-keysTicker.C:
+			v.refreshKeys(ctx)
+		case <-v.refresh:
+			// unknown kid may be a freshly rotated key,
+			// but don't let bad tokens hammer sso_service
+			v.mu.RLock()
+			recently := time.Since(v.keysFetched) < time.Minute
+			v.mu.RUnlock()
+			if !recently {
+				v.refreshKeys(ctx)
+			}
+		case <-revTicker.C:
+			v.pollRevocations(ctx)
+		}
+	}
+}
+
+// Verify returns the token owner.
+func (v *Verifier) Verify(ctx context.Context, tokenString string) (models.Principal, error) {
+	const op = "verifier.Verify"
+
+	token := strings.TrimPrefix(tokenString, "Bearer ")
+
+	claims := &claims{}
+	_, err := jwt.ParseWithClaims(token, claims, v.keyFunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(v.cfg.Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(5*time.Second),
+	)
+	if errors.Is(err, errUnknownKey) {
+		select {
+		case v.refresh <- struct{}{}:
+		default:
+		}
+		return v.auth.Authorize(ctx, tokenString)
+	}
+	if err != nil {
+		return models.Principal{}, fmt.Errorf("%s: %w: %w", op, ErrInvalidToken, err)
+	}
+
+	if claims.ID == "" || claims.IssuedAt == nil || claims.Purpose != "" ||
+		claims.UID <= 0 || claims.Subject != strconv.FormatInt(claims.UID, 10) {
+		return models.Principal{}, fmt.Errorf("%s: %w: bad claims", op, ErrInvalidToken)
+	}
+
+	revoked, fresh := v.revoked(claims)
+	if !fresh {
+		return v.auth.Authorize(ctx, tokenString)
+	}
+	if revoked {
+		return models.Principal{}, fmt.Errorf("%s: %w", op, ErrTokenRevoked)
+	}
+
+	return models.Principal{
+		UID:           claims.UID,
+		Role:          claims.Role,
+		EmailVerified: claims.EmailVerified,
+	}, nil
+}
+
+func (v *Verifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	v.mu.RLock()
+	key, ok := v.keys[kid]
+	v.mu.RUnlock()
+	if !ok {
+		return nil, errUnknownKey
+	}
+
+	return key, nil
+}
+
+// revoked reports whether token was revoked and if revocation list
+// is fresh enough to trust the answer.
+func (v *Verifier) revoked(claims *claims) (bool, bool) {
+	v.revMu.RLock()
+	defer v.revMu.RUnlock()
+
+	if time.Since(v.syncedAt) > v.cfg.MaxStaleness {
+		return false, false
+	}
+
+	if before, ok := v.users[claims.UID]; ok && claims.IssuedAt.UnixMilli() <= before {
+		return true, true
+	}
+	_, ok := v.tokens[claims.ID]
+
+	return ok, true
+}
+
+func (v *Verifier) pollRevocations(ctx context.Context) {
+	const op = "verifier.pollRevocations"
+
+	log := v.log.With(slog.String("op", op))
+
+	v.revMu.RLock()
+	since := v.since
+	v.revMu.RUnlock()
+	if since > 0 {
+		since = max(since-pollOverlap.Milliseconds(), 0)
+	}
+
+	revs, err := v.auth.Revocations(ctx, since)
+	if err != nil {
+		log.Warn("failed to poll revocations", sl.Err(err))
+		return
+	}
+
+	now := time.Now()
+
+	v.revMu.Lock()
+	defer v.revMu.Unlock()
+
+	for _, token := range revs.Tokens {
+		v.tokens[token.JTI] = token.ExpiresAt
+	}
+	for _, user := range revs.Users {
+		v.users[user.UID] = max(v.users[user.UID], user.RevokedBefore)
+	}
+	for jti, exp := range v.tokens {
+		if exp < now.Unix() {
+			delete(v.tokens, jti)
+		}
+	}
+
+	v.since = revs.Until
+	v.syncedAt = now
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+}
+
+func (v *Verifier) refreshKeys(ctx context.Context) {
+	const op = "verifier.refreshKeys"
+
+	log := v.log.With(slog.String("op", op))
+
+	keys, err := v.fetchKeys(ctx)
+	if err != nil {
+		log.Warn("failed to fetch JWKS", sl.Err(err))
+		return
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.keysFetched = time.Now()
+	v.mu.Unlock()
+
+	log.Debug("keys refreshed", slog.Int("count", len(keys)))
+}
+
+func (v *Verifier) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.cfg.JWKSURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := parseJWK(k)
+		if err != nil {
+			v.log.Warn("skipping bad key", slog.String("kid", k.Kid), sl.Err(err))
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func parseJWK(k jwk) (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("bad key size %d", len(x))
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+}
@@ -0,0 +1,355 @@
+package verifier_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liriquew/social-todo/api_service/internal/lib/config"
+	"github.com/liriquew/social-todo/api_service/internal/lib/verifier"
+	"github.com/liriquew/social-todo/api_service/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const issuer = "test-sso"
+
+var errFallback = errors.New("checked by sso_service")
+
+func TestMain(m *testing.M) {
+	// set by main of api_service
+	jwt.TimePrecision = time.Millisecond
+
+	os.Exit(m.Run())
+}
+
+// fakeAuth stands for sso_service, Authorize fails, so tokens
+// verified locally are told apart from the ones sent to sso_service.
+type fakeAuth struct {
+	mu         sync.Mutex
+	revs       models.Revocations
+	revsErr    error
+	authorized int
+}
+
+func (f *fakeAuth) Authorize(ctx context.Context, token string) (models.Principal, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.authorized++
+	return models.Principal{}, errFallback
+}
+
+func (f *fakeAuth) Revocations(ctx context.Context, since int64) (models.Revocations, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.revsErr != nil {
+		return models.Revocations{}, f.revsErr
+	}
+	revs := f.revs
+	revs.Until = time.Now().UnixMilli()
+	return revs, nil
+}
+
+func (f *fakeAuth) setRevocations(revs models.Revocations) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.revs = revs
+}
+
+func (f *fakeAuth) authorizeCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.authorized
+}
+
+type key struct {
+	kid     string
+	private ed25519.PrivateKey
+}
+
+func newKey(t *testing.T, kid string) key {
+	t.Helper()
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return key{kid: kid, private: private}
+}
+
+// jwks serves public parts of keys, they can be changed while it runs.
+type jwks struct {
+	mu   sync.Mutex
+	keys []key
+}
+
+func (j *jwks) set(keys ...key) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.keys = keys
+}
+
+func (j *jwks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	type jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+	}
+	resp := struct {
+		Keys []jwk `json:"keys"`
+	}{Keys: []jwk{}}
+	for _, k := range j.keys {
+		resp.Keys = append(resp.Keys, jwk{
+			Kty: "OKP",
+			Kid: k.kid,
+			Use: "sig",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(k.private.Public().(ed25519.PublicKey)),
+		})
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+type tokenOpts struct {
+	jti     string
+	iat     time.Time
+	purpose string
+}
+
+func sign(t *testing.T, k key, UID int64, opts tokenOpts) string {
+	t.Helper()
+
+	if opts.jti == "" {
+		opts.jti = strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	if opts.iat.IsZero() {
+		opts.iat = time.Now()
+	}
+
+	claims := jwt.MapClaims{
+		"iss": issuer,
+		"sub": strconv.FormatInt(UID, 10),
+		"jti": opts.jti,
+		"iat": jwt.NewNumericDate(opts.iat),
+		"exp": jwt.NewNumericDate(opts.iat.Add(time.Hour)),
+		"uid": UID,
+	}
+	if opts.purpose != "" {
+		claims["pur"] = opts.purpose
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = k.kid
+
+	s, err := token.SignedString(k.private)
+	require.NoError(t, err)
+	return s
+}
+
+type setup struct {
+	auth *fakeAuth
+	jwks *jwks
+	v    *verifier.Verifier
+}
+
+func newSetup(t *testing.T, auth *fakeAuth, cfg config.TokensConfig, keys ...key) *setup {
+	t.Helper()
+
+	s := &setup{auth: auth, jwks: &jwks{}}
+	s.jwks.set(keys...)
+
+	srv := httptest.NewServer(s.jwks)
+	t.Cleanup(srv.Close)
+
+	cfg.JWKSURL = srv.URL
+	cfg.Issuer = issuer
+	if cfg.KeysRefresh == 0 {
+		cfg.KeysRefresh = time.Hour
+	}
+	if cfg.RevocationsPoll == 0 {
+		cfg.RevocationsPoll = 10 * time.Millisecond
+	}
+	if cfg.MaxStaleness == 0 {
+		cfg.MaxStaleness = time.Minute
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s.v = verifier.New(log, auth, cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go s.v.Run(ctx)
+
+	return s
+}
+
+// verifiedLocally waits until token is verified without sso_service.
+func (s *setup) verifiedLocally(t *testing.T, token string) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		_, err := s.v.Verify(context.Background(), token)
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestVerify_KeyRotation(t *testing.T) {
+	oldKey, newKey := newKey(t, "old"), newKey(t, "new")
+
+	s := newSetup(t, &fakeAuth{}, config.TokensConfig{KeysRefresh: 20 * time.Millisecond}, oldKey)
+
+	oldToken := sign(t, oldKey, 1, tokenOpts{})
+	s.verifiedLocally(t, oldToken)
+
+	principal, err := s.v.Verify(context.Background(), oldToken)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), principal.UID)
+
+	// new key is published before it's used for signing
+	s.jwks.set(newKey, oldKey)
+	newToken := sign(t, newKey, 2, tokenOpts{})
+	s.verifiedLocally(t, newToken)
+
+	// old key is dropped once its tokens expired
+	s.jwks.set(newKey)
+	require.Eventually(t, func() bool {
+		_, err := s.v.Verify(context.Background(), oldToken)
+		return errors.Is(err, errFallback)
+	}, time.Second, 5*time.Millisecond)
+
+	_, err = s.v.Verify(context.Background(), newToken)
+	require.NoError(t, err)
+}
+
+func TestVerify_UnknownKey(t *testing.T) {
+	known, unknown := newKey(t, "known"), newKey(t, "unknown")
+
+	s := newSetup(t, &fakeAuth{}, config.TokensConfig{}, known)
+	s.verifiedLocally(t, sign(t, known, 1, tokenOpts{}))
+
+	calls := s.auth.authorizeCalls()
+	_, err := s.v.Verify(context.Background(), sign(t, unknown, 1, tokenOpts{}))
+	require.ErrorIs(t, err, errFallback)
+	assert.Equal(t, calls+1, s.auth.authorizeCalls())
+
+	// same kid, but not the published key
+	forged := key{kid: known.kid, private: unknown.private}
+	_, err = s.v.Verify(context.Background(), sign(t, forged, 1, tokenOpts{}))
+	require.ErrorIs(t, err, verifier.ErrInvalidToken)
+	assert.Equal(t, calls+1, s.auth.authorizeCalls())
+}
+
+func TestVerify_PurposeRejected(t *testing.T) {
+	k := newKey(t, "k")
+
+	s := newSetup(t, &fakeAuth{}, config.TokensConfig{}, k)
+	s.verifiedLocally(t, sign(t, k, 1, tokenOpts{}))
+
+	// 2FA challenges and tokens of OIDC clients
+	for _, purpose := range []string{"2fa", "userinfo"} {
+		_, err := s.v.Verify(context.Background(), sign(t, k, 1, tokenOpts{purpose: purpose}))
+		require.ErrorIs(t, err, verifier.ErrInvalidToken, purpose)
+	}
+	assert.Zero(t, s.auth.authorizeCalls())
+}
+
+func TestVerify_RevokedToken(t *testing.T) {
+	k := newKey(t, "k")
+
+	s := newSetup(t, &fakeAuth{}, config.TokensConfig{}, k)
+
+	revoked := sign(t, k, 1, tokenOpts{jti: "revoked"})
+	other := sign(t, k, 1, tokenOpts{jti: "other"})
+	s.verifiedLocally(t, revoked)
+
+	s.auth.setRevocations(models.Revocations{
+		Tokens: []models.RevokedToken{{JTI: "revoked", ExpiresAt: time.Now().Add(time.Hour).Unix()}},
+	})
+	require.Eventually(t, func() bool {
+		_, err := s.v.Verify(context.Background(), revoked)
+		return errors.Is(err, verifier.ErrTokenRevoked)
+	}, time.Second, 5*time.Millisecond)
+
+	_, err := s.v.Verify(context.Background(), other)
+	require.NoError(t, err)
+}
+
+func TestVerify_LogoutAllCutoff(t *testing.T) {
+	k := newKey(t, "k")
+
+	s := newSetup(t, &fakeAuth{}, config.TokensConfig{}, k)
+
+	cutoff := time.Now().Truncate(time.Millisecond)
+	before := sign(t, k, 1, tokenOpts{iat: cutoff.Add(-time.Millisecond)})
+	at := sign(t, k, 1, tokenOpts{iat: cutoff})
+	// iat in float seconds may parse a millisecond earlier, never later
+	after := sign(t, k, 1, tokenOpts{iat: cutoff.Add(2 * time.Millisecond)})
+	otherUser := sign(t, k, 2, tokenOpts{iat: cutoff.Add(-time.Millisecond)})
+	s.verifiedLocally(t, before)
+
+	s.auth.setRevocations(models.Revocations{
+		Users: []models.UserRevocation{{UID: 1, RevokedBefore: cutoff.UnixMilli()}},
+	})
+	require.Eventually(t, func() bool {
+		_, err := s.v.Verify(context.Background(), before)
+		return errors.Is(err, verifier.ErrTokenRevoked)
+	}, time.Second, 5*time.Millisecond)
+
+	_, err := s.v.Verify(context.Background(), at)
+	require.ErrorIs(t, err, verifier.ErrTokenRevoked)
+
+	// issued in the same second, but after LogoutAll
+	_, err = s.v.Verify(context.Background(), after)
+	require.NoError(t, err)
+
+	_, err = s.v.Verify(context.Background(), otherUser)
+	require.NoError(t, err)
+}
+
+func TestVerify_StaleRevocations(t *testing.T) {
+	k := newKey(t, "k")
+
+	auth := &fakeAuth{}
+	s := newSetup(t, auth, config.TokensConfig{MaxStaleness: 50 * time.Millisecond}, k)
+
+	token := sign(t, k, 1, tokenOpts{})
+	s.verifiedLocally(t, token)
+
+	// sso_service is unreachable, the list gets stale
+	auth.mu.Lock()
+	auth.revsErr = errors.New("unavailable")
+	auth.mu.Unlock()
+
+	require.Eventually(t, func() bool {
+		_, err := s.v.Verify(context.Background(), token)
+		return errors.Is(err, errFallback)
+	}, time.Second, 5*time.Millisecond)
+
+	// and is trusted again once synced
+	auth.mu.Lock()
+	auth.revsErr = nil
+	auth.mu.Unlock()
+
+	s.verifiedLocally(t, token)
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+type AdminUser struct {
+	UID         int64      `json:"uid"`
+	Username    string     `json:"username"`
+	DisplayName string     `json:"display_name,omitempty"`
+	Role        string     `json:"role"`
+	CreatedAt   time.Time  `json:"created_at"`
+	DisabledAt  *time.Time `json:"disabled_at,omitempty"`
+}
+
+type AdminUsersPage struct {
+	Users      []*AdminUser `json:"users"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+type SetRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type AuditEntry struct {
+	ID        int64     `json:"id"`
+	ActorUID  int64     `json:"actor_uid"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target"`
+	Details   string    `json:"details,omitempty"`
+	Result    string    `json:"result"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AuditLogPage struct {
+	Entries    []*AuditEntry `json:"entries"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
@@ -1,5 +1,32 @@
 package models
 
+import "time"
+
 type FriendID struct {
 	FID int64 `json:"FID"`
+	// AddFriend accepts username instead of FID
+	Username string `json:"username,omitempty"`
+}
+
+type Friend struct {
+	UserInfo
+	Since time.Time `json:"since"`
+}
+
+type FriendsPage struct {
+	Friends    []*Friend `json:"friends"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+type InviteRequest struct {
+	MaxUses int64 `json:"max_uses"`
+}
+
+type Invite struct {
+	InviteID  string    `json:"invite_id"`
+	Token     string    `json:"token,omitempty"`
+	MaxUses   int64     `json:"max_uses,omitempty"`
+	Uses      int64     `json:"uses"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
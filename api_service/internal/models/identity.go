@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Connector is external identity provider users can sign in with.
+type Connector struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ExternalAuthURL is provider's page to send the user to.
+type ExternalAuthURL struct {
+	AuthURL string `json:"auth_url"`
+}
+
+// ExternalCallback is what provider redirected the user back with.
+type ExternalCallback struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// Identity is external account linked to the user.
+type Identity struct {
+	ConnectorID string     `json:"connector_id"`
+	Subject     string     `json:"subject"`
+	Username    string     `json:"username,omitempty"`
+	Email       string     `json:"email,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
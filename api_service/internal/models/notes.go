@@ -14,6 +14,10 @@ type Note struct {
 	Content   string    `json:"content,omitempty"`
 	CreatedAt time.Time `json:"created_at,omitempty"`
 	Duration  int64     `json:"duration,omitempty"`
+	Public    *bool     `json:"public,omitempty"`
+
+	// set only in the news feed
+	Author *UserInfo `json:"author,omitempty"`
 }
 
 func NoteFromProto(n *notes.Note, NID ...int64) *Note {
@@ -27,6 +31,7 @@ func NoteFromProto(n *notes.Note, NID ...int64) *Note {
 		Content:   n.Content,
 		Duration:  int64(n.Duration.AsDuration().Minutes()),
 		CreatedAt: n.CreatedAt.AsTime(),
+		Public:    n.Public,
 	}
 }
 
@@ -37,5 +42,6 @@ func ProtoFromNote(n *Note) *notes.Note {
 		Duration: &durationpb.Duration{
 			Seconds: n.Duration * 60,
 		},
+		Public: n.Public,
 	}
 }
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type Notification struct {
+	ID        int64     `json:"id"`
+	Type      string    `json:"type"`
+	ActorID   int64     `json:"actor_id"`
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type NotificationsList struct {
+	Notifications []*Notification `json:"notifications"`
+	Unread        int64           `json:"unread"`
+}
+
+type NotificationIDs struct {
+	IDs []int64 `json:"ids"`
+}
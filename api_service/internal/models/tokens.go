@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+type RevokedToken struct {
+	JTI       string
+	ExpiresAt int64 // unix seconds
+}
+
+// UserRevocation revokes all user's tokens issued at or before RevokedBefore.
+type UserRevocation struct {
+	UID           int64
+	RevokedBefore int64 // unix milli
+}
+
+type Revocations struct {
+	Tokens []RevokedToken
+	Users  []UserRevocation
+	Until  int64 // unix milli, pass as since to the next request
+}
+
+type AccessToken struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+type CreateAccessTokenRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+	// seconds, token never expires if omitted
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+// CreatedAccessToken is the only response the token itself is shown in.
+type CreatedAccessToken struct {
+	AccessToken string       `json:"access_token"`
+	Info        *AccessToken `json:"info"`
+}
+
+// Client describes device the request came from.
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+type Session struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// session of the token the list was requested with
+	Current bool `json:"current"`
+}
@@ -1,6 +1,132 @@
 package models
 
+import "time"
+
 type User struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// optional, used only on sign up
+	Email string `json:"email,omitempty"`
+}
+
+// Principal is the owner of authorized token, as of token issue.
+type Principal struct {
+	UID           int64
+	Role          string
+	EmailVerified bool
+}
+
+type Email struct {
+	Email    string `json:"email"`
+	Verified bool   `json:"verified"`
+}
+
+type SetEmailRequest struct {
+	// empty removes the email
+	Email string `json:"email"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type TokenPair struct {
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+
+	// set instead of tokens if user has 2FA enabled
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+}
+
+type CompleteLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type TOTPCode struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type PasswordRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type AccountDeletion struct {
+	ID          string     `json:"deletion_id"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type PasswordResetRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+type ResetPasswordRequest struct {
+	ResetToken  string `json:"reset_token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type PasswordViolation struct {
+	Rule        string `json:"rule"`
+	Description string `json:"description"`
+}
+
+type UserInfo struct {
+	UID         int64  `json:"uid"`
+	Username    string `json:"username,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+}
+
+type UsersPage struct {
+	Users      []*UserInfo `json:"users"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// Name returns the name the user should be shown by.
+func (u *UserInfo) Name() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	return u.Username
+}
+
+type Profile struct {
+	UID         int64      `json:"uid"`
+	Username    string     `json:"username"`
+	DisplayName string     `json:"display_name"`
+	Bio         string     `json:"bio"`
+	AvatarURL   string     `json:"avatar_url"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// ProfileUpdate holds fields to change, omitted ones are kept.
+type ProfileUpdate struct {
+	DisplayName *string `json:"display_name"`
+	Bio         *string `json:"bio"`
+	AvatarURL   *string `json:"avatar_url"`
 }
@@ -0,0 +1,206 @@
+package admin
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/liriquew/social-todo/api_service/internal/models"
+	"github.com/liriquew/social-todo/api_service/internal/rest/apierr"
+	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
+
+	auth_grpc "github.com/liriquew/social-todo/api_service/internal/clients/authgrpc"
+	notes_grpc "github.com/liriquew/social-todo/api_service/internal/clients/notesgrpc"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminAPI interface {
+	ListUsers(c *gin.Context)
+	DisableUser(c *gin.Context)
+	EnableUser(c *gin.Context)
+	ForceLogout(c *gin.Context)
+	SetRole(c *gin.Context)
+	DeleteNote(c *gin.Context)
+	AuditLog(c *gin.Context)
+}
+
+// Admin handlers. Every call is checked and audit logged by sso_service,
+// actions on other services are authorized there before they are
+// performed and recorded after, with their result.
+type Admin struct {
+	log         *slog.Logger
+	authClient  *auth_grpc.Client
+	notesClient *notes_grpc.Client
+}
+
+func New(log *slog.Logger, authClient *auth_grpc.Client, notesClient *notes_grpc.Client) *Admin {
+	return &Admin{
+		log:         log,
+		authClient:  authClient,
+		notesClient: notesClient,
+	}
+}
+
+func (a *Admin) ListUsers(c *gin.Context) {
+	a.log.Info("ListUsers")
+
+	limit, ok := parseLimit(c)
+	if !ok {
+		return
+	}
+
+	page, err := a.authClient.ListUsers(c, c.GetHeader("Authorization"), c.Query("cursor"), limit)
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (a *Admin) DisableUser(c *gin.Context) {
+	a.log.Info("DisableUser")
+
+	a.setDisabled(c, true)
+}
+
+func (a *Admin) EnableUser(c *gin.Context) {
+	a.log.Info("EnableUser")
+
+	a.setDisabled(c, false)
+}
+
+func (a *Admin) setDisabled(c *gin.Context, disabled bool) {
+	uid, ok := parseID(c, "bad user id")
+	if !ok {
+		return
+	}
+
+	if err := a.authClient.SetUserDisabled(c, c.GetHeader("Authorization"), uid, disabled); err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (a *Admin) ForceLogout(c *gin.Context) {
+	a.log.Info("ForceLogout")
+
+	uid, ok := parseID(c, "bad user id")
+	if !ok {
+		return
+	}
+
+	if err := a.authClient.ForceLogout(c, c.GetHeader("Authorization"), uid); err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (a *Admin) SetRole(c *gin.Context) {
+	a.log.Info("SetRole")
+
+	uid, ok := parseID(c, "bad user id")
+	if !ok {
+		return
+	}
+
+	var req models.SetRoleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	if err := a.authClient.SetUserRole(c, c.GetHeader("Authorization"), uid, req.Role); err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteNote removes note of any user, optional "reason" query parameter
+// is saved in the audit log.
+func (a *Admin) DeleteNote(c *gin.Context) {
+	a.log.Info("DeleteNote")
+
+	nid, ok := parseID(c, "bad note id")
+	if !ok {
+		return
+	}
+
+	token := c.GetHeader("Authorization")
+	target := "note:" + strconv.FormatInt(nid, 10)
+	reason := c.Query("reason")
+
+	// role claim checked by AdminRequired may be stale, notes_service
+	// is called only once sso_service checks the role and audits it
+	if err := a.authClient.AuthorizeAdminAction(c, token, "note.delete", target, reason); err != nil {
+		a.log.Warn("note removal not authorized", slog.String("target", target), sl.Err(err))
+		apierr.Write(c, err)
+		return
+	}
+
+	_, err := a.notesClient.AdminDeleteNote(c, c.Value("uid").(int64), nid)
+
+	result := "ok"
+	if err != nil {
+		result = err.Error()
+	}
+	if err := a.authClient.RecordAdminAction(c, token, "note.delete", target, reason, result); err != nil {
+		a.log.Error("failed to audit note removal", slog.String("target", target), sl.Err(err))
+	}
+
+	if err != nil {
+		a.log.Error("failed to delete note", sl.Err(err))
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (a *Admin) AuditLog(c *gin.Context) {
+	a.log.Info("AuditLog")
+
+	limit, ok := parseLimit(c)
+	if !ok {
+		return
+	}
+
+	page, err := a.authClient.AuditLog(c, c.GetHeader("Authorization"), c.Query("cursor"), limit)
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func parseID(c *gin.Context, msg string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		apierr.BadRequest(c, msg)
+		return 0, false
+	}
+	return id, true
+}
+
+func parseLimit(c *gin.Context) (int64, bool) {
+	l := c.Query("limit")
+	if l == "" {
+		return 0, true
+	}
+
+	limit, err := strconv.ParseInt(l, 10, 64)
+	if err != nil || limit < 0 {
+		apierr.BadRequest(c, "bad limit")
+		return 0, false
+	}
+	return limit, true
+}
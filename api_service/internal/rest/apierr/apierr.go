@@ -0,0 +1,101 @@
+// Package apierr writes error responses of the REST API. Every error is
+// answered with Error body, status and code of service errors come from Map.
+package apierr
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	CodeBadRequest      = "bad_request"
+	CodeUnauthenticated = "unauthenticated"
+	CodeForbidden       = "forbidden"
+	CodeNotFound        = "not_found"
+	CodeConflict        = "conflict"
+	CodeTooManyRequests = "too_many_requests"
+	CodeInternal        = "internal"
+	CodeUnavailable     = "unavailable"
+	CodeTimeout         = "timeout"
+)
+
+// Error is the body of every error response.
+type Error struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Details   any    `json:"details,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// FieldError tells what is wrong with a request field.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// Abort writes error response and stops the handler chain.
+func Abort(c *gin.Context, status int, code, message string) {
+	AbortWithDetails(c, status, code, message, nil)
+}
+
+func AbortWithDetails(c *gin.Context, status int, code, message string, details any) {
+	c.AbortWithStatusJSON(status, Error{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: c.GetString(RequestIDKey),
+	})
+}
+
+// BadRequest rejects request with malformed parameters.
+func BadRequest(c *gin.Context, message string) {
+	Abort(c, http.StatusBadRequest, CodeBadRequest, message)
+}
+
+// Unauthenticated rejects request without authenticated user.
+func Unauthenticated(c *gin.Context) {
+	Abort(c, http.StatusUnauthorized, CodeUnauthenticated, "authentication required")
+}
+
+// Bind rejects request body that can't be bound, failed binding
+// rules are listed in details.
+func Bind(c *gin.Context, err error) {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		Abort(c, http.StatusBadRequest, CodeBadRequest, "malformed request body")
+		return
+	}
+
+	fields := make([]FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		reason := fe.Tag()
+		if fe.Param() != "" {
+			reason += "=" + fe.Param()
+		}
+		fields = append(fields, FieldError{Field: fe.Field(), Reason: reason})
+	}
+	AbortWithDetails(c, http.StatusBadRequest, CodeBadRequest, "invalid request body", fields)
+}
+
+// Write responds with err mapped by Map. Lockouts get Retry-After.
+func Write(c *gin.Context, err error) {
+	resp := Map(err)
+	if resp.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(resp.RetryAfter))
+	}
+	AbortWithDetails(c, resp.Status, resp.Code, resp.Message, resp.Details)
+}
+
+// NotFound answers requests to unknown routes.
+func NotFound(c *gin.Context) {
+	Abort(c, http.StatusNotFound, CodeNotFound, "route not found")
+}
+
+// Recovery answers with internal error if handler panics.
+func Recovery(c *gin.Context, _ any) {
+	Abort(c, http.StatusInternalServerError, CodeInternal, "internal error")
+}
@@ -0,0 +1,158 @@
+package apierr
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strings"
+
+	auth_grpc "github.com/liriquew/social-todo/api_service/internal/clients/authgrpc"
+	friends_grpc "github.com/liriquew/social-todo/api_service/internal/clients/friendsgrpc"
+	notes_grpc "github.com/liriquew/social-todo/api_service/internal/clients/notesgrpc"
+	"github.com/liriquew/social-todo/api_service/internal/lib/verifier"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Response is an error mapped to HTTP.
+type Response struct {
+	Status  int
+	Code    string
+	Message string
+	Details any
+	// seconds, set for lockouts
+	RetryAfter int
+}
+
+// sentinel maps client error. Empty message means the error text is
+// shown without ops that wrapped the sentinel, it may carry the reason
+// sso_service gave.
+type sentinel struct {
+	err     error
+	status  int
+	code    string
+	message string
+}
+
+// sentinels are checked in order, ErrInvalidArgument and similar
+// generic ones go last, as specific errors may wrap them.
+var sentinels = []sentinel{
+	{verifier.ErrInvalidToken, http.StatusUnauthorized, CodeUnauthenticated, "invalid token"},
+	{verifier.ErrTokenRevoked, http.StatusUnauthorized, CodeUnauthenticated, "token revoked"},
+	{auth_grpc.ErrMissJWTToken, http.StatusUnauthorized, CodeUnauthenticated, ""},
+	{auth_grpc.ErrUnauthenticated, http.StatusUnauthorized, CodeUnauthenticated, ""},
+	{auth_grpc.ErrInvalidCreds, http.StatusUnauthorized, "invalid_credentials", ""},
+	{auth_grpc.ErrExternalAuth, http.StatusUnauthorized, "external_auth_failed", ""},
+	{auth_grpc.ErrWrongPassword, http.StatusForbidden, "wrong_password", ""},
+	{auth_grpc.ErrUserDisabled, http.StatusForbidden, "account_disabled", ""},
+	{auth_grpc.ErrForbidden, http.StatusForbidden, CodeForbidden, ""},
+	{auth_grpc.ErrInvalidCode, http.StatusBadRequest, "invalid_code", ""},
+	{auth_grpc.ErrInvalidReset, http.StatusBadRequest, "invalid_reset_token", ""},
+	{auth_grpc.ErrInvalidVerify, http.StatusBadRequest, "invalid_verification_token", ""},
+	{auth_grpc.ErrTwoFactorState, http.StatusConflict, "two_factor_state", ""},
+	{auth_grpc.ErrTooManyTokens, http.StatusConflict, "too_many_tokens", ""},
+	{auth_grpc.ErrSelfAction, http.StatusConflict, "self_action", ""},
+	{auth_grpc.ErrIdentityExist, http.StatusConflict, "identity_exists", ""},
+	{auth_grpc.ErrEmailState, http.StatusConflict, "email_state", ""},
+	{auth_grpc.ErrEmailTaken, http.StatusConflict, "email_taken", ""},
+	{auth_grpc.ErrTooManyRequests, http.StatusTooManyRequests, CodeTooManyRequests, ""},
+	{friends_grpc.ErrInviteInvalid, http.StatusGone, "invite_invalid", ""},
+
+	// sso_service answers NotFound for users, sessions, tokens and so on
+	{auth_grpc.ErrNotFound, http.StatusNotFound, CodeNotFound, "not found"},
+	{auth_grpc.ErrAlreadyExists, http.StatusConflict, CodeConflict, "already exists"},
+	{auth_grpc.ErrInvalidArgument, http.StatusBadRequest, CodeBadRequest, ""},
+	{notes_grpc.ErrNotFound, http.StatusNotFound, CodeNotFound, "note not found"},
+	{notes_grpc.ErrAlreadyExists, http.StatusConflict, CodeConflict, "note already exists"},
+	{notes_grpc.ErrInvalidArgument, http.StatusBadRequest, CodeBadRequest, ""},
+	{friends_grpc.ErrNotFound, http.StatusNotFound, CodeNotFound, "not found"},
+	{friends_grpc.ErrAlreadyExists, http.StatusConflict, CodeConflict, "already exists"},
+	{friends_grpc.ErrInvalidArgument, http.StatusBadRequest, CodeBadRequest, ""},
+}
+
+// grpcCodes maps status of errors clients pass through as is.
+var grpcCodes = map[codes.Code]struct {
+	status int
+	code   string
+}{
+	codes.InvalidArgument:    {http.StatusBadRequest, CodeBadRequest},
+	codes.OutOfRange:         {http.StatusBadRequest, CodeBadRequest},
+	codes.Unauthenticated:    {http.StatusUnauthorized, CodeUnauthenticated},
+	codes.PermissionDenied:   {http.StatusForbidden, CodeForbidden},
+	codes.NotFound:           {http.StatusNotFound, CodeNotFound},
+	codes.AlreadyExists:      {http.StatusConflict, CodeConflict},
+	codes.Aborted:            {http.StatusConflict, CodeConflict},
+	codes.FailedPrecondition: {http.StatusConflict, CodeConflict},
+	codes.ResourceExhausted:  {http.StatusTooManyRequests, CodeTooManyRequests},
+	codes.DeadlineExceeded:   {http.StatusGatewayTimeout, CodeTimeout},
+	codes.Unavailable:        {http.StatusServiceUnavailable, CodeUnavailable},
+}
+
+// Map maps errors of service clients to HTTP. Errors of unknown origin
+// and server side gRPC errors are internal, their text is not shown.
+func Map(err error) Response {
+	var policyErr *auth_grpc.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		return Response{
+			Status:  http.StatusBadRequest,
+			Code:    "password_policy",
+			Message: policyErr.Error(),
+			Details: policyErr.Violations,
+		}
+	}
+
+	var profileErr *auth_grpc.ProfileError
+	if errors.As(err, &profileErr) {
+		return Response{
+			Status:  http.StatusBadRequest,
+			Code:    CodeBadRequest,
+			Message: "invalid profile",
+			Details: []FieldError{{Field: profileErr.Field, Reason: profileErr.Reason}},
+		}
+	}
+
+	var rateErr *auth_grpc.RateLimitError
+	if errors.As(err, &rateErr) {
+		return Response{
+			Status:     http.StatusTooManyRequests,
+			Code:       "too_many_attempts",
+			Message:    "too many failed login attempts",
+			RetryAfter: int(math.Ceil(rateErr.RetryAfter.Seconds())),
+		}
+	}
+
+	for _, s := range sentinels {
+		if !errors.Is(err, s.err) {
+			continue
+		}
+		message := s.message
+		if message == "" {
+			message = err.Error()
+			if i := strings.Index(message, s.err.Error()); i >= 0 {
+				message = message[i:]
+			}
+		}
+		return Response{Status: s.status, Code: s.code, Message: message}
+	}
+
+	// status of wrapped error has the wrapping text as message,
+	// so the original status is looked up
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		st := grpcErr.GRPCStatus()
+		if m, ok := grpcCodes[st.Code()]; ok {
+			message := st.Message()
+			if m.status >= http.StatusInternalServerError {
+				message = http.StatusText(m.status)
+			}
+			return Response{Status: m.status, Code: m.code, Message: message}
+		}
+	}
+
+	return Response{
+		Status:  http.StatusInternalServerError,
+		Code:    CodeInternal,
+		Message: "internal error",
+	}
+}
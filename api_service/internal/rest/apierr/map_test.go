@@ -0,0 +1,195 @@
+package apierr_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	auth_grpc "github.com/liriquew/social-todo/api_service/internal/clients/authgrpc"
+	friends_grpc "github.com/liriquew/social-todo/api_service/internal/clients/friendsgrpc"
+	notes_grpc "github.com/liriquew/social-todo/api_service/internal/clients/notesgrpc"
+	"github.com/liriquew/social-todo/api_service/internal/lib/verifier"
+	"github.com/liriquew/social-todo/api_service/internal/models"
+	"github.com/liriquew/social-todo/api_service/internal/rest/apierr"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMap_Sentinels(t *testing.T) {
+	tests := []struct {
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{verifier.ErrInvalidToken, http.StatusUnauthorized, apierr.CodeUnauthenticated, "invalid token"},
+		{verifier.ErrTokenRevoked, http.StatusUnauthorized, apierr.CodeUnauthenticated, "token revoked"},
+		{auth_grpc.ErrMissJWTToken, http.StatusUnauthorized, apierr.CodeUnauthenticated, "miss JWT auth token"},
+		{auth_grpc.ErrUnauthenticated, http.StatusUnauthorized, apierr.CodeUnauthenticated, "invalid or expired token"},
+		{auth_grpc.ErrInvalidCreds, http.StatusUnauthorized, "invalid_credentials", "invalid username or password"},
+		{auth_grpc.ErrExternalAuth, http.StatusUnauthorized, "external_auth_failed", "external authentication failed"},
+		{auth_grpc.ErrWrongPassword, http.StatusForbidden, "wrong_password", "wrong password"},
+		{auth_grpc.ErrUserDisabled, http.StatusForbidden, "account_disabled", "account disabled"},
+		{auth_grpc.ErrForbidden, http.StatusForbidden, apierr.CodeForbidden, "admin role required"},
+		{auth_grpc.ErrInvalidCode, http.StatusBadRequest, "invalid_code", "invalid code"},
+		{auth_grpc.ErrInvalidReset, http.StatusBadRequest, "invalid_reset_token", "invalid or expired reset token"},
+		{auth_grpc.ErrInvalidVerify, http.StatusBadRequest, "invalid_verification_token", "invalid or expired verification token"},
+		{auth_grpc.ErrTwoFactorState, http.StatusConflict, "two_factor_state", "two-factor authentication already enabled or not enrolled"},
+		{auth_grpc.ErrTooManyTokens, http.StatusConflict, "too_many_tokens", "too many access tokens"},
+		{auth_grpc.ErrSelfAction, http.StatusConflict, "self_action", "can't apply to own account"},
+		{auth_grpc.ErrIdentityExist, http.StatusConflict, "identity_exists", "identity already linked"},
+		{auth_grpc.ErrEmailState, http.StatusConflict, "email_state", "email is not set or already verified"},
+		{auth_grpc.ErrEmailTaken, http.StatusConflict, "email_taken", "email is used by another account"},
+		{auth_grpc.ErrTooManyRequests, http.StatusTooManyRequests, apierr.CodeTooManyRequests, "verification was sent recently"},
+		{friends_grpc.ErrInviteInvalid, http.StatusGone, "invite_invalid", friends_grpc.ErrInviteInvalid.Error()},
+		{auth_grpc.ErrNotFound, http.StatusNotFound, apierr.CodeNotFound, "not found"},
+		{auth_grpc.ErrAlreadyExists, http.StatusConflict, apierr.CodeConflict, "already exists"},
+		{auth_grpc.ErrInvalidArgument, http.StatusBadRequest, apierr.CodeBadRequest, "invalid argument"},
+		{notes_grpc.ErrNotFound, http.StatusNotFound, apierr.CodeNotFound, "note not found"},
+		{notes_grpc.ErrAlreadyExists, http.StatusConflict, apierr.CodeConflict, "note already exists"},
+		{notes_grpc.ErrInvalidArgument, http.StatusBadRequest, apierr.CodeBadRequest, notes_grpc.ErrInvalidArgument.Error()},
+		{friends_grpc.ErrNotFound, http.StatusNotFound, apierr.CodeNotFound, "not found"},
+		{friends_grpc.ErrAlreadyExists, http.StatusConflict, apierr.CodeConflict, "already exists"},
+		{friends_grpc.ErrInvalidArgument, http.StatusBadRequest, apierr.CodeBadRequest, friends_grpc.ErrInvalidArgument.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			// ops wrapping the sentinel aren't shown
+			err := fmt.Errorf("handler: %w", fmt.Errorf("client.Op: %w", tt.err))
+
+			resp := apierr.Map(err)
+			assert.Equal(t, tt.status, resp.Status)
+			assert.Equal(t, tt.code, resp.Code)
+			assert.Equal(t, tt.message, resp.Message)
+			assert.Nil(t, resp.Details)
+		})
+	}
+}
+
+func TestMap_SentinelMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		message string
+	}{
+		{
+			name:    "reason of sso_service is kept",
+			err:     fmt.Errorf("auth_grpc.SetEmail: %w", fmt.Errorf("%w: %s", auth_grpc.ErrInvalidArgument, "email is too long")),
+			message: "invalid argument: email is too long",
+		},
+		{
+			name:    "bare sentinel",
+			err:     auth_grpc.ErrInvalidCreds,
+			message: "invalid username or password",
+		},
+		{
+			name:    "fixed message hides the reason",
+			err:     fmt.Errorf("%w: user 42", auth_grpc.ErrNotFound),
+			message: "not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.message, apierr.Map(tt.err).Message)
+		})
+	}
+}
+
+func TestMap_GRPCStatus(t *testing.T) {
+	tests := []struct {
+		code    codes.Code
+		status  int
+		errCode string
+		message string
+	}{
+		{codes.InvalidArgument, http.StatusBadRequest, apierr.CodeBadRequest, "bad field"},
+		{codes.OutOfRange, http.StatusBadRequest, apierr.CodeBadRequest, "bad field"},
+		{codes.Unauthenticated, http.StatusUnauthorized, apierr.CodeUnauthenticated, "bad field"},
+		{codes.PermissionDenied, http.StatusForbidden, apierr.CodeForbidden, "bad field"},
+		{codes.NotFound, http.StatusNotFound, apierr.CodeNotFound, "bad field"},
+		{codes.AlreadyExists, http.StatusConflict, apierr.CodeConflict, "bad field"},
+		{codes.Aborted, http.StatusConflict, apierr.CodeConflict, "bad field"},
+		{codes.FailedPrecondition, http.StatusConflict, apierr.CodeConflict, "bad field"},
+		{codes.ResourceExhausted, http.StatusTooManyRequests, apierr.CodeTooManyRequests, "bad field"},
+		// server side messages are hidden
+		{codes.DeadlineExceeded, http.StatusGatewayTimeout, apierr.CodeTimeout, "Gateway Timeout"},
+		{codes.Unavailable, http.StatusServiceUnavailable, apierr.CodeUnavailable, "Service Unavailable"},
+		{codes.Internal, http.StatusInternalServerError, apierr.CodeInternal, "internal error"},
+		{codes.Unknown, http.StatusInternalServerError, apierr.CodeInternal, "internal error"},
+		{codes.DataLoss, http.StatusInternalServerError, apierr.CodeInternal, "internal error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			// status of the wrapped error is used, not the wrapping text
+			err := fmt.Errorf("client.Op: %w", status.Error(tt.code, "bad field"))
+
+			resp := apierr.Map(err)
+			assert.Equal(t, tt.status, resp.Status)
+			assert.Equal(t, tt.errCode, resp.Code)
+			assert.Equal(t, tt.message, resp.Message)
+		})
+	}
+}
+
+func TestMap_Unknown(t *testing.T) {
+	resp := apierr.Map(fmt.Errorf("storage: %w", errors.New("connection refused at 10.0.0.1")))
+	assert.Equal(t, apierr.Response{
+		Status:  http.StatusInternalServerError,
+		Code:    apierr.CodeInternal,
+		Message: "internal error",
+	}, resp)
+}
+
+func TestMap_PasswordPolicy(t *testing.T) {
+	violations := []models.PasswordViolation{
+		{Rule: "min_length", Description: "at least 12 characters"},
+		{Rule: "digit", Description: "at least one digit"},
+	}
+	err := fmt.Errorf("auth_grpc.Register: %w", &auth_grpc.PasswordPolicyError{Violations: violations})
+
+	assert.Equal(t, apierr.Response{
+		Status:  http.StatusBadRequest,
+		Code:    "password_policy",
+		Message: "password does not satisfy policy",
+		Details: violations,
+	}, apierr.Map(err))
+}
+
+func TestMap_Profile(t *testing.T) {
+	err := fmt.Errorf("auth_grpc.UpdateProfile: %w", &auth_grpc.ProfileError{Field: "bio", Reason: "too long"})
+
+	assert.Equal(t, apierr.Response{
+		Status:  http.StatusBadRequest,
+		Code:    apierr.CodeBadRequest,
+		Message: "invalid profile",
+		Details: []apierr.FieldError{{Field: "bio", Reason: "too long"}},
+	}, apierr.Map(err))
+}
+
+func TestMap_Lockout(t *testing.T) {
+	tests := []struct {
+		retryAfter time.Duration
+		want       int
+	}{
+		{retryAfter: 30 * time.Second, want: 30},
+		// rounded up, so retrying after Retry-After isn't locked again
+		{retryAfter: 1500 * time.Millisecond, want: 2},
+		{retryAfter: 0, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.retryAfter.String(), func(t *testing.T) {
+			err := fmt.Errorf("auth_grpc.Login: %w", &auth_grpc.RateLimitError{RetryAfter: tt.retryAfter})
+
+			assert.Equal(t, apierr.Response{
+				Status:     http.StatusTooManyRequests,
+				Code:       "too_many_attempts",
+				Message:    "too many failed login attempts",
+				RetryAfter: tt.want,
+			}, apierr.Map(err))
+		})
+	}
+}
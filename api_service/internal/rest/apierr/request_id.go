@@ -0,0 +1,33 @@
+package apierr
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	// context key of the request ID
+	RequestIDKey = "request_id"
+)
+
+var requestIDRe = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// RequestID keeps request ID set by a proxy or generates one, it's sent
+// back in the header and in error responses.
+func RequestID(c *gin.Context) {
+	id := c.GetHeader(RequestIDHeader)
+	if !requestIDRe.MatchString(id) {
+		b := make([]byte, 16)
+		rand.Read(b)
+		id = hex.EncodeToString(b)
+	}
+
+	c.Set(RequestIDKey, id)
+	c.Header(RequestIDHeader, id)
+
+	c.Next()
+}
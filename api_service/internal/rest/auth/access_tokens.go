@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/liriquew/social-todo/api_service/internal/models"
+	"github.com/liriquew/social-todo/api_service/internal/rest/apierr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAccessToken issues personal access token for scripts.
+// The token is shown only in this response.
+func (a *Auth) CreateAccessToken(c *gin.Context) {
+	a.log.Info("CreateAccessToken")
+
+	var req models.CreateAccessTokenRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	token, err := a.authClient.CreateAccessToken(c, c.GetHeader("Authorization"), req)
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, token)
+}
+
+func (a *Auth) ListAccessTokens(c *gin.Context) {
+	a.log.Info("ListAccessTokens")
+
+	list, err := a.authClient.AccessTokens(c, c.GetHeader("Authorization"))
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+func (a *Auth) RevokeAccessToken(c *gin.Context) {
+	a.log.Info("RevokeAccessToken")
+
+	ID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || ID <= 0 {
+		apierr.BadRequest(c, "bad token id")
+		return
+	}
+
+	if err := a.authClient.RevokeAccessToken(c, c.GetHeader("Authorization"), ID); err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
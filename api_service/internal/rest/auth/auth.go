@@ -1,12 +1,14 @@
 package auth
 
 import (
-	"errors"
-	"fmt"
 	"log/slog"
 	"net/http"
 
+	"github.com/liriquew/social-todo/api_service/internal/lib/config"
+	"github.com/liriquew/social-todo/api_service/internal/lib/ratelimit"
+	"github.com/liriquew/social-todo/api_service/internal/lib/verifier"
 	"github.com/liriquew/social-todo/api_service/internal/models"
+	"github.com/liriquew/social-todo/api_service/internal/rest/apierr"
 
 	auth_grpc "github.com/liriquew/social-todo/api_service/internal/clients/authgrpc"
 
@@ -16,18 +18,63 @@ import (
 type AuthAPI interface {
 	Login(c *gin.Context)
 	Register(c *gin.Context)
+	Refresh(c *gin.Context)
+	Logout(c *gin.Context)
+	LogoutAll(c *gin.Context)
+	ChangePassword(c *gin.Context)
+	RequestPasswordReset(c *gin.Context)
+	ResetPassword(c *gin.Context)
+	CompleteLogin(c *gin.Context)
+	EnrollTOTP(c *gin.Context)
+	ConfirmTOTP(c *gin.Context)
+	DisableTOTP(c *gin.Context)
+	CreateAccessToken(c *gin.Context)
+	ListAccessTokens(c *gin.Context)
+	RevokeAccessToken(c *gin.Context)
+	ListSessions(c *gin.Context)
+	RevokeSession(c *gin.Context)
+	ListConnectors(c *gin.Context)
+	StartExternalLogin(c *gin.Context)
+	ExternalLogin(c *gin.Context)
+	ListIdentities(c *gin.Context)
+	StartLinkIdentity(c *gin.Context)
+	LinkIdentity(c *gin.Context)
+	UnlinkIdentity(c *gin.Context)
+	GetEmail(c *gin.Context)
+	SetEmail(c *gin.Context)
+	SendVerification(c *gin.Context)
+	VerifyEmail(c *gin.Context)
+	AllowAccessTokens(resource string) gin.HandlerFunc
 	AuthRequired(c *gin.Context)
+	AdminRequired(c *gin.Context)
+	EmailVerifiedRequired(feature string) gin.HandlerFunc
 }
 
 type Auth struct {
 	log        *slog.Logger
 	authClient *auth_grpc.Client
+	verifier   *verifier.Verifier
+
+	emailVerification config.EmailVerificationConfig
+	externalLogin     config.ExternalLoginConfig
+	externalStarts    *ratelimit.Limiter
 }
 
-func New(log *slog.Logger, authClient *auth_grpc.Client) *Auth {
+func New(
+	log *slog.Logger,
+	authClient *auth_grpc.Client,
+	verifier *verifier.Verifier,
+	emailVerification config.EmailVerificationConfig,
+	externalLogin config.ExternalLoginConfig,
+) *Auth {
 	return &Auth{
 		log:        log,
 		authClient: authClient,
+		verifier:   verifier,
+
+		emailVerification: emailVerification,
+		externalLogin:     externalLogin,
+		externalStarts:    ratelimit.New(externalLogin.StartsPerIP, externalLogin.Window, maxLimitedIPs),
 	}
 }
 
@@ -37,28 +84,36 @@ func (a *Auth) Login(c *gin.Context) {
 	var user models.User
 
 	if err := c.ShouldBindJSON(&user); err != nil {
-		c.String(http.StatusBadRequest, fmt.Sprintf("error: %s", err))
+		apierr.Bind(c, err)
 		return
 	}
 
-	token, err := a.authClient.Login(c, user.Username, user.Password)
+	pair, err := a.authClient.Login(c, user.Username, user.Password, client(c))
 	if err != nil {
-		if errors.Is(err, auth_grpc.ErrNotFound) {
-			c.Status(http.StatusNotFound)
-			return
-		}
-		if errors.Is(err, auth_grpc.ErrInvalidArgument) {
-			c.Status(http.StatusBadRequest)
-			return
-		}
+		apierr.Write(c, err)
+		return
+	}
 
-		c.Status(http.StatusInternalServerError)
+	c.JSON(http.StatusOK, pair)
+}
+
+func (a *Auth) Refresh(c *gin.Context) {
+	a.log.Info("Refresh")
+
+	var req models.RefreshRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Bind(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-	})
+	pair, err := a.authClient.Refresh(c, req.RefreshToken, client(c))
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pair)
 }
 
 func (a *Auth) Register(c *gin.Context) {
@@ -67,24 +122,106 @@ func (a *Auth) Register(c *gin.Context) {
 	var user models.User
 
 	if err := c.ShouldBindJSON(&user); err != nil {
-		c.String(http.StatusBadRequest, fmt.Sprintf("error: %s", err))
+		apierr.Bind(c, err)
 		return
 	}
 
-	uid, err := a.authClient.Register(c, user.Username, user.Password)
+	uid, err := a.authClient.Register(c, user.Username, user.Password, user.Email)
 	if err != nil {
-		if errors.Is(err, auth_grpc.ErrAlreadyExists) {
-			c.Status(http.StatusConflict)
-			return
-		}
-		if errors.Is(err, auth_grpc.ErrInvalidArgument) {
-			c.Status(http.StatusBadRequest)
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"uid": uid,
+	})
+}
+
+func (a *Auth) Logout(c *gin.Context) {
+	a.log.Info("Logout")
+
+	// body is optional, refresh token is revoked only if given
+	var req models.LogoutRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierr.Bind(c, err)
 			return
 		}
+	}
+
+	err := a.authClient.Logout(c, c.GetHeader("Authorization"), req.RefreshToken)
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (a *Auth) LogoutAll(c *gin.Context) {
+	a.log.Info("LogoutAll")
+
+	err := a.authClient.LogoutAll(c, c.GetHeader("Authorization"))
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (a *Auth) ChangePassword(c *gin.Context) {
+	a.log.Info("ChangePassword")
+
+	var req models.ChangePasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	err := a.authClient.ChangePassword(c, c.GetHeader("Authorization"), req.OldPassword, req.NewPassword)
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RequestPasswordReset always answers 202, so usernames can't be probed.
+func (a *Auth) RequestPasswordReset(c *gin.Context) {
+	a.log.Info("RequestPasswordReset")
+
+	var req models.PasswordResetRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	if err := a.authClient.RequestPasswordReset(c, req.Username); err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+func (a *Auth) ResetPassword(c *gin.Context) {
+	a.log.Info("ResetPassword")
+
+	var req models.ResetPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
 
-		c.Status(http.StatusInternalServerError)
+	if err := a.authClient.ResetPassword(c, req.ResetToken, req.NewPassword); err != nil {
+		apierr.Write(c, err)
 		return
 	}
 
-	c.String(http.StatusOK, fmt.Sprintf("uid: %d", uid))
+	c.Status(http.StatusNoContent)
 }
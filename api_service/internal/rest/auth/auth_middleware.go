@@ -1,34 +1,133 @@
 package auth
 
 import (
-	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	auth_grpc "github.com/liriquew/social-todo/api_service/internal/clients/authgrpc"
+	"github.com/liriquew/social-todo/api_service/internal/rest/apierr"
+	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
 )
 
+// accessTokenPrefix marks personal access tokens issued by sso_service.
+const accessTokenPrefix = "pat_"
+
+const roleAdmin = "admin"
+
+// context key of the resource personal access tokens are allowed for
+const scopeResourceKey = "scope_resource"
+
+// AllowAccessTokens lets AuthRequired accept personal access tokens
+// on the route group. Tokens need "<resource>:read" scope for GET and
+// HEAD requests and "<resource>:write" for the others. It must be set
+// before AuthRequired, groups without it accept only JWT.
+func (a *Auth) AllowAccessTokens(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(scopeResourceKey, resource)
+		c.Next()
+	}
+}
+
 func (a *Auth) AuthRequired(c *gin.Context) {
 	a.log.Info("middleware")
 
 	token := c.GetHeader("Authorization")
 	if token == "" {
-		c.String(http.StatusUnauthorized, "jwt token required")
+		apierr.Abort(c, http.StatusUnauthorized, apierr.CodeUnauthenticated, "jwt token required")
+		return
 	}
 
-	uid, err := a.authClient.Authorize(c, token)
+	if strings.HasPrefix(strings.TrimPrefix(token, "Bearer "), accessTokenPrefix) {
+		a.accessTokenRequired(c, token)
+		return
+	}
+
+	principal, err := a.verifier.Verify(c, token)
 	if err != nil {
-		if errors.Is(err, auth_grpc.ErrMissJWTToken) {
-			c.String(http.StatusInternalServerError, fmt.Sprintf("error: %s", err))
+		a.authError(c, err)
+		return
+	}
+
+	c.Set("uid", principal.UID)
+	c.Set("role", principal.Role)
+	c.Set("email_verified", principal.EmailVerified)
+
+	c.Next()
+}
+
+// AdminRequired allows only tokens with admin role, it must be set after
+// AuthRequired. Personal access tokens carry no role. sso_service checks
+// the role again on each admin call, so this is only a fast path.
+func (a *Auth) AdminRequired(c *gin.Context) {
+	if c.GetString("role") != roleAdmin {
+		apierr.Abort(c, http.StatusForbidden, apierr.CodeForbidden, "admin role required")
+		return
+	}
+
+	c.Next()
+}
+
+// EmailVerifiedRequired allows only users with verified email to use the
+// feature, if config requires it. It must be set after AuthRequired.
+// JWT carries verification state it was issued with, so users refresh
+// tokens after verifying.
+func (a *Auth) EmailVerifiedRequired(feature string) gin.HandlerFunc {
+	if !slices.Contains(a.emailVerification.RequiredFor, feature) {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		if !c.GetBool("email_verified") {
+			apierr.Abort(c, http.StatusForbidden, "email_not_verified", "email verification required")
 			return
 		}
 
-		c.Status(http.StatusInternalServerError)
+		c.Next()
+	}
+}
+
+// accessTokenRequired authorizes personal access token. Unlike JWT, these
+// are checked by sso_service on each request, so revocation is immediate.
+func (a *Auth) accessTokenRequired(c *gin.Context, token string) {
+	resource := c.GetString(scopeResourceKey)
+	if resource == "" {
+		apierr.Abort(c, http.StatusForbidden, apierr.CodeForbidden, "personal access tokens are not allowed here")
+		return
+	}
+
+	principal, scopes, err := a.authClient.AuthorizeAccessToken(c, token)
+	if err != nil {
+		a.authError(c, err)
+		return
+	}
+
+	scope := resource + ":write"
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		scope = resource + ":read"
+	}
+	if !slices.Contains(scopes, scope) {
+		c.Header("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope="%s"`, scope))
+		apierr.AbortWithDetails(c, http.StatusForbidden, "insufficient_scope", "token lacks "+scope+" scope", gin.H{
+			"scope": scope,
+		})
 		return
 	}
 
-	c.Set("uid", uid)
+	c.Set("uid", principal.UID)
+	c.Set("email_verified", principal.EmailVerified)
 
 	c.Next()
 }
+
+func (a *Auth) authError(c *gin.Context, err error) {
+	a.log.Warn("authorize error", sl.Err(err))
+	if apierr.Map(err).Status == http.StatusUnauthorized {
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+	}
+
+	apierr.Write(c, err)
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/liriquew/social-todo/api_service/internal/models"
+	"github.com/liriquew/social-todo/api_service/internal/rest/apierr"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (a *Auth) GetEmail(c *gin.Context) {
+	a.log.Info("GetEmail")
+
+	email, err := a.authClient.Email(c, c.GetHeader("Authorization"))
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, email)
+}
+
+// SetEmail changes user's email and mails verification to it,
+// empty email removes it. Changing verified email revokes access
+// tokens, the client has to refresh them.
+func (a *Auth) SetEmail(c *gin.Context) {
+	a.log.Info("SetEmail")
+
+	var req models.SetEmailRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	if err := a.authClient.SetEmail(c, c.GetHeader("Authorization"), req.Email); err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SendVerification mails verification of user's email again.
+func (a *Auth) SendVerification(c *gin.Context) {
+	a.log.Info("SendVerification")
+
+	if err := a.authClient.SendVerification(c, c.GetHeader("Authorization")); err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// VerifyEmail verifies email with the mailed token, no login is needed.
+func (a *Auth) VerifyEmail(c *gin.Context) {
+	a.log.Info("VerifyEmail")
+
+	var req models.VerifyEmailRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	if err := a.authClient.VerifyEmail(c, req.Token); err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
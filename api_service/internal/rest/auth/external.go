@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/liriquew/social-todo/api_service/internal/models"
+	"github.com/liriquew/social-todo/api_service/internal/rest/apierr"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// stateCookie binds state of external login to the browser that
+	// started it, so state leaked from redirect can't be used elsewhere.
+	stateCookie = "external_state"
+
+	// maxLimitedIPs caps addresses counted by externalStarts in a window.
+	maxLimitedIPs = 100_000
+)
+
+func hashState(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
+
+// ListConnectors returns external identity providers users can sign in with.
+func (a *Auth) ListConnectors(c *gin.Context) {
+	a.log.Info("ListConnectors")
+
+	connectors, err := a.authClient.Connectors(c)
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, connectors)
+}
+
+// StartExternalLogin returns provider's page to send the user to. Provider
+// redirects back to the app with code and state, which are posted to
+// ExternalLogin. Hash of the state is kept in a cookie until then.
+func (a *Auth) StartExternalLogin(c *gin.Context) {
+	a.log.Info("StartExternalLogin")
+
+	if ok, retryAfter := a.externalStarts.Allow(c.ClientIP()); !ok {
+		c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+		apierr.Abort(c, http.StatusTooManyRequests, apierr.CodeTooManyRequests, "too many sign in attempts")
+		return
+	}
+
+	connector := c.Param("connector")
+	authURL, err := a.authClient.StartExternalLogin(c, connector)
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	u, err := url.Parse(authURL)
+	if err != nil || u.Query().Get("state") == "" {
+		a.log.Error("auth URL without state", slog.String("connector", connector))
+		apierr.Abort(c, http.StatusInternalServerError, apierr.CodeInternal, "internal error")
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(stateCookie, hashState(u.Query().Get("state")), int(a.externalLogin.StateTTL.Seconds()),
+		"/signin/external/"+connector, "", true, true)
+
+	c.JSON(http.StatusOK, models.ExternalAuthURL{AuthURL: authURL})
+}
+
+// ExternalLogin signs in the user the external identity is linked to.
+// Like /signin, it answers with challenge token if user has 2FA enabled.
+func (a *Auth) ExternalLogin(c *gin.Context) {
+	a.log.Info("ExternalLogin")
+
+	var req models.ExternalCallback
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	connector := c.Param("connector")
+	hash, _ := c.Cookie(stateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(stateCookie, "", -1, "/signin/external/"+connector, "", true, true)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(hashState(req.State))) != 1 {
+		apierr.Abort(c, http.StatusBadRequest, apierr.CodeBadRequest, "invalid or expired state")
+		return
+	}
+
+	pair, err := a.authClient.ExternalLogin(c, connector, req.State, req.Code, client(c))
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pair)
+}
+
+func (a *Auth) ListIdentities(c *gin.Context) {
+	a.log.Info("ListIdentities")
+
+	identities, err := a.authClient.Identities(c, c.GetHeader("Authorization"))
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, identities)
+}
+
+// StartLinkIdentity is StartExternalLogin for linking identity to the
+// user, code and state are posted to LinkIdentity.
+func (a *Auth) StartLinkIdentity(c *gin.Context) {
+	a.log.Info("StartLinkIdentity")
+
+	authURL, err := a.authClient.StartLinkIdentity(c, c.GetHeader("Authorization"), c.Param("connector"))
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ExternalAuthURL{AuthURL: authURL})
+}
+
+func (a *Auth) LinkIdentity(c *gin.Context) {
+	a.log.Info("LinkIdentity")
+
+	var req models.ExternalCallback
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	identity, err := a.authClient.LinkIdentity(c, c.GetHeader("Authorization"), c.Param("connector"), req.State, req.Code)
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, identity)
+}
+
+func (a *Auth) UnlinkIdentity(c *gin.Context) {
+	a.log.Info("UnlinkIdentity")
+
+	if err := a.authClient.UnlinkIdentity(c, c.GetHeader("Authorization"), c.Param("connector")); err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/liriquew/social-todo/api_service/internal/models"
+	"github.com/liriquew/social-todo/api_service/internal/rest/apierr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListSessions returns active logins of the user, current one is marked.
+func (a *Auth) ListSessions(c *gin.Context) {
+	a.log.Info("ListSessions")
+
+	sessions, err := a.authClient.Sessions(c, c.GetHeader("Authorization"))
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession logs out the session, e.g. of a lost device. Its tokens
+// are rejected once revocations reach the gateway.
+func (a *Auth) RevokeSession(c *gin.Context) {
+	a.log.Info("RevokeSession")
+
+	if err := a.authClient.RevokeSession(c, c.GetHeader("Authorization"), c.Param("id")); err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// client returns device the request came from, it's forwarded to sso_service.
+func client(c *gin.Context) models.Client {
+	return models.Client{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
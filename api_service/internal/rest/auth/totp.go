@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/liriquew/social-todo/api_service/internal/models"
+	"github.com/liriquew/social-todo/api_service/internal/rest/apierr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CompleteLogin exchanges challenge token from /signin and TOTP
+// or recovery code for token pair.
+func (a *Auth) CompleteLogin(c *gin.Context) {
+	a.log.Info("CompleteLogin")
+
+	var req models.CompleteLoginRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	pair, err := a.authClient.CompleteLogin(c, req.ChallengeToken, req.Code, client(c))
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pair)
+}
+
+func (a *Auth) EnrollTOTP(c *gin.Context) {
+	a.log.Info("EnrollTOTP")
+
+	enrollment, err := a.authClient.EnrollTOTP(c, c.GetHeader("Authorization"))
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+func (a *Auth) ConfirmTOTP(c *gin.Context) {
+	a.log.Info("ConfirmTOTP")
+
+	var req models.TOTPCode
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	codes, err := a.authClient.ConfirmTOTP(c, c.GetHeader("Authorization"), req.Code)
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RecoveryCodes{RecoveryCodes: codes})
+}
+
+func (a *Auth) DisableTOTP(c *gin.Context) {
+	a.log.Info("DisableTOTP")
+
+	var req models.PasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	if err := a.authClient.DisableTOTP(c, c.GetHeader("Authorization"), req.Password); err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,75 @@
+package friends
+
+import (
+	"sync"
+	"time"
+
+	"github.com/liriquew/social-todo/api_service/internal/models"
+)
+
+const (
+	byNameTTL       = time.Minute
+	byNameCacheSize = 1024
+)
+
+// byNameCache keeps friends lists sorted by name between pages, so
+// listing by name doesn't load every friend and name for each page.
+type byNameCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	entries map[int64]byNameEntry
+}
+
+type byNameEntry struct {
+	friends []*models.Friend
+	expires time.Time
+}
+
+func newByNameCache(ttl time.Duration, size int) *byNameCache {
+	return &byNameCache{
+		ttl:     ttl,
+		size:    size,
+		entries: make(map[int64]byNameEntry, size),
+	}
+}
+
+func (b *byNameCache) get(UID int64) ([]*models.Friend, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e, ok := b.entries[UID]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+	return e.friends, true
+}
+
+func (b *byNameCache) put(UID int64, friends []*models.Friend) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if _, ok := b.entries[UID]; !ok && len(b.entries) >= b.size {
+		b.evict(now)
+	}
+	b.entries[UID] = byNameEntry{friends: friends, expires: now.Add(b.ttl)}
+}
+
+// evict drops expired lists, or the oldest one if none has expired.
+func (b *byNameCache) evict(now time.Time) {
+	var oldest int64
+	var oldestExpires time.Time
+	for UID, e := range b.entries {
+		if now.After(e.expires) {
+			delete(b.entries, UID)
+			continue
+		}
+		if oldestExpires.IsZero() || e.expires.Before(oldestExpires) {
+			oldest, oldestExpires = UID, e.expires
+		}
+	}
+	if len(b.entries) >= b.size {
+		delete(b.entries, oldest)
+	}
+}
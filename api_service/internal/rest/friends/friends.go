@@ -1,12 +1,21 @@
 package friends
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	auth_grpc "github.com/liriquew/social-todo/api_service/internal/clients/authgrpc"
 	friends_grpc "github.com/liriquew/social-todo/api_service/internal/clients/friendsgrpc"
 	"github.com/liriquew/social-todo/api_service/internal/models"
+	"github.com/liriquew/social-todo/api_service/internal/rest/apierr"
 	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
 )
 
@@ -14,81 +23,498 @@ type FriendsAPI interface {
 	AddFriend(*gin.Context)
 	RemoveFriend(*gin.Context)
 	ListFriend(*gin.Context)
+
+	Follow(*gin.Context)
+	Unfollow(*gin.Context)
+	ListFollowers(*gin.Context)
+	ListFollowing(*gin.Context)
+
+	CreateInvite(*gin.Context)
+	AcceptInvite(*gin.Context)
+	RevokeInvite(*gin.Context)
+	ListInvites(*gin.Context)
 }
 
 type Friends struct {
 	log           *slog.Logger
 	friendsClient *friends_grpc.Client
+	authClient    *auth_grpc.Client
+
+	byName *byNameCache
 }
 
-func New(log *slog.Logger, friendsClient *friends_grpc.Client) *Friends {
+func New(log *slog.Logger, friendsClient *friends_grpc.Client, authClient *auth_grpc.Client) *Friends {
 	return &Friends{
 		log:           log,
 		friendsClient: friendsClient,
+		authClient:    authClient,
+		byName:        newByNameCache(byNameTTL, byNameCacheSize),
 	}
 }
 
 func (f *Friends) AddFriend(c *gin.Context) {
 	uid := c.Value("uid").(int64)
 	if uid <= 0 {
-		c.Status(http.StatusUnauthorized)
+		apierr.Unauthenticated(c)
 		return
 	}
 
 	var FID models.FriendID
 	if err := c.ShouldBindJSON(&FID); err != nil {
 		f.log.Warn("bad json", sl.Err(err))
-		c.String(http.StatusBadRequest, "bad json idk")
+		apierr.Bind(c, err)
+		return
+	}
+
+	if FID.Username != "" {
+		if FID.FID != 0 {
+			apierr.BadRequest(c, "either FID or username expected")
+			return
+		}
+
+		user, err := f.authClient.LookupUser(c, c.GetHeader("Authorization"), FID.Username)
+		if err != nil {
+			if errors.Is(err, auth_grpc.ErrNotFound) {
+				apierr.Abort(c, http.StatusNotFound, apierr.CodeNotFound, "user not found")
+				return
+			}
+			// lookup is done on behalf of the session, access tokens can't do it
+			if errors.Is(err, auth_grpc.ErrUnauthenticated) {
+				apierr.Abort(c, http.StatusForbidden, apierr.CodeForbidden, "adding friends by username requires a session token")
+				return
+			}
+			f.log.Warn("failed to lookup user", sl.Err(err))
+			apierr.Write(c, err)
+			return
+		}
+		FID.FID = user.UID
+	} else if !f.userExists(c, FID.FID) {
 		return
 	}
 
 	err := f.friendsClient.AddFriend(c, uid, FID.FID)
 	if err != nil {
-		f.log.Warn("bad json", sl.Err(err))
-		c.String(http.StatusBadRequest, "bad json idk")
+		f.log.Warn("add friend error", sl.Err(err))
+		apierr.Write(c, err)
 		return
 	}
 
 	c.Status(http.StatusOK)
 }
 
+// userExists writes error response if there is no such user, so
+// friends_service never gets nodes of deleted or unknown users.
+func (f *Friends) userExists(c *gin.Context, UID int64) bool {
+	if UID <= 0 {
+		apierr.BadRequest(c, "bad user id")
+		return false
+	}
+
+	users, err := f.authClient.Users(c, []int64{UID})
+	if err != nil {
+		f.log.Warn("failed to get user", sl.Err(err))
+		apierr.Write(c, err)
+		return false
+	}
+	if len(users) == 0 {
+		apierr.Abort(c, http.StatusNotFound, apierr.CodeNotFound, "user not found")
+		return false
+	}
+
+	return true
+}
+
 func (f *Friends) RemoveFriend(c *gin.Context) {
 	uid := c.Value("uid").(int64)
 	if uid <= 0 {
-		c.Status(http.StatusUnauthorized)
+		apierr.Unauthenticated(c)
 		return
 	}
 
 	var FID models.FriendID
 	if err := c.ShouldBindJSON(&FID); err != nil {
 		f.log.Warn("bad json", sl.Err(err))
-		c.String(http.StatusBadRequest, "bad json idk")
+		apierr.Bind(c, err)
 		return
 	}
 
 	err := f.friendsClient.RemoveFriend(c, uid, FID.FID)
 	if err != nil {
-		f.log.Warn("bad json", sl.Err(err))
-		c.String(http.StatusBadRequest, "bad json idk")
+		f.log.Warn("remove friend error", sl.Err(err))
+		apierr.Write(c, err)
 		return
 	}
 
 	c.Status(http.StatusOK)
 }
 
+const (
+	defaultFriendsLimit = 20
+	maxFriendsLimit     = 100
+
+	sortBySince = "since"
+	sortByName  = "name"
+)
+
+// ListFriend returns a page of user's friends with their names.
+// Query params: limit, cursor (from previous page), sort (since|name).
 func (f *Friends) ListFriend(c *gin.Context) {
 	uid := c.Value("uid").(int64)
 	if uid <= 0 {
-		c.Status(http.StatusUnauthorized)
+		apierr.Unauthenticated(c)
+		return
+	}
+
+	limit := int64(defaultFriendsLimit)
+	if l := c.Query("limit"); l != "" {
+		var err error
+		limit, err = strconv.ParseInt(l, 10, 64)
+		if err != nil || limit <= 0 {
+			apierr.BadRequest(c, "bad limit value")
+			return
+		}
+	}
+	if limit > maxFriendsLimit {
+		apierr.BadRequest(c, "limit value is too big")
+		return
+	}
+
+	var page models.FriendsPage
+	var err error
+	switch c.DefaultQuery("sort", sortBySince) {
+	case sortBySince:
+		page.Friends, page.NextCursor, err = f.friendsClient.ListFriendsPage(c, uid, c.Query("cursor"), limit)
+		if err == nil {
+			err = f.fillNames(c, page.Friends)
+		}
+	case sortByName:
+		page, err = f.listFriendsByName(c, uid, c.Query("cursor"), limit)
+	default:
+		apierr.BadRequest(c, "unknown sort value")
+		return
+	}
+	if err != nil {
+		f.log.Warn("list friends error", sl.Err(err))
+		if errors.Is(err, friends_grpc.ErrInvalidArgument) || errors.Is(err, errBadCursor) {
+			apierr.BadRequest(c, "bad cursor")
+			return
+		}
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+var errBadCursor = errors.New("bad cursor")
+
+// listFriendsByName sorts friends by name. Names are only known to sso_service,
+// friends_service can't order or paginate by them, so the whole list is loaded
+// with names, sorted here and kept per process for the following pages, the
+// first page reloads it. Cursor is the name and UID of the last friend of the
+// page, so a page served from a reloaded list (another instance, expired
+// cache) continues after it without repeats or gaps. Friends renamed between
+// pages may still be skipped or repeated.
+func (f *Friends) listFriendsByName(c *gin.Context, uid int64, cursor string, limit int64) (models.FriendsPage, error) {
+	var after *nameKey
+	if cursor != "" {
+		key, err := decodeNameCursor(cursor)
+		if err != nil {
+			return models.FriendsPage{}, err
+		}
+		after = &key
+	}
+
+	friendsList, ok := f.byName.get(uid)
+	if cursor == "" || !ok {
+		var err error
+		friendsList, err = f.loadFriendsByName(c, uid)
+		if err != nil {
+			return models.FriendsPage{}, err
+		}
+		f.byName.put(uid, friendsList)
+	}
+
+	return pageByName(friendsList, after, limit), nil
+}
+
+// nameKey orders friends by name, case insensitive, then by UID.
+type nameKey struct {
+	Name string `json:"n"`
+	UID  int64  `json:"u"`
+}
+
+func nameKeyOf(fr *models.Friend) nameKey {
+	return nameKey{Name: strings.ToLower(fr.Name()), UID: fr.UID}
+}
+
+func (k nameKey) less(o nameKey) bool {
+	if k.Name != o.Name {
+		return k.Name < o.Name
+	}
+	return k.UID < o.UID
+}
+
+func encodeNameCursor(k nameKey) string {
+	raw, _ := json.Marshal(k)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeNameCursor(cursor string) (nameKey, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nameKey{}, errBadCursor
+	}
+	var k nameKey
+	if err := json.Unmarshal(raw, &k); err != nil || k.UID <= 0 {
+		return nameKey{}, errBadCursor
+	}
+	return k, nil
+}
+
+// pageByName returns up to limit friends of the sorted list that come after
+// the given key, or from the start if it's nil.
+func pageByName(friendsList []*models.Friend, after *nameKey, limit int64) models.FriendsPage {
+	start := 0
+	if after != nil {
+		start = sort.Search(len(friendsList), func(i int) bool {
+			return after.less(nameKeyOf(friendsList[i]))
+		})
+	}
+	end := start + int(min(limit, int64(len(friendsList)-start)))
+
+	page := models.FriendsPage{Friends: friendsList[start:end]}
+	if end < len(friendsList) {
+		page.NextCursor = encodeNameCursor(nameKeyOf(friendsList[end-1]))
+	}
+	return page
+}
+
+func (f *Friends) loadFriendsByName(c *gin.Context, uid int64) ([]*models.Friend, error) {
+	friendsList, _, err := f.friendsClient.ListFriendsPage(c, uid, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.fillNames(c, friendsList); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(friendsList, func(i, j int) bool {
+		return nameKeyOf(friendsList[i]).less(nameKeyOf(friendsList[j]))
+	})
+
+	return friendsList, nil
+}
+
+// maxUsersBatch is the most users sso_service returns for one call.
+const maxUsersBatch = 1000
+
+// fillNames sets usernames and display names from sso_service.
+func (f *Friends) fillNames(c *gin.Context, friendsList []*models.Friend) error {
+	if len(friendsList) == 0 {
+		return nil
+	}
+
+	UIDs := make([]int64, 0, len(friendsList))
+	for _, fr := range friendsList {
+		UIDs = append(UIDs, fr.UID)
+	}
+
+	byID := make(map[int64]*models.UserInfo, len(friendsList))
+	for len(UIDs) != 0 {
+		n := min(len(UIDs), maxUsersBatch)
+		users, err := f.authClient.Users(c, UIDs[:n])
+		if err != nil {
+			// the request is built here, client has nothing to fix
+			if errors.Is(err, auth_grpc.ErrInvalidArgument) {
+				return fmt.Errorf("friends.fillNames: %s", err)
+			}
+			return err
+		}
+		for _, user := range users {
+			byID[user.UID] = user
+		}
+		UIDs = UIDs[n:]
+	}
+	for _, fr := range friendsList {
+		if user, ok := byID[fr.UID]; ok {
+			fr.UserInfo = *user
+		}
+	}
+
+	return nil
+}
+
+func (f *Friends) Follow(c *gin.Context) {
+	uid := c.Value("uid").(int64)
+	if uid <= 0 {
+		apierr.Unauthenticated(c)
+		return
+	}
+
+	var FID models.FriendID
+	if err := c.ShouldBindJSON(&FID); err != nil {
+		f.log.Warn("bad json", sl.Err(err))
+		apierr.Bind(c, err)
+		return
+	}
+
+	if !f.userExists(c, FID.FID) {
 		return
 	}
 
-	FIDs, err := f.friendsClient.ListFriends(c, uid)
+	err := f.friendsClient.Follow(c, uid, FID.FID)
 	if err != nil {
+		f.log.Warn("follow error", sl.Err(err))
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (f *Friends) Unfollow(c *gin.Context) {
+	uid := c.Value("uid").(int64)
+	if uid <= 0 {
+		apierr.Unauthenticated(c)
+		return
+	}
+
+	var FID models.FriendID
+	if err := c.ShouldBindJSON(&FID); err != nil {
 		f.log.Warn("bad json", sl.Err(err))
-		c.String(http.StatusBadRequest, "bad json idk")
+		apierr.Bind(c, err)
+		return
+	}
+
+	err := f.friendsClient.Unfollow(c, uid, FID.FID)
+	if err != nil {
+		f.log.Warn("unfollow error", sl.Err(err))
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (f *Friends) ListFollowers(c *gin.Context) {
+	uid := c.Value("uid").(int64)
+	if uid <= 0 {
+		apierr.Unauthenticated(c)
+		return
+	}
+
+	UIDs, err := f.friendsClient.ListFollowers(c, uid)
+	if err != nil {
+		f.log.Warn("list followers error", sl.Err(err))
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, UIDs)
+}
+
+func (f *Friends) ListFollowing(c *gin.Context) {
+	uid := c.Value("uid").(int64)
+	if uid <= 0 {
+		apierr.Unauthenticated(c)
+		return
+	}
+
+	UIDs, err := f.friendsClient.ListFollowing(c, uid)
+	if err != nil {
+		f.log.Warn("list following error", sl.Err(err))
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, UIDs)
+}
+
+func (f *Friends) CreateInvite(c *gin.Context) {
+	uid := c.Value("uid").(int64)
+	if uid <= 0 {
+		apierr.Unauthenticated(c)
+		return
+	}
+
+	var req models.InviteRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			f.log.Warn("bad json", sl.Err(err))
+			apierr.Bind(c, err)
+			return
+		}
+	}
+
+	invite, err := f.friendsClient.CreateInvite(c, uid, req.MaxUses)
+	if err != nil {
+		f.log.Warn("create invite error", sl.Err(err))
+		if errors.Is(err, friends_grpc.ErrInvalidArgument) {
+			apierr.BadRequest(c, "bad max_uses value")
+			return
+		}
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invite)
+}
+
+// AcceptInvite is the invite link handler, it makes user a friend of the inviter.
+func (f *Friends) AcceptInvite(c *gin.Context) {
+	uid := c.Value("uid").(int64)
+	if uid <= 0 {
+		apierr.Unauthenticated(c)
+		return
+	}
+
+	inviterID, err := f.friendsClient.AcceptInvite(c, uid, c.Param("token"))
+	if err != nil {
+		f.log.Warn("accept invite error", sl.Err(err))
+		if errors.Is(err, friends_grpc.ErrInvalidArgument) {
+			apierr.BadRequest(c, "bad invite")
+			return
+		}
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"FID": inviterID,
+	})
+}
+
+func (f *Friends) RevokeInvite(c *gin.Context) {
+	uid := c.Value("uid").(int64)
+	if uid <= 0 {
+		apierr.Unauthenticated(c)
+		return
+	}
+
+	err := f.friendsClient.RevokeInvite(c, uid, c.Param("id"))
+	if err != nil {
+		f.log.Warn("revoke invite error", sl.Err(err))
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (f *Friends) ListInvites(c *gin.Context) {
+	uid := c.Value("uid").(int64)
+	if uid <= 0 {
+		apierr.Unauthenticated(c)
+		return
+	}
+
+	invites, err := f.friendsClient.ListInvites(c, uid)
+	if err != nil {
+		f.log.Warn("list invites error", sl.Err(err))
+		apierr.Write(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, FIDs)
+	c.JSON(http.StatusOK, invites)
 }
@@ -0,0 +1,101 @@
+package friends
+
+import (
+	"testing"
+	"time"
+
+	"github.com/liriquew/social-todo/api_service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func friend(UID int64, name string) *models.Friend {
+	return &models.Friend{UserInfo: models.UserInfo{UID: UID, Username: name}}
+}
+
+func uids(friendsList []*models.Friend) []int64 {
+	res := make([]int64, 0, len(friendsList))
+	for _, fr := range friendsList {
+		res = append(res, fr.UID)
+	}
+	return res
+}
+
+// nextPage follows the cursor of the page the way listFriendsByName does.
+func nextPage(t *testing.T, friendsList []*models.Friend, page models.FriendsPage, limit int64) models.FriendsPage {
+	t.Helper()
+	require.NotEmpty(t, page.NextCursor)
+	after, err := decodeNameCursor(page.NextCursor)
+	require.NoError(t, err)
+	return pageByName(friendsList, &after, limit)
+}
+
+func TestPageByName(t *testing.T) {
+	friendsList := []*models.Friend{
+		friend(3, "alice"), friend(1, "Bob"), friend(2, "bob"), friend(5, "carol"), friend(4, "dave"),
+	}
+
+	page := pageByName(friendsList, nil, 2)
+	assert.Equal(t, []int64{3, 1}, uids(page.Friends))
+
+	page = nextPage(t, friendsList, page, 2)
+	assert.Equal(t, []int64{2, 5}, uids(page.Friends))
+
+	page = nextPage(t, friendsList, page, 2)
+	assert.Equal(t, []int64{4}, uids(page.Friends))
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestPageByName_ReloadedList(t *testing.T) {
+	friendsList := []*models.Friend{
+		friend(1, "alice"), friend(2, "bob"), friend(3, "carol"), friend(4, "dave"),
+	}
+	page := pageByName(friendsList, nil, 2)
+	require.Equal(t, []int64{1, 2}, uids(page.Friends))
+
+	// the list was loaded again by another instance or after the cache
+	// expired: alice is no longer a friend and aaron is a new one
+	reloaded := []*models.Friend{
+		friend(5, "aaron"), friend(2, "bob"), friend(3, "carol"), friend(4, "dave"),
+	}
+	page = nextPage(t, reloaded, page, 2)
+	assert.Equal(t, []int64{3, 4}, uids(page.Friends))
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestDecodeNameCursor(t *testing.T) {
+	k := nameKey{Name: "bob", UID: 2}
+	got, err := decodeNameCursor(encodeNameCursor(k))
+	require.NoError(t, err)
+	assert.Equal(t, k, got)
+
+	for _, cursor := range []string{"!", "MTA", encodeNameCursor(nameKey{Name: "bob"})} {
+		_, err := decodeNameCursor(cursor)
+		assert.ErrorIs(t, err, errBadCursor, cursor)
+	}
+}
+
+func TestByNameCache(t *testing.T) {
+	friendsList := []*models.Friend{friend(1, "alice")}
+
+	b := newByNameCache(time.Minute, 2)
+	b.put(1, friendsList)
+	time.Sleep(time.Millisecond)
+	b.put(2, friendsList)
+	got, ok := b.get(1)
+	require.True(t, ok)
+	assert.Equal(t, friendsList, got)
+
+	// the oldest list is dropped when full
+	b.put(3, friendsList)
+	_, ok = b.get(1)
+	assert.False(t, ok)
+	_, ok = b.get(3)
+	assert.True(t, ok)
+
+	b = newByNameCache(-time.Second, 2)
+	b.put(1, friendsList)
+	_, ok = b.get(1)
+	assert.False(t, ok)
+}
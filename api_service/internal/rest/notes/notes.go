@@ -1,7 +1,6 @@
 package notes
 
 import (
-	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -10,6 +9,7 @@ import (
 
 	notes_grpc "github.com/liriquew/social-todo/api_service/internal/clients/notesgrpc"
 	"github.com/liriquew/social-todo/api_service/internal/models"
+	"github.com/liriquew/social-todo/api_service/internal/rest/apierr"
 	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
 )
 
@@ -39,14 +39,14 @@ func New(log *slog.Logger, notesClient *notes_grpc.Client) *Notes {
 func (n *Notes) Create(c *gin.Context) {
 	uid := c.Value("uid").(int64)
 	if uid <= 0 {
-		c.Status(http.StatusUnauthorized)
+		apierr.Unauthenticated(c)
 		return
 	}
 
 	var note *models.Note
 	if err := c.ShouldBindJSON(&note); err != nil {
 		n.log.Warn("bad json", sl.Err(err))
-		c.String(http.StatusBadRequest, "bad json idk")
+		apierr.Bind(c, err)
 		return
 	}
 
@@ -55,17 +55,7 @@ func (n *Notes) Create(c *gin.Context) {
 	NoteID, err := n.notesClient.Create(c, uid, note)
 	if err != nil {
 		n.log.Warn("error:", sl.Err(err))
-
-		if errors.Is(err, notes_grpc.ErrAlreadyExists) {
-			c.Status(http.StatusConflict)
-			return
-		}
-		if errors.Is(err, notes_grpc.ErrInvalidArgument) {
-			c.Status(http.StatusBadRequest)
-			return
-		}
-
-		c.Status(http.StatusInternalServerError)
+		apierr.Write(c, err)
 		return
 	}
 
@@ -77,27 +67,19 @@ func (n *Notes) Create(c *gin.Context) {
 func (n *Notes) Get(c *gin.Context) {
 	uid := c.Value("uid").(int64)
 	if uid <= 0 {
-		c.Status(http.StatusUnauthorized)
+		apierr.Unauthenticated(c)
 		return
 	}
 
-	noteId, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if noteId <= 0 || err != nil {
-		n.log.Warn("ERROR GET parseInt", sl.Err(err))
-		c.Status(http.StatusBadRequest)
+	noteId, ok := parseID(c)
+	if !ok {
+		return
 	}
 
 	note, err := n.notesClient.Get(c, uid, noteId)
 	if err != nil {
 		n.log.Warn("error:", sl.Err(err))
-
-		// TODO: check err not exists
-		if errors.Is(err, notes_grpc.ErrNotFound) {
-			c.Status(http.StatusNotFound)
-			return
-		}
-
-		c.Status(http.StatusInternalServerError)
+		apierr.Write(c, err)
 		return
 	}
 
@@ -107,41 +89,26 @@ func (n *Notes) Get(c *gin.Context) {
 func (n *Notes) Update(c *gin.Context) {
 	uid := c.Value("uid").(int64)
 	if uid <= 0 {
-		c.Status(http.StatusUnauthorized)
+		apierr.Unauthenticated(c)
 		return
 	}
 
-	noteId, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if noteId <= 0 || err != nil {
-		n.log.Warn("ERROR GET parseInt", sl.Err(err))
-		c.Status(http.StatusOK)
+	noteId, ok := parseID(c)
+	if !ok {
+		return
 	}
 
 	var note *models.Note
 	if err := c.ShouldBindJSON(&note); err != nil {
 		n.log.Warn("bad json", sl.Err(err))
-		c.String(http.StatusBadRequest, "bad json idk")
+		apierr.Bind(c, err)
 		return
 	}
 
-	err = n.notesClient.Update(c, uid, noteId, note)
+	err := n.notesClient.Update(c, uid, noteId, note)
 	if err != nil {
 		n.log.Warn("error:", sl.Err(err))
-
-		if errors.Is(err, notes_grpc.ErrInvalidArgument) {
-			c.Status(http.StatusBadRequest)
-			return
-		}
-		if errors.Is(err, notes_grpc.ErrAlreadyExists) {
-			c.Status(http.StatusConflict)
-			return
-		}
-		if errors.Is(err, notes_grpc.ErrNotFound) {
-			c.Status(http.StatusNotFound)
-			return
-		}
-
-		c.Status(http.StatusInternalServerError)
+		apierr.Write(c, err)
 		return
 	}
 
@@ -151,21 +118,20 @@ func (n *Notes) Update(c *gin.Context) {
 func (n *Notes) Delete(c *gin.Context) {
 	uid := c.Value("uid").(int64)
 	if uid <= 0 {
-		c.Status(http.StatusUnauthorized)
+		apierr.Unauthenticated(c)
 		return
 	}
 
-	noteId, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if noteId <= 0 || err != nil {
-		n.log.Warn("ERROR GET parseInt", sl.Err(err))
-		c.Status(http.StatusOK)
+	noteId, ok := parseID(c)
+	if !ok {
+		return
 	}
 
-	err = n.notesClient.Delete(c, uid, noteId)
+	err := n.notesClient.Delete(c, uid, noteId)
 	if err != nil {
 		n.log.Warn("error:", sl.Err(err))
-
-		c.Status(http.StatusInternalServerError)
+		apierr.Write(c, err)
+		return
 	}
 
 	c.Status(http.StatusOK)
@@ -174,18 +140,14 @@ func (n *Notes) Delete(c *gin.Context) {
 func (n *Notes) ListIDs(c *gin.Context) {
 	uid := c.Value("uid").(int64)
 	if uid <= 0 {
-		c.Status(http.StatusUnauthorized)
+		apierr.Unauthenticated(c)
 		return
 	}
 
 	notesIDs, err := n.notesClient.ListUserIDs(c, uid)
 	if err != nil {
 		n.log.Warn("error:", sl.Err(err))
-		if errors.Is(err, notes_grpc.ErrNotFound) {
-			c.Status(http.StatusNotFound)
-			return
-		}
-		c.Status(http.StatusInternalServerError)
+		apierr.Write(c, err)
 		return
 	}
 
@@ -195,27 +157,32 @@ func (n *Notes) ListIDs(c *gin.Context) {
 func (n *Notes) ListNotes(c *gin.Context) {
 	uid := c.Value("uid").(int64)
 	if uid <= 0 {
-		c.Status(http.StatusUnauthorized)
+		apierr.Unauthenticated(c)
 		return
 	}
 
 	var notesIDs []int64
 	if err := c.ShouldBindJSON(&notesIDs); err != nil {
 		n.log.Warn("bad json", sl.Err(err))
-		c.String(http.StatusBadRequest, "bad json idk")
+		apierr.Bind(c, err)
 		return
 	}
 
 	notes, err := n.notesClient.ListUserNotes(c, uid, notesIDs)
 	if err != nil {
 		n.log.Warn("error:", sl.Err(err))
-		if errors.Is(err, notes_grpc.ErrNotFound) {
-			c.Status(http.StatusNotFound)
-			return
-		}
-		c.Status(http.StatusInternalServerError)
+		apierr.Write(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, notes)
 }
+
+func parseID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		apierr.BadRequest(c, "bad note id")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,80 @@
+package notifications
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	friends_grpc "github.com/liriquew/social-todo/api_service/internal/clients/friendsgrpc"
+	"github.com/liriquew/social-todo/api_service/internal/models"
+	"github.com/liriquew/social-todo/api_service/internal/rest/apierr"
+	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
+)
+
+type NotificationsAPI interface {
+	List(*gin.Context)
+	MarkRead(*gin.Context)
+}
+
+type Notifications struct {
+	log           *slog.Logger
+	friendsClient *friends_grpc.Client
+}
+
+func New(log *slog.Logger, friendsClient *friends_grpc.Client) *Notifications {
+	return &Notifications{
+		log:           log,
+		friendsClient: friendsClient,
+	}
+}
+
+// List returns user's notifications, only unread ones with ?unread=true.
+func (n *Notifications) List(c *gin.Context) {
+	uid := c.Value("uid").(int64)
+	if uid <= 0 {
+		apierr.Unauthenticated(c)
+		return
+	}
+
+	unreadOnly, err := strconv.ParseBool(c.DefaultQuery("unread", "false"))
+	if err != nil {
+		apierr.BadRequest(c, "bad unread value")
+		return
+	}
+
+	list, err := n.friendsClient.ListNotifications(c, uid, unreadOnly)
+	if err != nil {
+		n.log.Warn("list notifications error", sl.Err(err))
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// MarkRead marks notifications from {"ids": [...]} as read, all of them if ids is empty.
+func (n *Notifications) MarkRead(c *gin.Context) {
+	uid := c.Value("uid").(int64)
+	if uid <= 0 {
+		apierr.Unauthenticated(c)
+		return
+	}
+
+	var IDs models.NotificationIDs
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&IDs); err != nil {
+			n.log.Warn("bad json", sl.Err(err))
+			apierr.Bind(c, err)
+			return
+		}
+	}
+
+	if err := n.friendsClient.MarkNotificationsRead(c, uid, IDs.IDs); err != nil {
+		n.log.Warn("mark notifications error", sl.Err(err))
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,243 @@
+// Package openapi serves OpenAPI document of the gateway and validates
+// requests and responses of documented routes against it.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/liriquew/social-todo/api_service/internal/lib/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed openapi.json
+var document []byte
+
+var methods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete,
+}
+
+type Spec struct {
+	log               *slog.Logger
+	validateResponses bool
+
+	// by method and path with "{}" in place of parameters
+	operations map[string]*operation
+}
+
+type operation struct {
+	Parameters  []*parameter         `json:"parameters"`
+	RequestBody *requestBody         `json:"requestBody"`
+	Responses   map[string]*response `json:"responses"`
+
+	// path parameters in order of the path
+	pathParams []*parameter
+}
+
+type parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type response struct {
+	Ref     string               `json:"$ref"`
+	Content map[string]mediaType `json:"content"`
+}
+
+type mediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+func (b *requestBody) schema() *Schema {
+	if b == nil {
+		return nil
+	}
+	return b.Content["application/json"].Schema
+}
+
+func (r *response) schema() *Schema {
+	if r == nil {
+		return nil
+	}
+	return r.Content["application/json"].Schema
+}
+
+func New(log *slog.Logger, cfg config.OpenAPIConfig) (*Spec, error) {
+	const op = "openapi.New"
+
+	var doc struct {
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas   map[string]*Schema   `json:"schemas"`
+			Responses map[string]*response `json:"responses"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(document, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	r := resolver{
+		schemas:   doc.Components.Schemas,
+		responses: doc.Components.Responses,
+		resolved:  make(map[*Schema]bool),
+	}
+
+	spec := &Spec{
+		log:               log,
+		validateResponses: cfg.ValidateResponses,
+		operations:        make(map[string]*operation),
+	}
+	for path, item := range doc.Paths {
+		for _, method := range methods {
+			raw, ok := item[strings.ToLower(method)]
+			if !ok {
+				continue
+			}
+
+			var o operation
+			if err := json.Unmarshal(raw, &o); err != nil {
+				return nil, fmt.Errorf("%s: %s %s: %w", op, method, path, err)
+			}
+			if err := r.operation(&o); err != nil {
+				return nil, fmt.Errorf("%s: %s %s: %w", op, method, path, err)
+			}
+			if err := o.orderPathParams(path); err != nil {
+				return nil, fmt.Errorf("%s: %s %s: %w", op, method, path, err)
+			}
+
+			spec.operations[key(method, specParamRe.ReplaceAllString(path, "{}"))] = &o
+		}
+	}
+
+	return spec, nil
+}
+
+// Serve responds with the OpenAPI document.
+func (s *Spec) Serve(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", document)
+}
+
+var (
+	specParamRe  = regexp.MustCompile(`\{[^}/]+\}`)
+	routeParamRe = regexp.MustCompile(`[:*][^/]+`)
+)
+
+// operation returns operation documented for the route, route is gin
+// path with ":id" parameters.
+func (s *Spec) operation(method, route string) (*operation, bool) {
+	o, ok := s.operations[key(method, routeParamRe.ReplaceAllString(route, "{}"))]
+	return o, ok
+}
+
+func key(method, path string) string {
+	return method + " " + path
+}
+
+// orderPathParams matches path parameters by position, as gin routes
+// sharing a segment may name the parameter differently.
+func (o *operation) orderPathParams(path string) error {
+	for _, name := range specParamRe.FindAllString(path, -1) {
+		name = strings.Trim(name, "{}")
+
+		i := slices.IndexFunc(o.Parameters, func(p *parameter) bool {
+			return p.In == "path" && p.Name == name
+		})
+		if i < 0 {
+			return fmt.Errorf("path parameter %q is not described", name)
+		}
+		o.pathParams = append(o.pathParams, o.Parameters[i])
+	}
+
+	return nil
+}
+
+// resolver replaces $ref of the document with components it points to.
+type resolver struct {
+	schemas   map[string]*Schema
+	responses map[string]*response
+	resolved  map[*Schema]bool
+}
+
+func (r *resolver) operation(o *operation) error {
+	for _, p := range o.Parameters {
+		if err := r.schema(&p.Schema); err != nil {
+			return err
+		}
+	}
+	if o.RequestBody != nil {
+		for ct, mt := range o.RequestBody.Content {
+			if err := r.schema(&mt.Schema); err != nil {
+				return err
+			}
+			o.RequestBody.Content[ct] = mt
+		}
+	}
+
+	for status, resp := range o.Responses {
+		if name, ok := strings.CutPrefix(resp.Ref, "#/components/responses/"); ok {
+			ref, ok := r.responses[name]
+			if !ok {
+				return fmt.Errorf("unknown response %q", resp.Ref)
+			}
+			resp = ref
+			o.Responses[status] = resp
+		}
+		for ct, mt := range resp.Content {
+			if err := r.schema(&mt.Schema); err != nil {
+				return err
+			}
+			resp.Content[ct] = mt
+		}
+	}
+
+	return nil
+}
+
+func (r *resolver) schema(s **Schema) error {
+	if *s == nil {
+		return nil
+	}
+
+	if (*s).Ref != "" {
+		name, ok := strings.CutPrefix((*s).Ref, "#/components/schemas/")
+		ref, found := r.schemas[name]
+		if !ok || !found {
+			return fmt.Errorf("unknown schema %q", (*s).Ref)
+		}
+		*s = ref
+	}
+
+	// components are shared, and may refer to themselves
+	if r.resolved[*s] {
+		return nil
+	}
+	r.resolved[*s] = true
+
+	for name, prop := range (*s).Properties {
+		if err := r.schema(&prop); err != nil {
+			return err
+		}
+		(*s).Properties[name] = prop
+	}
+	for i := range (*s).AllOf {
+		if err := r.schema(&(*s).AllOf[i]); err != nil {
+			return err
+		}
+	}
+	return r.schema(&(*s).Items)
+}
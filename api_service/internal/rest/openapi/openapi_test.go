@@ -0,0 +1,135 @@
+package openapi
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/liriquew/social-todo/api_service/internal/lib/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	// every $ref of the served document resolves
+	spec, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), config.OpenAPIConfig{})
+	require.NoError(t, err)
+
+	o, ok := spec.operation("GET", "/note/:id")
+	require.True(t, ok)
+	require.Len(t, o.pathParams, 1)
+	assert.Equal(t, "id", o.pathParams[0].Name)
+
+	_, ok = spec.operation("GET", "/unknown")
+	assert.False(t, ok)
+}
+
+func TestResolver(t *testing.T) {
+	tree := &Schema{Type: "object", Properties: map[string]*Schema{
+		"children": {Type: "array", Items: &Schema{Ref: "#/components/schemas/Tree"}},
+	}}
+	errResp := &response{Content: map[string]mediaType{
+		"application/json": {Schema: &Schema{Ref: "#/components/schemas/Tree"}},
+	}}
+	newResolver := func() resolver {
+		return resolver{
+			schemas:   map[string]*Schema{"Tree": tree},
+			responses: map[string]*response{"Error": errResp},
+			resolved:  make(map[*Schema]bool),
+		}
+	}
+
+	o := &operation{
+		Parameters: []*parameter{{Name: "id", In: "path", Schema: &Schema{Ref: "#/components/schemas/Tree"}}},
+		RequestBody: &requestBody{Content: map[string]mediaType{
+			"application/json": {Schema: &Schema{AllOf: []*Schema{{Ref: "#/components/schemas/Tree"}}}},
+		}},
+		Responses: map[string]*response{
+			"200": {Content: map[string]mediaType{"application/json": {Schema: &Schema{Ref: "#/components/schemas/Tree"}}}},
+			"400": {Ref: "#/components/responses/Error"},
+		},
+	}
+	r := newResolver()
+	require.NoError(t, r.operation(o))
+
+	assert.Same(t, tree, o.Parameters[0].Schema)
+	assert.Same(t, tree, o.RequestBody.schema().AllOf[0])
+	assert.Same(t, tree, o.Responses["200"].schema())
+	assert.Same(t, errResp, o.Responses["400"])
+	assert.Same(t, tree, errResp.schema())
+	// refers to itself
+	assert.Same(t, tree, tree.Properties["children"].Items)
+
+	tests := []struct {
+		name string
+		o    *operation
+		err  string
+	}{
+		{
+			name: "unknown schema",
+			o:    &operation{Parameters: []*parameter{{Schema: &Schema{Ref: "#/components/schemas/Leaf"}}}},
+			err:  `unknown schema "#/components/schemas/Leaf"`,
+		},
+		{
+			name: "not a component",
+			o:    &operation{Parameters: []*parameter{{Schema: &Schema{Ref: "other.json#/Tree"}}}},
+			err:  `unknown schema "other.json#/Tree"`,
+		},
+		{
+			name: "unknown response",
+			o:    &operation{Responses: map[string]*response{"404": {Ref: "#/components/responses/NotFound"}}},
+			err:  `unknown response "#/components/responses/NotFound"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newResolver()
+			assert.EqualError(t, r.operation(tt.o), tt.err)
+		})
+	}
+}
+
+func TestOrderPathParams(t *testing.T) {
+	id := &parameter{Name: "id", In: "path"}
+	kind := &parameter{Name: "kind", In: "path"}
+	queryID := &parameter{Name: "id", In: "query"}
+
+	tests := []struct {
+		name   string
+		path   string
+		params []*parameter
+		want   []*parameter
+		err    string
+	}{
+		{
+			name:   "order of the path",
+			path:   "/users/{id}/block/{kind}",
+			params: []*parameter{queryID, kind, id},
+			want:   []*parameter{id, kind},
+		},
+		{
+			name:   "no parameters",
+			path:   "/users",
+			params: []*parameter{queryID},
+		},
+		{
+			name:   "not described",
+			path:   "/users/{id}",
+			params: []*parameter{queryID},
+			err:    `path parameter "id" is not described`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &operation{Parameters: tt.params}
+			err := o.orderPathParams(tt.path)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, o.pathParams)
+		})
+	}
+}
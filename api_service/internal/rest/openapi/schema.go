@@ -0,0 +1,198 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/liriquew/social-todo/api_service/internal/rest/apierr"
+)
+
+// Schema is the subset of OpenAPI schema object the gateway document uses.
+// additionalProperties may only be boolean.
+type Schema struct {
+	Ref string `json:"$ref"`
+
+	Type     string    `json:"type"`
+	Format   string    `json:"format"`
+	Nullable bool      `json:"nullable"`
+	Enum     []any     `json:"enum"`
+	AllOf    []*Schema `json:"allOf"`
+
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+
+	Items    *Schema `json:"items"`
+	MinItems *int    `json:"minItems"`
+	MaxItems *int    `json:"maxItems"`
+
+	MinLength *int     `json:"minLength"`
+	MaxLength *int     `json:"maxLength"`
+	Minimum   *float64 `json:"minimum"`
+	Maximum   *float64 `json:"maximum"`
+}
+
+// validate appends to errs what is wrong with v, decoded with UseNumber.
+// Reasons are named like binding tags, so they read the same as errors
+// apierr.Bind reports.
+func (s *Schema) validate(v any, field string, errs *[]apierr.FieldError) {
+	fail := func(reason string) {
+		*errs = append(*errs, apierr.FieldError{Field: field, Reason: reason})
+	}
+
+	for _, sub := range s.AllOf {
+		sub.validate(v, field, errs)
+	}
+
+	if v == nil {
+		if s.Type != "" && !s.Nullable {
+			fail("type=" + s.Type)
+		}
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			fail("type=object")
+			return
+		}
+		s.validateObject(obj, field, errs)
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			fail("type=array")
+			return
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			fail("min=" + strconv.Itoa(*s.MinItems))
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			fail("max=" + strconv.Itoa(*s.MaxItems))
+		}
+		if s.Items != nil {
+			for i, item := range arr {
+				s.Items.validate(item, fmt.Sprintf("%s[%d]", field, i), errs)
+			}
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			fail("type=string")
+			return
+		}
+		if s.MinLength != nil && utf8.RuneCountInString(str) < *s.MinLength {
+			fail("min=" + strconv.Itoa(*s.MinLength))
+		}
+		if s.MaxLength != nil && utf8.RuneCountInString(str) > *s.MaxLength {
+			fail("max=" + strconv.Itoa(*s.MaxLength))
+		}
+		if !validFormat(s.Format, str) {
+			fail(s.Format)
+		}
+	case "integer", "number":
+		num, ok := v.(json.Number)
+		if !ok {
+			fail("type=" + s.Type)
+			return
+		}
+		f, err := num.Float64()
+		if err != nil {
+			fail("type=" + s.Type)
+			return
+		}
+		if s.Type == "integer" {
+			if _, err := num.Int64(); err != nil {
+				fail("type=integer")
+				return
+			}
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			fail("min=" + strconv.FormatFloat(*s.Minimum, 'f', -1, 64))
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			fail("max=" + strconv.FormatFloat(*s.Maximum, 'f', -1, 64))
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			fail("type=boolean")
+			return
+		}
+	}
+
+	if len(s.Enum) != 0 && !inEnum(s.Enum, v) {
+		values := make([]string, 0, len(s.Enum))
+		for _, e := range s.Enum {
+			values = append(values, fmt.Sprint(e))
+		}
+		fail("oneof=" + strings.Join(values, " "))
+	}
+}
+
+func (s *Schema) validateObject(obj map[string]any, field string, errs *[]apierr.FieldError) {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			*errs = append(*errs, apierr.FieldError{Field: join(field, name), Reason: "required"})
+		}
+	}
+
+	// sorted, so the same request always gets the same details
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		prop, ok := s.Properties[name]
+		if !ok {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				*errs = append(*errs, apierr.FieldError{Field: join(field, name), Reason: "unknown"})
+			}
+			continue
+		}
+		// optional fields like email on sign up are cleared with empty
+		// strings, format doesn't apply to them, minLength still does
+		if obj[name] == "" && prop.Format != "" && !slices.Contains(s.Required, name) {
+			unformatted := *prop
+			unformatted.Format = ""
+			prop = &unformatted
+		}
+		prop.validate(obj[name], join(field, name), errs)
+	}
+}
+
+func validFormat(format, s string) bool {
+	switch format {
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	}
+	return true
+}
+
+func inEnum(enum []any, v any) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func join(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
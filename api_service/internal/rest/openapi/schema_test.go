@@ -0,0 +1,178 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/liriquew/social-todo/api_service/internal/rest/apierr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestSchema_Validate(t *testing.T) {
+	item := &Schema{
+		Type:     "object",
+		Required: []string{"name"},
+		Properties: map[string]*Schema{
+			"name":  {Type: "string", MinLength: ptr(1), MaxLength: ptr(3)},
+			"email": {Type: "string", Format: "email"},
+			"since": {Type: "string", Format: "date-time"},
+			"count": {Type: "integer", Minimum: ptr(1.0), Maximum: ptr(10.0)},
+			"ratio": {Type: "number", Minimum: ptr(0.5)},
+			"done":  {Type: "boolean"},
+			"kind":  {Type: "string", Enum: []any{"a", "b"}},
+			"note":  {Type: "string", Nullable: true},
+			"tags":  {Type: "array", MinItems: ptr(1), MaxItems: ptr(2), Items: &Schema{Type: "string", MinLength: ptr(1)}},
+			"nick":  {Type: "string", Format: "email", MinLength: ptr(1)},
+			"extra": {Type: "object", AdditionalProperties: ptr(false), Properties: map[string]*Schema{
+				"bio": {Type: "string", MaxLength: ptr(2)},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want []apierr.FieldError
+	}{
+		{
+			name: "valid",
+			body: `{"name": "ééé", "email": "a@example.com", "since": "2026-01-02T03:04:05Z", "count": 10,
+				"ratio": 0.5, "done": true, "kind": "b", "note": null, "tags": ["x"], "extra": {"bio": "hi"}}`,
+		},
+		{
+			name: "required",
+			body: `{}`,
+			want: []apierr.FieldError{{Field: "name", Reason: "required"}},
+		},
+		{
+			name: "types",
+			body: `{"name": 1, "count": "1", "ratio": true, "done": "yes", "tags": {}, "extra": [], "kind": null}`,
+			want: []apierr.FieldError{
+				{Field: "count", Reason: "type=integer"},
+				{Field: "done", Reason: "type=boolean"},
+				{Field: "extra", Reason: "type=object"},
+				{Field: "kind", Reason: "type=string"},
+				{Field: "name", Reason: "type=string"},
+				{Field: "ratio", Reason: "type=number"},
+				{Field: "tags", Reason: "type=array"},
+			},
+		},
+		{
+			name: "bounds",
+			body: `{"name": "abcd", "count": 11, "ratio": 0.25, "tags": []}`,
+			want: []apierr.FieldError{
+				{Field: "count", Reason: "max=10"},
+				{Field: "name", Reason: "max=3"},
+				{Field: "ratio", Reason: "min=0.5"},
+				{Field: "tags", Reason: "min=1"},
+			},
+		},
+		{
+			name: "integer",
+			body: `{"name": "a", "count": 1.5}`,
+			want: []apierr.FieldError{{Field: "count", Reason: "type=integer"}},
+		},
+		{
+			name: "formats",
+			body: `{"name": "a", "email": "Name <a@example.com>", "since": "2026-01-02"}`,
+			want: []apierr.FieldError{
+				{Field: "email", Reason: "email"},
+				{Field: "since", Reason: "date-time"},
+			},
+		},
+		{
+			name: "optional empty formats",
+			body: `{"name": "a", "email": "", "since": ""}`,
+		},
+		{
+			name: "optional empty keeps minLength",
+			body: `{"name": "a", "nick": ""}`,
+			want: []apierr.FieldError{{Field: "nick", Reason: "min=1"}},
+		},
+		{
+			name: "enum",
+			body: `{"name": "a", "kind": "c"}`,
+			want: []apierr.FieldError{{Field: "kind", Reason: "oneof=a b"}},
+		},
+		{
+			name: "items and nested",
+			body: `{"name": "a", "tags": ["x", "", "y"], "extra": {"bio": "long", "age": 1}}`,
+			want: []apierr.FieldError{
+				{Field: "extra.age", Reason: "unknown"},
+				{Field: "extra.bio", Reason: "max=2"},
+				{Field: "tags", Reason: "max=2"},
+				{Field: "tags[1]", Reason: "min=1"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := decode([]byte(tt.body))
+			require.NoError(t, err)
+
+			var errs []apierr.FieldError
+			item.validate(v, "", &errs)
+			assert.Equal(t, tt.want, errs)
+		})
+	}
+}
+
+func TestSchema_ValidateRequiredFormats(t *testing.T) {
+	s := &Schema{
+		Type:     "object",
+		Required: []string{"email", "at"},
+		Properties: map[string]*Schema{
+			"email": {Type: "string", Format: "email"},
+			"at":    {Type: "string", Format: "date-time"},
+		},
+	}
+
+	v, err := decode([]byte(`{"email": "", "at": ""}`))
+	require.NoError(t, err)
+
+	var errs []apierr.FieldError
+	s.validate(v, "", &errs)
+	assert.Equal(t, []apierr.FieldError{
+		{Field: "at", Reason: "date-time"},
+		{Field: "email", Reason: "email"},
+	}, errs)
+}
+
+func TestSchema_ValidateAllOf(t *testing.T) {
+	s := &Schema{AllOf: []*Schema{
+		{Type: "object", Required: []string{"id"}},
+		{Type: "object", Required: []string{"name"}, Properties: map[string]*Schema{
+			"name": {Type: "string"},
+		}},
+	}}
+
+	tests := []struct {
+		body string
+		want []apierr.FieldError
+	}{
+		{body: `{"id": 1, "name": "a"}`},
+		{body: `{"name": 1}`, want: []apierr.FieldError{
+			{Field: "id", Reason: "required"},
+			{Field: "name", Reason: "type=string"},
+		}},
+		{body: `null`, want: []apierr.FieldError{
+			{Field: "", Reason: "type=object"},
+			{Field: "", Reason: "type=object"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			v, err := decode([]byte(tt.body))
+			require.NoError(t, err)
+
+			var errs []apierr.FieldError
+			s.validate(v, "", &errs)
+			assert.Equal(t, tt.want, errs)
+		})
+	}
+}
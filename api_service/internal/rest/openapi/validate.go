@@ -0,0 +1,194 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/liriquew/social-todo/api_service/internal/rest/apierr"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxBodySize = 1 << 20
+
+// Validate checks parameters and body of requests to documented routes,
+// invalid ones are rejected with 400 listing failed fields. If enabled,
+// responses are checked too, mismatches are only logged. It's used after
+// authentication of the route.
+func (s *Spec) Validate(c *gin.Context) {
+	o, ok := s.operation(c.Request.Method, c.FullPath())
+	if !ok {
+		c.Next()
+		return
+	}
+
+	var errs []apierr.FieldError
+	o.validateParams(c, &errs)
+
+	if schema := o.RequestBody.schema(); schema != nil {
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize))
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				apierr.Abort(c, http.StatusRequestEntityTooLarge, apierr.CodeBadRequest, "request body is too large")
+				return
+			}
+			apierr.BadRequest(c, "failed to read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if len(body) == 0 {
+			if o.RequestBody.Required {
+				errs = append(errs, apierr.FieldError{Field: "body", Reason: "required"})
+			}
+		} else {
+			v, err := decode(body)
+			if err != nil {
+				apierr.BadRequest(c, "malformed request body")
+				return
+			}
+			schema.validate(v, "", &errs)
+		}
+	}
+
+	if len(errs) != 0 {
+		// errors of the body itself or its items, if it's an array
+		for i := range errs {
+			if errs[i].Field == "" || strings.HasPrefix(errs[i].Field, "[") {
+				errs[i].Field = "body" + errs[i].Field
+			}
+		}
+		apierr.AbortWithDetails(c, http.StatusBadRequest, apierr.CodeBadRequest, "invalid request", errs)
+		return
+	}
+
+	if !s.validateResponses {
+		c.Next()
+		return
+	}
+
+	w := &recorder{ResponseWriter: c.Writer}
+	c.Writer = w
+
+	c.Next()
+
+	s.checkResponse(c, o, w.body.Bytes())
+}
+
+func (o *operation) validateParams(c *gin.Context, errs *[]apierr.FieldError) {
+	for i, p := range o.pathParams {
+		if i < len(c.Params) {
+			p.validate(c.Params[i].Value, errs)
+		}
+	}
+
+	query := c.Request.URL.Query()
+	for _, p := range o.Parameters {
+		if p.In != "query" {
+			continue
+		}
+		value, ok := query[p.Name]
+		if !ok || len(value) == 0 {
+			if p.Required {
+				*errs = append(*errs, apierr.FieldError{Field: p.Name, Reason: "required"})
+			}
+			continue
+		}
+		p.validate(value[0], errs)
+	}
+}
+
+// validate converts raw value to the parameter type and checks it.
+func (p *parameter) validate(raw string, errs *[]apierr.FieldError) {
+	if p.Schema == nil {
+		return
+	}
+
+	var v any = raw
+	switch p.Schema.Type {
+	case "integer", "number":
+		v = json.Number(raw)
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			*errs = append(*errs, apierr.FieldError{Field: p.Name, Reason: "type=boolean"})
+			return
+		}
+		v = b
+	}
+
+	p.Schema.validate(v, p.Name, errs)
+}
+
+// checkResponse logs response that doesn't match the document, either
+// its status is not described or body doesn't match the schema.
+func (s *Spec) checkResponse(c *gin.Context, o *operation, body []byte) {
+	log := s.log.With(
+		slog.String("method", c.Request.Method),
+		slog.String("route", c.FullPath()),
+		slog.Int("status", c.Writer.Status()),
+	)
+
+	resp, ok := o.Responses[strconv.Itoa(c.Writer.Status())]
+	if !ok {
+		resp, ok = o.Responses["default"]
+	}
+	if !ok {
+		log.Warn("response status is not described")
+		return
+	}
+
+	schema := resp.schema()
+	if schema == nil || len(body) == 0 {
+		return
+	}
+
+	v, err := decode(body)
+	if err != nil {
+		log.Warn("response body is not json")
+		return
+	}
+
+	var errs []apierr.FieldError
+	schema.validate(v, "", &errs)
+	if len(errs) != 0 {
+		log.Warn("response doesn't match description", slog.Any("fields", errs))
+	}
+}
+
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("data after json value")
+	}
+	return v, nil
+}
+
+// recorder keeps copy of the response body for checkResponse.
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *recorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,173 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/liriquew/social-todo/api_service/internal/rest/apierr"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParameter_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		p    *parameter
+		raw  string
+		want []apierr.FieldError
+	}{
+		{name: "integer", p: &parameter{Name: "id", Schema: &Schema{Type: "integer", Minimum: ptr(1.0)}}, raw: "42"},
+		{
+			name: "not integer",
+			p:    &parameter{Name: "id", Schema: &Schema{Type: "integer"}},
+			raw:  "abc",
+			want: []apierr.FieldError{{Field: "id", Reason: "type=integer"}},
+		},
+		{
+			name: "below minimum",
+			p:    &parameter{Name: "id", Schema: &Schema{Type: "integer", Minimum: ptr(1.0)}},
+			raw:  "0",
+			want: []apierr.FieldError{{Field: "id", Reason: "min=1"}},
+		},
+		{name: "boolean", p: &parameter{Name: "all", Schema: &Schema{Type: "boolean"}}, raw: "true"},
+		{
+			name: "not boolean",
+			p:    &parameter{Name: "all", Schema: &Schema{Type: "boolean"}},
+			raw:  "yes",
+			want: []apierr.FieldError{{Field: "all", Reason: "type=boolean"}},
+		},
+		{
+			name: "string",
+			p:    &parameter{Name: "q", Schema: &Schema{Type: "string", MinLength: ptr(2)}},
+			raw:  "a",
+			want: []apierr.FieldError{{Field: "q", Reason: "min=2"}},
+		},
+		{
+			name: "format",
+			p:    &parameter{Name: "since", Schema: &Schema{Type: "string", Format: "date-time"}},
+			raw:  "",
+			want: []apierr.FieldError{{Field: "since", Reason: "date-time"}},
+		},
+		{name: "no schema", p: &parameter{Name: "q"}, raw: "anything"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs []apierr.FieldError
+			tt.p.validate(tt.raw, &errs)
+			assert.Equal(t, tt.want, errs)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	item := &Schema{
+		Type:     "object",
+		Required: []string{"name"},
+		Properties: map[string]*Schema{
+			"name": {Type: "string", MinLength: ptr(1)},
+		},
+	}
+	id := &parameter{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer"}}
+	spec := &Spec{
+		log: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		operations: map[string]*operation{
+			key(http.MethodPost, "/items/{}"): {
+				Parameters: []*parameter{
+					id,
+					{Name: "dry", In: "query", Required: true, Schema: &Schema{Type: "boolean"}},
+				},
+				RequestBody: &requestBody{Required: true, Content: map[string]mediaType{
+					"application/json": {Schema: &Schema{Type: "array", Items: item, MaxItems: ptr(2)}},
+				}},
+				pathParams: []*parameter{id},
+			},
+		},
+	}
+
+	r := gin.New()
+	r.POST("/items/:id", spec.Validate, func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	r.POST("/other", spec.Validate, func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name    string
+		target  string
+		body    string
+		status  int
+		message string
+		details []apierr.FieldError
+	}{
+		{name: "valid", target: "/items/1?dry=true", body: `[{"name": "a"}]`, status: http.StatusNoContent},
+		{name: "not documented", target: "/other", body: `not json`, status: http.StatusNoContent},
+		{
+			name:    "parameters",
+			target:  "/items/abc",
+			body:    `[]`,
+			status:  http.StatusBadRequest,
+			message: "invalid request",
+			details: []apierr.FieldError{
+				{Field: "id", Reason: "type=integer"},
+				{Field: "dry", Reason: "required"},
+			},
+		},
+		{
+			name:    "missing body",
+			target:  "/items/1?dry=false",
+			status:  http.StatusBadRequest,
+			message: "invalid request",
+			details: []apierr.FieldError{{Field: "body", Reason: "required"}},
+		},
+		{
+			name:    "body and its items",
+			target:  "/items/1?dry=false",
+			body:    `[{"name": ""}, {}, {"name": "c"}]`,
+			status:  http.StatusBadRequest,
+			message: "invalid request",
+			details: []apierr.FieldError{
+				{Field: "body", Reason: "max=2"},
+				{Field: "body[0].name", Reason: "min=1"},
+				{Field: "body[1].name", Reason: "required"},
+			},
+		},
+		{
+			name:    "malformed body",
+			target:  "/items/1?dry=false",
+			body:    `[{"name": "a"}] []`,
+			status:  http.StatusBadRequest,
+			message: "malformed request body",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body)))
+
+			require.Equal(t, tt.status, w.Code)
+			if tt.status != http.StatusBadRequest {
+				return
+			}
+
+			var resp struct {
+				Code    string              `json:"code"`
+				Message string              `json:"message"`
+				Details []apierr.FieldError `json:"details"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, apierr.CodeBadRequest, resp.Code)
+			assert.Equal(t, tt.message, resp.Message)
+			assert.Equal(t, tt.details, resp.Details)
+		})
+	}
+}
@@ -1,14 +1,17 @@
 package handlers
 
 import (
-	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	auth_grpc "github.com/liriquew/social-todo/api_service/internal/clients/authgrpc"
 	friends_grpc "github.com/liriquew/social-todo/api_service/internal/clients/friendsgrpc"
 	notes_grpc "github.com/liriquew/social-todo/api_service/internal/clients/notesgrpc"
+	"github.com/liriquew/social-todo/api_service/internal/models"
+	"github.com/liriquew/social-todo/api_service/internal/rest/apierr"
+	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
 )
 
 type GeneralAPI interface {
@@ -19,60 +22,123 @@ type General struct {
 	log           *slog.Logger
 	notesClient   *notes_grpc.Client
 	friendsClient *friends_grpc.Client
+	authClient    *auth_grpc.Client
 }
 
-func New(log *slog.Logger, notesClient *notes_grpc.Client, friendsClient *friends_grpc.Client) *General {
+func New(
+	log *slog.Logger,
+	notesClient *notes_grpc.Client,
+	friendsClient *friends_grpc.Client,
+	authClient *auth_grpc.Client,
+) *General {
 	return &General{
 		log:           log,
 		notesClient:   notesClient,
 		friendsClient: friendsClient,
+		authClient:    authClient,
 	}
 }
 
 func (a *General) ListLastNotes(c *gin.Context) {
 	uid := c.Value("uid").(int64)
 	if uid <= 0 {
-		c.Status(http.StatusUnauthorized)
+		apierr.Unauthenticated(c)
 		return
 	}
 
 	offset, err := strconv.ParseInt(c.Query("offset"), 10, 64)
-	if err != nil {
-		c.Status(http.StatusBadRequest)
+	if err != nil || offset < 0 {
+		apierr.BadRequest(c, "bad offset")
+		return
 	}
 	limit, err := strconv.ParseInt(c.Query("limit"), 10, 64)
 	if err != nil {
-		c.Status(http.StatusBadRequest)
-	}
-
-	if offset < 0 {
-		c.Status(http.StatusBadRequest)
+		apierr.BadRequest(c, "bad limit")
 		return
 	}
 	if limit > 10 {
-		c.String(http.StatusBadRequest, "limit value is too big")
+		apierr.BadRequest(c, "limit value is too big")
+		return
 	}
 
 	FIDs, err := a.friendsClient.ListFriends(c, uid)
 	if err != nil {
-		c.Status(http.StatusInternalServerError)
+		a.log.Warn("failed to list friends", sl.Err(err))
+		apierr.Write(c, err)
 		return
 	}
 
-	if len(FIDs) == 0 {
-		c.Status(http.StatusNotFound)
+	following, err := a.friendsClient.ListFollowing(c, uid)
+	if err != nil {
+		a.log.Warn("failed to list following", sl.Err(err))
+		apierr.Write(c, err)
 		return
 	}
 
-	notes, err := a.notesClient.ListUsersNotes(c, FIDs, offset, limit)
-	if err != nil {
-		if errors.Is(err, notes_grpc.ErrNotFound) {
-			c.Status(http.StatusNotFound)
-			return
+	// friends' notes are shown in full, followed users' only if public
+	isFriend := make(map[int64]struct{}, len(FIDs))
+	for _, FID := range FIDs {
+		isFriend[FID] = struct{}{}
+	}
+	publicUIDs := make([]int64, 0, len(following))
+	for _, UID := range following {
+		if _, ok := isFriend[UID]; !ok {
+			publicUIDs = append(publicUIDs, UID)
 		}
-		c.Status(http.StatusInternalServerError)
+	}
+
+	if len(FIDs) == 0 && len(publicUIDs) == 0 {
+		apierr.Abort(c, http.StatusNotFound, apierr.CodeNotFound, "no friends or followed users")
 		return
 	}
 
+	notes, err := a.notesClient.ListUsersNotes(c, FIDs, publicUIDs, offset, limit)
+	if err != nil {
+		a.log.Warn("failed to list notes", sl.Err(err))
+		apierr.Write(c, err)
+		return
+	}
+
+	// feed is still useful without names, so sso_service errors are not fatal
+	if err := a.fillAuthors(c, notes); err != nil {
+		a.log.Warn("failed to get note authors", sl.Err(err))
+	}
+
 	c.JSON(http.StatusOK, notes)
 }
+
+// fillAuthors sets author of each note from sso_service profiles.
+func (a *General) fillAuthors(c *gin.Context, notes []*models.Note) error {
+	if len(notes) == 0 {
+		return nil
+	}
+
+	UIDs := make([]int64, 0, len(notes))
+	seen := make(map[int64]struct{}, len(notes))
+	for _, note := range notes {
+		if _, ok := seen[note.UID]; !ok {
+			seen[note.UID] = struct{}{}
+			UIDs = append(UIDs, note.UID)
+		}
+	}
+
+	profiles, err := a.authClient.Profiles(c, UIDs)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[int64]*models.UserInfo, len(profiles))
+	for _, profile := range profiles {
+		byID[profile.UID] = &models.UserInfo{
+			UID:         profile.UID,
+			Username:    profile.Username,
+			DisplayName: profile.DisplayName,
+			AvatarURL:   profile.AvatarURL,
+		}
+	}
+	for _, note := range notes {
+		note.Author = byID[note.UID]
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package users
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/liriquew/social-todo/api_service/internal/models"
+	"github.com/liriquew/social-todo/api_service/internal/rest/apierr"
+
+	auth_grpc "github.com/liriquew/social-todo/api_service/internal/clients/authgrpc"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UsersAPI interface {
+	Me(c *gin.Context)
+	UpdateMe(c *gin.Context)
+	Get(c *gin.Context)
+	Search(c *gin.Context)
+	Block(c *gin.Context)
+	Unblock(c *gin.Context)
+	DeleteMe(c *gin.Context)
+	Deletion(c *gin.Context)
+}
+
+type Users struct {
+	log        *slog.Logger
+	authClient *auth_grpc.Client
+}
+
+func New(log *slog.Logger, authClient *auth_grpc.Client) *Users {
+	return &Users{
+		log:        log,
+		authClient: authClient,
+	}
+}
+
+func (u *Users) Me(c *gin.Context) {
+	u.log.Info("Me")
+
+	uid := c.Value("uid").(int64)
+	if uid <= 0 {
+		apierr.Unauthenticated(c)
+		return
+	}
+
+	u.writeProfile(c, uid)
+}
+
+func (u *Users) Get(c *gin.Context) {
+	u.log.Info("Get")
+
+	uid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || uid <= 0 {
+		apierr.BadRequest(c, "bad user id")
+		return
+	}
+
+	u.writeProfile(c, uid)
+}
+
+func (u *Users) writeProfile(c *gin.Context, uid int64) {
+	profile, err := u.authClient.Profile(c, uid)
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+func (u *Users) UpdateMe(c *gin.Context) {
+	u.log.Info("UpdateMe")
+
+	var update models.ProfileUpdate
+
+	if err := c.ShouldBindJSON(&update); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	profile, err := u.authClient.UpdateProfile(c, c.GetHeader("Authorization"), update)
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+func (u *Users) Search(c *gin.Context) {
+	u.log.Info("Search")
+
+	var limit int64
+	if l := c.Query("limit"); l != "" {
+		var err error
+		limit, err = strconv.ParseInt(l, 10, 64)
+		if err != nil || limit < 0 {
+			apierr.BadRequest(c, "bad limit")
+			return
+		}
+	}
+
+	page, err := u.authClient.SearchUsers(c, c.GetHeader("Authorization"), c.Query("q"), c.Query("cursor"), limit)
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (u *Users) Block(c *gin.Context) {
+	u.log.Info("Block")
+
+	u.setBlocked(c, u.authClient.BlockUser)
+}
+
+func (u *Users) Unblock(c *gin.Context) {
+	u.log.Info("Unblock")
+
+	u.setBlocked(c, u.authClient.UnblockUser)
+}
+
+func (u *Users) setBlocked(c *gin.Context, set func(context.Context, string, int64) error) {
+	uid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || uid <= 0 {
+		apierr.BadRequest(c, "bad user id")
+		return
+	}
+
+	if err := set(c, c.GetHeader("Authorization"), uid); err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteMe deletes user's account. Deletion of user's data finishes
+// in background, its status is available by returned deletion ID.
+func (u *Users) DeleteMe(c *gin.Context) {
+	u.log.Info("DeleteMe")
+
+	var req models.PasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	deletion, err := u.authClient.DeleteAccount(c, c.GetHeader("Authorization"), req.Password)
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, deletion)
+}
+
+func (u *Users) Deletion(c *gin.Context) {
+	u.log.Info("Deletion")
+
+	deletion, err := u.authClient.AccountDeletion(c, c.Param("id"))
+	if err != nil {
+		apierr.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deletion)
+}
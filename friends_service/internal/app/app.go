@@ -6,8 +6,11 @@ import (
 
 	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
 	grpcapp "github.com/liriquew/social-todo/friends_service/internal/app/app"
+	"github.com/liriquew/social-todo/friends_service/internal/events"
 	friendssrvc "github.com/liriquew/social-todo/friends_service/internal/grpc/friendsservice"
 	"github.com/liriquew/social-todo/friends_service/internal/lib/config"
+	"github.com/liriquew/social-todo/friends_service/internal/notifications"
+	mem_storage "github.com/liriquew/social-todo/friends_service/internal/storage/memory"
 	neo_storage "github.com/liriquew/social-todo/friends_service/internal/storage/neo4j"
 )
 
@@ -17,13 +20,20 @@ type App struct {
 	log        *slog.Logger
 }
 
+type storageCloser interface {
+	friendssrvc.StorageProvider
+	notifications.Store
+	Close() error
+}
+
 func New(log *slog.Logger, cfg config.Config) *App {
-	storage, err := neo_storage.New(cfg.Neo4jCfg)
-	if err != nil {
-		panic(err)
-	}
+	storage := mustStorage(log, cfg)
+
+	bus := events.NewBus()
+	inbox := notifications.NewInbox(storage, cfg.NotificationsCfg.MaxPerUser)
+	bus.Subscribe(inbox.Handle)
 
-	friends_service := friendssrvc.New(log, storage)
+	friends_service := friendssrvc.New(log, storage, cfg.InviteCfg, bus, inbox)
 
 	app := grpcapp.New(log, friends_service, cfg.Port)
 
@@ -32,6 +42,22 @@ func New(log *slog.Logger, cfg config.Config) *App {
 	return mainApp
 }
 
+func mustStorage(log *slog.Logger, cfg config.Config) storageCloser {
+	switch cfg.Storage {
+	case config.StorageNeo4j:
+		storage, err := neo_storage.New(cfg.Neo4jCfg)
+		if err != nil {
+			panic(err)
+		}
+		return storage
+	case config.StorageMemory:
+		log.Warn("using in-memory storage, data will be lost on restart")
+		return mem_storage.New()
+	default:
+		panic("unknown storage type: " + cfg.Storage)
+	}
+}
+
 func (a *App) Stop() {
 
 	const op = "app.App.Stop"
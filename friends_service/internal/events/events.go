@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"github.com/liriquew/social-todo/friends_service/internal/models"
+)
+
+type Publisher interface {
+	Publish(context.Context, models.Event) error
+}
+
+type Handler func(context.Context, models.Event) error
+
+// Bus is in-process Publisher, it calls subscribed handlers synchronously.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+func (b *Bus) Subscribe(h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers = append(b.handlers, h)
+}
+
+// Publish passes event to every handler and returns the first error,
+// handlers after the failed one are still called.
+func (b *Bus) Publish(ctx context.Context, event models.Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var firstErr error
+	for _, h := range b.handlers {
+		if err := h(ctx, event); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -2,21 +2,75 @@ package friendssrvc
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
-	neo_storage "github.com/liriquew/social-todo/friends_service/internal/storage/neo4j"
+	"github.com/liriquew/social-todo/friends_service/internal/events"
+	"github.com/liriquew/social-todo/friends_service/internal/lib/config"
+	"github.com/liriquew/social-todo/friends_service/internal/models"
+	"github.com/liriquew/social-todo/friends_service/internal/storage"
 )
 
 type ServiceFriends struct {
-	log     *slog.Logger
-	Storage *neo_storage.Storage
+	log       *slog.Logger
+	Storage   StorageProvider
+	inviteCfg config.InviteConfig
+	publisher events.Publisher
+	inbox     NotificationsInbox
 }
 
-func New(log *slog.Logger, storage *neo_storage.Storage) *ServiceFriends {
+type StorageProvider interface {
+	AddFriend(context.Context, int64, int64) (models.AddFriendResult, error)
+	RemoveFriend(context.Context, int64, int64) (bool, error)
+	ListFriends(context.Context, int64, *models.FriendsCursor, int64) ([]models.Friend, error)
+
+	Follow(context.Context, int64, int64) error
+	Unfollow(context.Context, int64, int64) error
+	ListFollowers(context.Context, int64) ([]int64, error)
+	ListFollowing(context.Context, int64) ([]int64, error)
+
+	SaveInvite(context.Context, models.Invite) error
+	UseInvite(context.Context, string, int64) (models.InviteUse, error)
+	RevokeInvite(context.Context, int64, string) error
+	ListInvites(context.Context, int64) ([]models.Invite, error)
+
+	DeleteUser(context.Context, int64) error
+}
+
+type NotificationsInbox interface {
+	List(context.Context, int64, bool) ([]models.Notification, int64, error)
+	MarkRead(context.Context, int64, []int64) error
+	DeleteUser(context.Context, int64) error
+}
+
+var (
+	ErrBadCursor      = fmt.Errorf("bad cursor")
+	ErrBadInvite      = fmt.Errorf("invite is invalid, expired or used up")
+	ErrSelfInvite     = fmt.Errorf("can't accept your own invite")
+	ErrInviteNotFound = fmt.Errorf("invite not found")
+	ErrTooManyUses    = fmt.Errorf("invite max uses value is too big")
+)
+
+func New(
+	log *slog.Logger,
+	storage StorageProvider,
+	inviteCfg config.InviteConfig,
+	publisher events.Publisher,
+	inbox NotificationsInbox,
+) *ServiceFriends {
 	return &ServiceFriends{
-		log:     log,
-		Storage: storage,
+		log:       log,
+		Storage:   storage,
+		inviteCfg: inviteCfg,
+		publisher: publisher,
+		inbox:     inbox,
 	}
 }
 
@@ -25,13 +79,21 @@ func (s *ServiceFriends) AddFriend(ctx context.Context, UID, friendID int64) err
 
 	log := s.log.With(slog.String("op", op), slog.Int64("UID", UID), slog.Int64("FID", friendID))
 	log.Info("Attempting to add friend")
-	err := s.Storage.AddFriend(ctx, UID, friendID)
+	res, err := s.Storage.AddFriend(ctx, UID, friendID)
 	if err != nil {
 		s.log.Warn("ERROR", sl.Err(err))
 
 		return err
 	}
 
+	if res.Created {
+		eventType := models.EventFriendRequestSent
+		if res.Mutual {
+			eventType = models.EventFriendRequestAccepted
+		}
+		s.publish(ctx, log, eventType, UID, friendID)
+	}
+
 	return nil
 }
 
@@ -40,27 +102,310 @@ func (s *ServiceFriends) RemoveFriend(ctx context.Context, UID, friendID int64)
 
 	log := s.log.With(slog.String("op", op), slog.Int64("UID", UID), slog.Int64("FID", friendID))
 	log.Info("Attempting to remove friend")
-	err := s.Storage.RemoveFriend(ctx, UID, friendID)
+	removed, err := s.Storage.RemoveFriend(ctx, UID, friendID)
 	if err != nil {
 		s.log.Warn("ERROR", sl.Err(err))
 
 		return err
 	}
 
+	if removed {
+		s.publish(ctx, log, models.EventFriendRemoved, UID, friendID)
+	}
+
 	return nil
 }
 
-func (s *ServiceFriends) ListUserFriends(ctx context.Context, UID int64) ([]int64, error) {
+// ListUserFriends returns a page of UID's friends and a cursor for the next page.
+// The cursor is empty when there are no more friends.
+func (s *ServiceFriends) ListUserFriends(ctx context.Context, UID int64, cursor string, limit int64) ([]models.Friend, string, error) {
 	const op = "friendssrvc.ListUserFriends"
 
 	log := s.log.With(slog.String("op", op), slog.Int64("UID", UID))
 	log.Info("Attempting to list friends")
-	FIDs, err := s.Storage.ListFriends(ctx, UID)
+
+	var after *models.FriendsCursor
+	if cursor != "" {
+		c, err := decodeCursor(cursor)
+		if err != nil {
+			log.Warn("bad cursor", sl.Err(err))
+			return nil, "", ErrBadCursor
+		}
+		after = &c
+	}
+
+	friends, err := s.Storage.ListFriends(ctx, UID, after, limit)
+	if err != nil {
+		s.log.Warn("ERROR", sl.Err(err))
+
+		return nil, "", err
+	}
+
+	var next string
+	if limit > 0 && int64(len(friends)) == limit {
+		last := friends[len(friends)-1]
+		next = encodeCursor(models.FriendsCursor{Since: last.Since.UnixMilli(), UID: last.UID})
+	}
+
+	return friends, next, nil
+}
+
+func encodeCursor(c models.FriendsCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", c.Since, c.UID)))
+}
+
+func decodeCursor(cursor string) (models.FriendsCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return models.FriendsCursor{}, err
+	}
+
+	var c models.FriendsCursor
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &c.Since, &c.UID); err != nil {
+		return models.FriendsCursor{}, err
+	}
+
+	return c, nil
+}
+
+func (s *ServiceFriends) Follow(ctx context.Context, UID, followID int64) error {
+	const op = "friendssrvc.Follow"
+
+	log := s.log.With(slog.String("op", op), slog.Int64("UID", UID), slog.Int64("FollowID", followID))
+	log.Info("Attempting to follow user")
+	err := s.Storage.Follow(ctx, UID, followID)
+	if err != nil {
+		s.log.Warn("ERROR", sl.Err(err))
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *ServiceFriends) Unfollow(ctx context.Context, UID, followID int64) error {
+	const op = "friendssrvc.Unfollow"
+
+	log := s.log.With(slog.String("op", op), slog.Int64("UID", UID), slog.Int64("FollowID", followID))
+	log.Info("Attempting to unfollow user")
+	err := s.Storage.Unfollow(ctx, UID, followID)
+	if err != nil {
+		s.log.Warn("ERROR", sl.Err(err))
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *ServiceFriends) ListFollowers(ctx context.Context, UID int64) ([]int64, error) {
+	const op = "friendssrvc.ListFollowers"
+
+	log := s.log.With(slog.String("op", op), slog.Int64("UID", UID))
+	log.Info("Attempting to list followers")
+	UIDs, err := s.Storage.ListFollowers(ctx, UID)
 	if err != nil {
 		s.log.Warn("ERROR", sl.Err(err))
 
 		return nil, err
 	}
 
-	return FIDs, nil
+	return UIDs, nil
+}
+
+func (s *ServiceFriends) ListFollowing(ctx context.Context, UID int64) ([]int64, error) {
+	const op = "friendssrvc.ListFollowing"
+
+	log := s.log.With(slog.String("op", op), slog.Int64("UID", UID))
+	log.Info("Attempting to list following")
+	UIDs, err := s.Storage.ListFollowing(ctx, UID)
+	if err != nil {
+		s.log.Warn("ERROR", sl.Err(err))
+
+		return nil, err
+	}
+
+	return UIDs, nil
+}
+
+// CreateInvite creates invite link token of UID. maxUses <= 0 makes single-use invite.
+func (s *ServiceFriends) CreateInvite(ctx context.Context, UID, maxUses int64) (models.Invite, string, error) {
+	const op = "friendssrvc.CreateInvite"
+
+	log := s.log.With(slog.String("op", op), slog.Int64("UID", UID), slog.Int64("MaxUses", maxUses))
+	log.Info("Attempting to create invite")
+
+	if maxUses <= 0 {
+		maxUses = 1
+	}
+	if maxUses > s.inviteCfg.MaxUses {
+		return models.Invite{}, "", ErrTooManyUses
+	}
+
+	inviteID := make([]byte, 16)
+	token := make([]byte, inviteTokenSize)
+	if _, err := rand.Read(inviteID); err != nil {
+		log.Error("failed to generate invite id", sl.Err(err))
+		return models.Invite{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := rand.Read(token); err != nil {
+		log.Error("failed to generate invite token", sl.Err(err))
+		return models.Invite{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := time.Now()
+	invite := models.Invite{
+		ID:        hex.EncodeToString(inviteID),
+		InviterID: UID,
+		MaxUses:   maxUses,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.inviteCfg.TTL),
+		TokenHash: hashInviteToken(token),
+	}
+
+	if err := s.Storage.SaveInvite(ctx, invite); err != nil {
+		log.Warn("ERROR", sl.Err(err))
+		return models.Invite{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return invite, base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+const inviteTokenSize = 32
+
+// hashInviteToken is what storage keeps instead of the token, so
+// its contents are no good for accepting invites.
+func hashInviteToken(token []byte) string {
+	sum := sha256.Sum256(token)
+	return hex.EncodeToString(sum[:])
+}
+
+// AcceptInvite makes UID and the inviter friends. Returns inviter ID.
+// It's idempotent, accepting invite again takes none of its uses.
+func (s *ServiceFriends) AcceptInvite(ctx context.Context, UID int64, token string) (int64, error) {
+	const op = "friendssrvc.AcceptInvite"
+
+	log := s.log.With(slog.String("op", op), slog.Int64("UID", UID))
+	log.Info("Attempting to accept invite")
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) != inviteTokenSize {
+		log.Warn("bad invite token")
+		return 0, ErrBadInvite
+	}
+
+	use, err := s.Storage.UseInvite(ctx, hashInviteToken(raw), UID)
+	if err != nil {
+		log.Warn("ERROR", sl.Err(err))
+		if errors.Is(err, storage.ErrInviteInvalid) {
+			return 0, ErrBadInvite
+		}
+		if errors.Is(err, storage.ErrInviteOwn) {
+			return 0, ErrSelfInvite
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// nothing changes on repeated accept or for friends already
+	if use.Accepted {
+		s.publish(ctx, log, models.EventFriendRequestAccepted, UID, use.InviterID)
+	}
+
+	return use.InviterID, nil
+}
+
+func (s *ServiceFriends) RevokeInvite(ctx context.Context, UID int64, inviteID string) error {
+	const op = "friendssrvc.RevokeInvite"
+
+	log := s.log.With(slog.String("op", op), slog.Int64("UID", UID), slog.String("InviteID", inviteID))
+	log.Info("Attempting to revoke invite")
+
+	if err := s.Storage.RevokeInvite(ctx, UID, inviteID); err != nil {
+		log.Warn("ERROR", sl.Err(err))
+		if errors.Is(err, storage.ErrInviteNotFound) {
+			return ErrInviteNotFound
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *ServiceFriends) ListInvites(ctx context.Context, UID int64) ([]models.Invite, error) {
+	const op = "friendssrvc.ListInvites"
+
+	log := s.log.With(slog.String("op", op), slog.Int64("UID", UID))
+	log.Info("Attempting to list invites")
+
+	invites, err := s.Storage.ListInvites(ctx, UID)
+	if err != nil {
+		log.Warn("ERROR", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return invites, nil
+}
+
+// ListNotifications returns UID's notifications, newest first, and the number of unread ones.
+func (s *ServiceFriends) ListNotifications(ctx context.Context, UID int64, unreadOnly bool) ([]models.Notification, int64, error) {
+	const op = "friendssrvc.ListNotifications"
+
+	log := s.log.With(slog.String("op", op), slog.Int64("UID", UID))
+	log.Info("Attempting to list notifications")
+
+	list, unread, err := s.inbox.List(ctx, UID, unreadOnly)
+	if err != nil {
+		log.Warn("ERROR", sl.Err(err))
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return list, unread, nil
+}
+
+func (s *ServiceFriends) MarkNotificationsRead(ctx context.Context, UID int64, IDs []int64) error {
+	const op = "friendssrvc.MarkNotificationsRead"
+
+	log := s.log.With(slog.String("op", op), slog.Int64("UID", UID))
+	log.Info("Attempting to mark notifications read")
+
+	if err := s.inbox.MarkRead(ctx, UID, IDs); err != nil {
+		log.Warn("ERROR", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteUser detaches deleted account from the graph and drops its notifications.
+// No events are published, it's idempotent, so sso_service can safely retry it.
+func (s *ServiceFriends) DeleteUser(ctx context.Context, UID int64) error {
+	const op = "friendssrvc.DeleteUser"
+
+	log := s.log.With(slog.String("op", op), slog.Int64("UID", UID))
+	log.Info("Attempting to delete user")
+
+	if err := s.Storage.DeleteUser(ctx, UID); err != nil {
+		log.Warn("ERROR", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.inbox.DeleteUser(ctx, UID); err != nil {
+		log.Warn("ERROR", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// publish doesn't fail the operation, the change is already stored.
+func (s *ServiceFriends) publish(ctx context.Context, log *slog.Logger, eventType string, actorID, targetID int64) {
+	err := s.publisher.Publish(ctx, models.Event{
+		Type:      eventType,
+		ActorID:   actorID,
+		TargetID:  targetID,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Warn("failed to publish event", slog.String("type", eventType), sl.Err(err))
+	}
 }
@@ -2,18 +2,37 @@ package friends_grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	friendssrvc "github.com/liriquew/social-todo/friends_service/internal/grpc/friendsservice"
+	"github.com/liriquew/social-todo/friends_service/internal/models"
 	"github.com/liriquew/todoprotos/gen/go/friends"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type FriendsService interface {
 	AddFriend(context.Context, int64, int64) error
 	RemoveFriend(context.Context, int64, int64) error
-	ListUserFriends(context.Context, int64) ([]int64, error)
+	ListUserFriends(context.Context, int64, string, int64) ([]models.Friend, string, error)
+
+	Follow(context.Context, int64, int64) error
+	Unfollow(context.Context, int64, int64) error
+	ListFollowers(context.Context, int64) ([]int64, error)
+	ListFollowing(context.Context, int64) ([]int64, error)
+
+	CreateInvite(context.Context, int64, int64) (models.Invite, string, error)
+	AcceptInvite(context.Context, int64, string) (int64, error)
+	RevokeInvite(context.Context, int64, string) error
+	ListInvites(context.Context, int64) ([]models.Invite, error)
+
+	ListNotifications(context.Context, int64, bool) ([]models.Notification, int64, error)
+	MarkNotificationsRead(context.Context, int64, []int64) error
+
+	DeleteUser(context.Context, int64) error
 }
 
 type serverAPI struct {
@@ -28,6 +47,12 @@ func Register(gRPC *grpc.Server, friendsService FriendsService) {
 var (
 	ErrBadUID      = fmt.Errorf("bad UID")
 	ErrBadFriendID = fmt.Errorf("bad friend ID")
+	ErrBadFollowID = fmt.Errorf("bad follow ID")
+	ErrSelfFollow  = fmt.Errorf("can't follow yourself")
+	ErrBadLimit    = fmt.Errorf("bad limit value")
+	ErrBadMaxUses  = fmt.Errorf("bad max uses value")
+	ErrEmptyToken  = fmt.Errorf("empty invite token")
+	ErrEmptyIID    = fmt.Errorf("empty invite ID")
 )
 
 func (s *serverAPI) AddFriend(ctx context.Context, req *friends.FriendRequest) (*friends.FriendResponse, error) {
@@ -61,12 +86,211 @@ func (s *serverAPI) ListFriends(ctx context.Context, req *friends.ListFriendRequ
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	friendIDs, err := s.api.ListUserFriends(ctx, req.UID)
+	friendsList, next, err := s.api.ListUserFriends(ctx, req.UID, req.Cursor, req.Limit)
+	if err != nil {
+		if errors.Is(err, friendssrvc.ErrBadCursor) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	friendIDs := make([]int64, 0, len(friendsList))
+	friendsItems := make([]*friends.Friend, 0, len(friendsList))
+	for _, fr := range friendsList {
+		friendIDs = append(friendIDs, fr.UID)
+		friendsItems = append(friendsItems, &friends.Friend{
+			FriendID: fr.UID,
+			Since:    timestamppb.New(fr.Since),
+		})
+	}
+
+	return &friends.ListFriendResponse{
+		FriendIDs:  friendIDs,
+		Friends:    friendsItems,
+		NextCursor: next,
+	}, nil
+}
+
+func (s *serverAPI) Follow(ctx context.Context, req *friends.FollowRequest) (*friends.FollowResponse, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err := s.api.Follow(ctx, req.UID, req.FollowID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &friends.FollowResponse{}, nil
+}
+
+func (s *serverAPI) Unfollow(ctx context.Context, req *friends.FollowRequest) (*friends.FollowResponse, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err := s.api.Unfollow(ctx, req.UID, req.FollowID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &friends.FollowResponse{}, nil
+}
+
+func (s *serverAPI) ListFollowers(ctx context.Context, req *friends.ListFollowRequest) (*friends.ListFollowResponse, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	UIDs, err := s.api.ListFollowers(ctx, req.UID)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	return &friends.ListFriendResponse{FriendIDs: friendIDs}, nil
+	return &friends.ListFollowResponse{UIDs: UIDs}, nil
+}
+
+func (s *serverAPI) ListFollowing(ctx context.Context, req *friends.ListFollowRequest) (*friends.ListFollowResponse, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	UIDs, err := s.api.ListFollowing(ctx, req.UID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &friends.ListFollowResponse{UIDs: UIDs}, nil
+}
+
+func (s *serverAPI) CreateInvite(ctx context.Context, req *friends.CreateInviteRequest) (*friends.CreateInviteResponse, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	invite, token, err := s.api.CreateInvite(ctx, req.UID, req.MaxUses)
+	if err != nil {
+		if errors.Is(err, friendssrvc.ErrTooManyUses) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &friends.CreateInviteResponse{
+		InviteID:  invite.ID,
+		Token:     token,
+		ExpiresAt: timestamppb.New(invite.ExpiresAt),
+	}, nil
+}
+
+func (s *serverAPI) AcceptInvite(ctx context.Context, req *friends.AcceptInviteRequest) (*friends.AcceptInviteResponse, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	inviterID, err := s.api.AcceptInvite(ctx, req.UID, req.Token)
+	if err != nil {
+		if errors.Is(err, friendssrvc.ErrBadInvite) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		if errors.Is(err, friendssrvc.ErrSelfInvite) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &friends.AcceptInviteResponse{InviterID: inviterID}, nil
+}
+
+func (s *serverAPI) RevokeInvite(ctx context.Context, req *friends.RevokeInviteRequest) (*friends.RevokeInviteResponse, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err := s.api.RevokeInvite(ctx, req.UID, req.InviteID)
+	if err != nil {
+		if errors.Is(err, friendssrvc.ErrInviteNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &friends.RevokeInviteResponse{}, nil
+}
+
+func (s *serverAPI) ListInvites(ctx context.Context, req *friends.ListInvitesRequest) (*friends.ListInvitesResponse, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	invites, err := s.api.ListInvites(ctx, req.UID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &friends.ListInvitesResponse{Invites: make([]*friends.Invite, 0, len(invites))}
+	for _, invite := range invites {
+		resp.Invites = append(resp.Invites, &friends.Invite{
+			InviteID:  invite.ID,
+			MaxUses:   invite.MaxUses,
+			Uses:      invite.Uses,
+			CreatedAt: timestamppb.New(invite.CreatedAt),
+			ExpiresAt: timestamppb.New(invite.ExpiresAt),
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) ListNotifications(ctx context.Context, req *friends.ListNotificationsRequest) (*friends.ListNotificationsResponse, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	list, unread, err := s.api.ListNotifications(ctx, req.UID, req.UnreadOnly)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &friends.ListNotificationsResponse{
+		Notifications: make([]*friends.Notification, 0, len(list)),
+		Unread:        unread,
+	}
+	for _, n := range list {
+		resp.Notifications = append(resp.Notifications, &friends.Notification{
+			ID:        n.ID,
+			Type:      n.Event.Type,
+			ActorID:   n.Event.ActorID,
+			Read:      n.Read,
+			CreatedAt: timestamppb.New(n.CreatedAt),
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) MarkNotificationsRead(ctx context.Context, req *friends.MarkNotificationsReadRequest) (*friends.MarkNotificationsReadResponse, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.api.MarkNotificationsRead(ctx, req.UID, req.IDs); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &friends.MarkNotificationsReadResponse{}, nil
+}
+
+func (s *serverAPI) DeleteUser(ctx context.Context, req *friends.DeleteUserRequest) (*friends.DeleteUserResponse, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.api.DeleteUser(ctx, req.UID); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &friends.DeleteUserResponse{}, nil
 }
 
 func validateRequest(req interface{}) error {
@@ -82,6 +306,60 @@ func validateRequest(req interface{}) error {
 		if val.UID <= 0 {
 			return ErrBadUID
 		}
+		if val.Limit < 0 {
+			return ErrBadLimit
+		}
+	case *friends.FollowRequest:
+		if val.UID <= 0 {
+			return ErrBadUID
+		}
+		if val.FollowID <= 0 {
+			return ErrBadFollowID
+		}
+		if val.UID == val.FollowID {
+			return ErrSelfFollow
+		}
+	case *friends.ListFollowRequest:
+		if val.UID <= 0 {
+			return ErrBadUID
+		}
+	case *friends.CreateInviteRequest:
+		if val.UID <= 0 {
+			return ErrBadUID
+		}
+		if val.MaxUses < 0 {
+			return ErrBadMaxUses
+		}
+	case *friends.AcceptInviteRequest:
+		if val.UID <= 0 {
+			return ErrBadUID
+		}
+		if val.Token == "" {
+			return ErrEmptyToken
+		}
+	case *friends.RevokeInviteRequest:
+		if val.UID <= 0 {
+			return ErrBadUID
+		}
+		if val.InviteID == "" {
+			return ErrEmptyIID
+		}
+	case *friends.ListInvitesRequest:
+		if val.UID <= 0 {
+			return ErrBadUID
+		}
+	case *friends.ListNotificationsRequest:
+		if val.UID <= 0 {
+			return ErrBadUID
+		}
+	case *friends.MarkNotificationsReadRequest:
+		if val.UID <= 0 {
+			return ErrBadUID
+		}
+	case *friends.DeleteUserRequest:
+		if val.UID <= 0 {
+			return ErrBadUID
+		}
 	}
 	return nil
 }
@@ -2,20 +2,41 @@ package config
 
 import (
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
+const (
+	StorageNeo4j  = "neo4j"
+	StorageMemory = "memory"
+)
+
 type Config struct {
-	Port     int         `yaml:"port" env-default:"4041"`
-	Neo4jCfg Neo4jConfig `yaml:"neo4j" env-required:"true"`
+	Port      int          `yaml:"port" env-default:"4041"`
+	Storage   string       `yaml:"storage" env-default:"neo4j"` // neo4j or memory
+	Neo4jCfg  Neo4jConfig  `yaml:"neo4j"`
+	InviteCfg InviteConfig `yaml:"invite"`
+
+	NotificationsCfg NotificationsConfig `yaml:"notifications"`
 }
 
+// Neo4jConfig is used only with neo4j storage.
 type Neo4jConfig struct {
-	Username string `yaml:"username" env-required:"true"`
-	Password string `yaml:"password" env-required:"true"`
-	Port     string `yaml:"port" env-required:"true"`
-	DBName   string `yaml:"db_name" env-required:"true"`
+	Host     string `yaml:"host" env-default:"localhost"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Port     string `yaml:"port" env-default:"7687"`
+	DBName   string `yaml:"db_name" env-default:"neo4j"`
+}
+
+type InviteConfig struct {
+	TTL     time.Duration `yaml:"ttl" env-default:"24h"`
+	MaxUses int64         `yaml:"max_uses" env-default:"100"`
+}
+
+type NotificationsConfig struct {
+	MaxPerUser int `yaml:"max_per_user" env-default:"100"`
 }
 
 func MustLoad() Config {
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	EventFriendRequestSent     = "friend_request_sent"
+	EventFriendRequestAccepted = "friend_request_accepted"
+	EventFriendRemoved         = "friend_removed"
+)
+
+// Event is a friendship domain event.
+// ActorID did something, TargetID is the user it was done to.
+type Event struct {
+	Type      string
+	ActorID   int64
+	TargetID  int64
+	CreatedAt time.Time
+}
+
+type Notification struct {
+	ID        int64
+	UID       int64
+	Event     Event
+	Read      bool
+	CreatedAt time.Time
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type Friend struct {
+	UID   int64
+	Since time.Time
+}
+
+// FriendsCursor points at the last friend of the previous page.
+// Friends are ordered by Since (newest first), then by UID.
+type FriendsCursor struct {
+	Since int64 // unix milli
+	UID   int64
+}
+
+// AddFriendResult describes what AddFriend changed.
+type AddFriendResult struct {
+	Created bool // UID's side didn't exist before
+	Mutual  bool // the other side exists, so users are friends now
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type Invite struct {
+	ID        string
+	InviterID int64
+	MaxUses   int64
+	Uses      int64
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	Revoked   bool
+
+	// sha256 of the invite token, the token itself is only given to the inviter
+	TokenHash string
+}
+
+// InviteUse describes what UseInvite changed.
+type InviteUse struct {
+	InviterID int64
+	Accepted  bool // a use was counted and users became friends
+}
@@ -0,0 +1,74 @@
+package notifications
+
+import (
+	"context"
+	"time"
+
+	"github.com/liriquew/social-todo/friends_service/internal/models"
+)
+
+// Store keeps notifications, storages of friends_service implement it.
+type Store interface {
+	// SaveNotification stores notification and drops the oldest ones of
+	// its user above maxPerUser.
+	SaveNotification(ctx context.Context, n models.Notification, maxPerUser int) error
+	ListNotifications(ctx context.Context, UID int64) ([]models.Notification, error)
+	MarkNotificationsRead(ctx context.Context, UID int64, IDs []int64) error
+	// DeleteNotifications drops UID's notifications and notifications about UID's actions.
+	DeleteNotifications(ctx context.Context, UID int64) error
+}
+
+// Inbox turns events into notifications of their targets.
+// Only the last maxPerUser notifications of each user are kept.
+type Inbox struct {
+	store      Store
+	maxPerUser int
+}
+
+func NewInbox(store Store, maxPerUser int) *Inbox {
+	return &Inbox{
+		store:      store,
+		maxPerUser: maxPerUser,
+	}
+}
+
+// Handle stores event as a notification of its target, it's events.Handler.
+func (i *Inbox) Handle(ctx context.Context, event models.Event) error {
+	return i.store.SaveNotification(ctx, models.Notification{
+		UID:       event.TargetID,
+		Event:     event,
+		CreatedAt: time.Now(),
+	}, i.maxPerUser)
+}
+
+// List returns UID's notifications, newest first, and the number of unread ones.
+func (i *Inbox) List(ctx context.Context, UID int64, unreadOnly bool) ([]models.Notification, int64, error) {
+	list, err := i.store.ListNotifications(ctx, UID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var unread int64
+	ans := make([]models.Notification, 0, len(list))
+	for _, n := range list {
+		if !n.Read {
+			unread++
+		}
+		if unreadOnly && n.Read {
+			continue
+		}
+		ans = append(ans, n)
+	}
+
+	return ans, unread, nil
+}
+
+// MarkRead marks UID's notifications with given IDs as read, all of them if IDs is empty.
+func (i *Inbox) MarkRead(ctx context.Context, UID int64, IDs []int64) error {
+	return i.store.MarkNotificationsRead(ctx, UID, IDs)
+}
+
+// DeleteUser drops UID's inbox and notifications about UID's actions.
+func (i *Inbox) DeleteUser(ctx context.Context, UID int64) error {
+	return i.store.DeleteNotifications(ctx, UID)
+}
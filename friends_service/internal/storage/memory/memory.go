@@ -0,0 +1,343 @@
+package mem_storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/liriquew/social-todo/friends_service/internal/models"
+	"github.com/liriquew/social-todo/friends_service/internal/storage"
+)
+
+// Storage keeps the friends graph in memory.
+// It has the same semantics as neo4j storage and is meant for local runs and tests.
+type Storage struct {
+	mu sync.RWMutex
+
+	friends map[int64]map[int64]int64 // from -> to -> since (unix milli)
+	follows map[int64]map[int64]struct{}
+	invites map[string]*models.Invite
+	tokens  map[string]string             // token hash -> invite ID
+	used    map[string]map[int64]struct{} // invite ID -> users who accepted it
+
+	notifications      map[int64][]*models.Notification // oldest first
+	lastNotificationID int64
+
+	now func() time.Time
+}
+
+func New() *Storage {
+	return &Storage{
+		friends: make(map[int64]map[int64]int64),
+		follows: make(map[int64]map[int64]struct{}),
+		invites: make(map[string]*models.Invite),
+		tokens:  make(map[string]string),
+		used:    make(map[string]map[int64]struct{}),
+
+		notifications: make(map[int64][]*models.Notification),
+		now:           time.Now,
+	}
+}
+
+func (s *Storage) Close() error {
+	return nil
+}
+
+// AddFriend stores UID's side of the friendship. Users become friends
+// only when both of them have added each other.
+func (s *Storage) AddFriend(ctx context.Context, UID, friendID int64) (models.AddFriendResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, existed := s.friends[UID][friendID]
+	s.addFriendEdge(UID, friendID)
+	_, mutual := s.friends[friendID][UID]
+
+	return models.AddFriendResult{Created: !existed, Mutual: mutual}, nil
+}
+
+// RemoveFriend drops the friendship (or pending request) in both directions.
+// Returns true if users were friends.
+func (s *Storage) RemoveFriend(ctx context.Context, UID, friendID int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok1 := s.friends[UID][friendID]
+	_, ok2 := s.friends[friendID][UID]
+
+	delete(s.friends[UID], friendID)
+	delete(s.friends[friendID], UID)
+	return ok1 && ok2, nil
+}
+
+// ListFriends returns users with mutual FRIEND relationship with UID.
+// Friendship date is the moment the second side was added.
+// If after is not nil listing starts right after it, limit <= 0 means no limit.
+func (s *Storage) ListFriends(ctx context.Context, UID int64, after *models.FriendsCursor, limit int64) ([]models.Friend, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type friend struct {
+		UID   int64
+		since int64
+	}
+
+	list := make([]friend, 0, len(s.friends[UID]))
+	for FID, since1 := range s.friends[UID] {
+		since2, ok := s.friends[FID][UID]
+		if !ok {
+			continue
+		}
+
+		since := max(since1, since2)
+		if after != nil && !(since < after.Since || (since == after.Since && FID > after.UID)) {
+			continue
+		}
+		list = append(list, friend{UID: FID, since: since})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].since != list[j].since {
+			return list[i].since > list[j].since
+		}
+		return list[i].UID < list[j].UID
+	})
+
+	if limit > 0 && int64(len(list)) > limit {
+		list = list[:limit]
+	}
+
+	ans := make([]models.Friend, 0, len(list))
+	for _, fr := range list {
+		ans = append(ans, models.Friend{UID: fr.UID, Since: time.UnixMilli(fr.since)})
+	}
+	return ans, nil
+}
+
+func (s *Storage) Follow(ctx context.Context, UID, followID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.follows[UID] == nil {
+		s.follows[UID] = make(map[int64]struct{})
+	}
+	s.follows[UID][followID] = struct{}{}
+	return nil
+}
+
+func (s *Storage) Unfollow(ctx context.Context, UID, followID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.follows[UID], followID)
+	return nil
+}
+
+func (s *Storage) ListFollowers(ctx context.Context, UID int64) ([]int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ans := make([]int64, 0)
+	for FID, following := range s.follows {
+		if _, ok := following[UID]; ok {
+			ans = append(ans, FID)
+		}
+	}
+	return ans, nil
+}
+
+func (s *Storage) ListFollowing(ctx context.Context, UID int64) ([]int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ans := make([]int64, 0, len(s.follows[UID]))
+	for FID := range s.follows[UID] {
+		ans = append(ans, FID)
+	}
+	return ans, nil
+}
+
+func (s *Storage) SaveInvite(ctx context.Context, invite models.Invite) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invite.Uses = 0
+	invite.Revoked = false
+	s.invites[invite.ID] = &invite
+	s.tokens[invite.TokenHash] = invite.ID
+	return nil
+}
+
+// UseInvite consumes one use of the invite found by token hash and makes
+// UID and inviter friends. Accepting it again or being friends already
+// changes nothing and takes no use.
+func (s *Storage) UseInvite(ctx context.Context, tokenHash string, UID int64) (models.InviteUse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invite, ok := s.invites[s.tokens[tokenHash]]
+	if !ok || invite.Revoked || !invite.ExpiresAt.After(s.now()) {
+		return models.InviteUse{}, storage.ErrInviteInvalid
+	}
+	if invite.InviterID == UID {
+		return models.InviteUse{}, storage.ErrInviteOwn
+	}
+
+	_, used := s.used[invite.ID][UID]
+	_, ok1 := s.friends[invite.InviterID][UID]
+	_, ok2 := s.friends[UID][invite.InviterID]
+	if used || (ok1 && ok2) {
+		return models.InviteUse{InviterID: invite.InviterID}, nil
+	}
+	if invite.Uses >= invite.MaxUses {
+		return models.InviteUse{}, storage.ErrInviteInvalid
+	}
+
+	invite.Uses++
+	if s.used[invite.ID] == nil {
+		s.used[invite.ID] = make(map[int64]struct{})
+	}
+	s.used[invite.ID][UID] = struct{}{}
+	s.addFriendEdge(invite.InviterID, UID)
+	s.addFriendEdge(UID, invite.InviterID)
+
+	return models.InviteUse{InviterID: invite.InviterID, Accepted: true}, nil
+}
+
+func (s *Storage) RevokeInvite(ctx context.Context, UID int64, inviteID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invite, ok := s.invites[inviteID]
+	if !ok || invite.InviterID != UID {
+		return storage.ErrInviteNotFound
+	}
+
+	invite.Revoked = true
+	return nil
+}
+
+// ListInvites returns invites of UID that can still be used.
+func (s *Storage) ListInvites(ctx context.Context, UID int64) ([]models.Invite, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ans := make([]models.Invite, 0)
+	for _, invite := range s.invites {
+		if invite.InviterID == UID && s.inviteUsable(invite) {
+			ans = append(ans, *invite)
+		}
+	}
+
+	sort.Slice(ans, func(i, j int) bool {
+		return ans[i].CreatedAt.After(ans[j].CreatedAt)
+	})
+	return ans, nil
+}
+
+// DeleteUser removes user with all its relationships and invites.
+func (s *Storage) DeleteUser(ctx context.Context, UID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.friends, UID)
+	for _, to := range s.friends {
+		delete(to, UID)
+	}
+	delete(s.follows, UID)
+	for _, to := range s.follows {
+		delete(to, UID)
+	}
+	for ID, invite := range s.invites {
+		if invite.InviterID == UID {
+			delete(s.invites, ID)
+			delete(s.tokens, invite.TokenHash)
+			delete(s.used, ID)
+		}
+	}
+	for _, users := range s.used {
+		delete(users, UID)
+	}
+	return nil
+}
+
+func (s *Storage) SaveNotification(ctx context.Context, n models.Notification, maxPerUser int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastNotificationID++
+	n.ID = s.lastNotificationID
+	n.Read = false
+
+	list := append(s.notifications[n.UID], &n)
+	if len(list) > maxPerUser {
+		list = list[len(list)-maxPerUser:]
+	}
+	s.notifications[n.UID] = list
+	return nil
+}
+
+// ListNotifications returns UID's notifications, newest first.
+func (s *Storage) ListNotifications(ctx context.Context, UID int64) ([]models.Notification, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := s.notifications[UID]
+	ans := make([]models.Notification, 0, len(list))
+	for i := len(list) - 1; i >= 0; i-- {
+		ans = append(ans, *list[i])
+	}
+	return ans, nil
+}
+
+// MarkNotificationsRead marks UID's notifications with given IDs as read,
+// all of them if IDs is empty.
+func (s *Storage) MarkNotificationsRead(ctx context.Context, UID int64, IDs []int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make(map[int64]struct{}, len(IDs))
+	for _, ID := range IDs {
+		ids[ID] = struct{}{}
+	}
+
+	for _, n := range s.notifications[UID] {
+		if _, ok := ids[n.ID]; ok || len(IDs) == 0 {
+			n.Read = true
+		}
+	}
+	return nil
+}
+
+// DeleteNotifications drops UID's notifications and notifications about UID's actions.
+func (s *Storage) DeleteNotifications(ctx context.Context, UID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.notifications, UID)
+	for target, list := range s.notifications {
+		kept := list[:0]
+		for _, n := range list {
+			if n.Event.ActorID != UID {
+				kept = append(kept, n)
+			}
+		}
+		s.notifications[target] = kept
+	}
+	return nil
+}
+
+// addFriendEdge must be called with s.mu locked.
+func (s *Storage) addFriendEdge(from, to int64) {
+	if s.friends[from] == nil {
+		s.friends[from] = make(map[int64]int64)
+	}
+	if _, ok := s.friends[from][to]; !ok {
+		s.friends[from][to] = s.now().UnixMilli()
+	}
+}
+
+func (s *Storage) inviteUsable(invite *models.Invite) bool {
+	return !invite.Revoked && invite.ExpiresAt.After(s.now()) && invite.Uses < invite.MaxUses
+}
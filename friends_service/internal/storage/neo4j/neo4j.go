@@ -2,8 +2,11 @@ package neo_storage
 
 import (
 	"context"
+	"time"
 
 	config "github.com/liriquew/social-todo/friends_service/internal/lib/config"
+	"github.com/liriquew/social-todo/friends_service/internal/models"
+	"github.com/liriquew/social-todo/friends_service/internal/storage"
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
@@ -13,7 +16,7 @@ type Storage struct {
 }
 
 func New(config config.Neo4jConfig) (*Storage, error) {
-	uri := "neo4j://localhost:" + config.Port
+	uri := "neo4j://" + config.Host + ":" + config.Port
 
 	neoDriver, err := neo4j.NewDriverWithContext(uri, neo4j.BasicAuth(config.Username, config.Password, ""))
 	if err != nil {
@@ -25,7 +28,29 @@ func New(config config.Neo4jConfig) (*Storage, error) {
 		return nil, err
 	}
 
-	return &Storage{driver: neoDriver, dbName: config.DBName}, nil
+	s := &Storage{driver: neoDriver, dbName: config.DBName}
+	if err := s.linkLegacyFriends(context.Background()); err != nil {
+		neoDriver.Close(context.Background())
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// linkLegacyFriends adds the reverse edge to friendships stored before
+// they became mutual, when a single FRIEND edge meant both users were
+// friends. Such edges have no since, edges added by AddFriend always have
+// it, so pending requests are never accepted and it's safe to run on
+// every start.
+func (s *Storage) linkLegacyFriends(ctx context.Context) error {
+	_, err := neo4j.ExecuteQuery(ctx, s.driver, `
+		MATCH (u1:User)-[r:FRIEND]->(u2:User)
+		WHERE r.since IS NULL AND NOT EXISTS { MATCH (u2)-[:FRIEND]->(u1) }
+		MERGE (u2)-[:FRIEND]->(u1)`,
+		nil, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(s.dbName))
+
+	return err
 }
 
 func (s *Storage) Close() error {
@@ -33,16 +58,107 @@ func (s *Storage) Close() error {
 	return s.driver.Close(c)
 }
 
-func (s *Storage) AddFriend(ctx context.Context, UID, friendID int64) error {
-	_, err := neo4j.ExecuteQuery(ctx, s.driver, `
+// AddFriend stores UID's side of the friendship. Users become friends
+// only when both of them have added each other.
+func (s *Storage) AddFriend(ctx context.Context, UID, friendID int64) (models.AddFriendResult, error) {
+	resp, err := neo4j.ExecuteQuery(ctx, s.driver, `
 		MERGE (u1:User {id: $UID}) 
 		MERGE (u2:User {id: $FID}) 
-		MERGE (u1)-[r:FRIEND]->(u2)`,
+		WITH u1, u2, EXISTS { MATCH (u1)-[:FRIEND]->(u2) } AS existed
+		MERGE (u1)-[r:FRIEND]->(u2)
+		ON CREATE SET r.since = timestamp()
+		RETURN existed, EXISTS { MATCH (u2)-[:FRIEND]->(u1) } AS mutual`,
+		map[string]any{
+			"UID": UID,
+			"FID": friendID,
+		}, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(s.dbName))
+
+	if err != nil {
+		return models.AddFriendResult{}, err
+	}
+
+	rec := resp.Records[0].AsMap()
+	return models.AddFriendResult{
+		Created: !rec["existed"].(bool),
+		Mutual:  rec["mutual"].(bool),
+	}, nil
+}
+
+// RemoveFriend drops the friendship (or pending request) in both directions.
+// Returns true if users were friends.
+func (s *Storage) RemoveFriend(ctx context.Context, UID, friendID int64) (bool, error) {
+	resp, err := neo4j.ExecuteQuery(ctx, s.driver, `
+		MATCH (u1:User{id: $UID})
+		MATCH (u1)-[r:FRIEND]-(b:User{id: $FID})
+		DELETE r
+		RETURN count(r) AS removed`,
 		map[string]any{
 			"UID": UID,
 			"FID": friendID,
 		}, neo4j.EagerResultTransformer,
-		neo4j.ExecuteQueryWithDatabase("neo4j"))
+		neo4j.ExecuteQueryWithDatabase(s.dbName))
+
+	if err != nil {
+		return false, err
+	}
+	return resp.Records[0].AsMap()["removed"].(int64) == 2, nil
+}
+
+// ListFriends returns users with mutual FRIEND relationship with UID.
+// Friendship date is the moment the second side was added.
+// If after is not nil listing starts right after it, limit <= 0 means no limit.
+func (s *Storage) ListFriends(ctx context.Context, UID int64, after *models.FriendsCursor, limit int64) ([]models.Friend, error) {
+	params := map[string]any{
+		"UID":       UID,
+		"hasCursor": after != nil,
+		"since":     int64(0),
+		"FID":       int64(0),
+		"limit":     limit,
+	}
+	if after != nil {
+		params["since"] = after.Since
+		params["FID"] = after.UID
+	}
+
+	resp, err := neo4j.ExecuteQuery(ctx, s.driver, `
+		MATCH (u: User{id: $UID}) 
+		MATCH (u)-[r1:FRIEND]->(fr:User)-[r2:FRIEND]->(u)
+		WITH fr, CASE 
+			WHEN coalesce(r1.since, 0) > coalesce(r2.since, 0) THEN coalesce(r1.since, 0) 
+			ELSE coalesce(r2.since, 0) END AS since
+		WHERE NOT $hasCursor OR since < $since OR (since = $since AND fr.id > $FID)
+		RETURN fr.id, since
+		ORDER BY since DESC, fr.id ASC
+		LIMIT CASE WHEN $limit > 0 THEN $limit ELSE 9223372036854775807 END`,
+		params, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(s.dbName))
+
+	if err != nil {
+		return nil, err
+	}
+
+	ans := make([]models.Friend, 0, len(resp.Records))
+	for _, record := range resp.Records {
+		rec := record.AsMap()
+		ans = append(ans, models.Friend{
+			UID:   rec["fr.id"].(int64),
+			Since: time.UnixMilli(rec["since"].(int64)),
+		})
+	}
+	return ans, nil
+}
+
+func (s *Storage) Follow(ctx context.Context, UID, followID int64) error {
+	_, err := neo4j.ExecuteQuery(ctx, s.driver, `
+		MERGE (u1:User {id: $UID}) 
+		MERGE (u2:User {id: $FID}) 
+		MERGE (u1)-[r:FOLLOWS]->(u2)`,
+		map[string]any{
+			"UID": UID,
+			"FID": followID,
+		}, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(s.dbName))
 
 	if err != nil {
 		return err
@@ -50,16 +166,15 @@ func (s *Storage) AddFriend(ctx context.Context, UID, friendID int64) error {
 	return nil
 }
 
-func (s *Storage) RemoveFriend(ctx context.Context, UID, friendID int64) error {
+func (s *Storage) Unfollow(ctx context.Context, UID, followID int64) error {
 	_, err := neo4j.ExecuteQuery(ctx, s.driver, `
-		MATCH (u1:User{id: $UID})
-		MATCH (u1)-[r:FRIEND]-(b:User{id: $FID})
+		MATCH (u1:User{id: $UID})-[r:FOLLOWS]->(u2:User{id: $FID})
 		DELETE r`,
 		map[string]any{
 			"UID": UID,
-			"FID": friendID,
+			"FID": followID,
 		}, neo4j.EagerResultTransformer,
-		neo4j.ExecuteQueryWithDatabase("neo4j"))
+		neo4j.ExecuteQueryWithDatabase(s.dbName))
 
 	if err != nil {
 		return err
@@ -67,15 +182,15 @@ func (s *Storage) RemoveFriend(ctx context.Context, UID, friendID int64) error {
 	return nil
 }
 
-func (s *Storage) ListFriends(ctx context.Context, UID int64) ([]int64, error) {
+func (s *Storage) ListFollowers(ctx context.Context, UID int64) ([]int64, error) {
 	resp, err := neo4j.ExecuteQuery(ctx, s.driver, `
 		MATCH (u: User{id: $UID}) 
-		MATCH (u)-[:FRIEND]-(fr:User)
-		return fr.id`,
+		MATCH (fl:User)-[:FOLLOWS]->(u)
+		return fl.id`,
 		map[string]any{
 			"UID": UID,
 		}, neo4j.EagerResultTransformer,
-		neo4j.ExecuteQueryWithDatabase("neo4j"))
+		neo4j.ExecuteQueryWithDatabase(s.dbName))
 
 	if err != nil {
 		return nil, err
@@ -83,7 +198,286 @@ func (s *Storage) ListFriends(ctx context.Context, UID int64) ([]int64, error) {
 
 	ans := make([]int64, 0, len(resp.Records))
 	for _, record := range resp.Records {
-		ans = append(ans, record.AsMap()["fr.id"].(int64))
+		ans = append(ans, record.AsMap()["fl.id"].(int64))
+	}
+	return ans, nil
+}
+
+func (s *Storage) ListFollowing(ctx context.Context, UID int64) ([]int64, error) {
+	resp, err := neo4j.ExecuteQuery(ctx, s.driver, `
+		MATCH (u: User{id: $UID}) 
+		MATCH (u)-[:FOLLOWS]->(fl:User)
+		return fl.id`,
+		map[string]any{
+			"UID": UID,
+		}, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(s.dbName))
+
+	if err != nil {
+		return nil, err
+	}
+
+	ans := make([]int64, 0, len(resp.Records))
+	for _, record := range resp.Records {
+		ans = append(ans, record.AsMap()["fl.id"].(int64))
+	}
+	return ans, nil
+}
+
+func (s *Storage) SaveInvite(ctx context.Context, invite models.Invite) error {
+	_, err := neo4j.ExecuteQuery(ctx, s.driver, `
+		MERGE (u:User {id: $UID})
+		CREATE (u)-[:INVITED]->(:Invite {
+			id: $IID, 
+			token_hash: $tokenHash, 
+			max_uses: $maxUses, 
+			uses: 0, 
+			created_at: $createdAt, 
+			expires_at: $expiresAt, 
+			revoked: false
+		})`,
+		map[string]any{
+			"UID":       invite.InviterID,
+			"IID":       invite.ID,
+			"tokenHash": invite.TokenHash,
+			"maxUses":   invite.MaxUses,
+			"createdAt": invite.CreatedAt.UnixMilli(),
+			"expiresAt": invite.ExpiresAt.UnixMilli(),
+		}, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(s.dbName))
+
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// UseInvite consumes one use of the invite found by token hash and makes
+// UID and inviter friends. Accepting it again or being friends already
+// changes nothing and takes no use.
+func (s *Storage) UseInvite(ctx context.Context, tokenHash string, UID int64) (models.InviteUse, error) {
+	// SET before the uses check takes the write lock on the invite node,
+	// so concurrent uses can't exceed max_uses
+	resp, err := neo4j.ExecuteQuery(ctx, s.driver, `
+		MATCH (u1:User)-[:INVITED]->(inv:Invite {token_hash: $hash})
+		WHERE NOT inv.revoked AND inv.expires_at > timestamp()
+		SET inv._lock = true
+		REMOVE inv._lock
+		WITH u1, inv
+		OPTIONAL MATCH (u2:User {id: $UID})
+		WITH u1, inv, u1.id = $UID AS own,
+			u2 IS NOT NULL AND (EXISTS { MATCH (u2)-[:USED]->(inv) }
+				OR (EXISTS { MATCH (u1)-[:FRIEND]->(u2) } AND EXISTS { MATCH (u2)-[:FRIEND]->(u1) })) AS done
+		WITH u1, inv, own, done, NOT own AND NOT done AND inv.uses < inv.max_uses AS accept
+		FOREACH (_ IN CASE WHEN accept THEN [1] ELSE [] END |
+			MERGE (u2:User {id: $UID})
+			SET inv.uses = inv.uses + 1
+			MERGE (u2)-[:USED]->(inv)
+			MERGE (u1)-[r1:FRIEND]->(u2)
+			ON CREATE SET r1.since = timestamp()
+			MERGE (u2)-[r2:FRIEND]->(u1)
+			ON CREATE SET r2.since = timestamp()
+		)
+		RETURN u1.id, own, done, accept`,
+		map[string]any{
+			"hash": tokenHash,
+			"UID":  UID,
+		}, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(s.dbName))
+
+	if err != nil {
+		return models.InviteUse{}, err
+	}
+	if len(resp.Records) == 0 {
+		return models.InviteUse{}, storage.ErrInviteInvalid
+	}
+
+	rec := resp.Records[0].AsMap()
+	switch {
+	case rec["own"].(bool):
+		return models.InviteUse{}, storage.ErrInviteOwn
+	case rec["done"].(bool):
+		return models.InviteUse{InviterID: rec["u1.id"].(int64)}, nil
+	case !rec["accept"].(bool):
+		return models.InviteUse{}, storage.ErrInviteInvalid
+	}
+
+	return models.InviteUse{InviterID: rec["u1.id"].(int64), Accepted: true}, nil
+}
+
+func (s *Storage) RevokeInvite(ctx context.Context, UID int64, inviteID string) error {
+	resp, err := neo4j.ExecuteQuery(ctx, s.driver, `
+		MATCH (u:User {id: $UID})-[:INVITED]->(inv:Invite {id: $IID})
+		SET inv.revoked = true
+		RETURN inv.id`,
+		map[string]any{
+			"UID": UID,
+			"IID": inviteID,
+		}, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(s.dbName))
+
+	if err != nil {
+		return err
+	}
+	if len(resp.Records) == 0 {
+		return storage.ErrInviteNotFound
+	}
+	return nil
+}
+
+// ListInvites returns invites of UID that can still be used.
+func (s *Storage) ListInvites(ctx context.Context, UID int64) ([]models.Invite, error) {
+	resp, err := neo4j.ExecuteQuery(ctx, s.driver, `
+		MATCH (u:User {id: $UID})-[:INVITED]->(inv:Invite)
+		WHERE NOT inv.revoked AND inv.expires_at > timestamp() AND inv.uses < inv.max_uses
+		RETURN inv.id, inv.max_uses, inv.uses, inv.created_at, inv.expires_at
+		ORDER BY inv.created_at DESC`,
+		map[string]any{
+			"UID": UID,
+		}, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(s.dbName))
+
+	if err != nil {
+		return nil, err
+	}
+
+	ans := make([]models.Invite, 0, len(resp.Records))
+	for _, record := range resp.Records {
+		rec := record.AsMap()
+		ans = append(ans, models.Invite{
+			ID:        rec["inv.id"].(string),
+			InviterID: UID,
+			MaxUses:   rec["inv.max_uses"].(int64),
+			Uses:      rec["inv.uses"].(int64),
+			CreatedAt: time.UnixMilli(rec["inv.created_at"].(int64)),
+			ExpiresAt: time.UnixMilli(rec["inv.expires_at"].(int64)),
+		})
+	}
+	return ans, nil
+}
+
+// DeleteUser removes user node with all its relationships and invites.
+func (s *Storage) DeleteUser(ctx context.Context, UID int64) error {
+	_, err := neo4j.ExecuteQuery(ctx, s.driver, `
+		MATCH (u:User {id: $UID})
+		OPTIONAL MATCH (u)-[:INVITED]->(inv:Invite)
+		DETACH DELETE inv, u`,
+		map[string]any{
+			"UID": UID,
+		}, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(s.dbName))
+
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SaveNotification stores notification as a node of its user. IDs come from
+// a counter node, so they grow the same way the memory storage ones do.
+func (s *Storage) SaveNotification(ctx context.Context, n models.Notification, maxPerUser int) error {
+	_, err := neo4j.ExecuteQuery(ctx, s.driver, `
+		MERGE (u:User {id: $UID})
+		MERGE (c:Counter {name: "notification"})
+		ON CREATE SET c.value = 0
+		SET c.value = c.value + 1
+		CREATE (u)-[:NOTIFIED]->(:Notification {
+			id: c.value, 
+			uid: $UID, 
+			type: $type, 
+			actor_id: $actorID, 
+			read: false, 
+			event_at: $eventAt, 
+			created_at: $createdAt
+		})
+		WITH u
+		MATCH (u)-[:NOTIFIED]->(old:Notification)
+		WITH old ORDER BY old.id DESC SKIP $max
+		DETACH DELETE old`,
+		map[string]any{
+			"UID":       n.UID,
+			"type":      n.Event.Type,
+			"actorID":   n.Event.ActorID,
+			"eventAt":   n.Event.CreatedAt.UnixMilli(),
+			"createdAt": n.CreatedAt.UnixMilli(),
+			"max":       maxPerUser,
+		}, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(s.dbName))
+
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ListNotifications returns UID's notifications, newest first.
+func (s *Storage) ListNotifications(ctx context.Context, UID int64) ([]models.Notification, error) {
+	resp, err := neo4j.ExecuteQuery(ctx, s.driver, `
+		MATCH (n:Notification {uid: $UID})
+		RETURN n.id, n.type, n.actor_id, n.read, n.event_at, n.created_at
+		ORDER BY n.id DESC`,
+		map[string]any{
+			"UID": UID,
+		}, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(s.dbName))
+
+	if err != nil {
+		return nil, err
+	}
+
+	ans := make([]models.Notification, 0, len(resp.Records))
+	for _, record := range resp.Records {
+		rec := record.AsMap()
+		ans = append(ans, models.Notification{
+			ID:  rec["n.id"].(int64),
+			UID: UID,
+			Event: models.Event{
+				Type:      rec["n.type"].(string),
+				ActorID:   rec["n.actor_id"].(int64),
+				TargetID:  UID,
+				CreatedAt: time.UnixMilli(rec["n.event_at"].(int64)),
+			},
+			Read:      rec["n.read"].(bool),
+			CreatedAt: time.UnixMilli(rec["n.created_at"].(int64)),
+		})
 	}
 	return ans, nil
 }
+
+// MarkNotificationsRead marks UID's notifications with given IDs as read,
+// all of them if IDs is empty.
+func (s *Storage) MarkNotificationsRead(ctx context.Context, UID int64, IDs []int64) error {
+	_, err := neo4j.ExecuteQuery(ctx, s.driver, `
+		MATCH (n:Notification {uid: $UID})
+		WHERE size($IDs) = 0 OR n.id IN $IDs
+		SET n.read = true`,
+		map[string]any{
+			"UID": UID,
+			"IDs": IDs,
+		}, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(s.dbName))
+
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteNotifications drops UID's notifications and notifications about
+// UID's actions. They keep user IDs as properties, so it works after the
+// user node is deleted too.
+func (s *Storage) DeleteNotifications(ctx context.Context, UID int64) error {
+	_, err := neo4j.ExecuteQuery(ctx, s.driver, `
+		MATCH (n:Notification)
+		WHERE n.uid = $UID OR n.actor_id = $UID
+		DETACH DELETE n`,
+		map[string]any{
+			"UID": UID,
+		}, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(s.dbName))
+
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -3,5 +3,8 @@ package storage
 import "fmt"
 
 var (
-	ErrNotFound = fmt.Errorf("user not found")
+	ErrNotFound       = fmt.Errorf("user not found")
+	ErrInviteNotFound = fmt.Errorf("invite not found")
+	ErrInviteInvalid  = fmt.Errorf("invite is revoked, expired or used up")
+	ErrInviteOwn      = fmt.Errorf("invite belongs to the user")
 )
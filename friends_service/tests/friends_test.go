@@ -0,0 +1,299 @@
+package tests
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/liriquew/social-todo/friends_service/tests/suite"
+	"github.com/liriquew/todoprotos/gen/go/friends"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomUID() int64 {
+	return rand.Int64N(1<<40) + 1
+}
+
+func TestAddFriend_MutualOnly(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	UID1, UID2 := randomUID(), randomUID()
+
+	_, err := st.FriendsClient.AddFriend(ctx, &friends.FriendRequest{UID: UID1, FriendID: UID2})
+	require.NoError(t, err)
+
+	resp, err := st.FriendsClient.ListFriends(ctx, &friends.ListFriendRequest{UID: UID2})
+	require.NoError(t, err)
+	assert.Empty(t, resp.FriendIDs)
+
+	_, err = st.FriendsClient.AddFriend(ctx, &friends.FriendRequest{UID: UID2, FriendID: UID1})
+	require.NoError(t, err)
+
+	resp, err = st.FriendsClient.ListFriends(ctx, &friends.ListFriendRequest{UID: UID2})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{UID1}, resp.FriendIDs)
+
+	_, err = st.FriendsClient.RemoveFriend(ctx, &friends.FriendRequest{UID: UID1, FriendID: UID2})
+	require.NoError(t, err)
+
+	resp, err = st.FriendsClient.ListFriends(ctx, &friends.ListFriendRequest{UID: UID1})
+	require.NoError(t, err)
+	assert.Empty(t, resp.FriendIDs)
+}
+
+func TestListFriends_Pagination(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	UID := randomUID()
+	expected := make(map[int64]bool)
+	for range 5 {
+		FID := randomUID()
+		expected[FID] = true
+
+		_, err := st.FriendsClient.AddFriend(ctx, &friends.FriendRequest{UID: UID, FriendID: FID})
+		require.NoError(t, err)
+		_, err = st.FriendsClient.AddFriend(ctx, &friends.FriendRequest{UID: FID, FriendID: UID})
+		require.NoError(t, err)
+	}
+
+	cursor := ""
+	for range 3 {
+		resp, err := st.FriendsClient.ListFriends(ctx, &friends.ListFriendRequest{UID: UID, Cursor: cursor, Limit: 2})
+		require.NoError(t, err)
+
+		for _, FID := range resp.FriendIDs {
+			require.True(t, expected[FID])
+			delete(expected, FID)
+		}
+
+		cursor = resp.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	assert.Empty(t, expected)
+}
+
+func TestFollow_HappyPath(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	UID1, UID2 := randomUID(), randomUID()
+
+	_, err := st.FriendsClient.Follow(ctx, &friends.FollowRequest{UID: UID1, FollowID: UID2})
+	require.NoError(t, err)
+
+	following, err := st.FriendsClient.ListFollowing(ctx, &friends.ListFollowRequest{UID: UID1})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{UID2}, following.UIDs)
+
+	followers, err := st.FriendsClient.ListFollowers(ctx, &friends.ListFollowRequest{UID: UID2})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{UID1}, followers.UIDs)
+
+	friendsResp, err := st.FriendsClient.ListFriends(ctx, &friends.ListFriendRequest{UID: UID1})
+	require.NoError(t, err)
+	assert.Empty(t, friendsResp.FriendIDs)
+
+	_, err = st.FriendsClient.Unfollow(ctx, &friends.FollowRequest{UID: UID1, FollowID: UID2})
+	require.NoError(t, err)
+
+	following, err = st.FriendsClient.ListFollowing(ctx, &friends.ListFollowRequest{UID: UID1})
+	require.NoError(t, err)
+	assert.Empty(t, following.UIDs)
+}
+
+func TestFollow_Idempotent(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	UID1, UID2 := randomUID(), randomUID()
+
+	for range 2 {
+		_, err := st.FriendsClient.Follow(ctx, &friends.FollowRequest{UID: UID1, FollowID: UID2})
+		require.NoError(t, err)
+	}
+
+	following, err := st.FriendsClient.ListFollowing(ctx, &friends.ListFollowRequest{UID: UID1})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{UID2}, following.UIDs)
+
+	for range 2 {
+		_, err = st.FriendsClient.Unfollow(ctx, &friends.FollowRequest{UID: UID1, FollowID: UID2})
+		require.NoError(t, err)
+	}
+
+	followers, err := st.FriendsClient.ListFollowers(ctx, &friends.ListFollowRequest{UID: UID2})
+	require.NoError(t, err)
+	assert.Empty(t, followers.UIDs)
+
+	// unfollowing never followed user is fine too
+	_, err = st.FriendsClient.Unfollow(ctx, &friends.FollowRequest{UID: UID2, FollowID: UID1})
+	require.NoError(t, err)
+}
+
+func TestFollow_Lists(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	UID := randomUID()
+	followers := []int64{randomUID(), randomUID(), randomUID()}
+	following := []int64{randomUID(), randomUID()}
+
+	for _, FID := range followers {
+		_, err := st.FriendsClient.Follow(ctx, &friends.FollowRequest{UID: FID, FollowID: UID})
+		require.NoError(t, err)
+	}
+	for _, FID := range following {
+		_, err := st.FriendsClient.Follow(ctx, &friends.FollowRequest{UID: UID, FollowID: FID})
+		require.NoError(t, err)
+	}
+
+	respFollowers, err := st.FriendsClient.ListFollowers(ctx, &friends.ListFollowRequest{UID: UID})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, followers, respFollowers.UIDs)
+
+	respFollowing, err := st.FriendsClient.ListFollowing(ctx, &friends.ListFollowRequest{UID: UID})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, following, respFollowing.UIDs)
+
+	// following is one-way, it makes no friends
+	respFriends, err := st.FriendsClient.ListFriends(ctx, &friends.ListFriendRequest{UID: UID})
+	require.NoError(t, err)
+	assert.Empty(t, respFriends.FriendIDs)
+}
+
+func TestInvite_UsageCapAndRevoke(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	inviter := randomUID()
+
+	invite, err := st.FriendsClient.CreateInvite(ctx, &friends.CreateInviteRequest{UID: inviter, MaxUses: 2})
+	require.NoError(t, err)
+	require.NotEmpty(t, invite.Token)
+
+	_, err = st.FriendsClient.AcceptInvite(ctx, &friends.AcceptInviteRequest{UID: inviter, Token: invite.Token})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "can't accept your own invite")
+
+	for range 2 {
+		resp, err := st.FriendsClient.AcceptInvite(ctx, &friends.AcceptInviteRequest{UID: randomUID(), Token: invite.Token})
+		require.NoError(t, err)
+		assert.Equal(t, inviter, resp.InviterID)
+	}
+
+	_, err = st.FriendsClient.AcceptInvite(ctx, &friends.AcceptInviteRequest{UID: randomUID(), Token: invite.Token})
+	require.Error(t, err)
+
+	list, err := st.FriendsClient.ListFriends(ctx, &friends.ListFriendRequest{UID: inviter})
+	require.NoError(t, err)
+	assert.Len(t, list.FriendIDs, 2)
+
+	revoked, err := st.FriendsClient.CreateInvite(ctx, &friends.CreateInviteRequest{UID: inviter})
+	require.NoError(t, err)
+
+	_, err = st.FriendsClient.RevokeInvite(ctx, &friends.RevokeInviteRequest{UID: inviter, InviteID: revoked.InviteID})
+	require.NoError(t, err)
+
+	_, err = st.FriendsClient.AcceptInvite(ctx, &friends.AcceptInviteRequest{UID: randomUID(), Token: revoked.Token})
+	require.Error(t, err)
+}
+
+func TestInvite_AcceptIdempotent(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	inviter, friend := randomUID(), randomUID()
+
+	_, err := st.FriendsClient.AddFriend(ctx, &friends.FriendRequest{UID: inviter, FriendID: friend})
+	require.NoError(t, err)
+	_, err = st.FriendsClient.AddFriend(ctx, &friends.FriendRequest{UID: friend, FriendID: inviter})
+	require.NoError(t, err)
+
+	invite, err := st.FriendsClient.CreateInvite(ctx, &friends.CreateInviteRequest{UID: inviter, MaxUses: 2})
+	require.NoError(t, err)
+
+	// neither friends nor the same user accepting again take a use
+	_, err = st.FriendsClient.AcceptInvite(ctx, &friends.AcceptInviteRequest{UID: friend, Token: invite.Token})
+	require.NoError(t, err)
+
+	UID := randomUID()
+	for range 3 {
+		resp, err := st.FriendsClient.AcceptInvite(ctx, &friends.AcceptInviteRequest{UID: UID, Token: invite.Token})
+		require.NoError(t, err)
+		assert.Equal(t, inviter, resp.InviterID)
+	}
+
+	invites, err := st.FriendsClient.ListInvites(ctx, &friends.ListInvitesRequest{UID: inviter})
+	require.NoError(t, err)
+	require.Len(t, invites.Invites, 1)
+	assert.Equal(t, int64(1), invites.Invites[0].Uses)
+
+	_, err = st.FriendsClient.AcceptInvite(ctx, &friends.AcceptInviteRequest{UID: randomUID(), Token: invite.Token})
+	require.NoError(t, err)
+
+	_, err = st.FriendsClient.AcceptInvite(ctx, &friends.AcceptInviteRequest{UID: randomUID(), Token: "not-a-token"})
+	require.Error(t, err)
+}
+
+func TestNotifications_FriendshipEvents(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	UID1, UID2 := randomUID(), randomUID()
+
+	_, err := st.FriendsClient.AddFriend(ctx, &friends.FriendRequest{UID: UID1, FriendID: UID2})
+	require.NoError(t, err)
+
+	resp, err := st.FriendsClient.ListNotifications(ctx, &friends.ListNotificationsRequest{UID: UID2})
+	require.NoError(t, err)
+	require.Len(t, resp.Notifications, 1)
+	assert.Equal(t, "friend_request_sent", resp.Notifications[0].Type)
+	assert.Equal(t, UID1, resp.Notifications[0].ActorID)
+	assert.EqualValues(t, 1, resp.Unread)
+
+	_, err = st.FriendsClient.AddFriend(ctx, &friends.FriendRequest{UID: UID2, FriendID: UID1})
+	require.NoError(t, err)
+
+	resp, err = st.FriendsClient.ListNotifications(ctx, &friends.ListNotificationsRequest{UID: UID1})
+	require.NoError(t, err)
+	require.Len(t, resp.Notifications, 1)
+	assert.Equal(t, "friend_request_accepted", resp.Notifications[0].Type)
+
+	_, err = st.FriendsClient.MarkNotificationsRead(ctx, &friends.MarkNotificationsReadRequest{UID: UID1})
+	require.NoError(t, err)
+
+	resp, err = st.FriendsClient.ListNotifications(ctx, &friends.ListNotificationsRequest{UID: UID1, UnreadOnly: true})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Notifications)
+	assert.EqualValues(t, 0, resp.Unread)
+}
+
+func TestDeleteUser_Detaches(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	UID1, UID2, UID3 := randomUID(), randomUID(), randomUID()
+
+	_, err := st.FriendsClient.AddFriend(ctx, &friends.FriendRequest{UID: UID1, FriendID: UID2})
+	require.NoError(t, err)
+	_, err = st.FriendsClient.AddFriend(ctx, &friends.FriendRequest{UID: UID2, FriendID: UID1})
+	require.NoError(t, err)
+	_, err = st.FriendsClient.Follow(ctx, &friends.FollowRequest{UID: UID3, FollowID: UID1})
+	require.NoError(t, err)
+
+	_, err = st.FriendsClient.DeleteUser(ctx, &friends.DeleteUserRequest{UID: UID1})
+	require.NoError(t, err)
+
+	respFriends, err := st.FriendsClient.ListFriends(ctx, &friends.ListFriendRequest{UID: UID2})
+	require.NoError(t, err)
+	assert.Empty(t, respFriends.FriendIDs)
+
+	respFollow, err := st.FriendsClient.ListFollowing(ctx, &friends.ListFollowRequest{UID: UID3})
+	require.NoError(t, err)
+	assert.Empty(t, respFollow.UIDs)
+
+	// notifications caused by deleted user are gone too
+	respNotif, err := st.FriendsClient.ListNotifications(ctx, &friends.ListNotificationsRequest{UID: UID2})
+	require.NoError(t, err)
+	assert.Empty(t, respNotif.Notifications)
+
+	// repeated call is fine
+	_, err = st.FriendsClient.DeleteUser(ctx, &friends.DeleteUserRequest{UID: UID1})
+	require.NoError(t, err)
+}
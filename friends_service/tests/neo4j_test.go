@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/liriquew/social-todo/friends_service/internal/lib/config"
+	neo_storage "github.com/liriquew/social-todo/friends_service/internal/storage/neo4j"
+	"github.com/liriquew/social-todo/friends_service/tests/suite"
+
+	"github.com/liriquew/todoprotos/gen/go/friends"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNeo4j_LegacyOneWayFriends needs friends_service running with Neo4j
+// storage, e.g. CONFIG_PATH pointing to a copy of test_config.yaml with
+// storage: neo4j.
+func TestNeo4j_LegacyOneWayFriends(t *testing.T) {
+	ctx, st := suite.New(t)
+	if st.Cfg.Storage != config.StorageNeo4j {
+		t.Skip("friends_service is not running with Neo4j storage")
+	}
+
+	cfg := st.Cfg.Neo4jCfg
+	driver, err := neo4j.NewDriverWithContext("neo4j://"+cfg.Host+":"+cfg.Port,
+		neo4j.BasicAuth(cfg.Username, cfg.Password, ""))
+	require.NoError(t, err)
+	t.Cleanup(func() { driver.Close(ctx) })
+
+	// friendship stored before it became mutual: one edge without since
+	UID1, UID2 := randomUID(), randomUID()
+	_, err = neo4j.ExecuteQuery(ctx, driver, `
+		MERGE (u1:User {id: $UID})
+		MERGE (u2:User {id: $FID})
+		CREATE (u1)-[:FRIEND]->(u2)`,
+		map[string]any{"UID": UID1, "FID": UID2}, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(cfg.DBName))
+	require.NoError(t, err)
+
+	// pending request made by AddFriend stays pending
+	UID3, UID4 := randomUID(), randomUID()
+	_, err = st.FriendsClient.AddFriend(ctx, &friends.FriendRequest{UID: UID3, FriendID: UID4})
+	require.NoError(t, err)
+
+	// reverse edges are added on start, starting again changes nothing
+	for range 2 {
+		storage, err := neo_storage.New(cfg)
+		require.NoError(t, err)
+		require.NoError(t, storage.Close())
+	}
+
+	resp, err := neo4j.ExecuteQuery(ctx, driver, `
+		MATCH (:User {id: $UID})-[r:FRIEND]-(:User {id: $FID})
+		RETURN count(r) AS edges`,
+		map[string]any{"UID": UID1, "FID": UID2}, neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(cfg.DBName))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), resp.Records[0].AsMap()["edges"])
+
+	respList, err := st.FriendsClient.ListFriends(ctx, &friends.ListFriendRequest{UID: UID1})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{UID2}, respList.FriendIDs)
+
+	respList, err = st.FriendsClient.ListFriends(ctx, &friends.ListFriendRequest{UID: UID2})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{UID1}, respList.FriendIDs)
+
+	respList, err = st.FriendsClient.ListFriends(ctx, &friends.ListFriendRequest{UID: UID4})
+	require.NoError(t, err)
+	assert.Empty(t, respList.FriendIDs)
+}
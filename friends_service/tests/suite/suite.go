@@ -0,0 +1,70 @@
+package suite
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/liriquew/social-todo/friends_service/internal/lib/config"
+
+	"github.com/liriquew/todoprotos/gen/go/friends"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type Suite struct {
+	*testing.T
+	Cfg           *config.Config
+	FriendsClient friends.FriendsClient
+}
+
+const (
+	grpcHost = "localhost"
+)
+
+// New creates new test suite.
+//
+// Tests expect friends_service to be running with ../config/test_config.yaml
+// (in-memory storage, no Neo4j needed).
+func New(t *testing.T) (context.Context, *Suite) {
+	t.Helper()
+	t.Parallel()
+
+	cfg := config.MustLoadPath(configPath())
+
+	ctx, cancelCtx := context.WithTimeout(context.Background(), time.Duration(time.Second*3))
+
+	t.Cleanup(func() {
+		t.Helper()
+		cancelCtx()
+	})
+
+	cc, err := grpc.NewClient(grpcAddress(&cfg),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc server connection failed: %v", err)
+	}
+
+	return ctx, &Suite{
+		T:             t,
+		Cfg:           &cfg,
+		FriendsClient: friends.NewFriendsClient(cc),
+	}
+}
+
+func configPath() string {
+	const key = "CONFIG_PATH"
+
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+
+	return "../config/test_config.yaml"
+}
+
+func grpcAddress(cfg *config.Config) string {
+	return net.JoinHostPort(grpcHost, strconv.Itoa(cfg.Port))
+}
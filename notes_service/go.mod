@@ -5,7 +5,6 @@ go 1.22.5
 require (
 	github.com/ilyakaznacheev/cleanenv v1.5.0
 	github.com/liriquew/social-todo/api_service v0.0.0-20240719180548-d070b4d200eb
-	// github.com/liriquew/todoprotos v0.0.0-20240719213612-7306e77ea8e8
 	google.golang.org/grpc v1.65.0
 )
 
@@ -14,7 +13,7 @@ require (
 	github.com/grpc-ecosystem/go-grpc-middleware/v2 v2.1.0
 	github.com/jmoiron/sqlx v1.4.0
 	github.com/lib/pq v1.10.9
-	github.com/liriquew/todoprotos v0.0.0-20240802221253-a78abb5294d7
+	github.com/liriquew/todoprotos v0.0.0-00010101000000-000000000000
 	github.com/stretchr/testify v1.8.4
 	google.golang.org/protobuf v1.34.2
 )
@@ -34,3 +33,5 @@ require (
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 	olympos.io/encoding/edn v0.0.0-20201019073823-d3554ca0b0a3 // indirect
 )
+
+replace github.com/liriquew/todoprotos => ../todoprotos
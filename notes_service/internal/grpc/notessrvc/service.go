@@ -22,11 +22,13 @@ type StorageProvider interface {
 	GetNote(context.Context, int64, int64) (*models.Note, error)
 	UpdateNote(context.Context, int64, int64, *models.Note) error
 	DeleteNote(context.Context, int64, int64) error
+	DeleteUserNotes(context.Context, int64) (int64, error)
+	DeleteAnyNote(context.Context, int64) (int64, error)
 
 	ListUserNotesID(context.Context, int64) ([]int64, error)
 	ListUserNotes(context.Context, int64, []int64) ([]*models.Note, error)
 
-	ListUsersNotes(context.Context, []int64, int64, int64) ([]*models.Note, error)
+	ListUsersNotes(context.Context, []int64, []int64, int64, int64) ([]*models.Note, error)
 }
 
 var (
@@ -114,6 +116,47 @@ func (s *ServiceNotes) DeleteNote(ctx context.Context, UID, NID int64) error {
 	return nil
 }
 
+// DeleteUserNotes removes all notes of deleted account.
+// It's idempotent, so sso_service can safely retry it.
+func (s *ServiceNotes) DeleteUserNotes(ctx context.Context, UID int64) (int64, error) {
+	const op = "notessrvc.DeleteUserNotes"
+
+	log := s.log.With(slog.String("op", op), slog.Int64("uid", UID))
+	log.Info("attempting to Delete user notes")
+
+	deleted, err := s.Storage.DeleteUserNotes(ctx, UID)
+	if err != nil {
+		log.Warn("ERROR:", sl.Err(err))
+		return 0, err
+	}
+
+	log.Info("user notes deleted", slog.Int64("deleted", deleted))
+
+	return deleted, nil
+}
+
+// AdminDeleteNote removes note of any owner on behalf of admin actorUID.
+// Caller is trusted to check actor is admin, the action is audit logged.
+func (s *ServiceNotes) AdminDeleteNote(ctx context.Context, actorUID, NID int64) (int64, error) {
+	const op = "notessrvc.AdminDeleteNote"
+
+	log := s.log.With(slog.String("op", op), slog.Int64("actor", actorUID), slog.Int64("nid", NID))
+	log.Info("attempting to Delete note as admin")
+
+	UID, err := s.Storage.DeleteAnyNote(ctx, NID)
+	if err != nil {
+		log.Warn("ERROR:", sl.Err(err))
+		if errors.Is(err, storage.ErrNotFound) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+
+	log.Warn("audit: note removed by admin", slog.Bool("audit", true), slog.Int64("owner", UID))
+
+	return UID, nil
+}
+
 func (s *ServiceNotes) ListUserNotesID(ctx context.Context, UID int64) ([]int64, error) {
 	const op = "notessrvc.ListUserNotesID"
 
@@ -158,12 +201,12 @@ func (s *ServiceNotes) ListUserNotes(ctx context.Context, UID int64, notesIDs []
 	return notesListRes, nil
 }
 
-func (s *ServiceNotes) ListUsersNotes(ctx context.Context, UIDs []int64, offset, limit int64) ([]*notes.UsersNotesListItem, error) {
+func (s *ServiceNotes) ListUsersNotes(ctx context.Context, UIDs, publicUIDs []int64, offset, limit int64) ([]*notes.UsersNotesListItem, error) {
 	const op = "notessrvc.ListUsersNotes"
 
 	log := s.log.With(slog.String("op", op))
-	log.Info("attempting to list users notes", slog.Any("UIDs", UIDs), slog.Int64("OFFSET", offset), slog.Int64("LIMIT", limit))
-	notesList, err := s.Storage.ListUsersNotes(ctx, UIDs, offset, limit)
+	log.Info("attempting to list users notes", slog.Any("UIDs", UIDs), slog.Any("PublicUIDs", publicUIDs), slog.Int64("OFFSET", offset), slog.Int64("LIMIT", limit))
+	notesList, err := s.Storage.ListUsersNotes(ctx, UIDs, publicUIDs, offset, limit)
 	if err != nil {
 		log.Warn("ERROR", sl.Err(err))
 		return nil, err
@@ -18,11 +18,13 @@ type NotesService interface {
 	GetNote(context.Context, int64, int64) (*notes.Note, error)
 	UpdateNote(context.Context, int64, int64, *notes.Note) error
 	DeleteNote(context.Context, int64, int64) error
+	DeleteUserNotes(context.Context, int64) (int64, error)
+	AdminDeleteNote(context.Context, int64, int64) (int64, error)
 
 	ListUserNotesID(context.Context, int64) ([]int64, error)
 	ListUserNotes(context.Context, int64, []int64) ([]*notes.NoteListItem, error)
 
-	ListUsersNotes(context.Context, []int64, int64, int64) ([]*notes.UsersNotesListItem, error)
+	ListUsersNotes(context.Context, []int64, []int64, int64, int64) ([]*notes.UsersNotesListItem, error)
 }
 
 type serverAPI struct {
@@ -125,6 +127,41 @@ func (s *serverAPI) DeleteNote(ctx context.Context, req *notes.NoteIDRequest) (*
 	return &notes.NoteResponse{NID: req.NID}, err
 }
 
+func (s *serverAPI) DeleteUserNotes(ctx context.Context, req *notes.UserIDRequest) (*notes.DeleteUserNotesResponse, error) {
+	if req.UID <= 0 {
+		return nil, status.Error(codes.InvalidArgument, ErrUID.Error())
+	}
+
+	deleted, err := s.api.DeleteUserNotes(ctx, req.UID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal error idk")
+	}
+
+	return &notes.DeleteUserNotesResponse{Deleted: deleted}, nil
+}
+
+// AdminDeleteNote is not scoped by owner, only api_service admin
+// endpoints may call it.
+func (s *serverAPI) AdminDeleteNote(ctx context.Context, req *notes.AdminDeleteNoteRequest) (*notes.AdminDeleteNoteResponse, error) {
+	if req.NID <= 0 {
+		return nil, status.Error(codes.InvalidArgument, ErrNID.Error())
+	}
+	if req.ActorID <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "actor id is required")
+	}
+
+	UID, err := s.api.AdminDeleteNote(ctx, req.ActorID, req.NID)
+	if err != nil {
+		if errors.Is(err, notessrvc.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, "internal error idk")
+	}
+
+	return &notes.AdminDeleteNoteResponse{NID: req.NID, UID: UID}, nil
+}
+
 func (s *serverAPI) ListUserNotesID(ctx context.Context, req *notes.UserIDRequest) (*notes.NoteIDList, error) {
 	if req.UID <= 0 {
 		return nil, status.Error(codes.InvalidArgument, ErrUID.Error())
@@ -170,7 +207,7 @@ func (s *serverAPI) ListUserNotes(ctx context.Context, req *notes.NoteIDList) (*
 
 func (s *serverAPI) ListUsersNotes(ctx context.Context, req *notes.UsersNotesRequest) (*notes.UsersNotesList, error) {
 
-	notesList, err := s.api.ListUsersNotes(ctx, req.UIDs, req.Offset, req.Limit)
+	notesList, err := s.api.ListUsersNotes(ctx, req.UIDs, req.PublicUIDs, req.Offset, req.Limit)
 	if err != nil {
 		if errors.Is(err, notessrvc.ErrNotFound) {
 			return nil, status.Error(codes.NotFound, err.Error())
@@ -230,7 +267,7 @@ func validateRequest(req interface{}) error {
 		if v.Offset <= 0 {
 			return ErrBadOffset
 		}
-		if len(v.UIDs) == 0 {
+		if len(v.UIDs) == 0 && len(v.PublicUIDs) == 0 {
 			return ErrEmptyUIDList
 		}
 		for _, UID := range v.UIDs {
@@ -238,6 +275,11 @@ func validateRequest(req interface{}) error {
 				return ErrUID
 			}
 		}
+		for _, UID := range v.PublicUIDs {
+			if UID <= 0 {
+				return ErrUID
+			}
+		}
 	}
 	return nil
 }
@@ -15,6 +15,7 @@ type Note struct {
 	Content     string    `db:"note"`
 	CreatedTime time.Time `db:"created_at"`
 	Duration    int64     `db:"duration"` // время в минутах
+	Public      *bool     `db:"public"`
 }
 
 func NoteFromProto(n *notes.Note) *Note {
@@ -23,6 +24,7 @@ func NoteFromProto(n *notes.Note) *Note {
 		Content:     n.Content,
 		Duration:    n.Duration.AsDuration().Milliseconds(),
 		CreatedTime: n.CreatedAt.AsTime(),
+		Public:      n.Public,
 	}
 }
 
@@ -32,5 +34,6 @@ func ProtoFromNote(n *Note) *notes.Note {
 		Content:   n.Content,
 		Duration:  durationpb.New(time.Duration(time.Millisecond * time.Duration(n.Duration))),
 		CreatedAt: timestamppb.New(n.CreatedTime),
+		Public:    n.Public,
 	}
 }
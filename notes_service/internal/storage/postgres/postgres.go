@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -45,13 +46,15 @@ func (s *Storage) Close() error {
 
 func (s *Storage) SaveNote(ctx context.Context, UID int64, note *models.Note) (int64, error) {
 	const op = "postgres.SaveNote"
-	stmt, err := s.db.Prepare("INSERT INTO notes (owner_id, title, note, duration, created_at) VALUES ($1, $2, $3, $4, $5) RETURNING id")
+	stmt, err := s.db.Prepare("INSERT INTO notes (owner_id, title, note, duration, created_at, public) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id")
 	if err != nil {
 		return 0, err
 	}
 
+	public := note.Public != nil && *note.Public
+
 	var NID int64
-	err = stmt.QueryRow(UID, note.Title, note.Content, note.Duration, time.Now()).Scan(&NID)
+	err = stmt.QueryRow(UID, note.Title, note.Content, note.Duration, time.Now(), public).Scan(&NID)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
 			return 0, fmt.Errorf("%s: %w", op, storage.ErrAlreadyExists)
@@ -66,7 +69,7 @@ func (s *Storage) SaveNote(ctx context.Context, UID int64, note *models.Note) (i
 func (s *Storage) GetNote(ctx context.Context, UID, noteID int64) (*models.Note, error) {
 	const op = "postgres.GetNote"
 
-	stmt, err := s.db.Preparex("SELECT title, note, duration, created_at FROM notes WHERE owner_id=$1 and id=$2")
+	stmt, err := s.db.Preparex("SELECT title, note, duration, created_at, public FROM notes WHERE owner_id=$1 and id=$2")
 	if err != nil {
 		return nil, err
 	}
@@ -90,7 +93,8 @@ func (s *Storage) UpdateNote(ctx context.Context, UID, NID int64, note *models.N
 		SET 
 			title=CASE WHEN $1::text<>'' THEN $1 ELSE title END, 
 			note=CASE WHEN $2::text<>'' THEN $2 ELSE note END, 
-			duration=CASE WHEN $3::bigint<>0 THEN $3 ELSE duration END
+			duration=CASE WHEN $3::bigint<>0 THEN $3 ELSE duration END,
+			public=COALESCE($6::boolean, public)
 		WHERE 
 			owner_id=$4 and id=$5 
 		RETURNING id;`)
@@ -99,7 +103,7 @@ func (s *Storage) UpdateNote(ctx context.Context, UID, NID int64, note *models.N
 	}
 
 	var id int64
-	err = stmt.QueryRow(note.Title, note.Content, note.Duration, UID, NID).Scan(&id)
+	err = stmt.QueryRow(note.Title, note.Content, note.Duration, UID, NID, note.Public).Scan(&id)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
 			return fmt.Errorf("%s: %w", op, storage.ErrAlreadyExists)
@@ -134,6 +138,40 @@ func (s *Storage) DeleteNote(ctx context.Context, UID, NID int64) error {
 	return nil
 }
 
+// DeleteUserNotes removes all notes of UID, returns number of deleted notes.
+func (s *Storage) DeleteUserNotes(ctx context.Context, UID int64) (int64, error) {
+	const op = "postgres.DeleteUserNotes"
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM notes WHERE owner_id=$1", UID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deleted, nil
+}
+
+// DeleteAnyNote removes note regardless of its owner, for moderation.
+// Returns owner of the deleted note.
+func (s *Storage) DeleteAnyNote(ctx context.Context, NID int64) (int64, error) {
+	const op = "postgres.DeleteAnyNote"
+
+	var UID int64
+	err := s.db.QueryRowContext(ctx, "DELETE FROM notes WHERE id=$1 RETURNING owner_id", NID).Scan(&UID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return UID, nil
+}
+
 func (s *Storage) ListUserNotesID(ctx context.Context, UID int64) ([]int64, error) {
 	const op = "postgres.ListUserNotedID"
 	stmt, err := s.db.Preparex("SELECT id FROM notes WHERE owner_id=$1")
@@ -153,7 +191,7 @@ func (s *Storage) ListUserNotesID(ctx context.Context, UID int64) ([]int64, erro
 func (s *Storage) ListUserNotes(ctx context.Context, UID int64, NIDs []int64) ([]*models.Note, error) {
 	const op = "postgres.ListUserNoted"
 
-	query, args, err := sqlx.In("SELECT id, title, note, duration FROM notes WHERE owner_id=? and id IN(?)", UID, NIDs)
+	query, args, err := sqlx.In("SELECT id, title, note, duration, public FROM notes WHERE owner_id=? and id IN(?)", UID, NIDs)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -169,15 +207,32 @@ func (s *Storage) ListUserNotes(ctx context.Context, UID int64, NIDs []int64) ([
 	return notes, err
 }
 
-func (s *Storage) ListUsersNotes(ctx context.Context, UIDs []int64, offset, limit int64) ([]*models.Note, error) {
+// ListUsersNotes returns the latest notes of UIDs together with the public
+// notes of publicUIDs.
+func (s *Storage) ListUsersNotes(ctx context.Context, UIDs, publicUIDs []int64, offset, limit int64) ([]*models.Note, error) {
 	const op = "postgres.ListUsersNotes"
 
+	conds := make([]string, 0, 2)
+	args := make([]any, 0, 4)
+	if len(UIDs) != 0 {
+		conds = append(conds, "owner_id IN(?)")
+		args = append(args, UIDs)
+	}
+	if len(publicUIDs) != 0 {
+		conds = append(conds, "(public AND owner_id IN(?))")
+		args = append(args, publicUIDs)
+	}
+	if len(conds) == 0 {
+		return nil, nil
+	}
+	args = append(args, limit, offset)
+
 	query, args, err := sqlx.In(`
-		SELECT id, owner_id, title, note, duration, created_at 
+		SELECT id, owner_id, title, note, duration, created_at, public 
 		FROM notes 
-		WHERE owner_id IN(?)
+		WHERE `+strings.Join(conds, " OR ")+`
 		ORDER BY created_at DESC
-		LIMIT ? OFFSET ?`, UIDs, limit, offset)
+		LIMIT ? OFFSET ?`, args...)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
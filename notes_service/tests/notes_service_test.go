@@ -9,6 +9,8 @@ import (
 	"github.com/liriquew/todoprotos/gen/go/notes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/durationpb"
 )
 
@@ -380,3 +382,131 @@ func TestListNotes_HappyPath(t *testing.T) {
 	}
 
 }
+
+func TestDeleteUserNotes(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	// separate owner, so other tests' notes are not touched
+	UID := gofakeit.Int64()&0xffffff + 1000
+
+	for range 3 {
+		_, err := st.NoteClient.CreateNote(ctx, &notes.CreateNoteRequest{
+			UID: UID,
+			Note: &notes.Note{
+				Title:    gofakeit.Name(),
+				Content:  gofakeit.HackerPhrase(),
+				Duration: durationpb.New(time.Minute * 10),
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	respDelete, err := st.NoteClient.DeleteUserNotes(ctx, &notes.UserIDRequest{UID: UID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), respDelete.Deleted)
+
+	_, err = st.NoteClient.ListUserNotesID(ctx, &notes.UserIDRequest{UID: UID})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "note not found")
+
+	// repeated call is fine
+	respDelete, err = st.NoteClient.DeleteUserNotes(ctx, &notes.UserIDRequest{UID: UID})
+	require.NoError(t, err)
+	assert.Zero(t, respDelete.Deleted)
+}
+
+func TestAdminDeleteNote(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	UID := gofakeit.Int64()&0xffffff + 1000
+	adminUID := UID + 1
+
+	respCreate, err := st.NoteClient.CreateNote(ctx, &notes.CreateNoteRequest{
+		UID: UID,
+		Note: &notes.Note{
+			Title:    gofakeit.Name(),
+			Content:  gofakeit.HackerPhrase(),
+			Duration: durationpb.New(time.Minute * 10),
+		},
+	})
+	require.NoError(t, err)
+
+	respDelete, err := st.NoteClient.AdminDeleteNote(ctx, &notes.AdminDeleteNoteRequest{
+		NID:     respCreate.NID,
+		ActorID: adminUID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, UID, respDelete.UID)
+
+	_, err = st.NoteClient.GetNote(ctx, &notes.NoteIDRequest{UID: UID, NID: respCreate.NID})
+	require.Error(t, err)
+
+	_, err = st.NoteClient.AdminDeleteNote(ctx, &notes.AdminDeleteNoteRequest{
+		NID:     respCreate.NID,
+		ActorID: adminUID,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestListUsersNotes_OnlyPublicOfFollowed(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	// separate users, so other tests' notes don't get into the feed
+	followed := gofakeit.Int64()&0xffffff + 1000
+	friend := gofakeit.Int64()&0xffffff + 1000
+
+	create := func(UID int64, public bool) int64 {
+		resp, err := st.NoteClient.CreateNote(ctx, &notes.CreateNoteRequest{
+			UID: UID,
+			Note: &notes.Note{
+				Title:    gofakeit.Name(),
+				Content:  gofakeit.HackerPhrase(),
+				Duration: durationpb.New(time.Minute * 10),
+				Public:   &public,
+			},
+		})
+		require.NoError(t, err)
+		return resp.NID
+	}
+
+	followedPublic := create(followed, true)
+	create(followed, false)
+	friendPublic := create(friend, true)
+	friendPrivate := create(friend, false)
+
+	nids := func(list *notes.UsersNotesList) []int64 {
+		var res []int64
+		for _, item := range list.Notes {
+			res = append(res, item.NID)
+		}
+		return res
+	}
+
+	resp, err := st.NoteClient.ListUsersNotes(ctx, &notes.UsersNotesRequest{
+		PublicUIDs: []int64{followed},
+		Limit:      10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{followedPublic}, nids(resp))
+	for _, item := range resp.Notes {
+		assert.Equal(t, followed, item.UID)
+	}
+
+	// friends' notes are all in the feed, followed users' only public ones
+	resp, err = st.NoteClient.ListUsersNotes(ctx, &notes.UsersNotesRequest{
+		UIDs:       []int64{friend},
+		PublicUIDs: []int64{followed},
+		Limit:      10,
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int64{followedPublic, friendPublic, friendPrivate}, nids(resp))
+
+	// nothing public to show
+	_, err = st.NoteClient.ListUsersNotes(ctx, &notes.UsersNotesRequest{
+		PublicUIDs: []int64{gofakeit.Int64()&0xffffff + 1000},
+		Limit:      10,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
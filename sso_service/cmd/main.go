@@ -10,6 +10,8 @@ import (
 	"github.com/liriquew/social-todo/sso_service/internal/app"
 	"github.com/liriquew/social-todo/sso_service/internal/lib/config"
 	"github.com/liriquew/social-todo/sso_service/internal/lib/jwt"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
 )
 
 func main() {
@@ -19,13 +21,25 @@ func main() {
 
 	log.Info("", slog.Any("CONFIG", cfg))
 
-	jwt.Secret = cfg.JWTSecret
+	keys, err := jwt.LoadKeys(cfg.Keys)
+	if err != nil {
+		panic(err)
+	}
+
+	// revocation cutoffs are compared with iat, it needs milliseconds
+	gojwt.TimePrecision = jwt.Precision
+	jwt.Keys = keys
+	jwt.TTL = cfg.TokenTTL
+	jwt.Issuer = cfg.Issuer
 
-	application := app.New(log, cfg)
+	application := app.New(log, cfg, keys)
 
 	go func() {
 		application.GRPCServer.MustRun()
 	}()
+	go func() {
+		application.HTTPServer.MustRun()
+	}()
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
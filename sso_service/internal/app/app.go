@@ -1,34 +1,93 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 
 	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
 	"github.com/liriquew/social-todo/sso_service/internal/app/grpcapp"
+	"github.com/liriquew/social-todo/sso_service/internal/app/httpapp"
+	friends_grpc "github.com/liriquew/social-todo/sso_service/internal/clients/friendsgrpc"
+	notes_grpc "github.com/liriquew/social-todo/sso_service/internal/clients/notesgrpc"
+	"github.com/liriquew/social-todo/sso_service/internal/connector"
+	"github.com/liriquew/social-todo/sso_service/internal/deletion"
+	"github.com/liriquew/social-todo/sso_service/internal/http/oidc"
 	"github.com/liriquew/social-todo/sso_service/internal/lib/config"
+	"github.com/liriquew/social-todo/sso_service/internal/lib/jwt"
+	"github.com/liriquew/social-todo/sso_service/internal/lib/mailer"
+	"github.com/liriquew/social-todo/sso_service/internal/lib/password"
+	"github.com/liriquew/social-todo/sso_service/internal/lockout"
+	"github.com/liriquew/social-todo/sso_service/internal/revocation"
 	"github.com/liriquew/social-todo/sso_service/internal/sevices/auth"
 	"github.com/liriquew/social-todo/sso_service/internal/storage/postgres"
 )
 
 type App struct {
 	GRPCServer *grpcapp.App
+	HTTPServer *httpapp.App
 	closers    []func() error
 	log        *slog.Logger
 }
 
-func New(log *slog.Logger, cfg config.Config) *App {
+func New(log *slog.Logger, cfg config.Config, keys *jwt.KeySet) *App {
 	storage, err := postgres.New(cfg.Postgres)
 	if err != nil {
 		panic(err)
 	}
 
-	auth := auth.New(log, storage)
+	if err := storage.PromoteAdmins(context.Background(), cfg.Admins); err != nil {
+		panic(err)
+	}
+
+	revoker := revocation.New(log, storage, cfg.RevocationCacheTTL)
+
+	mailer, err := mailer.New(cfg.Mailer)
+	if err != nil {
+		panic(err)
+	}
+
+	policy, err := password.NewPolicy(cfg.PasswordPolicy)
+	if err != nil {
+		panic(err)
+	}
+
+	connectors, err := connector.New(cfg.ExternalLogin)
+	if err != nil {
+		panic(err)
+	}
+
+	auth := auth.New(log, storage, revoker, mailer, policy, lockout.New(cfg.Lockout), connectors, cfg)
 
 	app := grpcapp.New(log, auth, cfg.Port)
 
-	mainApp := &App{GRPCServer: app, log: log}
-	mainApp.closers = append(mainApp.closers, storage.Close)
+	httpApp := httpapp.New(log, keys, cfg.JWKSPort)
+	if cfg.OIDC.Enabled {
+		provider := oidc.New(log, auth, cfg.OIDC, httpapp.JWKSPath, keys.Signing().Method.Alg())
+		httpApp.Handle(oidc.DiscoveryPath, provider)
+		httpApp.Handle("/oauth2/", provider)
+	}
+
+	notesClient, err := notes_grpc.New(cfg.AccountDeletion.NotesAddr, cfg.AccountDeletion.Timeout)
+	if err != nil {
+		panic(err)
+	}
+	friendsClient, err := friends_grpc.New(cfg.AccountDeletion.FriendsAddr, cfg.AccountDeletion.Timeout)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	deletionWorker := deletion.New(log, storage, notesClient, friendsClient, cfg.AccountDeletion)
+	go deletionWorker.Run(ctx)
+
+	mainApp := &App{GRPCServer: app, HTTPServer: httpApp, log: log}
+	mainApp.closers = append(mainApp.closers,
+		func() error { cancel(); return nil },
+		notesClient.Close,
+		friendsClient.Close,
+		storage.Close,
+	)
 	return mainApp
 }
 
@@ -42,5 +101,6 @@ func (a *App) Stop() {
 		}
 	}
 
+	a.HTTPServer.Stop()
 	a.GRPCServer.Stop()
 }
@@ -0,0 +1,92 @@
+package httpapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/liriquew/social-todo/sso_service/internal/lib/jwt"
+)
+
+const JWKSPath = "/.well-known/jwks.json"
+
+type App struct {
+	log    *slog.Logger
+	server *http.Server
+	mux    *http.ServeMux
+	port   int
+}
+
+// New creates HTTP server publishing JWKS document.
+func New(log *slog.Logger, keys *jwt.KeySet, port int) *App {
+	body, err := json.Marshal(keys.JWKS())
+	if err != nil {
+		panic(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+JWKSPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.Write(body)
+	})
+
+	return &App{
+		log: log,
+		server: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+		mux:  mux,
+		port: port,
+	}
+}
+
+// Handle registers additional handler, e.g. OIDC provider.
+// It must be called before Run.
+func (a *App) Handle(pattern string, handler http.Handler) {
+	a.mux.Handle(pattern, handler)
+}
+
+// MustRun runs HTTP server and panics if any error occurs.
+func (a *App) MustRun() {
+	if err := a.Run(); err != nil {
+		panic(err)
+	}
+}
+
+// Run runs HTTP server.
+func (a *App) Run() error {
+	const op = "httpapp.Run"
+
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", a.port))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.log.Info("http server started", slog.String("addr", l.Addr().String()))
+
+	if err := a.server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Stop stops HTTP server.
+func (a *App) Stop() {
+	const op = "httpapp.Stop"
+
+	a.log.With(slog.String("op", op)).
+		Info("stopping HTTP server", slog.Int("port", a.port))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	a.server.Shutdown(ctx)
+}
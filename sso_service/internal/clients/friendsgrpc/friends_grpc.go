@@ -0,0 +1,51 @@
+package friends_grpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/liriquew/todoprotos/gen/go/friends"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Client calls friends_service on behalf of sso_service.
+type Client struct {
+	api     friends.FriendsClient
+	conn    *grpc.ClientConn
+	timeout time.Duration
+}
+
+func New(addr string, timeout time.Duration) (*Client, error) {
+	const op = "friends_grpc.New"
+
+	cc, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Client{
+		api:     friends.NewFriendsClient(cc),
+		conn:    cc,
+		timeout: timeout,
+	}, nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// DeleteUser detaches UID from the friends graph.
+func (c *Client) DeleteUser(ctx context.Context, UID int64) error {
+	const op = "friends_grpc.DeleteUser"
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	if _, err := c.api.DeleteUser(ctx, &friends.DeleteUserRequest{UID: UID}); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package notes_grpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/liriquew/todoprotos/gen/go/notes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Client calls notes_service on behalf of sso_service.
+type Client struct {
+	api     notes.NotesClient
+	conn    *grpc.ClientConn
+	timeout time.Duration
+}
+
+func New(addr string, timeout time.Duration) (*Client, error) {
+	const op = "notes_grpc.New"
+
+	cc, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Client{
+		api:     notes.NewNotesClient(cc),
+		conn:    cc,
+		timeout: timeout,
+	}, nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// DeleteUserNotes removes all notes of UID.
+func (c *Client) DeleteUserNotes(ctx context.Context, UID int64) error {
+	const op = "notes_grpc.DeleteUserNotes"
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	if _, err := c.api.DeleteUserNotes(ctx, &notes.UserIDRequest{UID: UID}); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/liriquew/social-todo/sso_service/internal/lib/config"
+)
+
+// ErrAuthFailed is returned when provider rejects the code
+// or doesn't tell who the user is.
+var ErrAuthFailed = errors.New("external authentication failed")
+
+// Identity is user's account at external identity provider.
+// Subject is stable, other fields are informational.
+type Identity struct {
+	Subject  string
+	Username string
+	Email    string
+	Name     string
+}
+
+// Connector signs users in with external identity provider.
+type Connector interface {
+	ID() string
+	Name() string
+	// AuthURL is provider's page the user is sent to. state and
+	// codeChallenge (PKCE, S256) come back with the code.
+	AuthURL(state, codeChallenge string) string
+	// Exchange exchanges the code for the user's identity.
+	Exchange(ctx context.Context, code, codeVerifier string) (Identity, error)
+}
+
+// Set is configured connectors by ID.
+type Set map[string]Connector
+
+// New creates connectors from config, IDs must be unique.
+func New(cfg config.ExternalLoginConfig) (Set, error) {
+	client := &http.Client{Timeout: cfg.Timeout}
+
+	set := make(Set, len(cfg.Connectors))
+	for _, c := range cfg.Connectors {
+		if _, ok := set[c.ID]; ok {
+			return nil, fmt.Errorf("connector: duplicate id %q", c.ID)
+		}
+
+		conn, err := newOAuth2(c, client)
+		if err != nil {
+			return nil, err
+		}
+		set[c.ID] = conn
+	}
+
+	return set, nil
+}
+
+// Sorted returns connectors ordered by ID.
+func (s Set) Sorted() []Connector {
+	res := make([]Connector, 0, len(s))
+	for _, c := range s {
+		res = append(res, c)
+	}
+	slices.SortFunc(res, func(a, b Connector) int {
+		return strings.Compare(a.ID(), b.ID())
+	})
+
+	return res
+}
@@ -0,0 +1,191 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/liriquew/social-todo/sso_service/internal/lib/config"
+)
+
+const maxResponseSize = 1 << 20
+
+var idRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// oauth2 is a provider with authorization code flow and userinfo endpoint.
+type oauth2 struct {
+	cfg    config.ConnectorConfig
+	client *http.Client
+}
+
+func newOAuth2(cfg config.ConnectorConfig, client *http.Client) (*oauth2, error) {
+	if !idRe.MatchString(cfg.ID) {
+		return nil, fmt.Errorf("connector: invalid id %q", cfg.ID)
+	}
+	for name, u := range map[string]string{
+		"auth_url":     cfg.AuthURL,
+		"token_url":    cfg.TokenURL,
+		"userinfo_url": cfg.UserInfoURL,
+		"redirect_url": cfg.RedirectURL,
+	} {
+		parsed, err := url.Parse(u)
+		if err != nil || parsed.Host == "" || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+			return nil, fmt.Errorf("connector %s: invalid %s %q", cfg.ID, name, u)
+		}
+	}
+	if cfg.ClientID == "" {
+		return nil, fmt.Errorf("connector %s: client_id is required", cfg.ID)
+	}
+
+	if cfg.Name == "" {
+		cfg.Name = cfg.ID
+	}
+	if cfg.SubjectClaim == "" {
+		cfg.SubjectClaim = "sub"
+	}
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = "preferred_username"
+	}
+
+	return &oauth2{cfg: cfg, client: client}, nil
+}
+
+func (c *oauth2) ID() string   { return c.cfg.ID }
+func (c *oauth2) Name() string { return c.cfg.Name }
+
+func (c *oauth2) AuthURL(state, codeChallenge string) string {
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.cfg.ClientID},
+		"redirect_uri":          {c.cfg.RedirectURL},
+		"state":                 {state},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	if len(c.cfg.Scopes) > 0 {
+		params.Set("scope", strings.Join(c.cfg.Scopes, " "))
+	}
+
+	sep := "?"
+	if strings.Contains(c.cfg.AuthURL, "?") {
+		sep = "&"
+	}
+	return c.cfg.AuthURL + sep + params.Encode()
+}
+
+func (c *oauth2) Exchange(ctx context.Context, code, codeVerifier string) (Identity, error) {
+	accessToken, err := c.token(ctx, code, codeVerifier)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	return c.userInfo(ctx, accessToken)
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	Error       string `json:"error"`
+}
+
+func (c *oauth2) token(ctx context.Context, code, codeVerifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.cfg.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// some providers answer with form encoding unless asked
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+
+	var resp tokenResponse
+	status, err := c.do(req, &resp)
+	if err != nil {
+		return "", err
+	}
+	if (status >= 400 && status < 500) || resp.Error != "" {
+		return "", fmt.Errorf("%w: token endpoint: %d %s", ErrAuthFailed, status, resp.Error)
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("token endpoint: unexpected status %d", status)
+	}
+	if resp.AccessToken == "" || (resp.TokenType != "" && !strings.EqualFold(resp.TokenType, "bearer")) {
+		return "", fmt.Errorf("%w: token endpoint: no bearer token", ErrAuthFailed)
+	}
+
+	return resp.AccessToken, nil
+}
+
+func (c *oauth2) userInfo(ctx context.Context, accessToken string) (Identity, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.UserInfoURL, nil)
+	if err != nil {
+		return Identity{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	var claims map[string]any
+	status, err := c.do(req, &claims)
+	if err != nil {
+		return Identity{}, err
+	}
+	if status != http.StatusOK {
+		return Identity{}, fmt.Errorf("userinfo endpoint: unexpected status %d", status)
+	}
+
+	identity := Identity{
+		Subject:  claim(claims, c.cfg.SubjectClaim),
+		Username: claim(claims, c.cfg.UsernameClaim),
+		Email:    claim(claims, "email"),
+		Name:     claim(claims, "name"),
+	}
+	if identity.Subject == "" {
+		return Identity{}, fmt.Errorf("%w: no %s claim", ErrAuthFailed, c.cfg.SubjectClaim)
+	}
+
+	return identity, nil
+}
+
+// do sends the request and decodes JSON body into v. Body of error
+// responses is decoded if possible, providers put error codes there.
+func (c *oauth2) do(req *http.Request, v any) (int, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil && resp.StatusCode == http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("%s: bad response: %w", req.URL.Path, err)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// claim returns string or numeric claim as string, numeric IDs are common.
+func claim(claims map[string]any, name string) string {
+	switch v := claims[name].(type) {
+	case string:
+		return v
+	case json.Number:
+		if _, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return v.String()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+package deletion
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
+	"github.com/liriquew/social-todo/sso_service/internal/lib/config"
+	"github.com/liriquew/social-todo/sso_service/internal/models"
+)
+
+type Storage interface {
+	ClaimAccountDeletions(ctx context.Context, limit int, lease time.Duration) ([]models.AccountDeletion, error)
+	SaveAccountDeletion(ctx context.Context, d models.AccountDeletion) error
+	PurgeUser(ctx context.Context, UID int64) error
+}
+
+// NotesCleaner removes user's notes, see notes_grpc.Client.
+type NotesCleaner interface {
+	DeleteUserNotes(ctx context.Context, UID int64) error
+}
+
+// FriendsCleaner removes user from the friends graph, see friends_grpc.Client.
+type FriendsCleaner interface {
+	DeleteUser(ctx context.Context, UID int64) error
+}
+
+const batchSize = 10
+
+// Worker completes account deletions started by auth.DeleteAccount.
+// User's data is removed from notes_service and friends_service first,
+// the sso row is purged last, so a failure never leaves data of
+// a user nobody can authenticate as to delete it again.
+type Worker struct {
+	log     *slog.Logger
+	storage Storage
+	notes   NotesCleaner
+	friends FriendsCleaner
+	cfg     config.AccountDeletionConfig
+	now     func() time.Time
+}
+
+func New(
+	log *slog.Logger,
+	storage Storage,
+	notes NotesCleaner,
+	friends FriendsCleaner,
+	cfg config.AccountDeletionConfig,
+) *Worker {
+	return &Worker{
+		log:     log,
+		storage: storage,
+		notes:   notes,
+		friends: friends,
+		cfg:     cfg,
+		now:     time.Now,
+	}
+}
+
+// Run processes due deletions every PollInterval until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.processDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) processDue(ctx context.Context) {
+	const op = "deletion.Worker.processDue"
+
+	log := w.log.With(slog.String("op", op))
+
+	// both cleanups and the purge must fit in the lease
+	lease := 3*w.cfg.Timeout + time.Second
+
+	jobs, err := w.storage.ClaimAccountDeletions(ctx, batchSize, lease)
+	if err != nil {
+		log.Error("failed to claim deletions", sl.Err(err))
+		return
+	}
+
+	for _, d := range jobs {
+		d = w.process(ctx, d)
+		if err := w.storage.SaveAccountDeletion(ctx, d); err != nil {
+			// job is retried after the lease
+			log.Error("failed to save deletion", slog.String("ID", d.ID), sl.Err(err))
+		}
+	}
+}
+
+func (w *Worker) process(ctx context.Context, d models.AccountDeletion) models.AccountDeletion {
+	log := w.log.With(slog.String("deletion ID", d.ID), slog.Int64("UID", d.UID))
+
+	d.Attempts++
+
+	err := w.runSteps(ctx, &d)
+	if err == nil {
+		now := w.now()
+		d.Status = models.DeletionCompleted
+		d.LastError = ""
+		d.CompletedAt = &now
+		log.Info("account deleted")
+		return d
+	}
+
+	d.LastError = err.Error()
+	if d.Attempts >= w.cfg.MaxAttempts {
+		d.Status = models.DeletionFailed
+		log.Error("account deletion failed, giving up", slog.Int("attempts", d.Attempts), sl.Err(err))
+		return d
+	}
+
+	d.NextAttempt = w.now().Add(w.backoff(d.Attempts))
+	log.Warn("account deletion failed, will retry",
+		slog.Int("attempts", d.Attempts), slog.Time("next attempt", d.NextAttempt), sl.Err(err))
+	return d
+}
+
+func (w *Worker) runSteps(ctx context.Context, d *models.AccountDeletion) error {
+	if !d.NotesDone {
+		if err := w.notes.DeleteUserNotes(ctx, d.UID); err != nil {
+			return err
+		}
+		d.NotesDone = true
+	}
+
+	if !d.FriendsDone {
+		if err := w.friends.DeleteUser(ctx, d.UID); err != nil {
+			return err
+		}
+		d.FriendsDone = true
+	}
+
+	return w.storage.PurgeUser(ctx, d.UID)
+}
+
+// backoff doubles RetryDelay every attempt, up to MaxRetryDelay.
+func (w *Worker) backoff(attempts int) time.Duration {
+	delay := w.cfg.RetryDelay
+	for i := 1; i < attempts && delay < w.cfg.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, w.cfg.MaxRetryDelay)
+}
@@ -0,0 +1,214 @@
+package deletion
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liriquew/social-todo/sso_service/internal/lib/config"
+	"github.com/liriquew/social-todo/sso_service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errUnavailable = errors.New("unavailable")
+
+// fakeStorage keeps deletions in memory, claims pending ones that are due.
+type fakeStorage struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	deletions map[string]models.AccountDeletion
+	purged    []int64
+	purgeErr  error
+}
+
+func (f *fakeStorage) ClaimAccountDeletions(ctx context.Context, limit int, lease time.Duration) ([]models.AccountDeletion, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var ans []models.AccountDeletion
+	for ID, d := range f.deletions {
+		if len(ans) == limit {
+			break
+		}
+		if d.Status != models.DeletionPending || d.NextAttempt.After(f.now()) {
+			continue
+		}
+		ans = append(ans, d)
+		d.NextAttempt = f.now().Add(lease)
+		f.deletions[ID] = d
+	}
+	return ans, nil
+}
+
+func (f *fakeStorage) SaveAccountDeletion(ctx context.Context, d models.AccountDeletion) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.deletions[d.ID] = d
+	return nil
+}
+
+func (f *fakeStorage) PurgeUser(ctx context.Context, UID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.purgeErr != nil {
+		return f.purgeErr
+	}
+	f.purged = append(f.purged, UID)
+	return nil
+}
+
+func (f *fakeStorage) deletion(ID string) models.AccountDeletion {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.deletions[ID]
+}
+
+// fakeCleaner fails while errs isn't empty, one error per call.
+type fakeCleaner struct {
+	errs  []error
+	calls int
+}
+
+func (f *fakeCleaner) clean() error {
+	f.calls++
+	if len(f.errs) == 0 {
+		return nil
+	}
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return err
+}
+
+func (f *fakeCleaner) DeleteUserNotes(ctx context.Context, UID int64) error { return f.clean() }
+func (f *fakeCleaner) DeleteUser(ctx context.Context, UID int64) error      { return f.clean() }
+
+type setup struct {
+	now     time.Time
+	storage *fakeStorage
+	notes   *fakeCleaner
+	friends *fakeCleaner
+	w       *Worker
+}
+
+func newSetup(t *testing.T, maxAttempts int) *setup {
+	t.Helper()
+
+	s := &setup{
+		now:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		notes:   &fakeCleaner{},
+		friends: &fakeCleaner{},
+	}
+	now := func() time.Time { return s.now }
+	s.storage = &fakeStorage{
+		now: now,
+		deletions: map[string]models.AccountDeletion{
+			"d1": {ID: "d1", UID: 1, Status: models.DeletionPending, NextAttempt: s.now},
+		},
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s.w = New(log, s.storage, s.notes, s.friends, config.AccountDeletionConfig{
+		Timeout:       time.Second,
+		RetryDelay:    10 * time.Second,
+		MaxRetryDelay: time.Minute,
+		MaxAttempts:   maxAttempts,
+	})
+	s.w.now = now
+
+	return s
+}
+
+func TestWorker_RetryUntilCompleted(t *testing.T) {
+	s := newSetup(t, 5)
+	s.friends.errs = []error{errUnavailable}
+
+	s.w.processDue(context.Background())
+
+	d := s.storage.deletion("d1")
+	assert.Equal(t, models.DeletionPending, d.Status)
+	assert.Equal(t, 1, d.Attempts)
+	assert.Equal(t, errUnavailable.Error(), d.LastError)
+	assert.True(t, d.NotesDone)
+	assert.False(t, d.FriendsDone)
+	assert.Equal(t, s.now.Add(10*time.Second), d.NextAttempt)
+	assert.Empty(t, s.storage.purged)
+
+	// not due yet
+	s.now = s.now.Add(5 * time.Second)
+	s.w.processDue(context.Background())
+	assert.Equal(t, 1, s.storage.deletion("d1").Attempts)
+
+	s.now = s.now.Add(5 * time.Second)
+	s.w.processDue(context.Background())
+
+	d = s.storage.deletion("d1")
+	assert.Equal(t, models.DeletionCompleted, d.Status)
+	assert.Equal(t, 2, d.Attempts)
+	assert.Empty(t, d.LastError)
+	require.NotNil(t, d.CompletedAt)
+	assert.Equal(t, s.now, *d.CompletedAt)
+	assert.Equal(t, []int64{1}, s.storage.purged)
+
+	// done steps aren't repeated
+	assert.Equal(t, 1, s.notes.calls)
+	assert.Equal(t, 2, s.friends.calls)
+}
+
+func TestWorker_GiveUpAfterMaxAttempts(t *testing.T) {
+	s := newSetup(t, 3)
+	s.storage.purgeErr = errUnavailable
+
+	delays := []time.Duration{10 * time.Second, 20 * time.Second}
+	for i, delay := range delays {
+		s.w.processDue(context.Background())
+
+		d := s.storage.deletion("d1")
+		require.Equal(t, models.DeletionPending, d.Status)
+		require.Equal(t, i+1, d.Attempts)
+		require.Equal(t, s.now.Add(delay), d.NextAttempt)
+
+		s.now = d.NextAttempt
+	}
+
+	s.w.processDue(context.Background())
+
+	d := s.storage.deletion("d1")
+	assert.Equal(t, models.DeletionFailed, d.Status)
+	assert.Equal(t, 3, d.Attempts)
+	assert.Equal(t, errUnavailable.Error(), d.LastError)
+	assert.Nil(t, d.CompletedAt)
+
+	// failed deletions aren't claimed again
+	s.now = s.now.Add(time.Hour)
+	s.w.processDue(context.Background())
+	assert.Equal(t, 3, s.storage.deletion("d1").Attempts)
+	assert.Equal(t, 1, s.notes.calls)
+	assert.Equal(t, 1, s.friends.calls)
+}
+
+func TestWorker_Backoff(t *testing.T) {
+	s := newSetup(t, 10)
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 10 * time.Second},
+		{attempts: 2, want: 20 * time.Second},
+		{attempts: 3, want: 40 * time.Second},
+		{attempts: 4, want: time.Minute},
+		{attempts: 100, want: time.Minute},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, s.w.backoff(tt.attempts), "attempts=%d", tt.attempts)
+	}
+}
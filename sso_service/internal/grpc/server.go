@@ -4,19 +4,78 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/liriquew/social-todo/sso_service/internal/lib/password"
+	"github.com/liriquew/social-todo/sso_service/internal/lockout"
+	"github.com/liriquew/social-todo/sso_service/internal/models"
 	"github.com/liriquew/social-todo/sso_service/internal/sevices/auth"
 
 	"github.com/liriquew/todoprotos/gen/go/sso"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 type Auth interface {
-	Login(context.Context, string, string) (string, error)
-	Register(context.Context, string, string) (int64, error)
-	Authorize(context.Context, string) (int64, error)
+	Login(context.Context, string, string, models.Client) (models.LoginResult, error)
+	CompleteLogin(context.Context, string, string, models.Client) (models.TokenPair, error)
+	Refresh(context.Context, string, models.Client) (models.TokenPair, error)
+	Register(context.Context, string, string, string) (int64, error)
+	Authorize(context.Context, string) (models.Principal, error)
+	Logout(context.Context, string, string) error
+	LogoutAll(context.Context, string) error
+	Revocations(context.Context, time.Time) (models.Revocations, error)
+	ChangePassword(context.Context, string, string, string) error
+	RequestPasswordReset(context.Context, string) error
+	ResetPassword(context.Context, string, string) error
+	EnrollTOTP(context.Context, string) (models.TOTPEnrollment, error)
+	ConfirmTOTP(context.Context, string, string) ([]string, error)
+	DisableTOTP(context.Context, string, string) error
+	Users(context.Context, []int64) ([]models.User, error)
+	Profile(context.Context, int64) (models.Profile, error)
+	Profiles(context.Context, []int64) ([]models.Profile, error)
+	UpdateProfile(context.Context, string, models.ProfileUpdate) (models.Profile, error)
+	SearchUsers(context.Context, string, string, string, int64) ([]models.User, string, error)
+	LookupUser(context.Context, string, string) (models.User, error)
+	BlockUser(context.Context, string, int64) error
+	UnblockUser(context.Context, string, int64) error
+	DeleteAccount(context.Context, string, string) (models.AccountDeletion, error)
+	AccountDeletion(context.Context, string) (models.AccountDeletion, error)
+	CreateAccessToken(context.Context, string, string, []string, time.Duration) (models.AccessToken, string, error)
+	AccessTokens(context.Context, string) ([]models.AccessToken, error)
+	RevokeAccessToken(context.Context, string, int64) error
+	AuthorizeAccessToken(context.Context, string) (models.AccessToken, error)
+	Sessions(context.Context, string) ([]models.Session, string, error)
+	RevokeSession(context.Context, string, string) error
+	ListUsers(context.Context, string, string, int64) ([]models.User, string, error)
+	SetUserDisabled(context.Context, string, int64, bool) error
+	ForceLogout(context.Context, string, int64) error
+	SetUserRole(context.Context, string, int64, string) error
+	AuthorizeAdminAction(context.Context, string, string, string, string) error
+	RecordAdminAction(context.Context, string, string, string, string, string) error
+	AuditLog(context.Context, string, string, int64) ([]models.AuditEntry, string, error)
+	CreateOIDCClient(context.Context, string, string, []string, bool) (models.OIDCClient, string, error)
+	OIDCClients(context.Context, string) ([]models.OIDCClient, error)
+	DeleteOIDCClient(context.Context, string, string) error
+	Connectors() []models.Connector
+	StartExternalLogin(context.Context, string) (string, error)
+	ExternalLogin(context.Context, string, string, string, models.Client) (models.LoginResult, error)
+	StartLinkIdentity(context.Context, string, string) (string, error)
+	LinkIdentity(context.Context, string, string, string, string) (models.Identity, error)
+	UnlinkIdentity(context.Context, string, string) error
+	Identities(context.Context, string) ([]models.Identity, error)
+	SetEmail(context.Context, string, string) error
+	SendVerification(context.Context, string) error
+	VerifyEmail(context.Context, string) error
+	Email(context.Context, string) (string, bool, error)
 }
 
 type serverAPI struct {
@@ -24,6 +83,24 @@ type serverAPI struct {
 	auth Auth
 }
 
+const maxUsersBatch = 1000
+
+const (
+	defaultAdminListLimit = 50
+	maxAdminListLimit     = 200
+
+	maxAuditActionLen  = 64
+	maxAuditTargetLen  = 128
+	maxAuditDetailsLen = 1024
+)
+
+const (
+	minSearchQueryLen  = 2
+	maxSearchQueryLen  = 64
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+)
+
 func Register(gRPC *grpc.Server, auth Auth) {
 	sso.RegisterAuthServer(gRPC, &serverAPI{auth: auth})
 }
@@ -33,15 +110,153 @@ func (g *serverAPI) Login(ctx context.Context, req *sso.LoginRequest) (*sso.Logi
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	token, err := g.auth.Login(ctx, req.Username, req.Password)
+	res, err := g.auth.Login(ctx, req.Username, req.Password, client(ctx))
 	if err != nil {
-		if errors.Is(err, auth.ErrUserNotFound) {
-			return nil, status.Error(codes.NotFound, "invalid username or password")
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			return nil, status.Error(codes.Unauthenticated, "invalid username or password")
+		}
+		if st, ok := lockedStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrUserDisabled) {
+			return nil, status.Error(codes.PermissionDenied, "account disabled")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to login")
+	}
+
+	if res.ChallengeToken != "" {
+		return &sso.LoginResponse{TwoFactorRequired: true, ChallengeToken: res.ChallengeToken}, nil
+	}
+	return &sso.LoginResponse{Token: res.Tokens.AccessToken, RefreshToken: res.Tokens.RefreshToken}, nil
+}
+
+func (g *serverAPI) CompleteLogin(ctx context.Context, req *sso.CompleteLoginRequest) (*sso.LoginResponse, error) {
+	if req.ChallengeToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "challenge token is required")
+	}
+	if req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "code is required")
+	}
+
+	pair, err := g.auth.CompleteLogin(ctx, req.ChallengeToken, req.Code, client(ctx))
+	if err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrInvalidCode) {
+			return nil, status.Error(codes.Unauthenticated, "invalid code")
+		}
+		if st, ok := lockedStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrUserDisabled) {
+			return nil, status.Error(codes.PermissionDenied, "account disabled")
 		}
 
 		return nil, status.Error(codes.Internal, "failed to login")
 	}
-	return &sso.LoginResponse{Token: token}, nil
+
+	return &sso.LoginResponse{Token: pair.AccessToken, RefreshToken: pair.RefreshToken}, nil
+}
+
+func (g *serverAPI) EnrollTOTP(ctx context.Context, req *sso.EnrollTOTPRequest) (*sso.EnrollTOTPResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+
+	enrollment, err := g.auth.EnrollTOTP(ctx, req.Token)
+	if err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrTOTPEnabled) {
+			return nil, status.Error(codes.FailedPrecondition, "two-factor authentication already enabled")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to enroll totp")
+	}
+
+	return &sso.EnrollTOTPResponse{Secret: enrollment.Secret, Uri: enrollment.URI}, nil
+}
+
+func (g *serverAPI) ConfirmTOTP(ctx context.Context, req *sso.ConfirmTOTPRequest) (*sso.ConfirmTOTPResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	if req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "code is required")
+	}
+
+	recoveryCodes, err := g.auth.ConfirmTOTP(ctx, req.Token, req.Code)
+	if err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrInvalidCode) {
+			return nil, status.Error(codes.InvalidArgument, "invalid code")
+		}
+		if errors.Is(err, auth.ErrTOTPEnabled) {
+			return nil, status.Error(codes.FailedPrecondition, "two-factor authentication already enabled")
+		}
+		if errors.Is(err, auth.ErrTOTPNotEnrolled) {
+			return nil, status.Error(codes.FailedPrecondition, "two-factor authentication not enrolled")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to confirm totp")
+	}
+
+	return &sso.ConfirmTOTPResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (g *serverAPI) DisableTOTP(ctx context.Context, req *sso.DisableTOTPRequest) (*sso.DisableTOTPResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	if req.Password == "" {
+		return nil, status.Error(codes.InvalidArgument, "password is required")
+	}
+
+	if err := g.auth.DisableTOTP(ctx, req.Token, req.Password); err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			return nil, status.Error(codes.PermissionDenied, "invalid password")
+		}
+		if errors.Is(err, auth.ErrTOTPNotEnrolled) {
+			return nil, status.Error(codes.FailedPrecondition, "two-factor authentication not enrolled")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to disable totp")
+	}
+
+	return &sso.DisableTOTPResponse{}, nil
+}
+
+func (g *serverAPI) Refresh(ctx context.Context, req *sso.RefreshRequest) (*sso.RefreshResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "refresh token is empty")
+	}
+
+	pair, err := g.auth.Refresh(ctx, req.RefreshToken, client(ctx))
+	if err != nil {
+		if errors.Is(err, auth.ErrTokenReused) {
+			return nil, status.Error(codes.Unauthenticated, "refresh token reused")
+		}
+		if errors.Is(err, auth.ErrTokenExpired) {
+			return nil, status.Error(codes.Unauthenticated, "refresh token expired")
+		}
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+		}
+		if errors.Is(err, auth.ErrUserDisabled) {
+			return nil, status.Error(codes.PermissionDenied, "account disabled")
+		}
+		return nil, status.Error(codes.Internal, "failed to refresh token")
+	}
+
+	return &sso.RefreshResponse{Token: pair.AccessToken, RefreshToken: pair.RefreshToken}, nil
 }
 
 func (g *serverAPI) Register(ctx context.Context, req *sso.RegisterRequest) (*sso.RegisterResponse, error) {
@@ -49,8 +264,14 @@ func (g *serverAPI) Register(ctx context.Context, req *sso.RegisterRequest) (*ss
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	uid, err := g.auth.Register(ctx, req.Username, req.Password)
+	uid, err := g.auth.Register(ctx, req.Username, req.Password, req.Email)
 	if err != nil {
+		if st, ok := policyStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrInvalidEmail) {
+			return nil, status.Error(codes.InvalidArgument, "invalid email")
+		}
 		if errors.Is(err, auth.ErrUserExist) {
 			return nil, status.Error(codes.AlreadyExists, "user already exists")
 		}
@@ -68,12 +289,1237 @@ func (g *serverAPI) Authorize(ctx context.Context, req *sso.AuthorizeRequest) (*
 		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
 	}
 
-	uid, err := g.auth.Authorize(ctx, token)
+	principal, err := g.auth.Authorize(ctx, token)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to authorize")
+	}
+
+	return &sso.AuthorizeResponse{
+		Uid:           principal.UID,
+		Role:          principal.Role,
+		EmailVerified: principal.EmailVerified,
+	}, nil
+}
+
+func (g *serverAPI) Logout(ctx context.Context, req *sso.LogoutRequest) (*sso.LogoutResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+
+	if err := g.auth.Logout(ctx, req.Token, req.RefreshToken); err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to logout")
+	}
+
+	return &sso.LogoutResponse{}, nil
+}
+
+func (g *serverAPI) LogoutAll(ctx context.Context, req *sso.LogoutAllRequest) (*sso.LogoutAllResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+
+	if err := g.auth.LogoutAll(ctx, req.Token); err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to logout")
+	}
+
+	return &sso.LogoutAllResponse{}, nil
+}
+
+func (g *serverAPI) ChangePassword(ctx context.Context, req *sso.ChangePasswordRequest) (*sso.ChangePasswordResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	if req.OldPassword == "" {
+		return nil, status.Error(codes.InvalidArgument, "old password is required")
+	}
+	if req.NewPassword == "" {
+		return nil, status.Error(codes.InvalidArgument, "new password is required")
+	}
+
+	err := g.auth.ChangePassword(ctx, req.Token, req.OldPassword, req.NewPassword)
+	if err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		if st, ok := policyStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			return nil, status.Error(codes.PermissionDenied, "invalid old password")
+		}
+		return nil, status.Error(codes.Internal, "failed to change password")
+	}
+
+	return &sso.ChangePasswordResponse{}, nil
+}
+
+func (g *serverAPI) RequestPasswordReset(ctx context.Context, req *sso.RequestPasswordResetRequest) (*sso.RequestPasswordResetResponse, error) {
+	if req.Username == "" {
+		return nil, status.Error(codes.InvalidArgument, "username is required")
+	}
+
+	if err := g.auth.RequestPasswordReset(ctx, req.Username); err != nil {
+		return nil, status.Error(codes.Internal, "failed to request password reset")
+	}
+
+	return &sso.RequestPasswordResetResponse{}, nil
+}
+
+func (g *serverAPI) ResetPassword(ctx context.Context, req *sso.ResetPasswordRequest) (*sso.ResetPasswordResponse, error) {
+	if req.ResetToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "reset token is required")
+	}
+	if req.NewPassword == "" {
+		return nil, status.Error(codes.InvalidArgument, "new password is required")
+	}
+
+	if err := g.auth.ResetPassword(ctx, req.ResetToken, req.NewPassword); err != nil {
+		if st, ok := policyStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return nil, status.Error(codes.FailedPrecondition, "invalid or expired reset token")
+		}
+		return nil, status.Error(codes.Internal, "failed to reset password")
+	}
+
+	return &sso.ResetPasswordResponse{}, nil
+}
+
+func (g *serverAPI) ListRevocations(ctx context.Context, req *sso.ListRevocationsRequest) (*sso.ListRevocationsResponse, error) {
+	if req.Since < 0 {
+		return nil, status.Error(codes.InvalidArgument, "since must be non-negative")
+	}
+
+	revs, err := g.auth.Revocations(ctx, time.UnixMilli(req.Since))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list revocations")
+	}
+
+	resp := &sso.ListRevocationsResponse{
+		Tokens: make([]*sso.RevokedToken, 0, len(revs.Tokens)),
+		Users:  make([]*sso.UserRevocation, 0, len(revs.Users)),
+		Until:  revs.Until.UnixMilli(),
+	}
+	for _, token := range revs.Tokens {
+		resp.Tokens = append(resp.Tokens, &sso.RevokedToken{
+			Jti:       token.JTI,
+			ExpiresAt: token.ExpiresAt.Unix(),
+		})
+	}
+	for _, user := range revs.Users {
+		resp.Users = append(resp.Users, &sso.UserRevocation{
+			Uid:           user.UID,
+			RevokedBefore: user.RevokedBefore.UnixMilli(),
+		})
+	}
+
+	return resp, nil
+}
+
+// tokenStatus maps access token validation errors.
+func tokenStatus(err error) (*status.Status, bool) {
+	switch {
+	case errors.Is(err, auth.ErrTokenExpired):
+		return status.New(codes.Unauthenticated, "token expired"), true
+	case errors.Is(err, auth.ErrTokenRevoked):
+		return status.New(codes.Unauthenticated, "token revoked"), true
+	case errors.Is(err, auth.ErrInvalidToken):
+		return status.New(codes.Unauthenticated, "invalid token"), true
+	}
+	return nil, false
+}
+
+func (g *serverAPI) GetUsers(ctx context.Context, req *sso.GetUsersRequest) (*sso.GetUsersResponse, error) {
+	if len(req.Uids) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "uids list is empty")
+	}
+	if len(req.Uids) > maxUsersBatch {
+		return nil, status.Error(codes.InvalidArgument, "too many uids")
+	}
+
+	users, err := g.auth.Users(ctx, req.Uids)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get users")
+	}
+
+	resp := &sso.GetUsersResponse{Users: make([]*sso.UserInfo, 0, len(users))}
+	for _, user := range users {
+		resp.Users = append(resp.Users, userToProto(user))
+	}
+
+	return resp, nil
+}
+
+func (g *serverAPI) GetProfile(ctx context.Context, req *sso.GetProfileRequest) (*sso.GetProfileResponse, error) {
+	if req.Uid <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "uid is required")
+	}
+
+	profile, err := g.auth.Profile(ctx, req.Uid)
+	if err != nil {
+		if errors.Is(err, auth.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to get profile")
+	}
+
+	return &sso.GetProfileResponse{Profile: profileToProto(profile)}, nil
+}
+
+func (g *serverAPI) BatchGetProfiles(ctx context.Context, req *sso.BatchGetProfilesRequest) (*sso.BatchGetProfilesResponse, error) {
+	if len(req.Uids) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "uids list is empty")
+	}
+	if len(req.Uids) > maxUsersBatch {
+		return nil, status.Error(codes.InvalidArgument, "too many uids")
+	}
+
+	profiles, err := g.auth.Profiles(ctx, req.Uids)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get profiles")
+	}
+
+	resp := &sso.BatchGetProfilesResponse{Profiles: make([]*sso.Profile, 0, len(profiles))}
+	for _, profile := range profiles {
+		resp.Profiles = append(resp.Profiles, profileToProto(profile))
+	}
+
+	return resp, nil
+}
+
+func (g *serverAPI) UpdateProfile(ctx context.Context, req *sso.UpdateProfileRequest) (*sso.UpdateProfileResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+
+	profile, err := g.auth.UpdateProfile(ctx, req.Token, models.ProfileUpdate{
+		DisplayName: req.DisplayName,
+		Bio:         req.Bio,
+		AvatarURL:   req.AvatarUrl,
+	})
+	if err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		if st, ok := profileStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to update profile")
+	}
+
+	return &sso.UpdateProfileResponse{Profile: profileToProto(profile)}, nil
+}
+
+func (g *serverAPI) SearchUsers(ctx context.Context, req *sso.SearchUsersRequest) (*sso.SearchUsersResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	query := strings.TrimSpace(req.Query)
+	if n := utf8.RuneCountInString(query); n < minSearchQueryLen || n > maxSearchQueryLen {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"query must be from %d to %d characters", minSearchQueryLen, maxSearchQueryLen)
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		return nil, status.Error(codes.InvalidArgument, "limit is too big")
+	}
+
+	users, next, err := g.auth.SearchUsers(ctx, req.Token, query, req.Cursor, limit)
+	if err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrInvalidCursor) {
+			return nil, status.Error(codes.InvalidArgument, "invalid cursor")
+		}
+		return nil, status.Error(codes.Internal, "failed to search users")
+	}
+
+	resp := &sso.SearchUsersResponse{
+		Users:      make([]*sso.UserInfo, 0, len(users)),
+		NextCursor: next,
+	}
+	for _, user := range users {
+		resp.Users = append(resp.Users, userToProto(user))
+	}
+
+	return resp, nil
+}
+
+func (g *serverAPI) LookupUser(ctx context.Context, req *sso.LookupUserRequest) (*sso.LookupUserResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	if req.Username == "" {
+		return nil, status.Error(codes.InvalidArgument, "username is required")
+	}
+
+	user, err := g.auth.LookupUser(ctx, req.Token, req.Username)
+	if err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to lookup user")
+	}
+
+	return &sso.LookupUserResponse{User: userToProto(user)}, nil
+}
+
+func (g *serverAPI) BlockUser(ctx context.Context, req *sso.BlockUserRequest) (*sso.BlockUserResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	if req.Uid <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "uid is required")
+	}
+
+	if err := g.auth.BlockUser(ctx, req.Token, req.Uid); err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrBlockSelf) {
+			return nil, status.Error(codes.InvalidArgument, "can't block yourself")
+		}
+		if errors.Is(err, auth.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to block user")
+	}
+
+	return &sso.BlockUserResponse{}, nil
+}
+
+func (g *serverAPI) UnblockUser(ctx context.Context, req *sso.UnblockUserRequest) (*sso.UnblockUserResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	if req.Uid <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "uid is required")
+	}
+
+	if err := g.auth.UnblockUser(ctx, req.Token, req.Uid); err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to unblock user")
+	}
+
+	return &sso.UnblockUserResponse{}, nil
+}
+
+func (g *serverAPI) DeleteAccount(ctx context.Context, req *sso.DeleteAccountRequest) (*sso.AccountDeletionResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	if req.Password == "" {
+		return nil, status.Error(codes.InvalidArgument, "password is required")
+	}
+
+	d, err := g.auth.DeleteAccount(ctx, req.Token, req.Password)
+	if err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			return nil, status.Error(codes.PermissionDenied, "invalid password")
+		}
+		if errors.Is(err, auth.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to delete account")
+	}
+
+	return deletionToProto(d), nil
+}
+
+// GetAccountDeletion is not authenticated, user's tokens are revoked by then.
+// Deletion ID is random and only known to the user.
+func (g *serverAPI) GetAccountDeletion(ctx context.Context, req *sso.GetAccountDeletionRequest) (*sso.AccountDeletionResponse, error) {
+	if req.DeletionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "deletion id is required")
+	}
+
+	d, err := g.auth.AccountDeletion(ctx, req.DeletionId)
+	if err != nil {
+		if errors.Is(err, auth.ErrDeletionNotFound) {
+			return nil, status.Error(codes.NotFound, "account deletion not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to get account deletion")
+	}
+
+	return deletionToProto(d), nil
+}
+
+func deletionToProto(d models.AccountDeletion) *sso.AccountDeletionResponse {
+	resp := &sso.AccountDeletionResponse{
+		DeletionId: d.ID,
+		Status:     d.Status,
+		CreatedAt:  d.CreatedAt.Unix(),
+	}
+	if d.CompletedAt != nil {
+		resp.CompletedAt = d.CompletedAt.Unix()
+	}
+	return resp
+}
+
+func (g *serverAPI) CreateAccessToken(ctx context.Context, req *sso.CreateAccessTokenRequest) (*sso.CreateAccessTokenResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	if req.ExpiresIn < 0 {
+		return nil, status.Error(codes.InvalidArgument, "expires_in must not be negative")
 	}
 
-	return &sso.AuthorizeResponse{Uid: uid}, nil
+	token, raw, err := g.auth.CreateAccessToken(ctx, req.Token, req.Name, req.Scopes,
+		time.Duration(req.ExpiresIn)*time.Second)
+	if err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		var tokenErr *auth.AccessTokenError
+		if errors.As(err, &tokenErr) {
+			return nil, badRequestStatus(tokenErr.Error(), tokenErr.Field, tokenErr.Reason).Err()
+		}
+		if errors.Is(err, auth.ErrTooManyTokens) {
+			return nil, status.Error(codes.ResourceExhausted, "too many access tokens")
+		}
+		return nil, status.Error(codes.Internal, "failed to create access token")
+	}
+
+	return &sso.CreateAccessTokenResponse{
+		AccessToken: raw,
+		Info:        accessTokenToProto(token),
+	}, nil
+}
+
+func (g *serverAPI) ListAccessTokens(ctx context.Context, req *sso.ListAccessTokensRequest) (*sso.ListAccessTokensResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+
+	list, err := g.auth.AccessTokens(ctx, req.Token)
+	if err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to list access tokens")
+	}
+
+	resp := &sso.ListAccessTokensResponse{Tokens: make([]*sso.AccessTokenInfo, 0, len(list))}
+	for _, token := range list {
+		resp.Tokens = append(resp.Tokens, accessTokenToProto(token))
+	}
+
+	return resp, nil
+}
+
+func (g *serverAPI) RevokeAccessToken(ctx context.Context, req *sso.RevokeAccessTokenRequest) (*sso.RevokeAccessTokenResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	if req.Id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "bad token id")
+	}
+
+	if err := g.auth.RevokeAccessToken(ctx, req.Token, req.Id); err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrTokenNotFound) {
+			return nil, status.Error(codes.NotFound, "access token not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to revoke access token")
+	}
+
+	return &sso.RevokeAccessTokenResponse{}, nil
+}
+
+func (g *serverAPI) AuthorizeAccessToken(ctx context.Context, req *sso.AuthorizeAccessTokenRequest) (*sso.AuthorizeAccessTokenResponse, error) {
+	if req.AccessToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "access token is empty")
+	}
+
+	token, err := g.auth.AuthorizeAccessToken(ctx, req.AccessToken)
+	if err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to authorize")
+	}
+
+	return &sso.AuthorizeAccessTokenResponse{
+		Uid:           token.UID,
+		Scopes:        token.Scopes,
+		EmailVerified: token.OwnerEmailVerified,
+	}, nil
+}
+
+func accessTokenToProto(t models.AccessToken) *sso.AccessTokenInfo {
+	info := &sso.AccessTokenInfo{
+		Id:        t.ID,
+		Name:      t.Name,
+		Scopes:    t.Scopes,
+		CreatedAt: t.CreatedAt.Unix(),
+	}
+	if t.ExpiresAt != nil {
+		info.ExpiresAt = t.ExpiresAt.Unix()
+	}
+	if t.LastUsedAt != nil {
+		info.LastUsedAt = t.LastUsedAt.Unix()
+	}
+	return info
+}
+
+func (g *serverAPI) ListSessions(ctx context.Context, req *sso.ListSessionsRequest) (*sso.ListSessionsResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+
+	sessions, current, err := g.auth.Sessions(ctx, req.Token)
+	if err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to list sessions")
+	}
+
+	resp := &sso.ListSessionsResponse{Sessions: make([]*sso.Session, 0, len(sessions))}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, &sso.Session{
+			Id:         session.ID,
+			UserAgent:  session.Client.UserAgent,
+			Ip:         session.Client.IP,
+			CreatedAt:  session.CreatedAt.Unix(),
+			LastSeenAt: session.LastSeenAt.Unix(),
+			Current:    session.ID == current,
+		})
+	}
+
+	return resp, nil
+}
+
+func (g *serverAPI) RevokeSession(ctx context.Context, req *sso.RevokeSessionRequest) (*sso.RevokeSessionResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	if req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "session id is required")
+	}
+
+	if err := g.auth.RevokeSession(ctx, req.Token, req.SessionId); err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrSessionNotFound) {
+			return nil, status.Error(codes.NotFound, "session not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to revoke session")
+	}
+
+	return &sso.RevokeSessionResponse{}, nil
+}
+
+func (g *serverAPI) ListUsers(ctx context.Context, req *sso.ListUsersRequest) (*sso.ListUsersResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	limit, err := adminListLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	users, next, err := g.auth.ListUsers(ctx, req.Token, req.Cursor, limit)
+	if err != nil {
+		if st, ok := adminStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to list users")
+	}
+
+	resp := &sso.ListUsersResponse{
+		Users:      make([]*sso.AdminUserInfo, 0, len(users)),
+		NextCursor: next,
+	}
+	for _, user := range users {
+		info := &sso.AdminUserInfo{
+			Uid:         user.UID,
+			Username:    user.Username,
+			DisplayName: user.DisplayName,
+			Role:        user.Role,
+			CreatedAt:   user.CreatedAt.Unix(),
+		}
+		if user.DisabledAt != nil {
+			info.DisabledAt = user.DisabledAt.Unix()
+		}
+		resp.Users = append(resp.Users, info)
+	}
+
+	return resp, nil
+}
+
+func (g *serverAPI) SetUserDisabled(ctx context.Context, req *sso.SetUserDisabledRequest) (*sso.SetUserDisabledResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	if req.Uid <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "uid is required")
+	}
+
+	if err := g.auth.SetUserDisabled(ctx, req.Token, req.Uid, req.Disabled); err != nil {
+		if st, ok := adminStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to update user")
+	}
+
+	return &sso.SetUserDisabledResponse{}, nil
+}
+
+func (g *serverAPI) ForceLogout(ctx context.Context, req *sso.ForceLogoutRequest) (*sso.ForceLogoutResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	if req.Uid <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "uid is required")
+	}
+
+	if err := g.auth.ForceLogout(ctx, req.Token, req.Uid); err != nil {
+		if st, ok := adminStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to logout user")
+	}
+
+	return &sso.ForceLogoutResponse{}, nil
+}
+
+func (g *serverAPI) SetUserRole(ctx context.Context, req *sso.SetUserRoleRequest) (*sso.SetUserRoleResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	if req.Uid <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "uid is required")
+	}
+
+	if err := g.auth.SetUserRole(ctx, req.Token, req.Uid, req.Role); err != nil {
+		if st, ok := adminStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to update user")
+	}
+
+	return &sso.SetUserRoleResponse{}, nil
+}
+
+func (g *serverAPI) AuthorizeAdminAction(ctx context.Context, req *sso.AuthorizeAdminActionRequest) (*sso.AuthorizeAdminActionResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	if err := validateAuditEntry(req.Action, req.Target, req.Details); err != nil {
+		return nil, err
+	}
+
+	if err := g.auth.AuthorizeAdminAction(ctx, req.Token, req.Action, req.Target, req.Details); err != nil {
+		if st, ok := adminStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to authorize action")
+	}
+
+	return &sso.AuthorizeAdminActionResponse{}, nil
+}
+
+func (g *serverAPI) RecordAdminAction(ctx context.Context, req *sso.RecordAdminActionRequest) (*sso.RecordAdminActionResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	if err := validateAuditEntry(req.Action, req.Target, req.Details); err != nil {
+		return nil, err
+	}
+	if req.Result == "" || len(req.Result) > maxAuditDetailsLen {
+		return nil, status.Errorf(codes.InvalidArgument, "result must be from 1 to %d bytes", maxAuditDetailsLen)
+	}
+
+	if err := g.auth.RecordAdminAction(ctx, req.Token, req.Action, req.Target, req.Details, req.Result); err != nil {
+		if st, ok := adminStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to record action")
+	}
+
+	return &sso.RecordAdminActionResponse{}, nil
+}
+
+// validateAuditEntry checks fields of actions performed by other services.
+func validateAuditEntry(action, target, details string) error {
+	if action == "" || len(action) > maxAuditActionLen {
+		return status.Errorf(codes.InvalidArgument, "action must be from 1 to %d bytes", maxAuditActionLen)
+	}
+	if target == "" || len(target) > maxAuditTargetLen {
+		return status.Errorf(codes.InvalidArgument, "target must be from 1 to %d bytes", maxAuditTargetLen)
+	}
+	if len(details) > maxAuditDetailsLen {
+		return status.Error(codes.InvalidArgument, "details are too long")
+	}
+	return nil
+}
+
+func (g *serverAPI) ListAuditLog(ctx context.Context, req *sso.ListAuditLogRequest) (*sso.ListAuditLogResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	limit, err := adminListLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, next, err := g.auth.AuditLog(ctx, req.Token, req.Cursor, limit)
+	if err != nil {
+		if st, ok := adminStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to list audit log")
+	}
+
+	resp := &sso.ListAuditLogResponse{
+		Entries:    make([]*sso.AuditEntry, 0, len(entries)),
+		NextCursor: next,
+	}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, &sso.AuditEntry{
+			Id:        entry.ID,
+			ActorUid:  entry.ActorUID,
+			Action:    entry.Action,
+			Target:    entry.Target,
+			Details:   entry.Details,
+			Result:    entry.Result,
+			CreatedAt: entry.CreatedAt.Unix(),
+		})
+	}
+
+	return resp, nil
+}
+
+func (g *serverAPI) CreateOIDCClient(ctx context.Context, req *sso.CreateOIDCClientRequest) (*sso.CreateOIDCClientResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+
+	client, secret, err := g.auth.CreateOIDCClient(ctx, req.Token, req.Name, req.RedirectUris, req.Public)
+	if err != nil {
+		if st, ok := adminStatus(err); ok {
+			return nil, st.Err()
+		}
+		var clientErr *auth.OIDCClientError
+		if errors.As(err, &clientErr) {
+			return nil, badRequestStatus(clientErr.Error(), clientErr.Field, clientErr.Reason).Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to create oidc client")
+	}
+
+	return &sso.CreateOIDCClientResponse{
+		Client:       oidcClientToProto(client),
+		ClientSecret: secret,
+	}, nil
+}
+
+func (g *serverAPI) ListOIDCClients(ctx context.Context, req *sso.ListOIDCClientsRequest) (*sso.ListOIDCClientsResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+
+	clients, err := g.auth.OIDCClients(ctx, req.Token)
+	if err != nil {
+		if st, ok := adminStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to list oidc clients")
+	}
+
+	resp := &sso.ListOIDCClientsResponse{Clients: make([]*sso.OIDCClient, 0, len(clients))}
+	for _, client := range clients {
+		resp.Clients = append(resp.Clients, oidcClientToProto(client))
+	}
+
+	return resp, nil
+}
+
+func (g *serverAPI) DeleteOIDCClient(ctx context.Context, req *sso.DeleteOIDCClientRequest) (*sso.DeleteOIDCClientResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	if req.ClientId == "" {
+		return nil, status.Error(codes.InvalidArgument, "client id is required")
+	}
+
+	if err := g.auth.DeleteOIDCClient(ctx, req.Token, req.ClientId); err != nil {
+		if st, ok := adminStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrClientNotFound) {
+			return nil, status.Error(codes.NotFound, "oidc client not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to delete oidc client")
+	}
+
+	return &sso.DeleteOIDCClientResponse{}, nil
+}
+
+func oidcClientToProto(client models.OIDCClient) *sso.OIDCClient {
+	return &sso.OIDCClient{
+		ClientId:     client.ID,
+		Name:         client.Name,
+		RedirectUris: client.RedirectURIs,
+		Public:       client.Public(),
+		CreatedAt:    client.CreatedAt.Unix(),
+	}
+}
+
+func (g *serverAPI) ListConnectors(ctx context.Context, req *sso.ListConnectorsRequest) (*sso.ListConnectorsResponse, error) {
+	connectors := g.auth.Connectors()
+
+	resp := &sso.ListConnectorsResponse{Connectors: make([]*sso.Connector, 0, len(connectors))}
+	for _, c := range connectors {
+		resp.Connectors = append(resp.Connectors, &sso.Connector{Id: c.ID, Name: c.Name})
+	}
+
+	return resp, nil
+}
+
+func (g *serverAPI) StartExternalLogin(ctx context.Context, req *sso.StartExternalLoginRequest) (*sso.StartExternalLoginResponse, error) {
+	if req.ConnectorId == "" {
+		return nil, status.Error(codes.InvalidArgument, "connector id is required")
+	}
+
+	authURL, err := g.auth.StartExternalLogin(ctx, req.ConnectorId)
+	if err != nil {
+		if st, ok := externalStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to start login")
+	}
+
+	return &sso.StartExternalLoginResponse{AuthUrl: authURL}, nil
+}
+
+func (g *serverAPI) ExternalLogin(ctx context.Context, req *sso.ExternalLoginRequest) (*sso.LoginResponse, error) {
+	if req.ConnectorId == "" {
+		return nil, status.Error(codes.InvalidArgument, "connector id is required")
+	}
+	if req.State == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "state and code are required")
+	}
+
+	res, err := g.auth.ExternalLogin(ctx, req.ConnectorId, req.State, req.Code, client(ctx))
+	if err != nil {
+		if st, ok := externalStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrIdentityNotLinked) {
+			return nil, status.Error(codes.NotFound, "identity is not linked to any account")
+		}
+		if errors.Is(err, auth.ErrUserDisabled) {
+			return nil, status.Error(codes.PermissionDenied, "account disabled")
+		}
+		return nil, status.Error(codes.Internal, "failed to login")
+	}
+
+	if res.ChallengeToken != "" {
+		return &sso.LoginResponse{TwoFactorRequired: true, ChallengeToken: res.ChallengeToken}, nil
+	}
+	return &sso.LoginResponse{Token: res.Tokens.AccessToken, RefreshToken: res.Tokens.RefreshToken}, nil
+}
+
+func (g *serverAPI) StartLinkIdentity(ctx context.Context, req *sso.StartLinkIdentityRequest) (*sso.StartExternalLoginResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	if req.ConnectorId == "" {
+		return nil, status.Error(codes.InvalidArgument, "connector id is required")
+	}
+
+	authURL, err := g.auth.StartLinkIdentity(ctx, req.Token, req.ConnectorId)
+	if err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		if st, ok := externalStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to start linking")
+	}
+
+	return &sso.StartExternalLoginResponse{AuthUrl: authURL}, nil
+}
+
+func (g *serverAPI) LinkIdentity(ctx context.Context, req *sso.LinkIdentityRequest) (*sso.LinkIdentityResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	if req.ConnectorId == "" {
+		return nil, status.Error(codes.InvalidArgument, "connector id is required")
+	}
+	if req.State == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "state and code are required")
+	}
+
+	identity, err := g.auth.LinkIdentity(ctx, req.Token, req.ConnectorId, req.State, req.Code)
+	if err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		if st, ok := externalStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrIdentityExist) {
+			return nil, status.Error(codes.AlreadyExists, "identity already linked")
+		}
+		return nil, status.Error(codes.Internal, "failed to link identity")
+	}
+
+	return &sso.LinkIdentityResponse{Identity: identityToProto(identity)}, nil
+}
+
+func (g *serverAPI) UnlinkIdentity(ctx context.Context, req *sso.UnlinkIdentityRequest) (*sso.UnlinkIdentityResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+	if req.ConnectorId == "" {
+		return nil, status.Error(codes.InvalidArgument, "connector id is required")
+	}
+
+	if err := g.auth.UnlinkIdentity(ctx, req.Token, req.ConnectorId); err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		if errors.Is(err, auth.ErrIdentityNotFound) {
+			return nil, status.Error(codes.NotFound, "identity not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to unlink identity")
+	}
+
+	return &sso.UnlinkIdentityResponse{}, nil
+}
+
+func (g *serverAPI) ListIdentities(ctx context.Context, req *sso.ListIdentitiesRequest) (*sso.ListIdentitiesResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+
+	identities, err := g.auth.Identities(ctx, req.Token)
+	if err != nil {
+		if st, ok := tokenStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to list identities")
+	}
+
+	resp := &sso.ListIdentitiesResponse{Identities: make([]*sso.Identity, 0, len(identities))}
+	for _, identity := range identities {
+		resp.Identities = append(resp.Identities, identityToProto(identity))
+	}
+
+	return resp, nil
+}
+
+func (g *serverAPI) SetEmail(ctx context.Context, req *sso.SetEmailRequest) (*sso.SetEmailResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+
+	if err := g.auth.SetEmail(ctx, req.Token, req.Email); err != nil {
+		if st, ok := emailStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to set email")
+	}
+
+	return &sso.SetEmailResponse{}, nil
+}
+
+func (g *serverAPI) SendVerification(ctx context.Context, req *sso.SendVerificationRequest) (*sso.SendVerificationResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+
+	if err := g.auth.SendVerification(ctx, req.Token); err != nil {
+		if st, ok := emailStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to send verification")
+	}
+
+	return &sso.SendVerificationResponse{}, nil
+}
+
+func (g *serverAPI) VerifyEmail(ctx context.Context, req *sso.VerifyEmailRequest) (*sso.VerifyEmailResponse, error) {
+	if req.VerificationToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "verification token is empty")
+	}
+
+	if err := g.auth.VerifyEmail(ctx, req.VerificationToken); err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return nil, status.Error(codes.InvalidArgument, "invalid or expired verification token")
+		}
+		if st, ok := emailStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to verify email")
+	}
+
+	return &sso.VerifyEmailResponse{}, nil
+}
+
+func (g *serverAPI) GetEmail(ctx context.Context, req *sso.GetEmailRequest) (*sso.GetEmailResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "jwt token is empty")
+	}
+
+	email, verified, err := g.auth.Email(ctx, req.Token)
+	if err != nil {
+		if st, ok := emailStatus(err); ok {
+			return nil, st.Err()
+		}
+		return nil, status.Error(codes.Internal, "failed to get email")
+	}
+
+	return &sso.GetEmailResponse{Email: email, Verified: verified}, nil
+}
+
+// emailStatus maps errors shared by email RPCs.
+func emailStatus(err error) (*status.Status, bool) {
+	if st, ok := tokenStatus(err); ok {
+		return st, true
+	}
+
+	switch {
+	case errors.Is(err, auth.ErrInvalidEmail):
+		return status.New(codes.InvalidArgument, "invalid email"), true
+	case errors.Is(err, auth.ErrNoEmail):
+		return status.New(codes.FailedPrecondition, "email is not set"), true
+	case errors.Is(err, auth.ErrEmailVerified):
+		return status.New(codes.FailedPrecondition, "email already verified"), true
+	case errors.Is(err, auth.ErrEmailTaken):
+		return status.New(codes.AlreadyExists, "email is used by another account"), true
+	case errors.Is(err, auth.ErrVerificationSentRecently):
+		return status.New(codes.ResourceExhausted, "verification was sent recently"), true
+	case errors.Is(err, auth.ErrUserNotFound):
+		return status.New(codes.NotFound, "user not found"), true
+	}
+	return nil, false
+}
+
+func identityToProto(identity models.Identity) *sso.Identity {
+	var lastLoginAt int64
+	if identity.LastLoginAt != nil {
+		lastLoginAt = identity.LastLoginAt.Unix()
+	}
+
+	return &sso.Identity{
+		ConnectorId: identity.ConnectorID,
+		Subject:     identity.Subject,
+		Username:    identity.Username,
+		Email:       identity.Email,
+		CreatedAt:   identity.CreatedAt.Unix(),
+		LastLoginAt: lastLoginAt,
+	}
+}
+
+func externalStatus(err error) (*status.Status, bool) {
+	switch {
+	case errors.Is(err, auth.ErrConnectorNotFound):
+		return status.New(codes.NotFound, "connector not found"), true
+	case errors.Is(err, auth.ErrInvalidState):
+		return status.New(codes.InvalidArgument, "invalid or expired state"), true
+	case errors.Is(err, auth.ErrExternalAuth):
+		return status.New(codes.Unauthenticated, "external authentication failed"), true
+	}
+	return nil, false
+}
+
+func adminListLimit(limit int64) (int64, error) {
+	if limit <= 0 {
+		return defaultAdminListLimit, nil
+	}
+	if limit > maxAdminListLimit {
+		return 0, status.Error(codes.InvalidArgument, "limit is too big")
+	}
+	return limit, nil
+}
+
+// adminStatus maps errors shared by admin RPCs.
+func adminStatus(err error) (*status.Status, bool) {
+	if st, ok := tokenStatus(err); ok {
+		return st, true
+	}
+
+	switch {
+	case errors.Is(err, auth.ErrForbidden):
+		return status.New(codes.PermissionDenied, "admin role required"), true
+	case errors.Is(err, auth.ErrSelfAction):
+		return status.New(codes.FailedPrecondition, "can't apply to own account"), true
+	case errors.Is(err, auth.ErrInvalidRole):
+		return status.New(codes.InvalidArgument, "invalid role"), true
+	case errors.Is(err, auth.ErrUserNotFound):
+		return status.New(codes.NotFound, "user not found"), true
+	case errors.Is(err, auth.ErrInvalidCursor):
+		return status.New(codes.InvalidArgument, "invalid cursor"), true
+	}
+	return nil, false
+}
+
+func userToProto(user models.User) *sso.UserInfo {
+	return &sso.UserInfo{
+		Uid:         user.UID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+	}
+}
+
+func profileToProto(profile models.Profile) *sso.Profile {
+	var updatedAt int64
+	if !profile.UpdatedAt.IsZero() && profile.UpdatedAt.Unix() > 0 {
+		updatedAt = profile.UpdatedAt.Unix()
+	}
+
+	return &sso.Profile{
+		Uid:         profile.UID,
+		Username:    profile.Username,
+		DisplayName: profile.DisplayName,
+		Bio:         profile.Bio,
+		AvatarUrl:   profile.AvatarURL,
+		UpdatedAt:   updatedAt,
+	}
+}
+
+// lockedStatus reports login lockout with RetryInfo.
+func lockedStatus(err error) (*status.Status, bool) {
+	var locked *lockout.LockedError
+	if !errors.As(err, &locked) {
+		return nil, false
+	}
+
+	st, detailsErr := status.New(codes.ResourceExhausted, "too many failed login attempts").
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(locked.RetryAfter)})
+	if detailsErr != nil {
+		return status.New(codes.ResourceExhausted, "too many failed login attempts"), true
+	}
+	return st, true
+}
+
+// ClientIPKey is metadata key the gateway puts end-user address in.
+const ClientIPKey = "x-client-ip"
+
+// UserAgentKey is metadata key the gateway puts end-user User-Agent in.
+const UserAgentKey = "x-client-user-agent"
+
+// maxUserAgentLen bounds stored User-Agent, it's set by the client
+const maxUserAgentLen = 512
+
+func client(ctx context.Context) models.Client {
+	c := models.Client{IP: clientIP(ctx)}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if uas := md.Get(UserAgentKey); len(uas) > 0 {
+			c.UserAgent = truncate(strings.ToValidUTF8(uas[0], ""), maxUserAgentLen)
+		}
+	}
+
+	return c
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// clientIP returns address of the end user: the one forwarded by the
+// gateway or, for direct calls, the peer address. The metadata is trusted
+// as is, sso_service must be reachable only by internal services.
+func clientIP(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ips := md.Get(ClientIPKey); len(ips) > 0 && ips[0] != "" {
+			return ips[0]
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return host
+		}
+		return p.Addr.String()
+	}
+
+	return ""
+}
+
+// policyStatus reports password policy violations as BadRequest details,
+// one per broken rule.
+func policyStatus(err error) (*status.Status, bool) {
+	var policyErr *password.PolicyError
+	if !errors.As(err, &policyErr) {
+		return nil, false
+	}
+
+	br := &errdetails.BadRequest{}
+	for _, v := range policyErr.Violations {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       "password." + v.Rule,
+			Description: v.Description,
+		})
+	}
+
+	st, detailsErr := status.New(codes.InvalidArgument, "password does not satisfy policy").WithDetails(br)
+	if detailsErr != nil {
+		return status.New(codes.InvalidArgument, policyErr.Error()), true
+	}
+	return st, true
+}
+
+func profileStatus(err error) (*status.Status, bool) {
+	var profileErr *auth.ProfileError
+	if !errors.As(err, &profileErr) {
+		return nil, false
+	}
+
+	return badRequestStatus(profileErr.Error(), profileErr.Field, profileErr.Reason), true
+}
+
+// badRequestStatus returns InvalidArgument status with field violation details.
+func badRequestStatus(msg, field, reason string) *status.Status {
+	br := &errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+			Field:       field,
+			Description: reason,
+		}},
+	}
+
+	st, err := status.New(codes.InvalidArgument, msg).WithDetails(br)
+	if err != nil {
+		return status.New(codes.InvalidArgument, msg)
+	}
+	return st
 }
 
 func validateRequest(username, password string) error {
@@ -0,0 +1,495 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/liriquew/social-todo/sso_service/internal/lib/config"
+	"github.com/liriquew/social-todo/sso_service/internal/lockout"
+	"github.com/liriquew/social-todo/sso_service/internal/models"
+	"github.com/liriquew/social-todo/sso_service/internal/sevices/auth"
+
+	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
+)
+
+const (
+	DiscoveryPath = "/.well-known/openid-configuration"
+	AuthorizePath = "/oauth2/authorize"
+	TokenPath     = "/oauth2/token"
+	UserInfoPath  = "/oauth2/userinfo"
+)
+
+// maxUserAgentLen bounds stored User-Agent, it's set by the client
+const maxUserAgentLen = 512
+
+type Provider interface {
+	CheckAuthRequest(context.Context, models.OIDCAuthRequest) error
+	OIDCLogin(context.Context, models.OIDCAuthRequest, string, string, models.Client) (models.OIDCLoginResult, error)
+	OIDCCompleteLogin(context.Context, models.OIDCAuthRequest, string, string, models.Client) (string, error)
+	ExchangeOIDCCode(context.Context, models.OIDCTokenRequest) (models.OIDCTokens, error)
+	UserInfo(context.Context, string) (models.Profile, error)
+}
+
+// Handler serves OpenID Connect provider endpoints: discovery,
+// authorization with login page, token and userinfo.
+type Handler struct {
+	log       *slog.Logger
+	provider  Provider
+	discovery []byte
+	mux       *http.ServeMux
+}
+
+// New creates provider handler. jwksPath is path of JWKS document on the
+// same server, signingAlg is algorithm ID tokens are signed with.
+func New(log *slog.Logger, provider Provider, cfg config.OIDCConfig, jwksPath, signingAlg string) *Handler {
+	issuer := strings.TrimSuffix(cfg.Issuer, "/")
+
+	discovery, err := json.Marshal(map[string]any{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + AuthorizePath,
+		"token_endpoint":                        issuer + TokenPath,
+		"userinfo_endpoint":                     issuer + UserInfoPath,
+		"jwks_uri":                              issuer + jwksPath,
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{"authorization_code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{signingAlg},
+		"scopes_supported":                      auth.OIDCScopes,
+		"code_challenge_methods_supported":      []string{"S256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"claims_supported": []string{
+			"iss", "sub", "aud", "exp", "iat", "auth_time", "nonce", "azp",
+			"preferred_username", "name", "picture",
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	h := &Handler{
+		log:       log,
+		provider:  provider,
+		discovery: discovery,
+		mux:       http.NewServeMux(),
+	}
+
+	h.mux.HandleFunc("GET "+DiscoveryPath, h.serveDiscovery)
+	h.mux.HandleFunc("GET "+AuthorizePath, h.authorize)
+	h.mux.HandleFunc("POST "+AuthorizePath, h.authorize)
+	h.mux.HandleFunc("POST "+TokenPath, h.token)
+	h.mux.HandleFunc("GET "+UserInfoPath, h.userInfo)
+	h.mux.HandleFunc("POST "+UserInfoPath, h.userInfo)
+
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) serveDiscovery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Write(h.discovery)
+}
+
+// authorize shows login page (GET) and handles its submissions (POST).
+// Errors are sent to the client's redirect URI only after the client
+// and the URI are checked, otherwise they are shown to the user.
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request) {
+	const op = "oidc.authorize"
+
+	log := h.log.With(slog.String("op", op))
+
+	if err := r.ParseForm(); err != nil {
+		h.errorPage(w, http.StatusBadRequest, "malformed request")
+		return
+	}
+
+	req := models.OIDCAuthRequest{
+		ClientID:      r.Form.Get("client_id"),
+		RedirectURI:   r.Form.Get("redirect_uri"),
+		State:         r.Form.Get("state"),
+		Nonce:         r.Form.Get("nonce"),
+		CodeChallenge: r.Form.Get("code_challenge"),
+	}
+	if req.ClientID == "" || req.RedirectURI == "" {
+		h.errorPage(w, http.StatusBadRequest, "client_id and redirect_uri are required")
+		return
+	}
+
+	if err := h.provider.CheckAuthRequest(r.Context(), req); err != nil {
+		if errors.Is(err, auth.ErrClientNotFound) {
+			h.errorPage(w, http.StatusBadRequest, "unknown client")
+			return
+		}
+		if errors.Is(err, auth.ErrInvalidRedirectURI) {
+			h.errorPage(w, http.StatusBadRequest, "redirect_uri is not registered for the client")
+			return
+		}
+		log.Error("failed to check request", sl.Err(err))
+		h.errorPage(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	if r.Form.Get("response_type") != "code" {
+		redirectError(w, r, req, "unsupported_response_type", "only code flow is supported")
+		return
+	}
+
+	var ok bool
+	req.Scope, ok = normalizeScope(r.Form.Get("scope"))
+	if !ok {
+		redirectError(w, r, req, "invalid_scope", "openid scope is required")
+		return
+	}
+
+	if r.Form.Get("code_challenge_method") != "S256" || !auth.ValidCodeChallenge(req.CodeChallenge) {
+		redirectError(w, r, req, "invalid_request", "PKCE with S256 code challenge is required")
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		h.loginPage(w, http.StatusOK, req, loginPage{})
+		return
+	}
+
+	h.login(w, r, req)
+}
+
+func (h *Handler) login(w http.ResponseWriter, r *http.Request, req models.OIDCAuthRequest) {
+	const op = "oidc.login"
+
+	log := h.log.With(slog.String("op", op))
+
+	client := clientFromRequest(r)
+
+	var (
+		code string
+		page loginPage
+		err  error
+	)
+	if challenge := r.PostForm.Get("challenge_token"); challenge != "" {
+		code, err = h.provider.OIDCCompleteLogin(r.Context(), req, challenge, r.PostForm.Get("code"), client)
+		page.ChallengeToken = challenge
+	} else {
+		var res models.OIDCLoginResult
+		res, err = h.provider.OIDCLogin(r.Context(), req,
+			r.PostForm.Get("username"), r.PostForm.Get("password"), client)
+		if err == nil && res.ChallengeToken != "" {
+			h.loginPage(w, http.StatusOK, req, loginPage{ChallengeToken: res.ChallengeToken})
+			return
+		}
+		code = res.Code
+	}
+
+	if err != nil {
+		status := http.StatusUnauthorized
+		switch {
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			page.Error = "invalid username or password"
+		case errors.Is(err, auth.ErrInvalidCode):
+			page.Error = "invalid code"
+		case errors.Is(err, auth.ErrTokenExpired), errors.Is(err, auth.ErrInvalidToken),
+			errors.Is(err, auth.ErrTokenRevoked):
+			page = loginPage{Error: "login expired, sign in again"}
+		case errors.Is(err, auth.ErrUserDisabled):
+			status = http.StatusForbidden
+			page = loginPage{Error: "account disabled"}
+		case errors.Is(err, lockout.ErrLocked):
+			status = http.StatusTooManyRequests
+			page.Error = "too many failed login attempts, try again later"
+			var locked *lockout.LockedError
+			if errors.As(err, &locked) {
+				w.Header().Set("Retry-After", strconv.Itoa(int(locked.RetryAfter.Seconds())+1))
+			}
+		default:
+			log.Error("failed to login", sl.Err(err))
+			h.errorPage(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		h.loginPage(w, status, req, page)
+		return
+	}
+
+	params := url.Values{"code": {code}}
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	redirect(w, r, req.RedirectURI, params)
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	IDToken     string `json:"id_token"`
+	Scope       string `json:"scope"`
+}
+
+func (h *Handler) token(w http.ResponseWriter, r *http.Request) {
+	const op = "oidc.token"
+
+	log := h.log.With(slog.String("op", op))
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, http.StatusBadRequest, "invalid_request", "malformed request")
+		return
+	}
+	if grant := r.PostForm.Get("grant_type"); grant != "authorization_code" {
+		tokenError(w, http.StatusBadRequest, "unsupported_grant_type", "only authorization_code grant is supported")
+		return
+	}
+
+	req := models.OIDCTokenRequest{
+		ClientID:     r.PostForm.Get("client_id"),
+		ClientSecret: r.PostForm.Get("client_secret"),
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+	}
+
+	// client_secret_basic, credentials are form encoded before base64
+	id, secret, basic := r.BasicAuth()
+	if basic {
+		var err1, err2 error
+		req.ClientID, err1 = url.QueryUnescape(id)
+		req.ClientSecret, err2 = url.QueryUnescape(secret)
+		if err1 != nil || err2 != nil {
+			tokenError(w, http.StatusBadRequest, "invalid_request", "malformed client credentials")
+			return
+		}
+	}
+
+	if req.ClientID == "" || req.Code == "" || req.RedirectURI == "" || req.CodeVerifier == "" {
+		tokenError(w, http.StatusBadRequest, "invalid_request",
+			"client_id, code, redirect_uri and code_verifier are required")
+		return
+	}
+
+	tokens, err := h.provider.ExchangeOIDCCode(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidClient) {
+			if basic {
+				w.Header().Set("WWW-Authenticate", `Basic realm="oidc"`)
+			}
+			tokenError(w, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+			return
+		}
+		if errors.Is(err, auth.ErrInvalidGrant) {
+			tokenError(w, http.StatusBadRequest, "invalid_grant", "invalid, used or expired code")
+			return
+		}
+		log.Error("failed to exchange code", sl.Err(err))
+		tokenError(w, http.StatusInternalServerError, "server_error", "internal error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokenResponse{
+		AccessToken: tokens.AccessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(tokens.ExpiresIn.Seconds()),
+		IDToken:     tokens.IDToken,
+		Scope:       tokens.Scope,
+	})
+}
+
+type userInfoResponse struct {
+	Subject           string `json:"sub"`
+	PreferredUsername string `json:"preferred_username"`
+	Name              string `json:"name,omitempty"`
+	Picture           string `json:"picture,omitempty"`
+	UpdatedAt         int64  `json:"updated_at,omitempty"`
+}
+
+func (h *Handler) userInfo(w http.ResponseWriter, r *http.Request) {
+	const op = "oidc.userInfo"
+
+	log := h.log.With(slog.String("op", op))
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		w.Header().Set("WWW-Authenticate", `Bearer`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	profile, err := h.provider.UserInfo(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) || errors.Is(err, auth.ErrTokenExpired) ||
+			errors.Is(err, auth.ErrTokenRevoked) || errors.Is(err, auth.ErrUserNotFound) {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		log.Error("failed to get user info", sl.Err(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	resp := userInfoResponse{
+		Subject:           strconv.FormatInt(profile.UID, 10),
+		PreferredUsername: profile.Username,
+		Name:              profile.DisplayName,
+		Picture:           profile.AvatarURL,
+	}
+	if !profile.UpdatedAt.IsZero() && profile.UpdatedAt.Unix() > 0 {
+		resp.UpdatedAt = profile.UpdatedAt.Unix()
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// normalizeScope drops unsupported scopes, openid is required.
+func normalizeScope(scope string) (string, bool) {
+	var scopes []string
+	for _, s := range strings.Fields(scope) {
+		if slices.Contains(auth.OIDCScopes, s) && !slices.Contains(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+
+	return strings.Join(scopes, " "), slices.Contains(scopes, auth.ScopeOpenID)
+}
+
+func redirectError(w http.ResponseWriter, r *http.Request, req models.OIDCAuthRequest, code, description string) {
+	params := url.Values{
+		"error":             {code},
+		"error_description": {description},
+	}
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	redirect(w, r, req.RedirectURI, params)
+}
+
+// redirect sends user back to the client, redirectURI is already checked
+// to be registered.
+func redirect(w http.ResponseWriter, r *http.Request, redirectURI string, params url.Values) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "bad redirect uri", http.StatusInternalServerError)
+		return
+	}
+
+	q := u.Query()
+	for k, v := range params {
+		q[k] = v
+	}
+	u.RawQuery = q.Encode()
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, u.String(), http.StatusSeeOther)
+}
+
+func tokenError(w http.ResponseWriter, status int, code, description string) {
+	writeJSON(w, status, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func clientFromRequest(r *http.Request) models.Client {
+	client := models.Client{UserAgent: strings.ToValidUTF8(r.UserAgent(), "")}
+	if utf8.RuneCountInString(client.UserAgent) > maxUserAgentLen {
+		client.UserAgent = string([]rune(client.UserAgent)[:maxUserAgentLen])
+	}
+
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		client.IP = host
+	} else {
+		client.IP = r.RemoteAddr
+	}
+
+	return client
+}
+
+type loginPage struct {
+	Request        models.OIDCAuthRequest
+	ChallengeToken string
+	Error          string
+}
+
+func (h *Handler) loginPage(w http.ResponseWriter, status int, req models.OIDCAuthRequest, page loginPage) {
+	page.Request = req
+
+	setPageHeaders(w)
+	w.WriteHeader(status)
+	if err := loginTemplate.Execute(w, page); err != nil {
+		h.log.Error("failed to render login page", sl.Err(err))
+	}
+}
+
+func (h *Handler) errorPage(w http.ResponseWriter, status int, msg string) {
+	setPageHeaders(w)
+	w.WriteHeader(status)
+	if err := errorTemplate.Execute(w, msg); err != nil {
+		h.log.Error("failed to render error page", sl.Err(err))
+	}
+}
+
+func setPageHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors 'none'")
+}
+
+var loginTemplate = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Sign in to Social Todo</title></head>
+<body style="font-family: sans-serif; max-width: 320px; margin: 64px auto">
+<h2>Sign in to Social Todo</h2>
+{{if .Error}}<p style="color: #b00020">{{.Error}}</p>{{end}}
+<form method="post" action="/oauth2/authorize">
+<input type="hidden" name="response_type" value="code">
+<input type="hidden" name="client_id" value="{{.Request.ClientID}}">
+<input type="hidden" name="redirect_uri" value="{{.Request.RedirectURI}}">
+<input type="hidden" name="scope" value="{{.Request.Scope}}">
+<input type="hidden" name="state" value="{{.Request.State}}">
+<input type="hidden" name="nonce" value="{{.Request.Nonce}}">
+<input type="hidden" name="code_challenge" value="{{.Request.CodeChallenge}}">
+<input type="hidden" name="code_challenge_method" value="S256">
+{{if .ChallengeToken}}
+<input type="hidden" name="challenge_token" value="{{.ChallengeToken}}">
+<p><label>Authentication code<br><input name="code" autocomplete="one-time-code" autofocus required></label></p>
+{{else}}
+<p><label>Username<br><input name="username" autocomplete="username" autofocus required></label></p>
+<p><label>Password<br><input name="password" type="password" autocomplete="current-password" required></label></p>
+{{end}}
+<p><button type="submit">Sign in</button></p>
+</form>
+</body>
+</html>
+`))
+
+var errorTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Sign in error</title></head>
+<body style="font-family: sans-serif; max-width: 320px; margin: 64px auto">
+<h2>Can't sign in</h2>
+<p>{{.}}</p>
+</body>
+</html>
+`))
@@ -2,14 +2,160 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
 type Config struct {
-	Port      int            `yaml:"port" env-default:"4041"`
-	JWTSecret string         `yaml:"jwt_secret" env-required:"true"`
-	Postgres  PostgresConfig `yaml:"postgres" env-required:"true"`
+	Port               int                     `yaml:"port" env-default:"4041"`
+	JWKSPort           int                     `yaml:"jwks_port" env-default:"4044"`
+	Keys               KeysConfig              `yaml:"keys" env-required:"true"`
+	TokenTTL           time.Duration           `yaml:"timeout" env-default:"1h"`
+	RefreshTTL         time.Duration           `yaml:"refresh_timeout" env-default:"720h"`
+	RevocationCacheTTL time.Duration           `yaml:"revocation_cache_ttl" env-default:"1m"`
+	ResetTTL           time.Duration           `yaml:"reset_timeout" env-default:"1h"`
+	Issuer             string                  `yaml:"issuer" env-default:"social-todo-sso"`
+	Mailer             MailerConfig            `yaml:"mailer"`
+	EmailVerification  EmailVerificationConfig `yaml:"email_verification"`
+	BcryptCost         int                     `yaml:"bcrypt_cost" env-default:"12"`
+	PasswordPolicy     PasswordPolicyConfig    `yaml:"password_policy"`
+	Lockout            LockoutConfig           `yaml:"lockout"`
+	TwoFactor          TwoFactorConfig         `yaml:"two_factor"`
+	AccountDeletion    AccountDeletionConfig   `yaml:"account_deletion"`
+	OIDC               OIDCConfig              `yaml:"oidc"`
+	ExternalLogin      ExternalLoginConfig     `yaml:"external_login"`
+	Admins             []string                `yaml:"admins"`
+	Postgres           PostgresConfig          `yaml:"postgres" env-required:"true"`
+}
+
+// PasswordPolicyConfig configures rules new passwords must satisfy.
+// Upper, lower and digit rules are on unless set to false explicitly.
+// DenylistPath is a file with one password per line, relative path is
+// resolved against config file directory.
+type PasswordPolicyConfig struct {
+	MinLength      int    `yaml:"min_length" env-default:"8"`
+	RequireUpper   *bool  `yaml:"require_upper"`
+	RequireLower   *bool  `yaml:"require_lower"`
+	RequireDigit   *bool  `yaml:"require_digit"`
+	RequireSpecial bool   `yaml:"require_special" env-default:"false"`
+	DenylistPath   string `yaml:"denylist_path"`
+}
+
+// MailerConfig selects how mail is delivered: "smtp" sends it with SMTP,
+// "file" appends messages to Path (relative to config file directory),
+// "memory" keeps them in memory.
+type MailerConfig struct {
+	Type string     `yaml:"type" env-default:"file"`
+	Path string     `yaml:"path" env-default:"mail/outbox.jsonl"`
+	SMTP SMTPConfig `yaml:"smtp"`
+}
+
+// SMTPConfig is a relay mail is sent through. STARTTLS is used if
+// the server supports it, no authentication is done without Username.
+type SMTPConfig struct {
+	Host     string        `yaml:"host"`
+	Port     int           `yaml:"port" env-default:"587"`
+	Username string        `yaml:"username"`
+	Password string        `yaml:"password"`
+	From     string        `yaml:"from"`
+	Timeout  time.Duration `yaml:"timeout" env-default:"10s"`
+}
+
+// EmailVerificationConfig configures verification of users' emails.
+// Verification is sent again no sooner than ResendInterval.
+type EmailVerificationConfig struct {
+	TokenTTL       time.Duration `yaml:"token_timeout" env-default:"24h"`
+	ResendInterval time.Duration `yaml:"resend_interval" env-default:"1m"`
+}
+
+// KeysConfig lists token signing keys. All of them are used to verify
+// tokens and published in JWKS, new tokens are signed with SigningKID.
+type KeysConfig struct {
+	SigningKID string          `yaml:"signing_kid" env-required:"true"`
+	Files      []KeyFileConfig `yaml:"files" env-required:"true"`
+}
+
+// KeyFileConfig is a PEM file with private key.
+// Relative path is resolved against config file directory.
+type KeyFileConfig struct {
+	KID  string `yaml:"kid" env-required:"true"`
+	Path string `yaml:"path" env-required:"true"`
+}
+
+// LockoutConfig configures login brute-force protection. After
+// FreeAttempts failures per username (IPFreeAttempts per client IP)
+// each failure locks login for BaseDelay doubled every time,
+// up to MaxLockout. Counters are reset after Window without failures,
+// at most MaxEntries of them are kept in memory.
+type LockoutConfig struct {
+	FreeAttempts   int           `yaml:"free_attempts" env-default:"5"`
+	IPFreeAttempts int           `yaml:"ip_free_attempts" env-default:"20"`
+	BaseDelay      time.Duration `yaml:"base_delay" env-default:"1s"`
+	MaxLockout     time.Duration `yaml:"max_lockout" env-default:"15m"`
+	Window         time.Duration `yaml:"window" env-default:"15m"`
+	MaxEntries     int           `yaml:"max_entries" env-default:"100000"`
+}
+
+type TwoFactorConfig struct {
+	// issuer shown in authenticator apps
+	Issuer        string        `yaml:"issuer" env-default:"Social Todo"`
+	ChallengeTTL  time.Duration `yaml:"challenge_timeout" env-default:"5m"`
+	RecoveryCodes int           `yaml:"recovery_codes" env-default:"10"`
+}
+
+// AccountDeletionConfig configures removal of deleted accounts' data
+// from notes_service and friends_service. Failed attempts are retried
+// after RetryDelay doubled every time, up to MaxRetryDelay, MaxAttempts
+// times at most.
+type AccountDeletionConfig struct {
+	NotesAddr     string        `yaml:"notes_addr" env-default:"localhost:4042"`
+	FriendsAddr   string        `yaml:"friends_addr" env-default:"localhost:4043"`
+	Timeout       time.Duration `yaml:"timeout" env-default:"5s"`
+	PollInterval  time.Duration `yaml:"poll_interval" env-default:"5s"`
+	RetryDelay    time.Duration `yaml:"retry_delay" env-default:"10s"`
+	MaxRetryDelay time.Duration `yaml:"max_retry_delay" env-default:"1h"`
+	MaxAttempts   int           `yaml:"max_attempts" env-default:"30"`
+}
+
+// OIDCConfig enables OpenID Connect provider served by the HTTP server
+// on JWKSPort. Issuer is the public URL of that server, tools discover
+// the provider at Issuer + "/.well-known/openid-configuration".
+type OIDCConfig struct {
+	Enabled    bool          `yaml:"enabled" env-default:"false"`
+	Issuer     string        `yaml:"issuer"`
+	CodeTTL    time.Duration `yaml:"code_timeout" env-default:"1m"`
+	IDTokenTTL time.Duration `yaml:"id_token_timeout" env-default:"1h"`
+}
+
+// ExternalLoginConfig lists external identity providers users can link
+// to their accounts and sign in with. StateTTL limits how long the user
+// may stay on provider's page, Timeout limits requests to providers.
+type ExternalLoginConfig struct {
+	StateTTL   time.Duration     `yaml:"state_timeout" env-default:"10m"`
+	Timeout    time.Duration     `yaml:"timeout" env-default:"10s"`
+	Connectors []ConnectorConfig `yaml:"connectors"`
+}
+
+// ConnectorConfig is an OAuth2 or OpenID Connect provider with userinfo
+// endpoint. RedirectURL is the callback registered at the provider, a page
+// of the client app passing code and state to ExternalLogin or LinkIdentity,
+// whichever flow it started. Claims default
+// to "sub" and "preferred_username", providers like GitHub use "id"
+// and "login".
+type ConnectorConfig struct {
+	ID            string   `yaml:"id"`
+	Name          string   `yaml:"name"`
+	AuthURL       string   `yaml:"auth_url"`
+	TokenURL      string   `yaml:"token_url"`
+	UserInfoURL   string   `yaml:"userinfo_url"`
+	ClientID      string   `yaml:"client_id"`
+	ClientSecret  string   `yaml:"client_secret"`
+	RedirectURL   string   `yaml:"redirect_url"`
+	Scopes        []string `yaml:"scopes"`
+	SubjectClaim  string   `yaml:"subject_claim"`
+	UsernameClaim string   `yaml:"username_claim"`
 }
 
 type PostgresConfig struct {
@@ -44,5 +190,21 @@ func MustLoadPath(path string) Config {
 		panic("error while reading config" + err.Error())
 	}
 
+	for i, file := range cfg.Keys.Files {
+		if !filepath.IsAbs(file.Path) {
+			cfg.Keys.Files[i].Path = filepath.Join(filepath.Dir(path), file.Path)
+		}
+	}
+	if !filepath.IsAbs(cfg.Mailer.Path) {
+		cfg.Mailer.Path = filepath.Join(filepath.Dir(path), cfg.Mailer.Path)
+	}
+	if p := cfg.PasswordPolicy.DenylistPath; p != "" && !filepath.IsAbs(p) {
+		cfg.PasswordPolicy.DenylistPath = filepath.Join(filepath.Dir(path), p)
+	}
+
+	if cfg.OIDC.Enabled && cfg.OIDC.Issuer == "" {
+		panic("config: oidc issuer is required")
+	}
+
 	return cfg
 }
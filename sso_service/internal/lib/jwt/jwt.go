@@ -1,39 +1,207 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/liriquew/social-todo/sso_service/internal/models"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var Secret string
+var (
+	Keys   *KeySet
+	TTL    = time.Hour
+	Issuer = "social-todo-sso"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// Precision of token times, main sets jwt.TimePrecision to it. iat is
+// compared with LogoutAll cutoffs, with whole seconds tokens issued right
+// after it would be revoked too.
+const Precision = time.Millisecond
+
+// PurposeTwoFactor marks challenge tokens issued by the first login step.
+// They must never be accepted as access tokens.
+const PurposeTwoFactor = "2fa"
+
+// PurposeUserInfo marks access tokens issued to OIDC clients, they are
+// accepted only by the userinfo endpoint.
+const PurposeUserInfo = "userinfo"
 
-// NewToken creates new JWT token for given user and app.
-func NewToken(user models.User) (string, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
+type Claims struct {
+	jwt.RegisteredClaims
+	UID     int64  `json:"uid"`
+	Purpose string `json:"pur,omitempty"`
+	// login session the token was issued in, empty in tokens issued
+	// before sessions were tracked
+	SessionID string `json:"sid,omitempty"`
+	// user's role at the time token was issued, tokens are revoked
+	// when role changes
+	Role string `json:"role,omitempty"`
+	// whether user's email was verified at the time token was issued
+	EmailVerified bool `json:"ev,omitempty"`
+}
 
-	claims := token.Claims.(jwt.MapClaims)
-	claims["uid"] = user.UID
+// IDClaims are claims of OpenID Connect ID token. Its issuer is the
+// provider URL, so ID tokens are never accepted as access tokens.
+type IDClaims struct {
+	jwt.RegisteredClaims
+	Nonce             string           `json:"nonce,omitempty"`
+	AuthTime          *jwt.NumericDate `json:"auth_time,omitempty"`
+	AuthorizedParty   string           `json:"azp,omitempty"`
+	PreferredUsername string           `json:"preferred_username,omitempty"`
+	Name              string           `json:"name,omitempty"`
+	Picture           string           `json:"picture,omitempty"`
+}
 
-	tokenString, err := token.SignedString([]byte(Secret))
+// NewIDToken signs ID token. Issuer, subject and audience must be set
+// by the caller, ID and times are set here.
+func NewIDToken(claims IDClaims, ttl time.Duration) (string, error) {
+	jti, err := newTokenID()
 	if err != nil {
 		return "", err
 	}
 
-	return tokenString, nil
+	now := time.Now()
+	claims.ID = jti
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+
+	return sign(claims)
 }
 
-func Validate(tokenString string) (int64, error) {
-	claims := &jwt.MapClaims{}
+// NewToken creates new JWT token for given user in login session sessionID.
+// Token lives for TTL. Claims are returned, so token can be tracked by jti.
+func NewToken(user models.User, sessionID string) (string, *Claims, error) {
+	return newToken(user, "", sessionID, TTL)
+}
+
+// NewChallengeToken creates token to be exchanged for access token
+// after second authentication factor is checked.
+func NewChallengeToken(user models.User, ttl time.Duration) (string, error) {
+	token, _, err := newToken(user, PurposeTwoFactor, "", ttl)
+	return token, err
+}
+
+// NewUserInfoToken creates token for OIDC clients in login session
+// sessionID. It carries no role and is accepted only by ValidateUserInfo.
+func NewUserInfoToken(user models.User, sessionID string) (string, *Claims, error) {
+	return newToken(models.User{UID: user.UID}, PurposeUserInfo, sessionID, TTL)
+}
+
+func newToken(user models.User, purpose, sessionID string, ttl time.Duration) (string, *Claims, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Issuer:    Issuer,
+			Subject:   strconv.FormatInt(user.UID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		UID:       user.UID,
+		Purpose:   purpose,
+		SessionID: sessionID,
+		Role:      user.Role,
+
+		EmailVerified: user.EmailVerified(),
+	}
+
+	tokenString, err := sign(claims)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return tokenString, &claims, nil
+}
+
+func sign(claims jwt.Claims) (string, error) {
+	key := Keys.Signing()
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.Private)
+}
+
+// Validate checks access token signature and all registered claims.
+func Validate(tokenString string) (*Claims, error) {
+	return validate(tokenString, "")
+}
+
+// ValidateChallenge checks challenge token made by NewChallengeToken.
+func ValidateChallenge(tokenString string) (*Claims, error) {
+	return validate(tokenString, PurposeTwoFactor)
+}
+
+// ValidateUserInfo checks token made by NewUserInfoToken.
+func ValidateUserInfo(tokenString string) (*Claims, error) {
+	return validate(tokenString, PurposeUserInfo)
+}
+
+func validate(tokenString, purpose string) (*Claims, error) {
+	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(Secret), nil
-	})
+		kid, _ := token.Header["kid"].(string)
+		key, err := Keys.Key(kid)
+		if err != nil {
+			return nil, err
+		}
+		// kid must not be reused with another algorithm
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+		return key.Public(), nil
+	},
+		jwt.WithValidMethods(supportedSigningAlgs),
+		jwt.WithIssuer(Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(5*time.Second),
+	)
 
-	if err != nil || !token.Valid {
-		return 0, fmt.Errorf("invalid token")
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	if !token.Valid {
+		return nil, ErrInvalidToken
 	}
 
-	return int64((*claims)["uid"].(float64)), nil // да
+	// claims below are not required by the jwt library
+	if claims.ID == "" || claims.IssuedAt == nil || claims.NotBefore == nil {
+		return nil, fmt.Errorf("%w: missing claims", ErrInvalidToken)
+	}
+	if claims.UID <= 0 || claims.Subject != strconv.FormatInt(claims.UID, 10) {
+		return nil, fmt.Errorf("%w: bad subject", ErrInvalidToken)
+	}
+	if claims.Purpose != purpose {
+		return nil, fmt.Errorf("%w: unexpected purpose %q", ErrInvalidToken, claims.Purpose)
+	}
+
+	return claims, nil
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
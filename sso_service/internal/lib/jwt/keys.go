@@ -0,0 +1,168 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/liriquew/social-todo/sso_service/internal/lib/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownKey      = errors.New("unknown key")
+	ErrUnsupportedKey  = errors.New("unsupported key type")
+	ErrNoSigningKey    = errors.New("signing key not loaded")
+	ErrDuplicatedKeyID = errors.New("duplicated key id")
+	ErrInvalidPEM      = errors.New("invalid PEM file")
+)
+
+var supportedSigningAlgs = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+
+// Key is a private key used to sign tokens, identified by kid header.
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.Signer
+}
+
+func (k *Key) Public() crypto.PublicKey {
+	return k.Private.Public()
+}
+
+// KeySet holds all active keys. Tokens are signed with the signing key
+// and verified with any of them, so a new key can be published before
+// it's used for signing and the old one kept until its tokens expire.
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+// LoadKeys reads PEM encoded private keys (PKCS#8 RSA or Ed25519, or PKCS#1 RSA).
+func LoadKeys(cfg config.KeysConfig) (*KeySet, error) {
+	const op = "jwt.LoadKeys"
+
+	ks := &KeySet{keys: make(map[string]*Key, len(cfg.Files))}
+	for _, file := range cfg.Files {
+		if _, ok := ks.keys[file.KID]; ok {
+			return nil, fmt.Errorf("%s: %w: %s", op, ErrDuplicatedKeyID, file.KID)
+		}
+
+		key, err := loadKey(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", op, file.KID, err)
+		}
+		ks.keys[key.ID] = key
+	}
+
+	signing, ok := ks.keys[cfg.SigningKID]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w: %s", op, ErrNoSigningKey, cfg.SigningKID)
+	}
+	ks.signing = signing
+
+	return ks, nil
+}
+
+func loadKey(file config.KeyFileConfig) (*Key, error) {
+	data, err := os.ReadFile(file.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+
+	var private any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKey, block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		return &Key{ID: file.KID, Method: jwt.SigningMethodRS256, Private: k}, nil
+	case ed25519.PrivateKey:
+		return &Key{ID: file.KID, Method: jwt.SigningMethodEdDSA, Private: k}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, private)
+}
+
+// Signing returns key new tokens are signed with.
+func (ks *KeySet) Signing() *Key {
+	return ks.signing
+}
+
+// Key returns active key by its id.
+func (ks *KeySet) Key(kid string) (*Key, error) {
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// JWK is a public key in RFC 7517 format.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns public parts of all active keys.
+func (ks *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: make([]JWK, 0, len(ks.keys))}
+	for _, key := range ks.keys {
+		jwk := JWK{
+			Kid: key.ID,
+			Use: "sig",
+			Alg: key.Method.Alg(),
+		}
+
+		switch pub := key.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks
+}
@@ -0,0 +1,115 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/liriquew/social-todo/sso_service/internal/lib/config"
+)
+
+const (
+	TypeSMTP   = "smtp"
+	TypeFile   = "file"
+	TypeMemory = "memory"
+)
+
+type Message struct {
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New creates mailer of configured type.
+func New(cfg config.MailerConfig) (Mailer, error) {
+	switch cfg.Type {
+	case TypeSMTP:
+		return NewSMTP(cfg.SMTP)
+	case TypeFile:
+		return NewFile(cfg.Path)
+	case TypeMemory:
+		return NewMemory(), nil
+	}
+	return nil, fmt.Errorf("unknown mailer type %q", cfg.Type)
+}
+
+// File appends messages to a file as JSON lines.
+type File struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFile(path string) (*File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return &File{path: path}, nil
+}
+
+func (m *File) Send(ctx context.Context, msg Message) error {
+	const op = "mailer.File.Send"
+
+	msg.SentAt = time.Now()
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Memory keeps sent messages in memory.
+type Memory struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (m *Memory) Send(ctx context.Context, msg Message) error {
+	msg.SentAt = time.Now()
+
+	m.mu.Lock()
+	m.messages = append(m.messages, msg)
+	m.mu.Unlock()
+
+	return nil
+}
+
+// Messages returns messages sent to the address.
+func (m *Memory) Messages(to string) []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var res []Message
+	for _, msg := range m.messages {
+		if msg.To == to {
+			res = append(res, msg)
+		}
+	}
+	return res
+}
@@ -0,0 +1,143 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/liriquew/social-todo/sso_service/internal/lib/config"
+)
+
+// SMTP sends messages through a relay.
+type SMTP struct {
+	host    string
+	addr    string
+	from    *mail.Address
+	auth    smtp.Auth
+	timeout time.Duration
+}
+
+func NewSMTP(cfg config.SMTPConfig) (*SMTP, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("smtp: host is required")
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("smtp: invalid from %q: %w", cfg.From, err)
+	}
+
+	m := &SMTP{
+		host:    cfg.Host,
+		addr:    net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		from:    from,
+		timeout: cfg.Timeout,
+	}
+	if cfg.Username != "" {
+		// PlainAuth refuses to send credentials without TLS to remote hosts
+		m.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	return m, nil
+}
+
+func (m *SMTP) Send(ctx context.Context, msg Message) error {
+	const op = "mailer.SMTP.Send"
+
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("%s: invalid recipient: %w", op, err)
+	}
+
+	data, err := m.message(to, msg)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := m.send(ctx, to.Address, data); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (m *SMTP) send(ctx context.Context, to string, data []byte) error {
+	dialer := net.Dialer{Timeout: m.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	// net/smtp knows nothing about contexts, deadline bounds the whole dialog
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(m.timeout)
+	}
+	conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return err
+		}
+	}
+	if m.auth != nil {
+		if err := c.Auth(m.auth); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(m.from.Address); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+func (m *SMTP) message(to *mail.Address, msg Message) ([]byte, error) {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "From: %s\r\n", m.from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", to.String())
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	w := quotedprintable.NewWriter(&b)
+	if _, err := w.Write([]byte(msg.Body)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
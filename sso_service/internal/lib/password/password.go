@@ -0,0 +1,146 @@
+package password
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/liriquew/social-todo/sso_service/internal/lib/config"
+)
+
+//go:embed common.txt
+var commonPasswords []byte
+
+const (
+	RuleMinLength = "min_length"
+	RuleMaxLength = "max_length"
+	RuleUpper     = "upper"
+	RuleLower     = "lower"
+	RuleDigit     = "digit"
+	RuleSpecial   = "special"
+	RuleDenylist  = "denylist"
+)
+
+// bcrypt ignores everything after 72 bytes
+const maxLength = 72
+
+var ErrWeakPassword = errors.New("password does not satisfy policy")
+
+type Violation struct {
+	Rule        string
+	Description string
+}
+
+// PolicyError lists all rules password violates.
+type PolicyError struct {
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	descs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		descs = append(descs, v.Description)
+	}
+	return fmt.Sprintf("%s: %s", ErrWeakPassword, strings.Join(descs, "; "))
+}
+
+func (e *PolicyError) Is(target error) bool {
+	return target == ErrWeakPassword
+}
+
+type Policy struct {
+	cfg      config.PasswordPolicyConfig
+	denylist map[string]struct{}
+}
+
+// NewPolicy creates policy with built-in list of common passwords
+// extended by the one from cfg.DenylistPath, if set.
+func NewPolicy(cfg config.PasswordPolicyConfig) (*Policy, error) {
+	const op = "password.NewPolicy"
+
+	p := &Policy{cfg: cfg, denylist: make(map[string]struct{})}
+	p.addDenylist(commonPasswords)
+
+	if cfg.DenylistPath != "" {
+		data, err := os.ReadFile(cfg.DenylistPath)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		p.addDenylist(data)
+	}
+
+	return p, nil
+}
+
+func (p *Policy) addDenylist(data []byte) {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		if line := strings.TrimSpace(sc.Text()); line != "" {
+			p.denylist[strings.ToLower(line)] = struct{}{}
+		}
+	}
+}
+
+// Validate returns *PolicyError if password violates any rule.
+func (p *Policy) Validate(password string) error {
+	var violations []Violation
+
+	if len([]rune(password)) < p.cfg.MinLength {
+		violations = append(violations, Violation{
+			Rule:        RuleMinLength,
+			Description: fmt.Sprintf("must be at least %d characters long", p.cfg.MinLength),
+		})
+	}
+	if len(password) > maxLength {
+		violations = append(violations, Violation{
+			Rule:        RuleMaxLength,
+			Description: fmt.Sprintf("must be at most %d bytes long", maxLength),
+		})
+	}
+
+	var upper, lower, digit, special bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			special = true
+		}
+	}
+
+	if required(p.cfg.RequireUpper) && !upper {
+		violations = append(violations, Violation{Rule: RuleUpper, Description: "must contain an uppercase letter"})
+	}
+	if required(p.cfg.RequireLower) && !lower {
+		violations = append(violations, Violation{Rule: RuleLower, Description: "must contain a lowercase letter"})
+	}
+	if required(p.cfg.RequireDigit) && !digit {
+		violations = append(violations, Violation{Rule: RuleDigit, Description: "must contain a digit"})
+	}
+	if p.cfg.RequireSpecial && !special {
+		violations = append(violations, Violation{Rule: RuleSpecial, Description: "must contain a special character"})
+	}
+
+	if _, ok := p.denylist[strings.ToLower(password)]; ok {
+		violations = append(violations, Violation{Rule: RuleDenylist, Description: "is too common"})
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// required treats unset rule as on.
+func required(rule *bool) bool {
+	return rule == nil || *rule
+}
@@ -0,0 +1,175 @@
+package lockout
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/liriquew/social-todo/sso_service/internal/lib/config"
+)
+
+var ErrLocked = errors.New("too many failed attempts")
+
+// LockedError tells when the next attempt is allowed.
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrLocked, e.RetryAfter)
+}
+
+func (e *LockedError) Is(target error) bool {
+	return target == ErrLocked
+}
+
+type entry struct {
+	key         string
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// Tracker counts failed logins per username and per client IP.
+// After free attempts each failure locks the key for exponentially
+// growing time, counters are forgotten after Window without failures.
+// At most MaxEntries counters are kept, the least recently failed
+// ones are dropped first.
+type Tracker struct {
+	cfg config.LockoutConfig
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// entries by last failure, most recent first
+	order *list.List
+	now   func() time.Time
+}
+
+func New(cfg config.LockoutConfig) *Tracker {
+	return &Tracker{
+		cfg:     cfg,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+func userKey(username string) string { return "user:" + username }
+func ipKey(ip string) string         { return "ip:" + ip }
+
+// Check returns *LockedError if username or ip is locked.
+// Empty ip is not tracked.
+func (t *Tracker) Check(username, ip string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+
+	var retryAfter time.Duration
+	for _, key := range t.keys(username, ip) {
+		if e := t.entry(key, now); e != nil && now.Before(e.lockedUntil) {
+			retryAfter = max(retryAfter, e.lockedUntil.Sub(now))
+		}
+	}
+
+	if retryAfter > 0 {
+		// whole seconds, rounded up, as Retry-After expects
+		return &LockedError{RetryAfter: (retryAfter + time.Second - 1).Truncate(time.Second)}
+	}
+	return nil
+}
+
+// Fail records failed attempt. Empty username is for names that don't
+// exist, only ip counter is kept for them, so made up names can't
+// crowd out real ones.
+func (t *Tracker) Fail(username, ip string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	if username != "" {
+		t.fail(userKey(username), t.cfg.FreeAttempts, now)
+	}
+	if ip != "" {
+		t.fail(ipKey(ip), t.cfg.IPFreeAttempts, now)
+	}
+
+	t.purge(now)
+}
+
+// Success resets username counter. IP counter is kept, otherwise
+// one known account would let an attacker reset it at will.
+func (t *Tracker) Success(username string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if el, ok := t.entries[userKey(username)]; ok {
+		t.remove(el)
+	}
+}
+
+func (t *Tracker) keys(username, ip string) []string {
+	if ip == "" {
+		return []string{userKey(username)}
+	}
+	return []string{userKey(username), ipKey(ip)}
+}
+
+// entry returns live entry, forgetting expired one.
+func (t *Tracker) entry(key string, now time.Time) *entry {
+	el, ok := t.entries[key]
+	if !ok {
+		return nil
+	}
+	e := el.Value.(*entry)
+	if t.expired(e, now) {
+		t.remove(el)
+		return nil
+	}
+	return e
+}
+
+func (t *Tracker) expired(e *entry, now time.Time) bool {
+	return now.Sub(e.lastFailure) > t.cfg.Window && !now.Before(e.lockedUntil)
+}
+
+func (t *Tracker) remove(el *list.Element) {
+	t.order.Remove(el)
+	delete(t.entries, el.Value.(*entry).key)
+}
+
+func (t *Tracker) fail(key string, free int, now time.Time) {
+	e := t.entry(key, now)
+	if e == nil {
+		e = &entry{key: key}
+		t.entries[key] = t.order.PushFront(e)
+	} else {
+		t.order.MoveToFront(t.entries[key])
+	}
+
+	e.failures++
+	e.lastFailure = now
+
+	if over := e.failures - free; over > 0 {
+		delay := t.cfg.MaxLockout
+		// avoid overflow, delay is capped anyway
+		if over < 32 {
+			delay = min(t.cfg.BaseDelay<<(over-1), t.cfg.MaxLockout)
+		}
+		e.lockedUntil = now.Add(delay)
+	}
+}
+
+// purge drops forgotten entries from the tail, where the least recently
+// failed ones are, and the oldest ones above MaxEntries.
+// Must be called with mu held.
+func (t *Tracker) purge(now time.Time) {
+	for el := t.order.Back(); el != nil; el = t.order.Back() {
+		if !t.expired(el.Value.(*entry), now) && len(t.entries) <= t.cfg.MaxEntries {
+			return
+		}
+		t.remove(el)
+	}
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// AccessToken is personal access token, it is stored only as a hash.
+// Unlike JWT it grants only its Scopes and lives until revoked
+// or ExpiresAt, if set.
+type AccessToken struct {
+	ID         int64
+	UID        int64
+	Name       string
+	Hash       []byte
+	Scopes     []string
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+
+	// set only when token is looked up to authorize a request
+	OwnerEmailVerified bool
+}
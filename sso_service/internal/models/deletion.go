@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+const (
+	DeletionPending   = "pending"
+	DeletionCompleted = "completed"
+	// DeletionFailed means retries are exhausted, operator has to look into it
+	DeletionFailed = "failed"
+)
+
+// AccountDeletion tracks removal of user's data from all services.
+// Steps that succeeded are not repeated on retry.
+type AccountDeletion struct {
+	ID          string
+	UID         int64
+	Status      string
+	NotesDone   bool
+	FriendsDone bool
+	Attempts    int
+	LastError   string
+	NextAttempt time.Time
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Identity is user's account at external identity provider linked
+// to the local account. Subject is unique per connector.
+type Identity struct {
+	UID         int64
+	ConnectorID string
+	Subject     string
+	Username    string
+	Email       string
+	CreatedAt   time.Time
+	LastLoginAt *time.Time
+}
+
+// ExternalAuthState is a pending redirect to identity provider, stored
+// as a hash. UID is set when user links identity, zero for login.
+type ExternalAuthState struct {
+	Hash         []byte
+	ConnectorID  string
+	UID          int64
+	CodeVerifier string
+	ExpiresAt    time.Time
+}
+
+// Connector is external identity provider shown to users.
+type Connector struct {
+	ID   string
+	Name string
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// OIDCClient is an application registered to sign users in with
+// OpenID Connect. Public clients have no secret, all clients must use PKCE.
+type OIDCClient struct {
+	ID           string
+	Name         string
+	SecretHash   []byte
+	RedirectURIs []string
+	CreatedAt    time.Time
+}
+
+func (c OIDCClient) Public() bool {
+	return len(c.SecretHash) == 0
+}
+
+// OIDCAuthRequest holds authorization request parameters.
+// Only code flow with S256 code challenge is supported.
+type OIDCAuthRequest struct {
+	ClientID      string
+	RedirectURI   string
+	Scope         string
+	State         string
+	Nonce         string
+	CodeChallenge string
+}
+
+// OIDCCode is an issued authorization code, stored as a hash.
+type OIDCCode struct {
+	Hash          []byte
+	UID           int64
+	ClientID      string
+	RedirectURI   string
+	Scope         string
+	Nonce         string
+	CodeChallenge string
+	Client        Client
+	AuthTime      time.Time
+	ExpiresAt     time.Time
+}
+
+// OIDCLoginResult has either the code or, if user has 2FA enabled,
+// challenge token to complete login with.
+type OIDCLoginResult struct {
+	Code           string
+	ChallengeToken string
+}
+
+type OIDCTokenRequest struct {
+	ClientID     string
+	ClientSecret string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+}
+
+type OIDCTokens struct {
+	AccessToken string
+	IDToken     string
+	Scope       string
+	ExpiresIn   time.Duration
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Profile is public user info. Users without profile row
+// have empty Bio and AvatarURL.
+type Profile struct {
+	UID         int64
+	Username    string
+	DisplayName string
+	Bio         string
+	AvatarURL   string
+	UpdatedAt   time.Time
+}
+
+// ProfileUpdate holds changed profile fields, nil ones are kept as is.
+type ProfileUpdate struct {
+	DisplayName *string
+	Bio         *string
+	AvatarURL   *string
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Client describes device the request came from.
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+// Session is a single login, it lasts while its refresh tokens are rotated.
+// Session ID is the refresh token family ID.
+type Session struct {
+	ID         string
+	UID        int64
+	Client     Client
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+}
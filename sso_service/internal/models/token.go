@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// RefreshToken is stored only as a hash of the token.
+// Tokens issued one from another by rotation share FamilyID.
+type RefreshToken struct {
+	ID        int64
+	UID       int64
+	FamilyID  string
+	Hash      []byte
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+type RevokedToken struct {
+	JTI       string
+	ExpiresAt time.Time
+}
+
+// UserRevocation revokes all user's tokens issued before RevokedBefore.
+type UserRevocation struct {
+	UID           int64
+	RevokedBefore time.Time
+}
+
+type Revocations struct {
+	Tokens []RevokedToken
+	Users  []UserRevocation
+	Until  time.Time
+}
@@ -0,0 +1,21 @@
+package models
+
+type TOTP struct {
+	UID       int64
+	Secret    string
+	Confirmed bool
+	// last step a code was accepted for, codes can't be reused
+	LastUsedStep int64
+}
+
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// LoginResult holds either tokens or, if user has 2FA enabled,
+// challenge token to be completed with a code.
+type LoginResult struct {
+	Tokens         TokenPair
+	ChallengeToken string
+}
@@ -1,7 +1,55 @@
 package models
 
+import "time"
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
 	UID      int64
 	Username string
 	PassHash []byte
+
+	DisplayName string
+
+	// optional, empty if not set
+	Email           string
+	EmailVerifiedAt *time.Time
+
+	Role       string
+	DisabledAt *time.Time
+	CreatedAt  time.Time
+}
+
+func (u User) EmailVerified() bool {
+	return u.Email != "" && u.EmailVerifiedAt != nil
+}
+
+// Principal is the owner of authorized access token, as of token issue.
+type Principal struct {
+	UID           int64
+	Role          string
+	EmailVerified bool
+}
+
+// AuditResultOK is Result of actions that succeeded.
+const AuditResultOK = "ok"
+
+// AuditResultRequested is Result of actions in other services that were
+// authorized, their outcome is recorded in another entry.
+const AuditResultRequested = "requested"
+
+// AuditEntry records an admin action. Target is like "user:42" or "note:7".
+// Result is AuditResultOK, AuditResultRequested or the error the action
+// failed with.
+type AuditEntry struct {
+	ID        int64
+	ActorUID  int64
+	Action    string
+	Target    string
+	Details   string
+	Result    string
+	CreatedAt time.Time
 }
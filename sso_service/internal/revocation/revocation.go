@@ -0,0 +1,192 @@
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/liriquew/social-todo/sso_service/internal/lib/jwt"
+)
+
+// Storage persists revocations, so they survive restarts and are
+// shared between service instances.
+type Storage interface {
+	RevokeToken(ctx context.Context, jti string, UID int64, expiresAt time.Time) error
+	TokenRevoked(ctx context.Context, jti string) (bool, error)
+	RevokeUserTokens(ctx context.Context, UID int64, before time.Time) error
+	UserTokensRevokedBefore(ctx context.Context, UID int64) (time.Time, error)
+}
+
+type tokenEntry struct {
+	revoked bool
+	until   time.Time
+}
+
+type userEntry struct {
+	before time.Time
+	until  time.Time
+}
+
+// Store keeps revoked tokens in memory in front of Storage.
+// Revoked tokens are cached until they expire, everything else
+// is cached for ttl, so revocations made by other instances are
+// seen after at most ttl.
+type Store struct {
+	log     *slog.Logger
+	storage Storage
+	ttl     time.Duration
+
+	mu        sync.Mutex
+	tokens    map[string]tokenEntry
+	users     map[int64]userEntry
+	lastPurge time.Time
+}
+
+func New(log *slog.Logger, storage Storage, ttl time.Duration) *Store {
+	return &Store{
+		log:       log,
+		storage:   storage,
+		ttl:       ttl,
+		tokens:    make(map[string]tokenEntry),
+		users:     make(map[int64]userEntry),
+		lastPurge: time.Now(),
+	}
+}
+
+// Revoke revokes single token.
+func (s *Store) Revoke(ctx context.Context, claims *jwt.Claims) error {
+	return s.RevokeToken(ctx, claims.ID, claims.UID, claims.ExpiresAt.Time)
+}
+
+// RevokeToken revokes single token by its jti, for tokens caller
+// has no claims of.
+func (s *Store) RevokeToken(ctx context.Context, jti string, UID int64, expiresAt time.Time) error {
+	const op = "revocation.RevokeToken"
+
+	if err := s.storage.RevokeToken(ctx, jti, UID, expiresAt); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.Remember(jti, expiresAt)
+
+	return nil
+}
+
+// Remember marks token revoked in memory only, for revocations caller
+// saved in its own transaction.
+func (s *Store) Remember(jti string, expiresAt time.Time) {
+	s.mu.Lock()
+	s.tokens[jti] = tokenEntry{revoked: true, until: expiresAt}
+	s.mu.Unlock()
+}
+
+// RevokeAll revokes all tokens of the user issued before now.
+func (s *Store) RevokeAll(ctx context.Context, UID int64) error {
+	const op = "revocation.RevokeAll"
+
+	// the cutoff has the precision of iat it's compared with
+	now := time.Now().Truncate(jwt.Precision)
+	if err := s.storage.RevokeUserTokens(ctx, UID, now); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.Lock()
+	s.users[UID] = userEntry{before: now, until: now.Add(s.ttl)}
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Revoked reports whether token was revoked by Revoke or RevokeAll.
+func (s *Store) Revoked(ctx context.Context, claims *jwt.Claims) (bool, error) {
+	const op = "revocation.Revoked"
+
+	before, err := s.revokedBefore(ctx, claims.UID)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	if !before.IsZero() && !claims.IssuedAt.Time.After(before) {
+		return true, nil
+	}
+
+	revoked, err := s.tokenRevoked(ctx, claims)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return revoked, nil
+}
+
+func (s *Store) revokedBefore(ctx context.Context, UID int64) (time.Time, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	entry, ok := s.users[UID]
+	s.mu.Unlock()
+	if ok && now.Before(entry.until) {
+		return entry.before, nil
+	}
+
+	before, err := s.storage.UserTokensRevokedBefore(ctx, UID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	s.mu.Lock()
+	s.users[UID] = userEntry{before: before, until: now.Add(s.ttl)}
+	s.purge(now)
+	s.mu.Unlock()
+
+	return before, nil
+}
+
+func (s *Store) tokenRevoked(ctx context.Context, claims *jwt.Claims) (bool, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	entry, ok := s.tokens[claims.ID]
+	s.mu.Unlock()
+	if ok && now.Before(entry.until) {
+		return entry.revoked, nil
+	}
+
+	revoked, err := s.storage.TokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return false, err
+	}
+
+	entry = tokenEntry{revoked: revoked, until: now.Add(s.ttl)}
+	if revoked {
+		entry.until = claims.ExpiresAt.Time
+	}
+
+	s.mu.Lock()
+	s.tokens[claims.ID] = entry
+	s.purge(now)
+	s.mu.Unlock()
+
+	return revoked, nil
+}
+
+// purge drops stale entries, must be called with mu held.
+func (s *Store) purge(now time.Time) {
+	if now.Sub(s.lastPurge) < s.ttl {
+		return
+	}
+	s.lastPurge = now
+
+	for jti, entry := range s.tokens {
+		if !now.Before(entry.until) {
+			delete(s.tokens, jti)
+		}
+	}
+	for UID, entry := range s.users {
+		if !now.Before(entry.until) {
+			delete(s.users, UID)
+		}
+	}
+
+	s.log.Debug("revocation cache purged", slog.Int("tokens", len(s.tokens)), slog.Int("users", len(s.users)))
+}
@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/liriquew/social-todo/sso_service/internal/lib/tokens"
+	"github.com/liriquew/social-todo/sso_service/internal/models"
+	"github.com/liriquew/social-todo/sso_service/internal/storage"
+
+	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
+)
+
+// AccessTokenPrefix lets api_service tell personal access tokens from JWT.
+const AccessTokenPrefix = "pat_"
+
+const (
+	maxAccessTokens        = 50
+	maxAccessTokenNameLen  = 64
+	minAccessTokenLifetime = time.Minute
+)
+
+// Scopes personal access tokens can be issued with.
+var Scopes = []string{
+	"notes:read",
+	"notes:write",
+	"friends:read",
+	"friends:write",
+	"notifications:read",
+	"notifications:write",
+}
+
+var (
+	ErrInvalidAccessToken = errors.New("invalid access token request")
+	ErrTooManyTokens      = errors.New("too many access tokens")
+	ErrTokenNotFound      = errors.New("access token not found")
+)
+
+// AccessTokenError tells which field of access token request was rejected.
+type AccessTokenError struct {
+	Field  string
+	Reason string
+}
+
+func (e *AccessTokenError) Error() string {
+	return fmt.Sprintf("%s: %s %s", ErrInvalidAccessToken, e.Field, e.Reason)
+}
+
+func (e *AccessTokenError) Is(target error) bool {
+	return target == ErrInvalidAccessToken
+}
+
+// CreateAccessToken issues personal access token for the JWT owner.
+// Token itself is returned only here, only its hash is stored.
+// Zero ttl means token never expires.
+func (a *Auth) CreateAccessToken(
+	ctx context.Context,
+	tokenString, name string,
+	scopes []string,
+	ttl time.Duration,
+) (models.AccessToken, string, error) {
+	const op = "auth.CreateAccessToken"
+
+	log := a.log.With(slog.String("op", op))
+	log.Info("attempting to create access token")
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return models.AccessToken{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("UID", claims.UID))
+
+	name, scopes, err = normalizeAccessToken(name, scopes, ttl)
+	if err != nil {
+		log.Info("invalid access token request", sl.Err(err))
+		return models.AccessToken{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	secret, _, err := tokens.NewOpaque()
+	if err != nil {
+		log.Error("failed to generate token", sl.Err(err))
+		return models.AccessToken{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+	raw := AccessTokenPrefix + secret
+
+	token := models.AccessToken{
+		UID:    claims.UID,
+		Name:   name,
+		Hash:   tokens.Hash(raw),
+		Scopes: scopes,
+	}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		token.ExpiresAt = &expiresAt
+	}
+
+	token, err = a.Storage.SaveAccessToken(ctx, token, maxAccessTokens)
+	if err != nil {
+		if errors.Is(err, storage.ErrTooManyTokens) {
+			log.Warn("too many access tokens")
+			return models.AccessToken{}, "", fmt.Errorf("%s: %w", op, ErrTooManyTokens)
+		}
+
+		log.Error("failed to save access token", sl.Err(err))
+		return models.AccessToken{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("access token created", slog.Int64("ID", token.ID))
+
+	return token, raw, nil
+}
+
+// AccessTokens lists JWT owner's tokens which are not revoked.
+func (a *Auth) AccessTokens(ctx context.Context, tokenString string) ([]models.AccessToken, error) {
+	const op = "auth.AccessTokens"
+
+	log := a.log.With(slog.String("op", op))
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	list, err := a.Storage.AccessTokens(ctx, claims.UID)
+	if err != nil {
+		log.Error("failed to list access tokens", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return list, nil
+}
+
+func (a *Auth) RevokeAccessToken(ctx context.Context, tokenString string, ID int64) error {
+	const op = "auth.RevokeAccessToken"
+
+	log := a.log.With(slog.String("op", op), slog.Int64("ID", ID))
+	log.Info("attempting to revoke access token")
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.Storage.RevokeAccessToken(ctx, claims.UID, ID); err != nil {
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			log.Info("access token not found")
+			return fmt.Errorf("%s: %w", op, ErrTokenNotFound)
+		}
+
+		log.Error("failed to revoke access token", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// AuthorizeAccessToken checks personal access token and returns it,
+// caller must check token Scopes. Tokens of disabled users are invalid.
+func (a *Auth) AuthorizeAccessToken(ctx context.Context, raw string) (models.AccessToken, error) {
+	const op = "auth.AuthorizeAccessToken"
+
+	log := a.log.With(slog.String("op", op))
+
+	raw = strings.TrimPrefix(raw, "Bearer ")
+	if !strings.HasPrefix(raw, AccessTokenPrefix) {
+		return models.AccessToken{}, fmt.Errorf("%s: %w", op, ErrInvalidToken)
+	}
+
+	token, err := a.Storage.AccessToken(ctx, tokens.Hash(raw))
+	if err != nil {
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			log.Warn("unknown access token")
+			return models.AccessToken{}, fmt.Errorf("%s: %w", op, ErrInvalidToken)
+		}
+
+		log.Error("failed to get access token", sl.Err(err))
+		return models.AccessToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("UID", token.UID), slog.Int64("ID", token.ID))
+
+	if token.RevokedAt != nil {
+		log.Info("access token revoked")
+		return models.AccessToken{}, fmt.Errorf("%s: %w", op, ErrTokenRevoked)
+	}
+	if token.ExpiresAt != nil && time.Now().After(*token.ExpiresAt) {
+		log.Info("access token expired")
+		return models.AccessToken{}, fmt.Errorf("%s: %w", op, ErrTokenExpired)
+	}
+
+	if err := a.Storage.TouchAccessToken(ctx, token.ID); err != nil {
+		// not worth failing the request
+		log.Warn("failed to update last use", sl.Err(err))
+	}
+
+	return token, nil
+}
+
+// normalizeAccessToken trims name and returns sorted scopes without duplicates.
+func normalizeAccessToken(name string, scopes []string, ttl time.Duration) (string, []string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxAccessTokenNameLen {
+		return "", nil, &AccessTokenError{"name", fmt.Sprintf("must be from 1 to %d characters", maxAccessTokenNameLen)}
+	}
+	if ttl < 0 || (ttl > 0 && ttl < minAccessTokenLifetime) {
+		return "", nil, &AccessTokenError{"expires_in", fmt.Sprintf("must be at least %s", minAccessTokenLifetime)}
+	}
+	if len(scopes) == 0 {
+		return "", nil, &AccessTokenError{"scopes", "must not be empty"}
+	}
+
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !slices.Contains(Scopes, scope) {
+			return "", nil, &AccessTokenError{"scopes", fmt.Sprintf("has unknown scope %q", scope)}
+		}
+		normalized = append(normalized, scope)
+	}
+	slices.Sort(normalized)
+
+	return name, slices.Compact(normalized), nil
+}
@@ -0,0 +1,344 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/liriquew/social-todo/sso_service/internal/lib/jwt"
+	"github.com/liriquew/social-todo/sso_service/internal/models"
+	"github.com/liriquew/social-todo/sso_service/internal/storage"
+
+	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
+)
+
+var (
+	ErrForbidden    = errors.New("admin role required")
+	ErrUserDisabled = errors.New("account disabled")
+	ErrInvalidRole  = errors.New("invalid role")
+	ErrSelfAction   = errors.New("admins can't disable or demote themselves")
+)
+
+// requireAdmin validates token and checks its owner is admin now,
+// role claim may be stale.
+func (a *Auth) requireAdmin(ctx context.Context, log *slog.Logger, tokenString string) (*jwt.Claims, error) {
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.Storage.UserByID(ctx, claims.UID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrInvalidToken
+		}
+		log.Error("failed to get user", sl.Err(err))
+		return nil, err
+	}
+	if user.Role != models.RoleAdmin || user.DisabledAt != nil {
+		log.Warn("admin action attempted by non admin", slog.Int64("UID", claims.UID))
+		return nil, ErrForbidden
+	}
+
+	return claims, nil
+}
+
+// audit records admin action after it's performed, with the error it
+// failed with, if any. Failure to record is logged, the action is done.
+func (a *Auth) audit(ctx context.Context, actorUID int64, action, target, details string, actionErr error) {
+	result := models.AuditResultOK
+	if actionErr != nil {
+		result = actionErr.Error()
+	}
+
+	log := a.log.With(
+		slog.Int64("actor", actorUID),
+		slog.String("action", action),
+		slog.String("target", target),
+		slog.String("details", details),
+		slog.String("result", result),
+	)
+	log.Info("audit")
+
+	err := a.Storage.SaveAuditEntry(ctx, models.AuditEntry{
+		ActorUID: actorUID,
+		Action:   action,
+		Target:   target,
+		Details:  details,
+		Result:   result,
+	})
+	if err != nil {
+		log.Error("failed to save audit entry", sl.Err(err))
+	}
+}
+
+func userTarget(UID int64) string {
+	return "user:" + strconv.FormatInt(UID, 10)
+}
+
+// ListUsers returns page of all users for admin and cursor of the next page.
+func (a *Auth) ListUsers(ctx context.Context, tokenString, cursor string, limit int64) ([]models.User, string, error) {
+	const op = "auth.ListUsers"
+
+	log := a.log.With(slog.String("op", op))
+
+	if _, err := a.requireAdmin(ctx, log, tokenString); err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	offset, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	users, err := a.Storage.ListUsers(ctx, offset, limit+1)
+	if err != nil {
+		log.Error("failed to list users", sl.Err(err))
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	var next string
+	if int64(len(users)) > limit {
+		users = users[:limit]
+		next = encodeCursor(offset + limit)
+	}
+
+	return users, next, nil
+}
+
+// SetUserDisabled disables or enables account. Disabled users can't log in
+// and all their sessions are ended.
+func (a *Auth) SetUserDisabled(ctx context.Context, tokenString string, UID int64, disabled bool) error {
+	const op = "auth.SetUserDisabled"
+
+	log := a.log.With(slog.String("op", op), slog.Int64("target", UID), slog.Bool("disabled", disabled))
+	log.Info("attempting to change account state")
+
+	claims, err := a.requireAdmin(ctx, log, tokenString)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if claims.UID == UID {
+		return fmt.Errorf("%s: %w", op, ErrSelfAction)
+	}
+
+	action := "user.enable"
+	if disabled {
+		action = "user.disable"
+	}
+
+	err = a.setUserDisabled(ctx, log, UID, disabled)
+	a.audit(ctx, claims.UID, action, userTarget(UID), "", err)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (a *Auth) setUserDisabled(ctx context.Context, log *slog.Logger, UID int64, disabled bool) error {
+	if err := a.Storage.SetUserDisabled(ctx, UID, disabled); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		log.Error("failed to update user", sl.Err(err))
+		return err
+	}
+
+	if disabled {
+		if err := a.endSessions(ctx, UID); err != nil {
+			log.Error("failed to revoke tokens", sl.Err(err))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ForceLogout ends all sessions of the user.
+func (a *Auth) ForceLogout(ctx context.Context, tokenString string, UID int64) error {
+	const op = "auth.ForceLogout"
+
+	log := a.log.With(slog.String("op", op), slog.Int64("target", UID))
+	log.Info("attempting to force logout")
+
+	claims, err := a.requireAdmin(ctx, log, tokenString)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = a.forceLogout(ctx, log, UID)
+	a.audit(ctx, claims.UID, "user.logout", userTarget(UID), "", err)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (a *Auth) forceLogout(ctx context.Context, log *slog.Logger, UID int64) error {
+	if _, err := a.Storage.UserByID(ctx, UID); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		log.Error("failed to get user", sl.Err(err))
+		return err
+	}
+
+	if err := a.endSessions(ctx, UID); err != nil {
+		log.Error("failed to revoke tokens", sl.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+// SetUserRole changes user's role. User's tokens are revoked, so role
+// claims of issued tokens never outlive the change.
+func (a *Auth) SetUserRole(ctx context.Context, tokenString string, UID int64, role string) error {
+	const op = "auth.SetUserRole"
+
+	log := a.log.With(slog.String("op", op), slog.Int64("target", UID), slog.String("role", role))
+	log.Info("attempting to change role")
+
+	if role != models.RoleUser && role != models.RoleAdmin {
+		return fmt.Errorf("%s: %w", op, ErrInvalidRole)
+	}
+
+	claims, err := a.requireAdmin(ctx, log, tokenString)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if claims.UID == UID {
+		return fmt.Errorf("%s: %w", op, ErrSelfAction)
+	}
+
+	err = a.setUserRole(ctx, log, UID, role)
+	a.audit(ctx, claims.UID, "user.set_role", userTarget(UID), role, err)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (a *Auth) setUserRole(ctx context.Context, log *slog.Logger, UID int64, role string) error {
+	if err := a.Storage.SetUserRole(ctx, UID, role); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		log.Error("failed to update user", sl.Err(err))
+		return err
+	}
+
+	if err := a.endSessions(ctx, UID); err != nil {
+		log.Error("failed to revoke tokens", sl.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+// AuthorizeAdminAction checks that the token owner is admin before other
+// services perform an action, e.g. note removal, and records the request.
+// The action must not be performed if it fails.
+func (a *Auth) AuthorizeAdminAction(ctx context.Context, tokenString, action, target, details string) error {
+	const op = "auth.AuthorizeAdminAction"
+
+	log := a.log.With(slog.String("op", op), slog.String("action", action))
+
+	claims, err := a.requireAdmin(ctx, log, tokenString)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = a.Storage.SaveAuditEntry(ctx, models.AuditEntry{
+		ActorUID: claims.UID,
+		Action:   action,
+		Target:   target,
+		Details:  details,
+		Result:   models.AuditResultRequested,
+	})
+	if err != nil {
+		log.Error("failed to audit", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RecordAdminAction audits admin action performed by other services,
+// e.g. note removal, with its result. It must be called after the action
+// authorized by AuthorizeAdminAction, whether it succeeded or not.
+func (a *Auth) RecordAdminAction(ctx context.Context, tokenString, action, target, details, result string) error {
+	const op = "auth.RecordAdminAction"
+
+	log := a.log.With(slog.String("op", op), slog.String("action", action))
+
+	claims, err := a.requireAdmin(ctx, log, tokenString)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = a.Storage.SaveAuditEntry(ctx, models.AuditEntry{
+		ActorUID: claims.UID,
+		Action:   action,
+		Target:   target,
+		Details:  details,
+		Result:   result,
+	})
+	if err != nil {
+		log.Error("failed to audit", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// AuditLog returns page of admin actions, newest first.
+func (a *Auth) AuditLog(ctx context.Context, tokenString, cursor string, limit int64) ([]models.AuditEntry, string, error) {
+	const op = "auth.AuditLog"
+
+	log := a.log.With(slog.String("op", op))
+
+	if _, err := a.requireAdmin(ctx, log, tokenString); err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	offset, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	entries, err := a.Storage.AuditLog(ctx, offset, limit+1)
+	if err != nil {
+		log.Error("failed to get audit log", sl.Err(err))
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	var next string
+	if int64(len(entries)) > limit {
+		entries = entries[:limit]
+		next = encodeCursor(offset + limit)
+	}
+
+	return entries, next, nil
+}
+
+// endSessions revokes all access, refresh and personal access tokens
+// of the user.
+func (a *Auth) endSessions(ctx context.Context, UID int64) error {
+	if err := a.revoker.RevokeAll(ctx, UID); err != nil {
+		return err
+	}
+
+	revoked, err := a.Storage.RevokeUserRefreshTokens(ctx, UID)
+	if err != nil {
+		return err
+	}
+	a.remember(revoked)
+
+	return a.Storage.RevokeUserAccessTokens(ctx, UID)
+}
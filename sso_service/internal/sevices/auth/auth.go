@@ -6,8 +6,13 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
+	"github.com/liriquew/social-todo/sso_service/internal/connector"
+	"github.com/liriquew/social-todo/sso_service/internal/lib/config"
 	"github.com/liriquew/social-todo/sso_service/internal/lib/jwt"
+	"github.com/liriquew/social-todo/sso_service/internal/lib/mailer"
+	"github.com/liriquew/social-todo/sso_service/internal/lib/tokens"
 	"github.com/liriquew/social-todo/sso_service/internal/models"
 	"github.com/liriquew/social-todo/sso_service/internal/storage"
 
@@ -17,72 +22,435 @@ import (
 )
 
 type Auth struct {
-	log     *slog.Logger
-	Storage StorageProvider
+	log        *slog.Logger
+	Storage    StorageProvider
+	revoker    Revoker
+	mailer     mailer.Mailer
+	policy     PasswordPolicy
+	limiter    LoginLimiter
+	bcryptCost int
+	// compared against when user doesn't exist, so login takes as long
+	dummyHash  []byte
+	refreshTTL time.Duration
+	resetTTL   time.Duration
+	twoFactor  config.TwoFactorConfig
+	oidc       config.OIDCConfig
+	connectors connector.Set
+	stateTTL   time.Duration
+
+	verification config.EmailVerificationConfig
+}
+
+type PasswordPolicy interface {
+	Validate(password string) error
+}
+
+// LoginLimiter tracks failed logins, see lockout.Tracker.
+type LoginLimiter interface {
+	Check(username, ip string) error
+	Fail(username, ip string)
+	Success(username string)
 }
 
 type StorageProvider interface {
-	SaveUser(context.Context, string, []byte) (int64, error)
+	SaveUser(context.Context, string, []byte, string) (int64, error)
 	User(context.Context, string) (models.User, error)
+	UserByID(context.Context, int64) (models.User, error)
+	UsersByIDs(context.Context, []int64) ([]models.User, error)
+
+	SaveRefreshToken(context.Context, models.RefreshToken) error
+	RefreshToken(context.Context, []byte) (models.RefreshToken, error)
+	UseRefreshToken(context.Context, int64) (bool, error)
+	RevokeRefreshFamily(context.Context, string) ([]models.RevokedToken, error)
+	RevokeUserRefreshTokens(context.Context, int64) ([]models.RevokedToken, error)
+	Revocations(context.Context, time.Time) (models.Revocations, error)
+
+	UpdatePassword(context.Context, int64, []byte) error
+	SaveResetToken(context.Context, int64, []byte, time.Time) error
+	UseResetToken(context.Context, []byte) (int64, error)
+
+	TOTP(context.Context, int64) (models.TOTP, error)
+	SaveTOTPSecret(context.Context, int64, string) error
+	ConfirmTOTP(context.Context, int64, int64, [][]byte) error
+	UseTOTPStep(context.Context, int64, int64) (bool, error)
+	UseRecoveryCode(context.Context, int64, []byte) (bool, error)
+	DeleteTOTP(context.Context, int64) error
+
+	Profile(context.Context, int64) (models.Profile, error)
+	ProfilesByIDs(context.Context, []int64) ([]models.Profile, error)
+	UpdateProfile(context.Context, int64, models.ProfileUpdate) error
+
+	SearchUsers(context.Context, int64, string, int64, int64) ([]models.User, error)
+	VisibleUser(context.Context, int64, string) (models.User, error)
+	BlockUser(context.Context, int64, int64) error
+	UnblockUser(context.Context, int64, int64) error
+
+	StartAccountDeletion(context.Context, int64, string) error
+	AccountDeletion(context.Context, string) (models.AccountDeletion, error)
+
+	SaveAccessToken(context.Context, models.AccessToken, int) (models.AccessToken, error)
+	AccessTokens(context.Context, int64) ([]models.AccessToken, error)
+	AccessToken(context.Context, []byte) (models.AccessToken, error)
+	TouchAccessToken(context.Context, int64) error
+	RevokeAccessToken(context.Context, int64, int64) error
+	RevokeUserAccessTokens(context.Context, int64) error
+
+	SaveSession(context.Context, models.Session) error
+	ExtendSession(context.Context, string, models.Client, time.Time) error
+	TouchSession(context.Context, string) (bool, error)
+	SaveSessionToken(context.Context, string, string, time.Time) error
+	Sessions(context.Context, int64) ([]models.Session, error)
+	RevokeSession(context.Context, int64, string) ([]models.RevokedToken, error)
+
+	ListUsers(context.Context, int64, int64) ([]models.User, error)
+	SetUserDisabled(context.Context, int64, bool) error
+	SetUserRole(context.Context, int64, string) error
+	SaveAuditEntry(context.Context, models.AuditEntry) error
+	AuditLog(context.Context, int64, int64) ([]models.AuditEntry, error)
+
+	SaveOIDCClient(context.Context, models.OIDCClient) (models.OIDCClient, error)
+	OIDCClient(context.Context, string) (models.OIDCClient, error)
+	OIDCClients(context.Context) ([]models.OIDCClient, error)
+	DeleteOIDCClient(context.Context, string) error
+	SaveOIDCCode(context.Context, models.OIDCCode) error
+	UseOIDCCode(context.Context, []byte, string) (models.OIDCCode, error)
+
+	SaveExternalAuthState(context.Context, models.ExternalAuthState) error
+	UseExternalAuthState(context.Context, []byte) (models.ExternalAuthState, error)
+	SaveIdentity(context.Context, models.Identity) (models.Identity, error)
+	LoginIdentity(context.Context, models.Identity) (models.Identity, error)
+	Identities(context.Context, int64) ([]models.Identity, error)
+	DeleteIdentity(context.Context, int64, string) error
+
+	SetEmail(context.Context, int64, string) error
+	VerifyEmail(context.Context, int64, string) error
+	SaveVerificationToken(context.Context, int64, string, []byte, time.Time) error
+	LastVerificationSent(context.Context, int64) (time.Time, error)
+	UseVerificationToken(context.Context, []byte) (int64, string, error)
+}
+
+type Revoker interface {
+	Revoke(context.Context, *jwt.Claims) error
+	RevokeAll(context.Context, int64) error
+	Remember(string, time.Time)
+	Revoked(context.Context, *jwt.Claims) (bool, error)
 }
 
 var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUserExist          = errors.New("user already exist")
 	ErrUserNotFound       = errors.New("user not found")
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrTokenExpired       = errors.New("token expired")
+	ErrTokenReused        = errors.New("refresh token reused")
+	ErrTokenRevoked       = errors.New("token revoked")
+	ErrTOTPEnabled        = errors.New("two-factor authentication already enabled")
+	ErrTOTPNotEnrolled    = errors.New("two-factor authentication not enrolled")
+	ErrInvalidCode        = errors.New("invalid code")
 )
 
-func New(log *slog.Logger, Storage StorageProvider) *Auth {
+func New(
+	log *slog.Logger,
+	Storage StorageProvider,
+	revoker Revoker,
+	mailer mailer.Mailer,
+	policy PasswordPolicy,
+	limiter LoginLimiter,
+	connectors connector.Set,
+	cfg config.Config,
+) *Auth {
+	// bcrypt fails only for passwords over 72 bytes
+	dummyHash, _ := bcrypt.GenerateFromPassword([]byte("dummy password"), cfg.BcryptCost)
+
 	return &Auth{
-		log:     log,
-		Storage: Storage,
+		log:        log,
+		Storage:    Storage,
+		revoker:    revoker,
+		mailer:     mailer,
+		policy:     policy,
+		limiter:    limiter,
+		bcryptCost: cfg.BcryptCost,
+		dummyHash:  dummyHash,
+		refreshTTL: cfg.RefreshTTL,
+		resetTTL:   cfg.ResetTTL,
+		twoFactor:  cfg.TwoFactor,
+		oidc:       cfg.OIDC,
+		connectors: connectors,
+		stateTTL:   cfg.ExternalLogin.StateTTL,
+
+		verification: cfg.EmailVerification,
 	}
 }
 
-func (a *Auth) Login(ctx context.Context, username, password string) (string, error) {
+// Login checks credentials and issues token pair. If user has 2FA enabled,
+// only challenge token is returned, see CompleteLogin. client IP may be
+// empty, then failed attempts are counted only per username.
+func (a *Auth) Login(ctx context.Context, username, password string, client models.Client) (models.LoginResult, error) {
 	const op = "Auth.Login"
 
-	log := a.log.With(slog.String("op", op), slog.String("username", username))
+	clientIP := client.IP
+
+	log := a.log.With(slog.String("op", op), slog.String("username", username), slog.String("ip", clientIP))
 	log.Info("attempting to login user")
 
+	user, challenge, err := a.authenticate(ctx, log, username, password, clientIP)
+	if err != nil {
+		return models.LoginResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if challenge != "" {
+		return models.LoginResult{ChallengeToken: challenge}, nil
+	}
+
+	pair, err := a.newSession(ctx, user, client)
+	if err != nil {
+		log.Error("failed to issue tokens", sl.Err(err))
+		return models.LoginResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return models.LoginResult{Tokens: pair}, nil
+}
+
+// authenticate checks user's password. If user has 2FA enabled, challenge
+// token is returned too and login must be completed with passChallenge.
+// Unknown username and wrong password both give ErrInvalidCredentials,
+// so usernames can't be enumerated.
+func (a *Auth) authenticate(ctx context.Context, log *slog.Logger, username, password, clientIP string) (models.User, string, error) {
+	if err := a.limiter.Check(username, clientIP); err != nil {
+		log.Warn("login locked", sl.Err(err))
+		return models.User{}, "", err
+	}
+
 	user, err := a.Storage.User(ctx, username)
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
-			a.log.Warn("user not found", sl.Err(err))
-			return "", fmt.Errorf("%s: %w", op, ErrUserNotFound)
+			_ = bcrypt.CompareHashAndPassword(a.dummyHash, []byte(password))
+			a.limiter.Fail("", clientIP)
+			log.Info("user not found", sl.Err(err))
+			return models.User{}, "", ErrInvalidCredentials
 		}
 
 		a.log.Error("failed to get user", sl.Err(err))
-		return "", fmt.Errorf("%s: %w", op, err)
+		return models.User{}, "", err
 	}
 
 	if err := bcrypt.CompareHashAndPassword(user.PassHash, []byte(password)); err != nil {
+		a.limiter.Fail(username, clientIP)
 		a.log.Info("invalid credentials", sl.Err(err))
-		return "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+		return models.User{}, "", ErrInvalidCredentials
 	}
 
-	token, err := jwt.NewToken(user)
+	// checked after password, so the state isn't disclosed to others
+	if user.DisabledAt != nil {
+		log.Info("account disabled")
+		return models.User{}, "", ErrUserDisabled
+	}
+
+	a.rehash(ctx, log, user, password)
+
+	challenge, err := a.twoFactorChallenge(ctx, log, user)
+	if err != nil {
+		return models.User{}, "", err
+	}
+	if challenge != "" {
+		// failures counter is reset only when the second step passes,
+		// so codes can't be brute-forced by logging in again
+		return user, challenge, nil
+	}
+
+	a.limiter.Success(username)
+
+	return user, "", nil
+}
+
+// twoFactorChallenge returns challenge token if user has 2FA enabled,
+// empty string otherwise.
+func (a *Auth) twoFactorChallenge(ctx context.Context, log *slog.Logger, user models.User) (string, error) {
+	totp, err := a.Storage.TOTP(ctx, user.UID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return "", nil
+		}
+		log.Error("failed to get totp", sl.Err(err))
+		return "", err
+	}
+	if !totp.Confirmed {
+		return "", nil
+	}
+
+	challenge, err := jwt.NewChallengeToken(user, a.twoFactor.ChallengeTTL)
+	if err != nil {
+		log.Error("failed to generate challenge token", sl.Err(err))
+		return "", err
+	}
+
+	return challenge, nil
+}
+
+// newSession issues token pair starting new refresh token family,
+// which is also the login session.
+func (a *Auth) newSession(ctx context.Context, user models.User, client models.Client) (models.TokenPair, error) {
+	familyID, err := tokens.NewID()
 	if err != nil {
-		a.log.Error("failed to generate token", sl.Err(err))
-		return "", fmt.Errorf("%s: %w", op, err)
+		return models.TokenPair{}, err
 	}
 
-	return token, nil
+	err = a.Storage.SaveSession(ctx, models.Session{
+		ID:        familyID,
+		UID:       user.UID,
+		Client:    client,
+		ExpiresAt: time.Now().Add(a.refreshTTL),
+	})
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	return a.issueTokens(ctx, user, familyID)
 }
 
-func (a *Auth) Register(ctx context.Context, username, password string) (int64, error) {
+// Refresh exchanges refresh token for a new token pair. Every refresh token
+// can be used once; presenting used token again revokes its whole family,
+// since it means that token was stolen by someone.
+func (a *Auth) Refresh(ctx context.Context, refreshToken string, client models.Client) (models.TokenPair, error) {
+	const op = "auth.Refresh"
+
+	log := a.log.With(slog.String("op", op))
+	log.Info("attempting to refresh token")
+
+	token, err := a.Storage.RefreshToken(ctx, tokens.Hash(refreshToken))
+	if err != nil {
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			log.Warn("refresh token not found")
+			return models.TokenPair{}, fmt.Errorf("%s: %w", op, ErrInvalidToken)
+		}
+
+		log.Error("failed to get refresh token", sl.Err(err))
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("UID", token.UID), slog.String("family", token.FamilyID))
+
+	if token.RevokedAt != nil {
+		log.Warn("refresh token revoked")
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, ErrInvalidToken)
+	}
+
+	if token.UsedAt != nil {
+		return models.TokenPair{}, a.revokeReused(ctx, log, op, token.FamilyID)
+	}
+
+	if time.Now().After(token.ExpiresAt) {
+		log.Info("refresh token expired")
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, ErrTokenExpired)
+	}
+
+	ok, err := a.Storage.UseRefreshToken(ctx, token.ID)
+	if err != nil {
+		log.Error("failed to use refresh token", sl.Err(err))
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if !ok {
+		// concurrent refresh with the same token
+		return models.TokenPair{}, a.revokeReused(ctx, log, op, token.FamilyID)
+	}
+
+	user, err := a.Storage.UserByID(ctx, token.UID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("user not found")
+			return models.TokenPair{}, fmt.Errorf("%s: %w", op, ErrInvalidToken)
+		}
+
+		log.Error("failed to get user", sl.Err(err))
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if user.DisabledAt != nil {
+		log.Info("account disabled")
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, ErrUserDisabled)
+	}
+
+	pair, err := a.issueTokens(ctx, user, token.FamilyID)
+	if err != nil {
+		log.Error("failed to issue tokens", sl.Err(err))
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.Storage.ExtendSession(ctx, token.FamilyID, client, time.Now().Add(a.refreshTTL)); err != nil {
+		log.Error("failed to extend session", sl.Err(err))
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pair, nil
+}
+
+func (a *Auth) revokeReused(ctx context.Context, log *slog.Logger, op, familyID string) error {
+	log.Warn("refresh token reused, revoking family")
+
+	revoked, err := a.Storage.RevokeRefreshFamily(ctx, familyID)
+	if err != nil {
+		log.Error("failed to revoke token family", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	a.remember(revoked)
+
+	return fmt.Errorf("%s: %w", op, ErrTokenReused)
+}
+
+func (a *Auth) issueTokens(ctx context.Context, user models.User, familyID string) (models.TokenPair, error) {
+	access, claims, err := jwt.NewToken(user, familyID)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	err = a.Storage.SaveSessionToken(ctx, familyID, claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	refresh, hash, err := tokens.NewOpaque()
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	err = a.Storage.SaveRefreshToken(ctx, models.RefreshToken{
+		UID:       user.UID,
+		FamilyID:  familyID,
+		Hash:      hash,
+		ExpiresAt: time.Now().Add(a.refreshTTL),
+	})
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	return models.TokenPair{AccessToken: access, RefreshToken: refresh}, nil
+}
+
+// Register creates user. Email is optional, it's saved with the user
+// and verification is mailed to it. Registration doesn't fail if it
+// can't be sent, the user can ask to resend it.
+func (a *Auth) Register(ctx context.Context, username, password, email string) (int64, error) {
 	const op = "auth.Register"
 
 	log := a.log.With(slog.String("op", op), slog.String("username", username))
 	log.Info("attempting to register user")
 
-	passHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err := a.policy.Validate(password); err != nil {
+		log.Info("weak password", sl.Err(err))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	email, err := normalizeEmail(email)
+	if err != nil {
+		log.Info("invalid email", sl.Err(err))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	passHash, err := bcrypt.GenerateFromPassword([]byte(password), a.bcryptCost)
 	if err != nil {
 		log.Warn("failed to generate hash", sl.Err(err))
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
-	uid, err := a.Storage.SaveUser(ctx, username, passHash)
+	uid, err := a.Storage.SaveUser(ctx, username, passHash, email)
 	if err != nil {
 		if errors.Is(err, storage.ErrUserExist) {
 			a.log.Warn("user already exist")
@@ -92,26 +460,388 @@ func (a *Auth) Register(ctx context.Context, username, password string) (int64,
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return uid, err
+	if email == "" {
+		return uid, nil
+	}
+
+	log = log.With(slog.Int64("UID", uid))
+
+	if err := a.sendVerification(ctx, uid, email); err != nil {
+		log.Error("failed to send verification", sl.Err(err))
+	}
+
+	return uid, nil
 }
 
-func (a *Auth) Authorize(ctx context.Context, tokenString string) (int64, error) {
-	const op = "auth.Register"
+// Authorize returns owner of the access token with role and email
+// verification the token was issued with.
+func (a *Auth) Authorize(ctx context.Context, tokenString string) (models.Principal, error) {
+	const op = "auth.Authorize"
 
-	log := a.log.With(slog.String("op", op), slog.String("tokenString", tokenString))
+	log := a.log.With(slog.String("op", op))
 	log.Info("attempting to authorize user")
 
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return models.Principal{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.checkSession(ctx, log, claims); err != nil {
+		return models.Principal{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return models.Principal{
+		UID:           claims.UID,
+		Role:          claims.Role,
+		EmailVerified: claims.EmailVerified,
+	}, nil
+}
+
+// checkSession fails if the session token was issued in is revoked.
+// Its tokens are revoked too, but sessions are checked here as well,
+// this also keeps their last seen time.
+func (a *Auth) checkSession(ctx context.Context, log *slog.Logger, claims *jwt.Claims) error {
+	if claims.SessionID == "" {
+		return nil
+	}
+
+	active, err := a.Storage.TouchSession(ctx, claims.SessionID)
+	if err != nil {
+		log.Error("failed to check session", sl.Err(err))
+		return err
+	}
+	if !active {
+		log.Info("session revoked", slog.Int64("UID", claims.UID))
+		return ErrTokenRevoked
+	}
+
+	return nil
+}
+
+// Logout revokes given access token and, if refreshToken is not empty,
+// the refresh token family it belongs to.
+func (a *Auth) Logout(ctx context.Context, tokenString, refreshToken string) error {
+	const op = "auth.Logout"
+
+	log := a.log.With(slog.String("op", op))
+	log.Info("attempting to logout")
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("UID", claims.UID))
+
+	if err := a.revoker.Revoke(ctx, claims); err != nil {
+		log.Error("failed to revoke token", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	token, err := a.Storage.RefreshToken(ctx, tokens.Hash(refreshToken))
+	if err != nil {
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			log.Warn("refresh token not found")
+			return nil
+		}
+
+		log.Error("failed to get refresh token", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if token.UID != claims.UID {
+		log.Warn("refresh token belongs to another user")
+		return nil
+	}
+
+	revoked, err := a.Storage.RevokeRefreshFamily(ctx, token.FamilyID)
+	if err != nil {
+		log.Error("failed to revoke token family", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	a.remember(revoked)
+
+	return nil
+}
+
+// LogoutAll revokes all access and refresh tokens of the token owner
+// issued before now, and all personal access tokens.
+func (a *Auth) LogoutAll(ctx context.Context, tokenString string) error {
+	const op = "auth.LogoutAll"
+
+	log := a.log.With(slog.String("op", op))
+	log.Info("attempting to logout from all sessions")
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("UID", claims.UID))
+
+	if err := a.revoker.RevokeAll(ctx, claims.UID); err != nil {
+		log.Error("failed to revoke tokens", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	revoked, err := a.Storage.RevokeUserRefreshTokens(ctx, claims.UID)
+	if err != nil {
+		log.Error("failed to revoke refresh tokens", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	a.remember(revoked)
+
+	if err := a.Storage.RevokeUserAccessTokens(ctx, claims.UID); err != nil {
+		log.Error("failed to revoke access tokens", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ChangePassword sets new password if old one matches and revokes
+// all sessions of the user, including the current one.
+func (a *Auth) ChangePassword(ctx context.Context, tokenString, oldPassword, newPassword string) error {
+	const op = "auth.ChangePassword"
+
+	log := a.log.With(slog.String("op", op))
+	log.Info("attempting to change password")
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("UID", claims.UID))
+
+	if err := a.policy.Validate(newPassword); err != nil {
+		log.Info("weak password", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, err := a.Storage.UserByID(ctx, claims.UID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("user not found")
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		log.Error("failed to get user", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword(user.PassHash, []byte(oldPassword)); err != nil {
+		log.Info("invalid credentials", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+	}
+
+	if err := a.setPassword(ctx, claims.UID, newPassword); err != nil {
+		log.Error("failed to set password", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RequestPasswordReset mails single-use reset token to the user's
+// verified email. Unknown usernames and users without verified email
+// are not reported, so they can't be enumerated.
+func (a *Auth) RequestPasswordReset(ctx context.Context, username string) error {
+	const op = "auth.RequestPasswordReset"
+
+	log := a.log.With(slog.String("op", op), slog.String("username", username))
+	log.Info("attempting to request password reset")
+
+	user, err := a.Storage.User(ctx, username)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("user not found")
+			return nil
+		}
+
+		log.Error("failed to get user", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !user.EmailVerified() {
+		log.Warn("no verified email")
+		return nil
+	}
+
+	token, hash, err := tokens.NewOpaque()
+	if err != nil {
+		log.Error("failed to generate reset token", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.Storage.SaveResetToken(ctx, user.UID, hash, time.Now().Add(a.resetTTL)); err != nil {
+		log.Error("failed to save reset token", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = a.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Password reset",
+		Body: fmt.Sprintf("Use this token to reset your password: %s\nIt expires in %s.",
+			token, a.resetTTL),
+	})
+	if err != nil {
+		log.Error("failed to send reset token", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets new password using reset token and revokes
+// all sessions of the user.
+func (a *Auth) ResetPassword(ctx context.Context, resetToken, newPassword string) error {
+	const op = "auth.ResetPassword"
+
+	log := a.log.With(slog.String("op", op))
+	log.Info("attempting to reset password")
+
+	// check before the token is used up
+	if err := a.policy.Validate(newPassword); err != nil {
+		log.Info("weak password", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	UID, err := a.Storage.UseResetToken(ctx, tokens.Hash(resetToken))
+	if err != nil {
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			log.Warn("reset token not found, used or expired")
+			return fmt.Errorf("%s: %w", op, ErrInvalidToken)
+		}
+
+		log.Error("failed to use reset token", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.setPassword(ctx, UID, newPassword); err != nil {
+		log.Error("failed to set password", slog.Int64("UID", UID), sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// rehash upgrades hash made with lower cost than configured one.
+// Login doesn't fail if it can't be done.
+func (a *Auth) rehash(ctx context.Context, log *slog.Logger, user models.User, password string) {
+	cost, err := bcrypt.Cost(user.PassHash)
+	if err != nil || cost >= a.bcryptCost {
+		return
+	}
+
+	passHash, err := bcrypt.GenerateFromPassword([]byte(password), a.bcryptCost)
+	if err != nil {
+		log.Warn("failed to rehash password", sl.Err(err))
+		return
+	}
+
+	if err := a.Storage.UpdatePassword(ctx, user.UID, passHash); err != nil {
+		log.Warn("failed to save rehashed password", sl.Err(err))
+		return
+	}
+
+	log.Info("password rehashed", slog.Int("from", cost), slog.Int("to", a.bcryptCost))
+}
+
+// setPassword updates password hash and revokes all user tokens,
+// personal access tokens included.
+func (a *Auth) setPassword(ctx context.Context, UID int64, password string) error {
+	passHash, err := bcrypt.GenerateFromPassword([]byte(password), a.bcryptCost)
+	if err != nil {
+		return err
+	}
+
+	if err := a.Storage.UpdatePassword(ctx, UID, passHash); err != nil {
+		return err
+	}
+
+	if err := a.revoker.RevokeAll(ctx, UID); err != nil {
+		return err
+	}
+
+	revoked, err := a.Storage.RevokeUserRefreshTokens(ctx, UID)
+	if err != nil {
+		return err
+	}
+	a.remember(revoked)
+
+	return a.Storage.RevokeUserAccessTokens(ctx, UID)
+}
+
+// Revocations returns revocations made after since, so other services
+// verifying tokens locally can keep their revocation lists up to date.
+func (a *Auth) Revocations(ctx context.Context, since time.Time) (models.Revocations, error) {
+	const op = "auth.Revocations"
+
+	log := a.log.With(slog.String("op", op), slog.Time("since", since))
+	log.Debug("attempting to list revocations")
+
+	revs, err := a.Storage.Revocations(ctx, since)
+	if err != nil {
+		log.Error("failed to list revocations", sl.Err(err))
+		return models.Revocations{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return revs, nil
+}
+
+// validate checks token and that it was not revoked.
+func (a *Auth) validate(ctx context.Context, log *slog.Logger, tokenString string) (*jwt.Claims, error) {
+	return a.validateWith(ctx, log, tokenString, jwt.Validate)
+}
+
+// validateWith is validate for tokens of other purposes, parse checks
+// the purpose.
+func (a *Auth) validateWith(
+	ctx context.Context,
+	log *slog.Logger,
+	tokenString string,
+	parse func(string) (*jwt.Claims, error),
+) (*jwt.Claims, error) {
 	token := strings.TrimPrefix(tokenString, "Bearer ")
 
-	log.Info("Claimed token:", slog.String("TOKEN", token))
-	uid, err := jwt.Validate(token)
+	claims, err := parse(token)
+	if err != nil {
+		log.Warn("err while validate token", sl.Err(err))
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, ErrInvalidToken
+	}
 
+	revoked, err := a.revoker.Revoked(ctx, claims)
 	if err != nil {
-		a.log.Warn("err while validate token", sl.Err(err))
-		return 0, err
+		log.Error("failed to check token revocation", sl.Err(err))
+		return nil, err
 	}
+	if revoked {
+		log.Info("token revoked", slog.Int64("UID", claims.UID))
+		return nil, ErrTokenRevoked
+	}
+
+	return claims, nil
+}
 
-	fmt.Println("OK")
+// Users returns public info of the users with given IDs.
+// Unknown IDs are skipped.
+func (a *Auth) Users(ctx context.Context, UIDs []int64) ([]models.User, error) {
+	const op = "auth.Users"
 
-	return uid, nil
+	log := a.log.With(slog.String("op", op), slog.Any("UIDs", UIDs))
+	log.Info("attempting to get users")
+
+	users, err := a.Storage.UsersByIDs(ctx, UIDs)
+	if err != nil {
+		log.Error("failed to get users", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return users, nil
 }
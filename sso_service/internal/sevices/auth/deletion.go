@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/liriquew/social-todo/sso_service/internal/lib/tokens"
+	"github.com/liriquew/social-todo/sso_service/internal/models"
+	"github.com/liriquew/social-todo/sso_service/internal/storage"
+
+	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrDeletionNotFound = errors.New("account deletion not found")
+
+// DeleteAccount logs the token owner out everywhere and hides the account
+// right away. The rest of user's data is removed in background by
+// deletion.Worker, returned deletion ID can be used to poll its status.
+func (a *Auth) DeleteAccount(ctx context.Context, tokenString, password string) (models.AccountDeletion, error) {
+	const op = "auth.DeleteAccount"
+
+	log := a.log.With(slog.String("op", op))
+	log.Info("attempting to delete account")
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("UID", claims.UID))
+
+	user, err := a.Storage.UserByID(ctx, claims.UID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("user not found")
+			return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		log.Error("failed to get user", sl.Err(err))
+		return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword(user.PassHash, []byte(password)); err != nil {
+		log.Info("invalid credentials", sl.Err(err))
+		return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+	}
+
+	// sessions are revoked first, so a failure below never leaves
+	// hidden account with valid tokens
+	if err := a.revoker.RevokeAll(ctx, user.UID); err != nil {
+		log.Error("failed to revoke tokens", sl.Err(err))
+		return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, err)
+	}
+	revoked, err := a.Storage.RevokeUserRefreshTokens(ctx, user.UID)
+	if err != nil {
+		log.Error("failed to revoke refresh tokens", sl.Err(err))
+		return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, err)
+	}
+	a.remember(revoked)
+
+	ID, err := tokens.NewID()
+	if err != nil {
+		log.Error("failed to generate deletion ID", sl.Err(err))
+		return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.Storage.StartAccountDeletion(ctx, user.UID, ID); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("user not found")
+			return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		log.Error("failed to start account deletion", sl.Err(err))
+		return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("account deletion started", slog.String("deletion ID", ID))
+
+	return a.AccountDeletion(ctx, ID)
+}
+
+// AccountDeletion returns status of deletion started by DeleteAccount.
+func (a *Auth) AccountDeletion(ctx context.Context, ID string) (models.AccountDeletion, error) {
+	const op = "auth.AccountDeletion"
+
+	log := a.log.With(slog.String("op", op), slog.String("deletion ID", ID))
+
+	d, err := a.Storage.AccountDeletion(ctx, ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, ErrDeletionNotFound)
+		}
+
+		log.Error("failed to get account deletion", sl.Err(err))
+		return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return d, nil
+}
@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/liriquew/social-todo/sso_service/internal/lib/mailer"
+	"github.com/liriquew/social-todo/sso_service/internal/lib/tokens"
+	"github.com/liriquew/social-todo/sso_service/internal/storage"
+
+	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
+)
+
+const maxEmailLen = 254
+
+var (
+	ErrInvalidEmail             = errors.New("invalid email")
+	ErrNoEmail                  = errors.New("email is not set")
+	ErrEmailVerified            = errors.New("email already verified")
+	ErrEmailTaken               = errors.New("email is used by another account")
+	ErrVerificationSentRecently = errors.New("verification was sent recently")
+)
+
+// SetEmail changes email of the token owner and mails verification to
+// the new address. Empty email removes it. Setting the same verified
+// email does nothing. If verified email is changed or removed, access
+// tokens carrying the verification are revoked, refreshed ones carry
+// the new state.
+func (a *Auth) SetEmail(ctx context.Context, tokenString, email string) error {
+	const op = "auth.SetEmail"
+
+	log := a.log.With(slog.String("op", op))
+	log.Info("attempting to set email")
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("UID", claims.UID))
+
+	email, err = normalizeEmail(email)
+	if err != nil {
+		log.Info("invalid email", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, err := a.Storage.UserByID(ctx, claims.UID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("user not found")
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+		log.Error("failed to get user", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if user.Email == email && (email == "" || user.EmailVerified()) {
+		return nil
+	}
+
+	if email != "" {
+		// checked before the change, so addresses can't be spammed by switching them
+		if err := a.verificationAllowed(ctx, claims.UID); err != nil {
+			if !errors.Is(err, ErrVerificationSentRecently) {
+				log.Error("failed to check last verification", sl.Err(err))
+			}
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := a.Storage.SetEmail(ctx, claims.UID, email); err != nil {
+		log.Error("failed to set email", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if user.EmailVerified() {
+		if err := a.revoker.RevokeAll(ctx, claims.UID); err != nil {
+			log.Error("failed to revoke tokens", sl.Err(err))
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if email == "" {
+		return nil
+	}
+
+	if err := a.sendVerification(ctx, claims.UID, email); err != nil {
+		log.Error("failed to send verification", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// SendVerification mails verification of the token owner's email again,
+// no sooner than configured resend interval.
+func (a *Auth) SendVerification(ctx context.Context, tokenString string) error {
+	const op = "auth.SendVerification"
+
+	log := a.log.With(slog.String("op", op))
+	log.Info("attempting to send verification")
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("UID", claims.UID))
+
+	user, err := a.Storage.UserByID(ctx, claims.UID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("user not found")
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+		log.Error("failed to get user", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if user.Email == "" {
+		return fmt.Errorf("%s: %w", op, ErrNoEmail)
+	}
+	if user.EmailVerified() {
+		return fmt.Errorf("%s: %w", op, ErrEmailVerified)
+	}
+
+	if err := a.verificationAllowed(ctx, claims.UID); err != nil {
+		if !errors.Is(err, ErrVerificationSentRecently) {
+			log.Error("failed to check last verification", sl.Err(err))
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.sendVerification(ctx, claims.UID, user.Email); err != nil {
+		log.Error("failed to send verification", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// VerifyEmail marks email verified using the mailed token. The token
+// is useless once the email is changed. Access tokens issued before
+// don't carry the verification, it's granted to refreshed ones.
+func (a *Auth) VerifyEmail(ctx context.Context, verificationToken string) error {
+	const op = "auth.VerifyEmail"
+
+	log := a.log.With(slog.String("op", op))
+	log.Info("attempting to verify email")
+
+	UID, email, err := a.Storage.UseVerificationToken(ctx, tokens.Hash(verificationToken))
+	if err != nil {
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			log.Warn("unknown, used or expired verification token")
+			return fmt.Errorf("%s: %w", op, ErrInvalidToken)
+		}
+		log.Error("failed to use verification token", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("UID", UID))
+
+	if err := a.Storage.VerifyEmail(ctx, UID, email); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Info("email changed or already verified")
+			return fmt.Errorf("%s: %w", op, ErrInvalidToken)
+		}
+		if errors.Is(err, storage.ErrEmailTaken) {
+			log.Info("email verified by another account")
+			return fmt.Errorf("%s: %w", op, ErrEmailTaken)
+		}
+		log.Error("failed to verify email", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Email returns email of the token owner and whether it's verified.
+func (a *Auth) Email(ctx context.Context, tokenString string) (string, bool, error) {
+	const op = "auth.Email"
+
+	log := a.log.With(slog.String("op", op))
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return "", false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, err := a.Storage.UserByID(ctx, claims.UID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("user not found", slog.Int64("UID", claims.UID))
+			return "", false, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+		log.Error("failed to get user", sl.Err(err))
+		return "", false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return user.Email, user.EmailVerified(), nil
+}
+
+func (a *Auth) verificationAllowed(ctx context.Context, UID int64) error {
+	sentAt, err := a.Storage.LastVerificationSent(ctx, UID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if time.Since(sentAt) < a.verification.ResendInterval {
+		return ErrVerificationSentRecently
+	}
+	return nil
+}
+
+func (a *Auth) sendVerification(ctx context.Context, UID int64, email string) error {
+	token, hash, err := tokens.NewOpaque()
+	if err != nil {
+		return err
+	}
+
+	err = a.Storage.SaveVerificationToken(ctx, UID, email, hash, time.Now().Add(a.verification.TokenTTL))
+	if err != nil {
+		return err
+	}
+
+	return a.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Email verification",
+		Body: fmt.Sprintf("Use this token to verify your email: %s\nIt expires in %s.",
+			token, a.verification.TokenTTL),
+	})
+}
+
+// normalizeEmail trims the address and checks it's a bare address,
+// without display name.
+func normalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return "", nil
+	}
+	if len(email) > maxEmailLen {
+		return "", ErrInvalidEmail
+	}
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", ErrInvalidEmail
+	}
+
+	return email, nil
+}
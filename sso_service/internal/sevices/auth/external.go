@@ -0,0 +1,318 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/liriquew/social-todo/sso_service/internal/connector"
+	"github.com/liriquew/social-todo/sso_service/internal/lib/tokens"
+	"github.com/liriquew/social-todo/sso_service/internal/models"
+	"github.com/liriquew/social-todo/sso_service/internal/storage"
+
+	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
+)
+
+var (
+	ErrConnectorNotFound = errors.New("connector not found")
+	ErrInvalidState      = errors.New("invalid or expired state")
+	ErrExternalAuth      = errors.New("external authentication failed")
+	ErrIdentityNotLinked = errors.New("identity is not linked to any account")
+	ErrIdentityExist     = errors.New("identity already linked")
+	ErrIdentityNotFound  = errors.New("identity not found")
+)
+
+// Connectors returns external identity providers users can sign in with.
+func (a *Auth) Connectors() []models.Connector {
+	res := make([]models.Connector, 0, len(a.connectors))
+	for _, c := range a.connectors.Sorted() {
+		res = append(res, models.Connector{ID: c.ID(), Name: c.Name()})
+	}
+	return res
+}
+
+// StartExternalLogin returns URL of provider's page to send the user to.
+// Provider redirects the user back with code and state, which are passed
+// to ExternalLogin.
+func (a *Auth) StartExternalLogin(ctx context.Context, connectorID string) (string, error) {
+	const op = "auth.StartExternalLogin"
+
+	log := a.log.With(slog.String("op", op), slog.String("connector", connectorID))
+
+	conn, err := a.connector(log, connectorID)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	authURL, err := a.startExternal(ctx, conn, 0)
+	if err != nil {
+		log.Error("failed to save state", sl.Err(err))
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return authURL, nil
+}
+
+// ExternalLogin signs in the user the external identity is linked to.
+// Like Login, only challenge token is returned if user has 2FA enabled.
+// Identities are never linked implicitly, see LinkIdentity.
+func (a *Auth) ExternalLogin(
+	ctx context.Context,
+	connectorID, state, code string,
+	client models.Client,
+) (models.LoginResult, error) {
+	const op = "auth.ExternalLogin"
+
+	log := a.log.With(slog.String("op", op), slog.String("connector", connectorID), slog.String("ip", client.IP))
+	log.Info("attempting to login user")
+
+	conn, err := a.connector(log, connectorID)
+	if err != nil {
+		return models.LoginResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ext, err := a.finishExternal(ctx, log, conn, state, code, 0)
+	if err != nil {
+		return models.LoginResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.String("subject", ext.Subject))
+
+	identity, err := a.Storage.LoginIdentity(ctx, models.Identity{
+		ConnectorID: conn.ID(),
+		Subject:     ext.Subject,
+		Username:    ext.Username,
+		Email:       ext.Email,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Info("identity is not linked")
+			return models.LoginResult{}, fmt.Errorf("%s: %w", op, ErrIdentityNotLinked)
+		}
+		log.Error("failed to get identity", sl.Err(err))
+		return models.LoginResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("UID", identity.UID))
+
+	user, err := a.Storage.UserByID(ctx, identity.UID)
+	if err != nil {
+		log.Error("failed to get user", sl.Err(err))
+		return models.LoginResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if user.DisabledAt != nil {
+		log.Info("account disabled")
+		return models.LoginResult{}, fmt.Errorf("%s: %w", op, ErrUserDisabled)
+	}
+
+	challenge, err := a.twoFactorChallenge(ctx, log, user)
+	if err != nil {
+		return models.LoginResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if challenge != "" {
+		return models.LoginResult{ChallengeToken: challenge}, nil
+	}
+
+	pair, err := a.newSession(ctx, user, client)
+	if err != nil {
+		log.Error("failed to issue tokens", sl.Err(err))
+		return models.LoginResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return models.LoginResult{Tokens: pair}, nil
+}
+
+// StartLinkIdentity is StartExternalLogin for the token owner linking
+// external identity, code and state are passed to LinkIdentity.
+func (a *Auth) StartLinkIdentity(ctx context.Context, tokenString, connectorID string) (string, error) {
+	const op = "auth.StartLinkIdentity"
+
+	log := a.log.With(slog.String("op", op), slog.String("connector", connectorID))
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	conn, err := a.connector(log, connectorID)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	authURL, err := a.startExternal(ctx, conn, claims.UID)
+	if err != nil {
+		log.Error("failed to save state", sl.Err(err))
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return authURL, nil
+}
+
+// LinkIdentity links external identity to the token owner's account.
+// State must be issued by StartLinkIdentity for the same user.
+func (a *Auth) LinkIdentity(ctx context.Context, tokenString, connectorID, state, code string) (models.Identity, error) {
+	const op = "auth.LinkIdentity"
+
+	log := a.log.With(slog.String("op", op), slog.String("connector", connectorID))
+	log.Info("attempting to link identity")
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return models.Identity{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("UID", claims.UID))
+
+	conn, err := a.connector(log, connectorID)
+	if err != nil {
+		return models.Identity{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ext, err := a.finishExternal(ctx, log, conn, state, code, claims.UID)
+	if err != nil {
+		return models.Identity{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	identity, err := a.Storage.SaveIdentity(ctx, models.Identity{
+		UID:         claims.UID,
+		ConnectorID: conn.ID(),
+		Subject:     ext.Subject,
+		Username:    ext.Username,
+		Email:       ext.Email,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrIdentityExist) {
+			log.Info("identity already linked", slog.String("subject", ext.Subject))
+			return models.Identity{}, fmt.Errorf("%s: %w", op, ErrIdentityExist)
+		}
+		log.Error("failed to save identity", sl.Err(err))
+		return models.Identity{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return identity, nil
+}
+
+// UnlinkIdentity removes token owner's identity of the provider. It can
+// be done for connectors removed from config too.
+func (a *Auth) UnlinkIdentity(ctx context.Context, tokenString, connectorID string) error {
+	const op = "auth.UnlinkIdentity"
+
+	log := a.log.With(slog.String("op", op), slog.String("connector", connectorID))
+	log.Info("attempting to unlink identity")
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("UID", claims.UID))
+
+	if err := a.Storage.DeleteIdentity(ctx, claims.UID, connectorID); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Info("identity not found")
+			return fmt.Errorf("%s: %w", op, ErrIdentityNotFound)
+		}
+		log.Error("failed to delete identity", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Identities returns external identities linked to the token owner.
+func (a *Auth) Identities(ctx context.Context, tokenString string) ([]models.Identity, error) {
+	const op = "auth.Identities"
+
+	log := a.log.With(slog.String("op", op))
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	identities, err := a.Storage.Identities(ctx, claims.UID)
+	if err != nil {
+		log.Error("failed to list identities", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return identities, nil
+}
+
+func (a *Auth) connector(log *slog.Logger, ID string) (connector.Connector, error) {
+	conn, ok := a.connectors[ID]
+	if !ok {
+		log.Info("unknown connector")
+		return nil, ErrConnectorNotFound
+	}
+	return conn, nil
+}
+
+// startExternal saves state with PKCE verifier, only the state leaves
+// the service. UID is set when identity is linked.
+func (a *Auth) startExternal(ctx context.Context, conn connector.Connector, UID int64) (string, error) {
+	state, hash, err := tokens.NewOpaque()
+	if err != nil {
+		return "", err
+	}
+	// 43 characters, the shortest verifier allowed
+	verifier, _, err := tokens.NewOpaque()
+	if err != nil {
+		return "", err
+	}
+
+	err = a.Storage.SaveExternalAuthState(ctx, models.ExternalAuthState{
+		Hash:         hash,
+		ConnectorID:  conn.ID(),
+		UID:          UID,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(a.stateTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return conn.AuthURL(state, codeChallenge(verifier)), nil
+}
+
+// finishExternal uses the state and exchanges code for user's identity.
+// State must be issued for the connector and the user, zero UID for login.
+func (a *Auth) finishExternal(
+	ctx context.Context,
+	log *slog.Logger,
+	conn connector.Connector,
+	state, code string,
+	UID int64,
+) (connector.Identity, error) {
+	if state == "" || code == "" {
+		return connector.Identity{}, ErrInvalidState
+	}
+
+	saved, err := a.Storage.UseExternalAuthState(ctx, tokens.Hash(state))
+	if err != nil {
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			log.Warn("unknown, used or expired state")
+			return connector.Identity{}, ErrInvalidState
+		}
+		log.Error("failed to use state", sl.Err(err))
+		return connector.Identity{}, err
+	}
+	if saved.ConnectorID != conn.ID() || saved.UID != UID {
+		log.Warn("state issued for another connector or user")
+		return connector.Identity{}, ErrInvalidState
+	}
+
+	identity, err := conn.Exchange(ctx, code, saved.CodeVerifier)
+	if err != nil {
+		if errors.Is(err, connector.ErrAuthFailed) {
+			log.Warn("provider rejected code", sl.Err(err))
+			return connector.Identity{}, ErrExternalAuth
+		}
+		log.Error("failed to exchange code", sl.Err(err))
+		return connector.Identity{}, err
+	}
+
+	return identity, nil
+}
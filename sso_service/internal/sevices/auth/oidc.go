@@ -0,0 +1,513 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/liriquew/social-todo/sso_service/internal/lib/jwt"
+	"github.com/liriquew/social-todo/sso_service/internal/lib/tokens"
+	"github.com/liriquew/social-todo/sso_service/internal/models"
+	"github.com/liriquew/social-todo/sso_service/internal/storage"
+
+	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+)
+
+// OIDCScopes lists scopes the provider supports, others are ignored.
+var OIDCScopes = []string{ScopeOpenID, ScopeProfile}
+
+const (
+	maxOIDCClientNameLen   = 64
+	maxOIDCRedirectURIs    = 10
+	maxOIDCRedirectURILen  = 512
+	minCodeVerifierLen     = 43
+	maxCodeVerifierLen     = 128
+	codeChallengeEncodeLen = 43
+)
+
+var (
+	ErrClientNotFound     = errors.New("oidc client not found")
+	ErrInvalidRedirectURI = errors.New("redirect uri is not registered for the client")
+	ErrInvalidClient      = errors.New("invalid client credentials")
+	ErrInvalidGrant       = errors.New("invalid authorization code")
+	ErrInvalidOIDCClient  = errors.New("invalid oidc client")
+)
+
+// OIDCClientError tells which field of client registration was rejected.
+type OIDCClientError struct {
+	Field  string
+	Reason string
+}
+
+func (e *OIDCClientError) Error() string {
+	return fmt.Sprintf("%s: %s %s", ErrInvalidOIDCClient, e.Field, e.Reason)
+}
+
+func (e *OIDCClientError) Is(target error) bool {
+	return target == ErrInvalidOIDCClient
+}
+
+// CheckAuthRequest checks the client is registered with the redirect URI.
+// Until it passes, errors must not be sent to the redirect URI.
+func (a *Auth) CheckAuthRequest(ctx context.Context, req models.OIDCAuthRequest) error {
+	const op = "auth.CheckAuthRequest"
+
+	log := a.log.With(slog.String("op", op), slog.String("client", req.ClientID))
+
+	client, err := a.Storage.OIDCClient(ctx, req.ClientID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Info("unknown client")
+			return fmt.Errorf("%s: %w", op, ErrClientNotFound)
+		}
+		log.Error("failed to get client", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// exact match only, no prefixes or wildcards
+	if !slices.Contains(client.RedirectURIs, req.RedirectURI) {
+		log.Info("unregistered redirect uri", slog.String("redirect_uri", req.RedirectURI))
+		return fmt.Errorf("%s: %w", op, ErrInvalidRedirectURI)
+	}
+
+	return nil
+}
+
+// OIDCLogin checks user's credentials on the provider login page and issues
+// authorization code. If user has 2FA enabled, challenge token is returned
+// instead, see OIDCCompleteLogin.
+func (a *Auth) OIDCLogin(
+	ctx context.Context,
+	req models.OIDCAuthRequest,
+	username, password string,
+	client models.Client,
+) (models.OIDCLoginResult, error) {
+	const op = "auth.OIDCLogin"
+
+	log := a.log.With(slog.String("op", op), slog.String("client", req.ClientID),
+		slog.String("username", username), slog.String("ip", client.IP))
+	log.Info("attempting to login user")
+
+	if err := a.CheckAuthRequest(ctx, req); err != nil {
+		return models.OIDCLoginResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, challenge, err := a.authenticate(ctx, log, username, password, client.IP)
+	if err != nil {
+		return models.OIDCLoginResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if challenge != "" {
+		return models.OIDCLoginResult{ChallengeToken: challenge}, nil
+	}
+
+	code, err := a.issueCode(ctx, req, user, client)
+	if err != nil {
+		log.Error("failed to issue code", sl.Err(err))
+		return models.OIDCLoginResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return models.OIDCLoginResult{Code: code}, nil
+}
+
+// OIDCCompleteLogin checks second factor code and issues authorization code.
+func (a *Auth) OIDCCompleteLogin(
+	ctx context.Context,
+	req models.OIDCAuthRequest,
+	challengeToken, code string,
+	client models.Client,
+) (string, error) {
+	const op = "auth.OIDCCompleteLogin"
+
+	log := a.log.With(slog.String("op", op), slog.String("client", req.ClientID), slog.String("ip", client.IP))
+	log.Info("attempting to complete login")
+
+	if err := a.CheckAuthRequest(ctx, req); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, err := a.passChallenge(ctx, log, challengeToken, code, client.IP)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	authCode, err := a.issueCode(ctx, req, user, client)
+	if err != nil {
+		log.Error("failed to issue code", sl.Err(err))
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return authCode, nil
+}
+
+func (a *Auth) issueCode(ctx context.Context, req models.OIDCAuthRequest, user models.User, client models.Client) (string, error) {
+	code, hash, err := tokens.NewOpaque()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	err = a.Storage.SaveOIDCCode(ctx, models.OIDCCode{
+		Hash:          hash,
+		UID:           user.UID,
+		ClientID:      req.ClientID,
+		RedirectURI:   req.RedirectURI,
+		Scope:         req.Scope,
+		Nonce:         req.Nonce,
+		CodeChallenge: req.CodeChallenge,
+		Client:        client,
+		AuthTime:      now,
+		ExpiresAt:     now.Add(a.oidc.CodeTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// ExchangeOIDCCode authenticates the client and exchanges authorization
+// code for access and ID tokens. Access token is accepted only by the
+// userinfo endpoint, it starts a login session shown among user's
+// sessions. No refresh token is issued, tools sign the user in again
+// when access token expires.
+func (a *Auth) ExchangeOIDCCode(ctx context.Context, req models.OIDCTokenRequest) (models.OIDCTokens, error) {
+	const op = "auth.ExchangeOIDCCode"
+
+	log := a.log.With(slog.String("op", op), slog.String("client", req.ClientID))
+	log.Info("attempting to exchange code")
+
+	client, err := a.Storage.OIDCClient(ctx, req.ClientID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Info("unknown client")
+			return models.OIDCTokens{}, fmt.Errorf("%s: %w", op, ErrInvalidClient)
+		}
+		log.Error("failed to get client", sl.Err(err))
+		return models.OIDCTokens{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if !client.Public() && subtle.ConstantTimeCompare(client.SecretHash, tokens.Hash(req.ClientSecret)) != 1 {
+		log.Warn("invalid client secret")
+		return models.OIDCTokens{}, fmt.Errorf("%s: %w", op, ErrInvalidClient)
+	}
+
+	// code of another client is left for its owner
+	code, err := a.Storage.UseOIDCCode(ctx, tokens.Hash(req.Code), client.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			log.Warn("unknown, used, expired or another client's code")
+			return models.OIDCTokens{}, fmt.Errorf("%s: %w", op, ErrInvalidGrant)
+		}
+		log.Error("failed to use code", sl.Err(err))
+		return models.OIDCTokens{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("UID", code.UID))
+
+	if code.RedirectURI != req.RedirectURI {
+		log.Warn("code issued for another redirect uri")
+		return models.OIDCTokens{}, fmt.Errorf("%s: %w", op, ErrInvalidGrant)
+	}
+	if !verifyCodeChallenge(code.CodeChallenge, req.CodeVerifier) {
+		log.Warn("code verifier doesn't match")
+		return models.OIDCTokens{}, fmt.Errorf("%s: %w", op, ErrInvalidGrant)
+	}
+
+	user, err := a.Storage.UserByID(ctx, code.UID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("user not found")
+			return models.OIDCTokens{}, fmt.Errorf("%s: %w", op, ErrInvalidGrant)
+		}
+		log.Error("failed to get user", sl.Err(err))
+		return models.OIDCTokens{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if user.DisabledAt != nil {
+		log.Info("account disabled")
+		return models.OIDCTokens{}, fmt.Errorf("%s: %w", op, ErrInvalidGrant)
+	}
+
+	access, err := a.newAccessSession(ctx, user, code.Client)
+	if err != nil {
+		log.Error("failed to issue access token", sl.Err(err))
+		return models.OIDCTokens{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	claims := jwt.IDClaims{
+		RegisteredClaims: gojwt.RegisteredClaims{
+			Issuer:   strings.TrimSuffix(a.oidc.Issuer, "/"),
+			Subject:  strconv.FormatInt(user.UID, 10),
+			Audience: gojwt.ClaimStrings{client.ID},
+		},
+		Nonce:           code.Nonce,
+		AuthTime:        gojwt.NewNumericDate(code.AuthTime),
+		AuthorizedParty: client.ID,
+	}
+	if hasScope(code.Scope, ScopeProfile) {
+		profile, err := a.Storage.Profile(ctx, user.UID)
+		if err != nil {
+			log.Error("failed to get profile", sl.Err(err))
+			return models.OIDCTokens{}, fmt.Errorf("%s: %w", op, err)
+		}
+		claims.PreferredUsername = profile.Username
+		claims.Name = profile.DisplayName
+		claims.Picture = profile.AvatarURL
+	}
+
+	idToken, err := jwt.NewIDToken(claims, a.oidc.IDTokenTTL)
+	if err != nil {
+		log.Error("failed to sign id token", sl.Err(err))
+		return models.OIDCTokens{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("user signed in with oidc")
+
+	return models.OIDCTokens{
+		AccessToken: access,
+		IDToken:     idToken,
+		Scope:       code.Scope,
+		ExpiresIn:   jwt.TTL,
+	}, nil
+}
+
+// newAccessSession starts login session with userinfo token only,
+// the session ends with the token.
+func (a *Auth) newAccessSession(ctx context.Context, user models.User, client models.Client) (string, error) {
+	sessionID, err := tokens.NewID()
+	if err != nil {
+		return "", err
+	}
+
+	access, claims, err := jwt.NewUserInfoToken(user, sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	err = a.Storage.SaveSession(ctx, models.Session{
+		ID:        sessionID,
+		UID:       user.UID,
+		Client:    client,
+		ExpiresAt: claims.ExpiresAt.Time,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if err := a.Storage.SaveSessionToken(ctx, sessionID, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return "", err
+	}
+
+	return access, nil
+}
+
+// UserInfo returns profile of the owner of access token issued to OIDC
+// client, for the userinfo endpoint.
+func (a *Auth) UserInfo(ctx context.Context, tokenString string) (models.Profile, error) {
+	const op = "auth.UserInfo"
+
+	log := a.log.With(slog.String("op", op))
+
+	claims, err := a.validateWith(ctx, log, tokenString, jwt.ValidateUserInfo)
+	if err != nil {
+		return models.Profile{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.checkSession(ctx, log, claims); err != nil {
+		return models.Profile{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	profile, err := a.Profile(ctx, claims.UID)
+	if err != nil {
+		return models.Profile{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return profile, nil
+}
+
+// CreateOIDCClient registers client application. Public clients, e.g.
+// single page apps, get no secret. Secret is returned only here.
+func (a *Auth) CreateOIDCClient(
+	ctx context.Context,
+	tokenString, name string,
+	redirectURIs []string,
+	public bool,
+) (models.OIDCClient, string, error) {
+	const op = "auth.CreateOIDCClient"
+
+	log := a.log.With(slog.String("op", op), slog.String("name", name))
+	log.Info("attempting to create oidc client")
+
+	claims, err := a.requireAdmin(ctx, log, tokenString)
+	if err != nil {
+		return models.OIDCClient{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	name = strings.TrimSpace(name)
+	if err := validateOIDCClient(name, redirectURIs); err != nil {
+		log.Info("invalid oidc client", sl.Err(err))
+		return models.OIDCClient{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	ID, err := tokens.NewID()
+	if err != nil {
+		log.Error("failed to generate client id", sl.Err(err))
+		return models.OIDCClient{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	client := models.OIDCClient{
+		ID:           ID,
+		Name:         name,
+		RedirectURIs: redirectURIs,
+	}
+
+	var secret string
+	if !public {
+		secret, client.SecretHash, err = tokens.NewOpaque()
+		if err != nil {
+			log.Error("failed to generate client secret", sl.Err(err))
+			return models.OIDCClient{}, "", fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	client, err = a.Storage.SaveOIDCClient(ctx, client)
+	a.audit(ctx, claims.UID, "oidc_client.create", "oidc_client:"+ID, name, err)
+	if err != nil {
+		log.Error("failed to save oidc client", sl.Err(err))
+		return models.OIDCClient{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return client, secret, nil
+}
+
+func (a *Auth) OIDCClients(ctx context.Context, tokenString string) ([]models.OIDCClient, error) {
+	const op = "auth.OIDCClients"
+
+	log := a.log.With(slog.String("op", op))
+
+	if _, err := a.requireAdmin(ctx, log, tokenString); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	clients, err := a.Storage.OIDCClients(ctx)
+	if err != nil {
+		log.Error("failed to list oidc clients", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return clients, nil
+}
+
+// DeleteOIDCClient removes client, tokens issued to it stay valid until
+// they expire or their sessions are revoked.
+func (a *Auth) DeleteOIDCClient(ctx context.Context, tokenString, ID string) error {
+	const op = "auth.DeleteOIDCClient"
+
+	log := a.log.With(slog.String("op", op), slog.String("client", ID))
+	log.Info("attempting to delete oidc client")
+
+	claims, err := a.requireAdmin(ctx, log, tokenString)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = a.Storage.DeleteOIDCClient(ctx, ID)
+	if errors.Is(err, storage.ErrNotFound) {
+		err = ErrClientNotFound
+	}
+	a.audit(ctx, claims.UID, "oidc_client.delete", "oidc_client:"+ID, "", err)
+	if err != nil {
+		if !errors.Is(err, ErrClientNotFound) {
+			log.Error("failed to delete oidc client", sl.Err(err))
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func validateOIDCClient(name string, redirectURIs []string) error {
+	if name == "" || utf8.RuneCountInString(name) > maxOIDCClientNameLen {
+		return &OIDCClientError{
+			Field:  "name",
+			Reason: fmt.Sprintf("must be from 1 to %d characters", maxOIDCClientNameLen),
+		}
+	}
+
+	if len(redirectURIs) == 0 || len(redirectURIs) > maxOIDCRedirectURIs {
+		return &OIDCClientError{
+			Field:  "redirect_uris",
+			Reason: fmt.Sprintf("must have from 1 to %d uris", maxOIDCRedirectURIs),
+		}
+	}
+	for _, uri := range redirectURIs {
+		if len(uri) > maxOIDCRedirectURILen || !validRedirectURI(uri) {
+			return &OIDCClientError{
+				Field:  "redirect_uris",
+				Reason: fmt.Sprintf("%q must be absolute https url, or http for localhost, without fragment", uri),
+			}
+		}
+	}
+
+	return nil
+}
+
+func validRedirectURI(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" || u.Fragment != "" || u.User != nil {
+		return false
+	}
+
+	switch u.Scheme {
+	case "https":
+		return true
+	case "http":
+		host := u.Hostname()
+		return host == "localhost" || host == "127.0.0.1" || host == "::1"
+	}
+	return false
+}
+
+// ValidCodeChallenge checks S256 code challenge format.
+func ValidCodeChallenge(challenge string) bool {
+	if len(challenge) != codeChallengeEncodeLen {
+		return false
+	}
+	_, err := base64.RawURLEncoding.DecodeString(challenge)
+	return err == nil
+}
+
+func verifyCodeChallenge(challenge, verifier string) bool {
+	if len(verifier) < minCodeVerifierLen || len(verifier) > maxCodeVerifierLen {
+		return false
+	}
+
+	expected := codeChallenge(verifier)
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// codeChallenge is S256 PKCE challenge of the verifier.
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func hasScope(scope, want string) bool {
+	return slices.Contains(strings.Fields(scope), want)
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/liriquew/social-todo/sso_service/internal/models"
+	"github.com/liriquew/social-todo/sso_service/internal/storage"
+
+	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
+)
+
+const (
+	maxDisplayNameLen = 64
+	maxBioLen         = 500
+	maxAvatarURLLen   = 2048
+)
+
+var ErrInvalidProfile = errors.New("invalid profile")
+
+// ProfileError tells which profile field was rejected and why.
+type ProfileError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ProfileError) Error() string {
+	return fmt.Sprintf("%s: %s %s", ErrInvalidProfile, e.Field, e.Reason)
+}
+
+func (e *ProfileError) Is(target error) bool {
+	return target == ErrInvalidProfile
+}
+
+func (a *Auth) Profile(ctx context.Context, UID int64) (models.Profile, error) {
+	const op = "auth.Profile"
+
+	log := a.log.With(slog.String("op", op), slog.Int64("UID", UID))
+	log.Info("attempting to get profile")
+
+	profile, err := a.Storage.Profile(ctx, UID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("user not found")
+			return models.Profile{}, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		log.Error("failed to get profile", sl.Err(err))
+		return models.Profile{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return profile, nil
+}
+
+// Profiles returns profiles of the users with given IDs.
+// Unknown IDs are skipped.
+func (a *Auth) Profiles(ctx context.Context, UIDs []int64) ([]models.Profile, error) {
+	const op = "auth.Profiles"
+
+	log := a.log.With(slog.String("op", op), slog.Any("UIDs", UIDs))
+	log.Info("attempting to get profiles")
+
+	profiles, err := a.Storage.ProfilesByIDs(ctx, UIDs)
+	if err != nil {
+		log.Error("failed to get profiles", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return profiles, nil
+}
+
+// UpdateProfile changes profile of the token owner and returns updated one.
+func (a *Auth) UpdateProfile(ctx context.Context, tokenString string, update models.ProfileUpdate) (models.Profile, error) {
+	const op = "auth.UpdateProfile"
+
+	log := a.log.With(slog.String("op", op))
+	log.Info("attempting to update profile")
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return models.Profile{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("UID", claims.UID))
+
+	if err := normalizeProfile(&update); err != nil {
+		log.Info("invalid profile", sl.Err(err))
+		return models.Profile{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.Storage.UpdateProfile(ctx, claims.UID, update); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("user not found")
+			return models.Profile{}, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		log.Error("failed to update profile", sl.Err(err))
+		return models.Profile{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("profile updated")
+
+	return a.Profile(ctx, claims.UID)
+}
+
+// normalizeProfile trims given fields and checks their limits.
+func normalizeProfile(update *models.ProfileUpdate) error {
+	if update.DisplayName != nil {
+		name := strings.TrimSpace(*update.DisplayName)
+		if utf8.RuneCountInString(name) > maxDisplayNameLen {
+			return &ProfileError{"display_name", fmt.Sprintf("is longer than %d characters", maxDisplayNameLen)}
+		}
+		if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+			return &ProfileError{"display_name", "contains control characters"}
+		}
+		update.DisplayName = &name
+	}
+
+	if update.Bio != nil {
+		bio := strings.TrimSpace(*update.Bio)
+		if utf8.RuneCountInString(bio) > maxBioLen {
+			return &ProfileError{"bio", fmt.Sprintf("is longer than %d characters", maxBioLen)}
+		}
+		update.Bio = &bio
+	}
+
+	if update.AvatarURL != nil {
+		avatar := strings.TrimSpace(*update.AvatarURL)
+		// empty URL removes avatar
+		if avatar != "" {
+			if len(avatar) > maxAvatarURLLen {
+				return &ProfileError{"avatar_url", "is too long"}
+			}
+			u, err := url.Parse(avatar)
+			if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				return &ProfileError{"avatar_url", "must be absolute http(s) url"}
+			}
+		}
+		update.AvatarURL = &avatar
+	}
+
+	return nil
+}
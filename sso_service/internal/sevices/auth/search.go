@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/liriquew/social-todo/sso_service/internal/models"
+	"github.com/liriquew/social-todo/sso_service/internal/storage"
+
+	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrBlockSelf     = errors.New("can't block yourself")
+)
+
+// SearchUsers returns page of users matching query for the token owner
+// and cursor of the next page, empty if there is none.
+func (a *Auth) SearchUsers(ctx context.Context, tokenString, query, cursor string, limit int64) ([]models.User, string, error) {
+	const op = "auth.SearchUsers"
+
+	log := a.log.With(slog.String("op", op))
+	log.Info("attempting to search users")
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	offset, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	// one more row tells if there is next page
+	users, err := a.Storage.SearchUsers(ctx, claims.UID, strings.TrimSpace(query), offset, limit+1)
+	if err != nil {
+		log.Error("failed to search users", sl.Err(err))
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	var next string
+	if int64(len(users)) > limit {
+		users = users[:limit]
+		next = encodeCursor(offset + limit)
+	}
+
+	return users, next, nil
+}
+
+// LookupUser resolves exact username for the token owner.
+// Users blocked either way are reported as not found.
+func (a *Auth) LookupUser(ctx context.Context, tokenString, username string) (models.User, error) {
+	const op = "auth.LookupUser"
+
+	log := a.log.With(slog.String("op", op), slog.String("username", username))
+	log.Info("attempting to lookup user")
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, err := a.Storage.VisibleUser(ctx, claims.UID, username)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Info("user not found")
+			return models.User{}, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		log.Error("failed to get user", sl.Err(err))
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return user, nil
+}
+
+// BlockUser hides users from each other in search and lookup.
+func (a *Auth) BlockUser(ctx context.Context, tokenString string, UID int64) error {
+	const op = "auth.BlockUser"
+
+	log := a.log.With(slog.String("op", op), slog.Int64("blocked UID", UID))
+	log.Info("attempting to block user")
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if claims.UID == UID {
+		return fmt.Errorf("%s: %w", op, ErrBlockSelf)
+	}
+
+	if err := a.Storage.BlockUser(ctx, claims.UID, UID); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("user not found")
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		log.Error("failed to block user", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (a *Auth) UnblockUser(ctx context.Context, tokenString string, UID int64) error {
+	const op = "auth.UnblockUser"
+
+	log := a.log.With(slog.String("op", op), slog.Int64("blocked UID", UID))
+	log.Info("attempting to unblock user")
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.Storage.UnblockUser(ctx, claims.UID, UID); err != nil {
+		log.Error("failed to unblock user", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func encodeCursor(offset int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(offset, 10)))
+}
+
+func decodeCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	offset, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil || offset < 0 {
+		return 0, ErrInvalidCursor
+	}
+
+	return offset, nil
+}
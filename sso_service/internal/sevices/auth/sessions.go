@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/liriquew/social-todo/sso_service/internal/models"
+	"github.com/liriquew/social-todo/sso_service/internal/storage"
+
+	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// Sessions returns active sessions of the token owner and ID of
+// the session the token belongs to.
+func (a *Auth) Sessions(ctx context.Context, tokenString string) ([]models.Session, string, error) {
+	const op = "auth.Sessions"
+
+	log := a.log.With(slog.String("op", op))
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	sessions, err := a.Storage.Sessions(ctx, claims.UID)
+	if err != nil {
+		log.Error("failed to list sessions", sl.Err(err))
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sessions, claims.SessionID, nil
+}
+
+// RevokeSession ends session of the token owner. Its refresh tokens
+// can't be used anymore and access tokens issued in it are revoked,
+// all in one transaction.
+func (a *Auth) RevokeSession(ctx context.Context, tokenString, ID string) error {
+	const op = "auth.RevokeSession"
+
+	log := a.log.With(slog.String("op", op), slog.String("session", ID))
+	log.Info("attempting to revoke session")
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("UID", claims.UID))
+
+	revoked, err := a.Storage.RevokeSession(ctx, claims.UID, ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Info("session not found")
+			return fmt.Errorf("%s: %w", op, ErrSessionNotFound)
+		}
+
+		log.Error("failed to revoke session", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.remember(revoked)
+
+	return nil
+}
+
+// remember adds tokens revoked in storage to the revoker cache, so they
+// are rejected without a lookup.
+func (a *Auth) remember(revoked []models.RevokedToken) {
+	for _, token := range revoked {
+		a.revoker.Remember(token.JTI, token.ExpiresAt)
+	}
+}
@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/liriquew/social-todo/sso_service/internal/lib/jwt"
+	"github.com/liriquew/social-todo/sso_service/internal/lib/tokens"
+	"github.com/liriquew/social-todo/sso_service/internal/lib/totp"
+	"github.com/liriquew/social-todo/sso_service/internal/models"
+	"github.com/liriquew/social-todo/sso_service/internal/storage"
+
+	"github.com/liriquew/social-todo/api_service/pkg/logger/sl"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// accepted clock drift, in TOTP periods
+const totpSkew = 1
+
+// CompleteLogin exchanges challenge token from Login and TOTP or
+// recovery code for token pair.
+func (a *Auth) CompleteLogin(ctx context.Context, challengeToken, code string, client models.Client) (models.TokenPair, error) {
+	const op = "auth.CompleteLogin"
+
+	clientIP := client.IP
+
+	log := a.log.With(slog.String("op", op), slog.String("ip", clientIP))
+	log.Info("attempting to complete login")
+
+	user, err := a.passChallenge(ctx, log, challengeToken, code, clientIP)
+	if err != nil {
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	pair, err := a.newSession(ctx, user, client)
+	if err != nil {
+		log.Error("failed to issue tokens", sl.Err(err))
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pair, nil
+}
+
+// passChallenge checks second factor code for challenge token issued
+// by authenticate. Challenge token can be used once.
+func (a *Auth) passChallenge(ctx context.Context, log *slog.Logger, challengeToken, code, clientIP string) (models.User, error) {
+	claims, err := jwt.ValidateChallenge(challengeToken)
+	if err != nil {
+		log.Warn("invalid challenge token", sl.Err(err))
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return models.User{}, ErrTokenExpired
+		}
+		return models.User{}, ErrInvalidToken
+	}
+
+	log = log.With(slog.Int64("UID", claims.UID))
+
+	revoked, err := a.revoker.Revoked(ctx, claims)
+	if err != nil {
+		log.Error("failed to check token revocation", sl.Err(err))
+		return models.User{}, err
+	}
+	if revoked {
+		log.Warn("challenge token already used")
+		return models.User{}, ErrTokenRevoked
+	}
+
+	user, err := a.Storage.UserByID(ctx, claims.UID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("user not found")
+			return models.User{}, ErrInvalidToken
+		}
+
+		log.Error("failed to get user", sl.Err(err))
+		return models.User{}, err
+	}
+	if user.DisabledAt != nil {
+		log.Info("account disabled")
+		return models.User{}, ErrUserDisabled
+	}
+
+	if err := a.limiter.Check(user.Username, clientIP); err != nil {
+		log.Warn("login locked", sl.Err(err))
+		return models.User{}, err
+	}
+
+	t, err := a.Storage.TOTP(ctx, user.UID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("totp disabled after challenge was issued")
+			return models.User{}, ErrInvalidToken
+		}
+
+		log.Error("failed to get totp", sl.Err(err))
+		return models.User{}, err
+	}
+
+	ok, err := a.checkCode(ctx, t, code)
+	if err != nil {
+		log.Error("failed to check code", sl.Err(err))
+		return models.User{}, err
+	}
+	if !ok {
+		a.limiter.Fail(user.Username, clientIP)
+		log.Info("invalid code")
+		return models.User{}, ErrInvalidCode
+	}
+
+	a.limiter.Success(user.Username)
+
+	if err := a.revoker.Revoke(ctx, claims); err != nil {
+		log.Error("failed to revoke challenge token", sl.Err(err))
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// checkCode accepts unused TOTP code or unused recovery code.
+func (a *Auth) checkCode(ctx context.Context, t models.TOTP, code string) (bool, error) {
+	if step, ok := totp.Validate(t.Secret, code, time.Now(), totpSkew); ok {
+		return a.Storage.UseTOTPStep(ctx, t.UID, step)
+	}
+
+	return a.Storage.UseRecoveryCode(ctx, t.UID, tokens.Hash(normalizeRecoveryCode(code)))
+}
+
+// EnrollTOTP generates new secret. 2FA is enabled only after ConfirmTOTP.
+func (a *Auth) EnrollTOTP(ctx context.Context, tokenString string) (models.TOTPEnrollment, error) {
+	const op = "auth.EnrollTOTP"
+
+	log := a.log.With(slog.String("op", op))
+	log.Info("attempting to enroll totp")
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return models.TOTPEnrollment{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("UID", claims.UID))
+
+	user, err := a.Storage.UserByID(ctx, claims.UID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("user not found")
+			return models.TOTPEnrollment{}, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		log.Error("failed to get user", sl.Err(err))
+		return models.TOTPEnrollment{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		log.Error("failed to generate secret", sl.Err(err))
+		return models.TOTPEnrollment{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.Storage.SaveTOTPSecret(ctx, user.UID, secret); err != nil {
+		if errors.Is(err, storage.ErrTOTPEnabled) {
+			log.Warn("totp already enabled")
+			return models.TOTPEnrollment{}, fmt.Errorf("%s: %w", op, ErrTOTPEnabled)
+		}
+
+		log.Error("failed to save secret", sl.Err(err))
+		return models.TOTPEnrollment{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return models.TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.URI(a.twoFactor.Issuer, user.Username, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables 2FA if code matches enrolled secret
+// and returns one-time recovery codes.
+func (a *Auth) ConfirmTOTP(ctx context.Context, tokenString, code string) ([]string, error) {
+	const op = "auth.ConfirmTOTP"
+
+	log := a.log.With(slog.String("op", op))
+	log.Info("attempting to confirm totp")
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("UID", claims.UID))
+
+	t, err := a.Storage.TOTP(ctx, claims.UID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("totp not enrolled")
+			return nil, fmt.Errorf("%s: %w", op, ErrTOTPNotEnrolled)
+		}
+
+		log.Error("failed to get totp", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if t.Confirmed {
+		log.Warn("totp already enabled")
+		return nil, fmt.Errorf("%s: %w", op, ErrTOTPEnabled)
+	}
+
+	step, ok := totp.Validate(t.Secret, code, time.Now(), totpSkew)
+	if !ok {
+		log.Info("invalid code")
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidCode)
+	}
+
+	codes := make([]string, a.twoFactor.RecoveryCodes)
+	hashes := make([][]byte, a.twoFactor.RecoveryCodes)
+	for i := range codes {
+		codes[i], err = newRecoveryCode()
+		if err != nil {
+			log.Error("failed to generate recovery code", sl.Err(err))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		hashes[i] = tokens.Hash(normalizeRecoveryCode(codes[i]))
+	}
+
+	if err := a.Storage.ConfirmTOTP(ctx, claims.UID, step, hashes); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("totp confirmed concurrently")
+			return nil, fmt.Errorf("%s: %w", op, ErrTOTPEnabled)
+		}
+
+		log.Error("failed to confirm totp", sl.Err(err))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return codes, nil
+}
+
+// DisableTOTP turns 2FA off, password is required.
+func (a *Auth) DisableTOTP(ctx context.Context, tokenString, password string) error {
+	const op = "auth.DisableTOTP"
+
+	log := a.log.With(slog.String("op", op))
+	log.Info("attempting to disable totp")
+
+	claims, err := a.validate(ctx, log, tokenString)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("UID", claims.UID))
+
+	user, err := a.Storage.UserByID(ctx, claims.UID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("user not found")
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		log.Error("failed to get user", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword(user.PassHash, []byte(password)); err != nil {
+		log.Info("invalid credentials", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+	}
+
+	if _, err := a.Storage.TOTP(ctx, user.UID); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("totp not enrolled")
+			return fmt.Errorf("%s: %w", op, ErrTOTPNotEnrolled)
+		}
+
+		log.Error("failed to get totp", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.Storage.DeleteTOTP(ctx, user.UID); err != nil {
+		log.Error("failed to delete totp", sl.Err(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newRecoveryCode returns code like "abcd-efgh".
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(recoveryEncoding.EncodeToString(b))
+	return code[:4] + "-" + code[4:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
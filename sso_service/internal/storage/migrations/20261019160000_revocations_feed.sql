@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE revoked_tokens ADD COLUMN revoked_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE user_revocations ADD COLUMN revoked_at TIMESTAMPTZ NOT NULL DEFAULT now();
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_revoked_at ON revoked_tokens (revoked_at);
+CREATE INDEX IF NOT EXISTS idx_user_revocations_revoked_at ON user_revocations (revoked_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_revocations DROP COLUMN IF EXISTS revoked_at;
+ALTER TABLE revoked_tokens DROP COLUMN IF EXISTS revoked_at;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- revocations must outlive deleted users, their tokens are valid until exp
+ALTER TABLE revoked_tokens DROP CONSTRAINT IF EXISTS revoked_tokens_user_id_fkey;
+ALTER TABLE user_revocations DROP CONSTRAINT IF EXISTS user_revocations_user_id_fkey;
+
+CREATE TABLE IF NOT EXISTS account_deletions
+(
+    id              TEXT PRIMARY KEY,
+    user_id         INTEGER NOT NULL,
+    status          TEXT NOT NULL DEFAULT 'pending',
+    notes_done      BOOLEAN NOT NULL DEFAULT false,
+    friends_done    BOOLEAN NOT NULL DEFAULT false,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    last_error      TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at    TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_account_deletions_pending
+    ON account_deletions (next_attempt_at) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS account_deletions;
+DELETE FROM user_revocations WHERE user_id NOT IN (SELECT id FROM users);
+DELETE FROM revoked_tokens WHERE user_id NOT IN (SELECT id FROM users);
+ALTER TABLE user_revocations ADD CONSTRAINT user_revocations_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE revoked_tokens ADD CONSTRAINT revoked_tokens_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS oidc_clients
+(
+    id            TEXT PRIMARY KEY,
+    name          TEXT NOT NULL,
+    -- NULL for public clients
+    secret_hash   BYTEA,
+    redirect_uris TEXT[] NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS oidc_codes
+(
+    code_hash      BYTEA PRIMARY KEY,
+    client_id      TEXT NOT NULL REFERENCES oidc_clients (id) ON DELETE CASCADE,
+    user_id        INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    redirect_uri   TEXT NOT NULL,
+    scope          TEXT NOT NULL,
+    nonce          TEXT NOT NULL DEFAULT '',
+    code_challenge TEXT NOT NULL,
+    ip             TEXT NOT NULL DEFAULT '',
+    user_agent     TEXT NOT NULL DEFAULT '',
+    auth_time      TIMESTAMPTZ NOT NULL,
+    expires_at     TIMESTAMPTZ NOT NULL,
+    used_at        TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_oidc_codes_expires ON oidc_codes (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS oidc_codes;
+DROP TABLE IF EXISTS oidc_clients;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS user_identities
+(
+    user_id       INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    connector_id  TEXT NOT NULL,
+    subject       TEXT NOT NULL,
+    username      TEXT NOT NULL DEFAULT '',
+    email         TEXT NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_login_at TIMESTAMPTZ,
+    PRIMARY KEY (connector_id, subject),
+    -- one identity per provider
+    UNIQUE (user_id, connector_id)
+);
+
+CREATE TABLE IF NOT EXISTS external_auth_states
+(
+    state_hash    BYTEA PRIMARY KEY,
+    connector_id  TEXT NOT NULL,
+    -- set when identity is being linked
+    user_id       INTEGER REFERENCES users (id) ON DELETE CASCADE,
+    code_verifier TEXT NOT NULL,
+    expires_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_external_auth_states_expires ON external_auth_states (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS external_auth_states;
+DROP TABLE IF EXISTS user_identities;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- entries written before the column existed were recorded before the action
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS result TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE audit_log DROP COLUMN IF EXISTS result;
+-- +goose StatementEnd
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/liriquew/social-todo/sso_service/internal/lib/config"
 	"github.com/liriquew/social-todo/sso_service/internal/models"
@@ -45,13 +47,14 @@ func (s *Storage) Close() error {
 	return s.db.Close()
 }
 
-func (s *Storage) SaveUser(ctx context.Context, username string, passHash []byte) (int64, error) {
+// SaveUser creates user with unverified email, empty email isn't set.
+func (s *Storage) SaveUser(ctx context.Context, username string, passHash []byte, email string) (int64, error) {
 	const op = "storage.postgres.SaveUser"
 
 	var userID int64
-	query := "INSERT INTO users (username, pass_hash) VALUES ($1, $2) RETURNING id"
+	query := "INSERT INTO users (username, pass_hash, email) VALUES ($1, $2, NULLIF($3, '')) RETURNING id"
 
-	err := s.db.QueryRowContext(ctx, query, username, passHash).Scan(&userID)
+	err := s.db.QueryRowContext(ctx, query, username, passHash, email).Scan(&userID)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
 			return 0, fmt.Errorf("%s: %w", op, storage.ErrUserExist)
@@ -65,7 +68,10 @@ func (s *Storage) SaveUser(ctx context.Context, username string, passHash []byte
 func (s *Storage) User(ctx context.Context, username string) (models.User, error) {
 	const op = "storage.postgres.User"
 
-	stmt, err := s.db.Prepare("SELECT id, username, pass_hash FROM users WHERE username = $1")
+	stmt, err := s.db.Prepare(`
+		SELECT id, username, pass_hash, role, disabled_at,
+			COALESCE(email, ''), email_verified_at FROM users 
+		WHERE username = $1 AND deleted_at IS NULL`)
 	if err != nil {
 		return models.User{}, fmt.Errorf("%s: %w", op, err)
 	}
@@ -73,7 +79,8 @@ func (s *Storage) User(ctx context.Context, username string) (models.User, error
 	row := stmt.QueryRowContext(ctx, username)
 
 	var user models.User
-	err = row.Scan(&user.UID, &user.Username, &user.PassHash)
+	err = row.Scan(&user.UID, &user.Username, &user.PassHash, &user.Role, &user.DisabledAt,
+		&user.Email, &user.EmailVerifiedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.User{}, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
@@ -84,3 +91,1676 @@ func (s *Storage) User(ctx context.Context, username string) (models.User, error
 
 	return user, nil
 }
+
+func (s *Storage) UserByID(ctx context.Context, UID int64) (models.User, error) {
+	const op = "storage.postgres.UserByID"
+
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, username, pass_hash, display_name, role, disabled_at,
+			COALESCE(email, ''), email_verified_at FROM users 
+		WHERE id = $1 AND deleted_at IS NULL`, UID)
+
+	var user models.User
+	err := row.Scan(&user.UID, &user.Username, &user.PassHash, &user.DisplayName, &user.Role, &user.DisabledAt,
+		&user.Email, &user.EmailVerifiedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return user, nil
+}
+
+func (s *Storage) UsersByIDs(ctx context.Context, UIDs []int64) ([]models.User, error) {
+	const op = "storage.postgres.UsersByIDs"
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, username, display_name FROM users 
+		WHERE id = ANY($1) AND deleted_at IS NULL`, pq.Array(UIDs))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0, len(UIDs))
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.UID, &user.Username, &user.DisplayName); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return users, nil
+}
+
+func (s *Storage) SaveRefreshToken(ctx context.Context, token models.RefreshToken) error {
+	const op = "storage.postgres.SaveRefreshToken"
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at) 
+		VALUES ($1, $2, $3, $4)`,
+		token.UID, token.FamilyID, token.Hash, token.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) RefreshToken(ctx context.Context, hash []byte) (models.RefreshToken, error) {
+	const op = "storage.postgres.RefreshToken"
+
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, user_id, family_id, token_hash, created_at, expires_at, used_at, revoked_at 
+		FROM refresh_tokens 
+		WHERE token_hash = $1`, hash)
+
+	var token models.RefreshToken
+	err := row.Scan(&token.ID, &token.UID, &token.FamilyID, &token.Hash,
+		&token.CreatedAt, &token.ExpiresAt, &token.UsedAt, &token.RevokedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.RefreshToken{}, fmt.Errorf("%s: %w", op, storage.ErrTokenNotFound)
+		}
+
+		return models.RefreshToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return token, nil
+}
+
+// UseRefreshToken marks token used. Returns false if it was already used,
+// so only one of concurrent refreshes with the same token wins.
+func (s *Storage) UseRefreshToken(ctx context.Context, ID int64) (bool, error) {
+	const op = "storage.postgres.UseRefreshToken"
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE refresh_tokens SET used_at = now() 
+		WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`, ID)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return n == 1, nil
+}
+
+// RevokeRefreshFamily revokes refresh token family, its session and access
+// tokens issued in it that are not expired yet. Tokens revoked by this
+// call are returned.
+func (s *Storage) RevokeRefreshFamily(ctx context.Context, familyID string) ([]models.RevokedToken, error) {
+	const op = "storage.postgres.RevokeRefreshFamily"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = now() 
+		WHERE family_id = $1 AND revoked_at IS NULL`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// family is the session, it can't be continued anymore
+	_, err = tx.ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = now()
+		WHERE id = $1 AND revoked_at IS NULL`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tokens, err := revokeSessionTokens(ctx, tx, `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at)
+		SELECT t.jti, s.user_id, t.expires_at FROM session_tokens t
+		JOIN sessions s ON s.id = t.session_id
+		WHERE t.session_id = $1 AND t.expires_at > now()
+		ON CONFLICT (jti) DO NOTHING
+		RETURNING jti, expires_at`, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tokens, nil
+}
+
+// RevokeUserRefreshTokens revokes all refresh tokens and sessions of the
+// user, and access tokens issued in them that are not expired yet. Tokens
+// revoked by this call are returned.
+func (s *Storage) RevokeUserRefreshTokens(ctx context.Context, UID int64) ([]models.RevokedToken, error) {
+	const op = "storage.postgres.RevokeUserRefreshTokens"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = now() 
+		WHERE user_id = $1 AND revoked_at IS NULL`, UID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = now()
+		WHERE user_id = $1 AND revoked_at IS NULL`, UID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tokens, err := revokeSessionTokens(ctx, tx, `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at)
+		SELECT t.jti, s.user_id, t.expires_at FROM session_tokens t
+		JOIN sessions s ON s.id = t.session_id
+		WHERE s.user_id = $1 AND t.expires_at > now()
+		ON CONFLICT (jti) DO NOTHING
+		RETURNING jti, expires_at`, UID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tokens, nil
+}
+
+// revokeSessionTokens runs query inserting access tokens of sessions into
+// revoked_tokens and returns the inserted ones.
+func revokeSessionTokens(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]models.RevokedToken, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []models.RevokedToken
+	for rows.Next() {
+		var token models.RevokedToken
+		if err := rows.Scan(&token.JTI, &token.ExpiresAt); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (s *Storage) RevokeToken(ctx context.Context, jti string, UID int64, expiresAt time.Time) error {
+	const op = "storage.postgres.RevokeToken"
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at) 
+		VALUES ($1, $2, $3) 
+		ON CONFLICT (jti) DO NOTHING`,
+		jti, UID, expiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// expired tokens are rejected anyway, no need to keep them
+	_, err = s.db.ExecContext(ctx, "DELETE FROM revoked_tokens WHERE expires_at < now()")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) TokenRevoked(ctx context.Context, jti string) (bool, error) {
+	const op = "storage.postgres.TokenRevoked"
+
+	var revoked bool
+	err := s.db.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)", jti).Scan(&revoked)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return revoked, nil
+}
+
+func (s *Storage) RevokeUserTokens(ctx context.Context, UID int64, before time.Time) error {
+	const op = "storage.postgres.RevokeUserTokens"
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO user_revocations (user_id, revoked_before) 
+		VALUES ($1, $2) 
+		ON CONFLICT (user_id) DO UPDATE SET 
+			revoked_before = GREATEST(user_revocations.revoked_before, EXCLUDED.revoked_before), 
+			revoked_at = now()`,
+		UID, before)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UserTokensRevokedBefore returns zero time if user never revoked his tokens.
+func (s *Storage) UserTokensRevokedBefore(ctx context.Context, UID int64) (time.Time, error) {
+	const op = "storage.postgres.UserTokensRevokedBefore"
+
+	var before time.Time
+	err := s.db.QueryRowContext(ctx,
+		"SELECT revoked_before FROM user_revocations WHERE user_id = $1", UID).Scan(&before)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return before, nil
+}
+
+// Revocations returns token and user revocations made after since and
+// database time the result is consistent with.
+func (s *Storage) Revocations(ctx context.Context, since time.Time) (models.Revocations, error) {
+	const op = "storage.postgres.Revocations"
+
+	var revs models.Revocations
+	if err := s.db.QueryRowContext(ctx, "SELECT now()").Scan(&revs.Until); err != nil {
+		return models.Revocations{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT jti, expires_at FROM revoked_tokens 
+		WHERE revoked_at > $1 AND revoked_at <= $2 AND expires_at > $2`,
+		since, revs.Until)
+	if err != nil {
+		return models.Revocations{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var token models.RevokedToken
+		if err := rows.Scan(&token.JTI, &token.ExpiresAt); err != nil {
+			return models.Revocations{}, fmt.Errorf("%s: %w", op, err)
+		}
+		revs.Tokens = append(revs.Tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Revocations{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT user_id, revoked_before FROM user_revocations 
+		WHERE revoked_at > $1 AND revoked_at <= $2`,
+		since, revs.Until)
+	if err != nil {
+		return models.Revocations{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user models.UserRevocation
+		if err := rows.Scan(&user.UID, &user.RevokedBefore); err != nil {
+			return models.Revocations{}, fmt.Errorf("%s: %w", op, err)
+		}
+		revs.Users = append(revs.Users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Revocations{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return revs, nil
+}
+
+func (s *Storage) UpdatePassword(ctx context.Context, UID int64, passHash []byte) error {
+	const op = "storage.postgres.UpdatePassword"
+
+	res, err := s.db.ExecContext(ctx, "UPDATE users SET pass_hash = $1 WHERE id = $2", passHash, UID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) SaveResetToken(ctx context.Context, UID int64, hash []byte, expiresAt time.Time) error {
+	const op = "storage.postgres.SaveResetToken"
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at) 
+		VALUES ($1, $2, $3)`,
+		UID, hash, expiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UseResetToken marks unused and not expired reset token used
+// and returns its owner.
+func (s *Storage) UseResetToken(ctx context.Context, hash []byte) (int64, error) {
+	const op = "storage.postgres.UseResetToken"
+
+	var UID int64
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE password_reset_tokens SET used_at = now() 
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now() 
+		RETURNING user_id`, hash).Scan(&UID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrTokenNotFound)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return UID, nil
+}
+
+func (s *Storage) TOTP(ctx context.Context, UID int64) (models.TOTP, error) {
+	const op = "storage.postgres.TOTP"
+
+	totp := models.TOTP{UID: UID}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT secret, confirmed_at IS NOT NULL, last_used_step 
+		FROM user_totp WHERE user_id = $1`, UID).
+		Scan(&totp.Secret, &totp.Confirmed, &totp.LastUsedStep)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.TOTP{}, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+		return models.TOTP{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return totp, nil
+}
+
+// SaveTOTPSecret saves secret of not yet confirmed enrollment,
+// replacing previous unconfirmed one.
+func (s *Storage) SaveTOTPSecret(ctx context.Context, UID int64, secret string) error {
+	const op = "storage.postgres.SaveTOTPSecret"
+
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO user_totp (user_id, secret) VALUES ($1, $2) 
+		ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_used_step = 0 
+		WHERE user_totp.confirmed_at IS NULL`, UID, secret)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrTOTPEnabled)
+	}
+
+	return nil
+}
+
+// ConfirmTOTP enables 2FA and replaces recovery codes.
+func (s *Storage) ConfirmTOTP(ctx context.Context, UID, step int64, codeHashes [][]byte) error {
+	const op = "storage.postgres.ConfirmTOTP"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE user_totp SET confirmed_at = now(), last_used_step = $2 
+		WHERE user_id = $1 AND confirmed_at IS NULL`, UID, step)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM recovery_codes WHERE user_id = $1", UID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, hash := range codeHashes {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)", UID, hash)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UseTOTPStep returns false if code of this or later step was already used.
+func (s *Storage) UseTOTPStep(ctx context.Context, UID, step int64) (bool, error) {
+	const op = "storage.postgres.UseTOTPStep"
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE user_totp SET last_used_step = $2 
+		WHERE user_id = $1 AND last_used_step < $2`, UID, step)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return n == 1, nil
+}
+
+func (s *Storage) UseRecoveryCode(ctx context.Context, UID int64, hash []byte) (bool, error) {
+	const op = "storage.postgres.UseRecoveryCode"
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE recovery_codes SET used_at = now() 
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`, UID, hash)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return n == 1, nil
+}
+
+func (s *Storage) DeleteTOTP(ctx context.Context, UID int64) error {
+	const op = "storage.postgres.DeleteTOTP"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM recovery_codes WHERE user_id = $1", UID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_totp WHERE user_id = $1", UID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+const profileColumns = `
+	u.id, u.username, u.display_name, 
+	COALESCE(p.bio, ''), COALESCE(p.avatar_url, ''), COALESCE(p.updated_at, 'epoch')`
+
+func scanProfile(row interface{ Scan(...any) error }) (models.Profile, error) {
+	var profile models.Profile
+	err := row.Scan(&profile.UID, &profile.Username, &profile.DisplayName,
+		&profile.Bio, &profile.AvatarURL, &profile.UpdatedAt)
+	return profile, err
+}
+
+func (s *Storage) Profile(ctx context.Context, UID int64) (models.Profile, error) {
+	const op = "storage.postgres.Profile"
+
+	row := s.db.QueryRowContext(ctx, `
+		SELECT `+profileColumns+`
+		FROM users u LEFT JOIN profiles p ON p.user_id = u.id 
+		WHERE u.id = $1 AND u.deleted_at IS NULL`, UID)
+
+	profile, err := scanProfile(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Profile{}, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+		return models.Profile{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return profile, nil
+}
+
+func (s *Storage) ProfilesByIDs(ctx context.Context, UIDs []int64) ([]models.Profile, error) {
+	const op = "storage.postgres.ProfilesByIDs"
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+profileColumns+`
+		FROM users u LEFT JOIN profiles p ON p.user_id = u.id 
+		WHERE u.id = ANY($1) AND u.deleted_at IS NULL`, pq.Array(UIDs))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	profiles := make([]models.Profile, 0, len(UIDs))
+	for rows.Next() {
+		profile, err := scanProfile(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		profiles = append(profiles, profile)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return profiles, nil
+}
+
+// UpdateProfile sets non-nil fields of update.
+func (s *Storage) UpdateProfile(ctx context.Context, UID int64, update models.ProfileUpdate) error {
+	const op = "storage.postgres.UpdateProfile"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if update.DisplayName != nil {
+		res, err := tx.ExecContext(ctx,
+			"UPDATE users SET display_name = $2 WHERE id = $1", UID, *update.DisplayName)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if n == 0 {
+			return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+	}
+
+	// NULL arguments keep current values
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO profiles (user_id, bio, avatar_url) 
+		VALUES ($1, COALESCE($2, ''), COALESCE($3, ''))
+		ON CONFLICT (user_id) DO UPDATE SET 
+			bio = COALESCE($2, profiles.bio),
+			avatar_url = COALESCE($3, profiles.avatar_url),
+			updated_at = now()`, UID, update.Bio, update.AvatarURL)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// notBlocked excludes users.id that blocked or were blocked by $1.
+const notBlocked = `NOT EXISTS (
+		SELECT 1 FROM user_blocks b 
+		WHERE (b.blocker_id = $1 AND b.blocked_id = users.id) 
+		   OR (b.blocker_id = users.id AND b.blocked_id = $1))`
+
+// SearchUsers finds users by username or display name prefix
+// and, less relevant, by trigram similarity. The searcher itself
+// and users blocked either way are not returned.
+func (s *Storage) SearchUsers(ctx context.Context, UID int64, query string, offset, limit int64) ([]models.User, error) {
+	const op = "storage.postgres.SearchUsers"
+
+	prefix := escapeLike(query) + "%"
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, username, display_name FROM users
+		WHERE (username ILIKE $2 OR display_name ILIKE $2 OR username % $3 OR display_name % $3)
+			AND id <> $1 AND deleted_at IS NULL AND `+notBlocked+`
+		ORDER BY 
+			(username ILIKE $2 OR display_name ILIKE $2) DESC,
+			GREATEST(similarity(username, $3), similarity(display_name, $3)) DESC,
+			id
+		OFFSET $4 LIMIT $5`, UID, prefix, query, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0, limit)
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.UID, &user.Username, &user.DisplayName); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return users, nil
+}
+
+// VisibleUser returns user with given username, unless blocked either way with UID.
+func (s *Storage) VisibleUser(ctx context.Context, UID int64, username string) (models.User, error) {
+	const op = "storage.postgres.VisibleUser"
+
+	var user models.User
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, username, display_name FROM users 
+		WHERE username = $2 AND deleted_at IS NULL AND `+notBlocked, UID, username).
+		Scan(&user.UID, &user.Username, &user.DisplayName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return user, nil
+}
+
+func (s *Storage) BlockUser(ctx context.Context, blockerID, blockedID int64) error {
+	const op = "storage.postgres.BlockUser"
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO user_blocks (blocker_id, blocked_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`, blockerID, blockedID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) UnblockUser(ctx context.Context, blockerID, blockedID int64) error {
+	const op = "storage.postgres.UnblockUser"
+
+	_, err := s.db.ExecContext(ctx,
+		"DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2", blockerID, blockedID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// StartAccountDeletion marks user deleted and creates deletion job.
+// Deleted users are hidden from all lookups until the row is purged.
+func (s *Storage) StartAccountDeletion(ctx context.Context, UID int64, ID string) error {
+	const op = "storage.postgres.StartAccountDeletion"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"UPDATE users SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL", UID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO account_deletions (id, user_id) VALUES ($1, $2)", ID, UID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+const deletionColumns = `
+	id, user_id, status, notes_done, friends_done, attempts, 
+	last_error, next_attempt_at, created_at, completed_at`
+
+func scanDeletion(row interface{ Scan(...any) error }) (models.AccountDeletion, error) {
+	var d models.AccountDeletion
+	var completedAt sql.NullTime
+	err := row.Scan(&d.ID, &d.UID, &d.Status, &d.NotesDone, &d.FriendsDone, &d.Attempts,
+		&d.LastError, &d.NextAttempt, &d.CreatedAt, &completedAt)
+	if completedAt.Valid {
+		d.CompletedAt = &completedAt.Time
+	}
+	return d, err
+}
+
+func (s *Storage) AccountDeletion(ctx context.Context, ID string) (models.AccountDeletion, error) {
+	const op = "storage.postgres.AccountDeletion"
+
+	d, err := scanDeletion(s.db.QueryRowContext(ctx,
+		"SELECT "+deletionColumns+" FROM account_deletions WHERE id = $1", ID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+		return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return d, nil
+}
+
+// ClaimAccountDeletions returns up to limit pending jobs that are due and
+// postpones them by lease, so other instances don't pick them meanwhile.
+func (s *Storage) ClaimAccountDeletions(ctx context.Context, limit int, lease time.Duration) ([]models.AccountDeletion, error) {
+	const op = "storage.postgres.ClaimAccountDeletions"
+
+	rows, err := s.db.QueryContext(ctx, `
+		UPDATE account_deletions SET next_attempt_at = now() + $2 * interval '1 millisecond'
+		WHERE id IN (
+			SELECT id FROM account_deletions 
+			WHERE status = 'pending' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED)
+		RETURNING `+deletionColumns, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ans []models.AccountDeletion
+	for rows.Next() {
+		d, err := scanDeletion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ans = append(ans, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ans, nil
+}
+
+func (s *Storage) SaveAccountDeletion(ctx context.Context, d models.AccountDeletion) error {
+	const op = "storage.postgres.SaveAccountDeletion"
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE account_deletions SET 
+			status = $2, notes_done = $3, friends_done = $4, attempts = $5,
+			last_error = $6, next_attempt_at = $7, completed_at = $8, updated_at = now()
+		WHERE id = $1`,
+		d.ID, d.Status, d.NotesDone, d.FriendsDone, d.Attempts,
+		d.LastError, d.NextAttempt, d.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// PurgeUser removes row of the user marked deleted, with everything
+// that references it. Username becomes free again.
+func (s *Storage) PurgeUser(ctx context.Context, UID int64) error {
+	const op = "storage.postgres.PurgeUser"
+
+	_, err := s.db.ExecContext(ctx,
+		"DELETE FROM users WHERE id = $1 AND deleted_at IS NOT NULL", UID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+const accessTokenColumns = `t.id, t.user_id, t.name, t.token_hash, t.scopes,
+	t.created_at, t.expires_at, t.last_used_at, t.revoked_at`
+
+// scanAccessToken scans accessTokenColumns, extra columns go after them.
+func scanAccessToken(row interface{ Scan(...any) error }, extra ...any) (models.AccessToken, error) {
+	var t models.AccessToken
+	dest := append([]any{&t.ID, &t.UID, &t.Name, &t.Hash, pq.Array(&t.Scopes),
+		&t.CreatedAt, &t.ExpiresAt, &t.LastUsedAt, &t.RevokedAt}, extra...)
+	err := row.Scan(dest...)
+	return t, err
+}
+
+// SaveAccessToken stores token unless its owner already has max tokens
+// that are not revoked. Owner's row is locked, so concurrent requests
+// can't both pass the check.
+func (s *Storage) SaveAccessToken(ctx context.Context, token models.AccessToken, max int) (models.AccessToken, error) {
+	const op = "storage.postgres.SaveAccessToken"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.AccessToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "SELECT 1 FROM users WHERE id = $1 FOR UPDATE", token.UID)
+	if err != nil {
+		return models.AccessToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var count int
+	err = tx.QueryRowContext(ctx, `
+		SELECT count(*) FROM access_tokens
+		WHERE user_id = $1 AND revoked_at IS NULL`, token.UID).Scan(&count)
+	if err != nil {
+		return models.AccessToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if count >= max {
+		return models.AccessToken{}, fmt.Errorf("%s: %w", op, storage.ErrTooManyTokens)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO access_tokens (user_id, name, token_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`,
+		token.UID, token.Name, token.Hash, pq.Array(token.Scopes), token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return models.AccessToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.AccessToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return token, nil
+}
+
+// AccessTokens returns user's tokens that are not revoked, newest first.
+func (s *Storage) AccessTokens(ctx context.Context, UID int64) ([]models.AccessToken, error) {
+	const op = "storage.postgres.AccessTokens"
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+accessTokenColumns+`
+		FROM access_tokens t
+		WHERE t.user_id = $1 AND t.revoked_at IS NULL
+		ORDER BY t.created_at DESC, t.id DESC`, UID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var tokens []models.AccessToken
+	for rows.Next() {
+		t, err := scanAccessToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		tokens = append(tokens, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tokens, nil
+}
+
+// AccessToken returns token by its hash with owner's email verification
+// state. Tokens of deleted or disabled users are reported as not found.
+func (s *Storage) AccessToken(ctx context.Context, hash []byte) (models.AccessToken, error) {
+	const op = "storage.postgres.AccessToken"
+
+	row := s.db.QueryRowContext(ctx, `
+		SELECT `+accessTokenColumns+`,
+			u.email IS NOT NULL AND u.email_verified_at IS NOT NULL
+		FROM access_tokens t
+		JOIN users u ON u.id = t.user_id
+		WHERE t.token_hash = $1 AND u.deleted_at IS NULL AND u.disabled_at IS NULL`, hash)
+
+	var emailVerified bool
+	t, err := scanAccessToken(row, &emailVerified)
+	t.OwnerEmailVerified = emailVerified
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.AccessToken{}, fmt.Errorf("%s: %w", op, storage.ErrTokenNotFound)
+		}
+
+		return models.AccessToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return t, nil
+}
+
+// TouchAccessToken updates last use time. It's written at most once
+// a minute, so busy scripts don't write on every request.
+func (s *Storage) TouchAccessToken(ctx context.Context, ID int64) error {
+	const op = "storage.postgres.TouchAccessToken"
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE access_tokens SET last_used_at = now()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')`, ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) RevokeAccessToken(ctx context.Context, UID, ID int64) error {
+	const op = "storage.postgres.RevokeAccessToken"
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE access_tokens SET revoked_at = now()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`, ID, UID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrTokenNotFound)
+	}
+
+	return nil
+}
+
+// RevokeUserAccessTokens revokes all user's access tokens.
+func (s *Storage) RevokeUserAccessTokens(ctx context.Context, UID int64) error {
+	const op = "storage.postgres.RevokeUserAccessTokens"
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE access_tokens SET revoked_at = now()
+		WHERE user_id = $1 AND revoked_at IS NULL`, UID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) SaveSession(ctx context.Context, session models.Session) error {
+	const op = "storage.postgres.SaveSession"
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO sessions (id, user_id, user_agent, ip, expires_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		session.ID, session.UID, session.Client.UserAgent, session.Client.IP, session.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ExtendSession is called on refresh, it moves session expiry and
+// updates last seen time and client.
+func (s *Storage) ExtendSession(ctx context.Context, ID string, client models.Client, expiresAt time.Time) error {
+	const op = "storage.postgres.ExtendSession"
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE sessions SET
+			last_seen_at = now(),
+			expires_at = $2,
+			ip = COALESCE(NULLIF($3, ''), ip),
+			user_agent = COALESCE(NULLIF($4, ''), user_agent)
+		WHERE id = $1`,
+		ID, expiresAt, client.IP, client.UserAgent)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// TouchSession updates last seen time of the session. Returns false
+// if session is revoked, expired or unknown. The time is written at
+// most once a minute, so every request doesn't write.
+func (s *Storage) TouchSession(ctx context.Context, ID string) (bool, error) {
+	const op = "storage.postgres.TouchSession"
+
+	var active bool
+	err := s.db.QueryRowContext(ctx, `
+		WITH active AS (
+			SELECT id, last_seen_at FROM sessions
+			WHERE id = $1 AND revoked_at IS NULL AND expires_at > now()
+		), touched AS (
+			UPDATE sessions s SET last_seen_at = now()
+			FROM active a
+			WHERE s.id = a.id AND a.last_seen_at < now() - interval '1 minute'
+		)
+		SELECT EXISTS (SELECT 1 FROM active)`, ID).Scan(&active)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return active, nil
+}
+
+func (s *Storage) SaveSessionToken(ctx context.Context, sessionID, jti string, expiresAt time.Time) error {
+	const op = "storage.postgres.SaveSessionToken"
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO session_tokens (jti, session_id, expires_at)
+		VALUES ($1, $2, $3)`,
+		jti, sessionID, expiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// expired tokens need no revocation, no need to keep them
+	_, err = s.db.ExecContext(ctx, `
+		DELETE FROM session_tokens WHERE session_id = $1 AND expires_at < now()`, sessionID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Sessions returns user's sessions that are not revoked or expired,
+// most recently seen first.
+func (s *Storage) Sessions(ctx context.Context, UID int64) ([]models.Session, error) {
+	const op = "storage.postgres.Sessions"
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > now()
+		ORDER BY last_seen_at DESC`, UID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		var session models.Session
+		err := rows.Scan(&session.ID, &session.UID, &session.Client.UserAgent, &session.Client.IP,
+			&session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt, &session.RevokedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession revokes user's session with its refresh tokens and
+// access tokens issued in it that are not expired yet. Tokens revoked
+// by this call are returned.
+func (s *Storage) RevokeSession(ctx context.Context, UID int64, ID string) ([]models.RevokedToken, error) {
+	const op = "storage.postgres.RevokeSession"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = now()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`, ID, UID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = now()
+		WHERE family_id = $1 AND revoked_at IS NULL`, ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tokens, err := revokeSessionTokens(ctx, tx, `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at)
+		SELECT jti, $2, expires_at FROM session_tokens
+		WHERE session_id = $1 AND expires_at > now()
+		ON CONFLICT (jti) DO NOTHING
+		RETURNING jti, expires_at`, ID, UID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tokens, nil
+}
+
+// ListUsers returns page of users ordered by ID, for admins.
+func (s *Storage) ListUsers(ctx context.Context, offset, limit int64) ([]models.User, error) {
+	const op = "storage.postgres.ListUsers"
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, username, display_name, role, disabled_at, created_at
+		FROM users
+		WHERE deleted_at IS NULL
+		ORDER BY id
+		OFFSET $1 LIMIT $2`, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0, limit)
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(&user.UID, &user.Username, &user.DisplayName,
+			&user.Role, &user.DisabledAt, &user.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return users, nil
+}
+
+func (s *Storage) SetUserDisabled(ctx context.Context, UID int64, disabled bool) error {
+	const op = "storage.postgres.SetUserDisabled"
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE users SET disabled_at = CASE WHEN $2 THEN COALESCE(disabled_at, now()) END
+		WHERE id = $1 AND deleted_at IS NULL`, UID, disabled)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return expectAffected(op, res)
+}
+
+func (s *Storage) SetUserRole(ctx context.Context, UID int64, role string) error {
+	const op = "storage.postgres.SetUserRole"
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE users SET role = $2
+		WHERE id = $1 AND deleted_at IS NULL`, UID, role)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return expectAffected(op, res)
+}
+
+// PromoteAdmins gives admin role to existing users with given usernames.
+func (s *Storage) PromoteAdmins(ctx context.Context, usernames []string) error {
+	const op = "storage.postgres.PromoteAdmins"
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE users SET role = 'admin'
+		WHERE username = ANY($1) AND role <> 'admin' AND deleted_at IS NULL`, pq.Array(usernames))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// expectAffected returns storage.ErrNotFound if statement changed nothing.
+func expectAffected(op string, res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	return nil
+}
+
+func (s *Storage) SaveAuditEntry(ctx context.Context, entry models.AuditEntry) error {
+	const op = "storage.postgres.SaveAuditEntry"
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO audit_log (actor_id, action, target, details, result)
+		VALUES ($1, $2, $3, $4, $5)`,
+		entry.ActorUID, entry.Action, entry.Target, entry.Details, entry.Result)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// AuditLog returns page of audit entries, newest first.
+func (s *Storage) AuditLog(ctx context.Context, offset, limit int64) ([]models.AuditEntry, error) {
+	const op = "storage.postgres.AuditLog"
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, actor_id, action, target, details, result, created_at
+		FROM audit_log
+		ORDER BY id DESC
+		OFFSET $1 LIMIT $2`, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	entries := make([]models.AuditEntry, 0, limit)
+	for rows.Next() {
+		var entry models.AuditEntry
+		err := rows.Scan(&entry.ID, &entry.ActorUID, &entry.Action,
+			&entry.Target, &entry.Details, &entry.Result, &entry.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return entries, nil
+}
+
+func (s *Storage) SaveOIDCClient(ctx context.Context, client models.OIDCClient) (models.OIDCClient, error) {
+	const op = "storage.postgres.SaveOIDCClient"
+
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO oidc_clients (id, name, secret_hash, redirect_uris)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at`,
+		client.ID, client.Name, client.SecretHash, pq.Array(client.RedirectURIs),
+	).Scan(&client.CreatedAt)
+	if err != nil {
+		return models.OIDCClient{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return client, nil
+}
+
+const oidcClientColumns = "id, name, secret_hash, redirect_uris, created_at"
+
+func scanOIDCClient(row interface{ Scan(...any) error }) (models.OIDCClient, error) {
+	var client models.OIDCClient
+	err := row.Scan(&client.ID, &client.Name, &client.SecretHash,
+		pq.Array(&client.RedirectURIs), &client.CreatedAt)
+	return client, err
+}
+
+func (s *Storage) OIDCClient(ctx context.Context, ID string) (models.OIDCClient, error) {
+	const op = "storage.postgres.OIDCClient"
+
+	row := s.db.QueryRowContext(ctx, `
+		SELECT `+oidcClientColumns+` FROM oidc_clients WHERE id = $1`, ID)
+
+	client, err := scanOIDCClient(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.OIDCClient{}, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+		return models.OIDCClient{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return client, nil
+}
+
+func (s *Storage) OIDCClients(ctx context.Context) ([]models.OIDCClient, error) {
+	const op = "storage.postgres.OIDCClients"
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+oidcClientColumns+` FROM oidc_clients ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var clients []models.OIDCClient
+	for rows.Next() {
+		client, err := scanOIDCClient(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		clients = append(clients, client)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return clients, nil
+}
+
+// DeleteOIDCClient removes client with its unused codes.
+func (s *Storage) DeleteOIDCClient(ctx context.Context, ID string) error {
+	const op = "storage.postgres.DeleteOIDCClient"
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM oidc_clients WHERE id = $1`, ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return expectAffected(op, res)
+}
+
+func (s *Storage) SaveOIDCCode(ctx context.Context, code models.OIDCCode) error {
+	const op = "storage.postgres.SaveOIDCCode"
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO oidc_codes (code_hash, client_id, user_id, redirect_uri, scope, nonce,
+			code_challenge, ip, user_agent, auth_time, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		code.Hash, code.ClientID, code.UID, code.RedirectURI, code.Scope, code.Nonce,
+		code.CodeChallenge, code.Client.IP, code.Client.UserAgent, code.AuthTime, code.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// expired codes can't be used or replayed, no need to keep them
+	_, err = s.db.ExecContext(ctx, "DELETE FROM oidc_codes WHERE expires_at < now()")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UseOIDCCode marks code issued to clientID used and returns it. Every
+// code can be used once, storage.ErrTokenNotFound is returned for unknown,
+// used or expired codes and codes of other clients.
+func (s *Storage) UseOIDCCode(ctx context.Context, hash []byte, clientID string) (models.OIDCCode, error) {
+	const op = "storage.postgres.UseOIDCCode"
+
+	code := models.OIDCCode{Hash: hash}
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE oidc_codes SET used_at = now()
+		WHERE code_hash = $1 AND client_id = $2 AND used_at IS NULL AND expires_at > now()
+		RETURNING client_id, user_id, redirect_uri, scope, nonce, code_challenge,
+			ip, user_agent, auth_time, expires_at`, hash, clientID,
+	).Scan(&code.ClientID, &code.UID, &code.RedirectURI, &code.Scope, &code.Nonce, &code.CodeChallenge,
+		&code.Client.IP, &code.Client.UserAgent, &code.AuthTime, &code.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.OIDCCode{}, fmt.Errorf("%s: %w", op, storage.ErrTokenNotFound)
+		}
+		return models.OIDCCode{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return code, nil
+}
+
+func (s *Storage) SaveExternalAuthState(ctx context.Context, state models.ExternalAuthState) error {
+	const op = "storage.postgres.SaveExternalAuthState"
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO external_auth_states (state_hash, connector_id, user_id, code_verifier, expires_at)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5)`,
+		state.Hash, state.ConnectorID, state.UID, state.CodeVerifier, state.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// states of abandoned logins are never used, drop them once expired
+	_, err = s.db.ExecContext(ctx, "DELETE FROM external_auth_states WHERE expires_at < now()")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UseExternalAuthState deletes state and returns it, so every state is used
+// once. storage.ErrTokenNotFound is returned for unknown or expired states.
+func (s *Storage) UseExternalAuthState(ctx context.Context, hash []byte) (models.ExternalAuthState, error) {
+	const op = "storage.postgres.UseExternalAuthState"
+
+	state := models.ExternalAuthState{Hash: hash}
+	var uid sql.NullInt64
+	err := s.db.QueryRowContext(ctx, `
+		DELETE FROM external_auth_states
+		WHERE state_hash = $1
+		RETURNING connector_id, user_id, code_verifier, expires_at`, hash,
+	).Scan(&state.ConnectorID, &uid, &state.CodeVerifier, &state.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ExternalAuthState{}, fmt.Errorf("%s: %w", op, storage.ErrTokenNotFound)
+		}
+		return models.ExternalAuthState{}, fmt.Errorf("%s: %w", op, err)
+	}
+	// expired states are deleted too, they are useless anyway
+	if !state.ExpiresAt.After(time.Now()) {
+		return models.ExternalAuthState{}, fmt.Errorf("%s: %w", op, storage.ErrTokenNotFound)
+	}
+	state.UID = uid.Int64
+
+	return state, nil
+}
+
+// SaveIdentity links identity to the user. storage.ErrIdentityExist is
+// returned if it's linked to someone already or the user has another
+// identity of the same provider.
+func (s *Storage) SaveIdentity(ctx context.Context, identity models.Identity) (models.Identity, error) {
+	const op = "storage.postgres.SaveIdentity"
+
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO user_identities (user_id, connector_id, subject, username, email)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at`,
+		identity.UID, identity.ConnectorID, identity.Subject, identity.Username, identity.Email,
+	).Scan(&identity.CreatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return models.Identity{}, fmt.Errorf("%s: %w", op, storage.ErrIdentityExist)
+		}
+		return models.Identity{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return identity, nil
+}
+
+const identityColumns = "user_id, connector_id, subject, username, email, created_at, last_login_at"
+
+func scanIdentity(row interface{ Scan(...any) error }) (models.Identity, error) {
+	var identity models.Identity
+	err := row.Scan(&identity.UID, &identity.ConnectorID, &identity.Subject,
+		&identity.Username, &identity.Email, &identity.CreatedAt, &identity.LastLoginAt)
+	return identity, err
+}
+
+// LoginIdentity records login with the identity and returns it.
+// Username and email are updated, they may change at the provider.
+func (s *Storage) LoginIdentity(ctx context.Context, identity models.Identity) (models.Identity, error) {
+	const op = "storage.postgres.LoginIdentity"
+
+	row := s.db.QueryRowContext(ctx, `
+		UPDATE user_identities SET username = $3, email = $4, last_login_at = now()
+		WHERE connector_id = $1 AND subject = $2
+		RETURNING `+identityColumns,
+		identity.ConnectorID, identity.Subject, identity.Username, identity.Email)
+
+	identity, err := scanIdentity(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Identity{}, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+		}
+		return models.Identity{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return identity, nil
+}
+
+func (s *Storage) Identities(ctx context.Context, UID int64) ([]models.Identity, error) {
+	const op = "storage.postgres.Identities"
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+identityColumns+` FROM user_identities
+		WHERE user_id = $1 ORDER BY connector_id`, UID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var identities []models.Identity
+	for rows.Next() {
+		identity, err := scanIdentity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		identities = append(identities, identity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return identities, nil
+}
+
+func (s *Storage) DeleteIdentity(ctx context.Context, UID int64, connectorID string) error {
+	const op = "storage.postgres.DeleteIdentity"
+
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM user_identities WHERE user_id = $1 AND connector_id = $2`, UID, connectorID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return expectAffected(op, res)
+}
+
+// SetEmail changes user's email, it's unverified until VerifyEmail.
+// Empty email removes it.
+func (s *Storage) SetEmail(ctx context.Context, UID int64, email string) error {
+	const op = "storage.postgres.SetEmail"
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE users SET email = NULLIF($2, ''), email_verified_at = NULL
+		WHERE id = $1 AND deleted_at IS NULL`, UID, email)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return expectAffected(op, res)
+}
+
+// VerifyEmail marks user's email verified if it's still the given one,
+// storage.ErrNotFound is returned otherwise. storage.ErrEmailTaken is
+// returned if another account has verified the address.
+func (s *Storage) VerifyEmail(ctx context.Context, UID int64, email string) error {
+	const op = "storage.postgres.VerifyEmail"
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE users SET email_verified_at = now()
+		WHERE id = $1 AND email = $2 AND email_verified_at IS NULL AND deleted_at IS NULL`,
+		UID, email)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return fmt.Errorf("%s: %w", op, storage.ErrEmailTaken)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return expectAffected(op, res)
+}
+
+func (s *Storage) SaveVerificationToken(ctx context.Context, UID int64, email string, hash []byte, expiresAt time.Time) error {
+	const op = "storage.postgres.SaveVerificationToken"
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO email_verification_tokens (token_hash, user_id, email, expires_at)
+		VALUES ($1, $2, $3, $4)`,
+		hash, UID, email, expiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// LastVerificationSent returns when verification was last sent to the
+// user, storage.ErrNotFound is returned if it never was.
+func (s *Storage) LastVerificationSent(ctx context.Context, UID int64) (time.Time, error) {
+	const op = "storage.postgres.LastVerificationSent"
+
+	var sentAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, `
+		SELECT max(created_at) FROM email_verification_tokens WHERE user_id = $1`, UID,
+	).Scan(&sentAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if !sentAt.Valid {
+		return time.Time{}, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+
+	return sentAt.Time, nil
+}
+
+// UseVerificationToken marks unused and not expired verification token
+// used and returns its owner and the address it verifies.
+func (s *Storage) UseVerificationToken(ctx context.Context, hash []byte) (int64, string, error) {
+	const op = "storage.postgres.UseVerificationToken"
+
+	var (
+		UID   int64
+		email string
+	)
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE email_verification_tokens SET used_at = now()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now()
+		RETURNING user_id, email`, hash).Scan(&UID, &email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, "", fmt.Errorf("%s: %w", op, storage.ErrTokenNotFound)
+		}
+		return 0, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return UID, email, nil
+}
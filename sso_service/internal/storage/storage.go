@@ -5,4 +5,11 @@ import "fmt"
 var (
 	ErrNotFound  = fmt.Errorf("user not found")
 	ErrUserExist = fmt.Errorf("user exist")
+
+	ErrTokenNotFound = fmt.Errorf("token not found")
+	ErrTooManyTokens = fmt.Errorf("too many tokens")
+	ErrTOTPEnabled   = fmt.Errorf("totp already enabled")
+
+	ErrIdentityExist = fmt.Errorf("identity already linked")
+	ErrEmailTaken    = fmt.Errorf("email already verified by another user")
 )
@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/liriquew/social-todo/sso_service/tests/suite"
+
+	"github.com/brianvoe/gofakeit/v6"
+	ssov1 "github.com/liriquew/todoprotos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAccessToken_CreateAuthorizeRevoke(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	token, uid := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+
+	respCreate, err := st.AuthClient.CreateAccessToken(ctx, &ssov1.CreateAccessTokenRequest{
+		Token:     token,
+		Name:      "backup script",
+		Scopes:    []string{"notes:read", "friends:read", "notes:read"},
+		ExpiresIn: 3600,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, respCreate.GetAccessToken())
+	assert.Equal(t, "backup script", respCreate.GetInfo().GetName())
+	assert.Equal(t, []string{"friends:read", "notes:read"}, respCreate.GetInfo().GetScopes())
+	assert.NotZero(t, respCreate.GetInfo().GetExpiresAt())
+
+	respAuth, err := st.AuthClient.AuthorizeAccessToken(ctx, &ssov1.AuthorizeAccessTokenRequest{
+		AccessToken: respCreate.GetAccessToken(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uid, respAuth.GetUid())
+	assert.ElementsMatch(t, []string{"friends:read", "notes:read"}, respAuth.GetScopes())
+
+	// access token is not a JWT
+	_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: respCreate.GetAccessToken()})
+	require.Error(t, err)
+
+	respList, err := st.AuthClient.ListAccessTokens(ctx, &ssov1.ListAccessTokensRequest{Token: token})
+	require.NoError(t, err)
+	require.Len(t, respList.GetTokens(), 1)
+	assert.Equal(t, respCreate.GetInfo().GetId(), respList.GetTokens()[0].GetId())
+	assert.NotZero(t, respList.GetTokens()[0].GetLastUsedAt())
+
+	_, err = st.AuthClient.RevokeAccessToken(ctx, &ssov1.RevokeAccessTokenRequest{
+		Token: token,
+		Id:    respCreate.GetInfo().GetId(),
+	})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.AuthorizeAccessToken(ctx, &ssov1.AuthorizeAccessTokenRequest{
+		AccessToken: respCreate.GetAccessToken(),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	respList, err = st.AuthClient.ListAccessTokens(ctx, &ssov1.ListAccessTokensRequest{Token: token})
+	require.NoError(t, err)
+	assert.Empty(t, respList.GetTokens())
+}
+
+func TestAccessToken_RevokeOthersToken(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	token, _ := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+	otherToken, _ := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+
+	respCreate, err := st.AuthClient.CreateAccessToken(ctx, &ssov1.CreateAccessTokenRequest{
+		Token:  token,
+		Name:   "ci",
+		Scopes: []string{"notes:write"},
+	})
+	require.NoError(t, err)
+	assert.Zero(t, respCreate.GetInfo().GetExpiresAt())
+
+	_, err = st.AuthClient.RevokeAccessToken(ctx, &ssov1.RevokeAccessTokenRequest{
+		Token: otherToken,
+		Id:    respCreate.GetInfo().GetId(),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = st.AuthClient.AuthorizeAccessToken(ctx, &ssov1.AuthorizeAccessTokenRequest{
+		AccessToken: respCreate.GetAccessToken(),
+	})
+	require.NoError(t, err)
+}
+
+func TestAccessToken_RevokedOnLogoutAll(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	token, _ := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+
+	respCreate, err := st.AuthClient.CreateAccessToken(ctx, &ssov1.CreateAccessTokenRequest{
+		Token:  token,
+		Name:   "ci",
+		Scopes: []string{"notes:read"},
+	})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.LogoutAll(ctx, &ssov1.LogoutAllRequest{Token: token})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.AuthorizeAccessToken(ctx, &ssov1.AuthorizeAccessTokenRequest{
+		AccessToken: respCreate.GetAccessToken(),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestAccessToken_RevokedOnPasswordChange(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	username := gofakeit.Username() + gofakeit.LetterN(6)
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	respCreate, err := st.AuthClient.CreateAccessToken(ctx, &ssov1.CreateAccessTokenRequest{
+		Token:  respLogin.GetToken(),
+		Name:   "ci",
+		Scopes: []string{"notes:read"},
+	})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.ChangePassword(ctx, &ssov1.ChangePasswordRequest{
+		Token:       respLogin.GetToken(),
+		OldPassword: pass,
+		NewPassword: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.AuthorizeAccessToken(ctx, &ssov1.AuthorizeAccessTokenRequest{
+		AccessToken: respCreate.GetAccessToken(),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestAccessToken_LimitConcurrent(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	token, _ := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+
+	const maxTokens, requests = 50, 60
+
+	var created, exhausted atomic.Int64
+	var wg sync.WaitGroup
+	for range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := st.AuthClient.CreateAccessToken(ctx, &ssov1.CreateAccessTokenRequest{
+				Token:  token,
+				Name:   "ci",
+				Scopes: []string{"notes:read"},
+			})
+			switch status.Code(err) {
+			case codes.OK:
+				created.Add(1)
+			case codes.ResourceExhausted:
+				exhausted.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(maxTokens), created.Load())
+	assert.Equal(t, int64(requests-maxTokens), exhausted.Load())
+}
+
+func TestAccessToken_InvalidRequest(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	token, _ := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+
+	tests := []struct {
+		name      string
+		tokenName string
+		scopes    []string
+		expiresIn int64
+	}{
+		{name: "empty name", tokenName: " ", scopes: []string{"notes:read"}},
+		{name: "no scopes", tokenName: "script"},
+		{name: "unknown scope", tokenName: "script", scopes: []string{"admin"}},
+		{name: "too short lifetime", tokenName: "script", scopes: []string{"notes:read"}, expiresIn: 10},
+		{name: "negative lifetime", tokenName: "script", scopes: []string{"notes:read"}, expiresIn: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := st.AuthClient.CreateAccessToken(ctx, &ssov1.CreateAccessTokenRequest{
+				Token:     token,
+				Name:      tt.tokenName,
+				Scopes:    tt.scopes,
+				ExpiresIn: tt.expiresIn,
+			})
+			require.Error(t, err)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+
+	_, err := st.AuthClient.AuthorizeAccessToken(ctx, &ssov1.AuthorizeAccessTokenRequest{
+		AccessToken: "pat_" + gofakeit.LetterN(43),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -0,0 +1,296 @@
+package tests
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/liriquew/social-todo/sso_service/tests/suite"
+
+	"github.com/brianvoe/gofakeit/v6"
+	ssov1 "github.com/liriquew/todoprotos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdmin_RoleInToken(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	adminToken, _ := registerAdmin(ctx, t, st)
+	userToken, _ := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+
+	respAdmin, err := st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: adminToken})
+	require.NoError(t, err)
+	assert.Equal(t, "admin", respAdmin.GetRole())
+
+	respUser, err := st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: userToken})
+	require.NoError(t, err)
+	assert.Equal(t, "user", respUser.GetRole())
+}
+
+func TestAdmin_NonAdminForbidden(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	userToken, _ := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+	_, victimUID := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+
+	_, err := st.AuthClient.ListUsers(ctx, &ssov1.ListUsersRequest{Token: userToken})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AuthClient.SetUserDisabled(ctx, &ssov1.SetUserDisabledRequest{
+		Token:    userToken,
+		Uid:      victimUID,
+		Disabled: true,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AuthClient.ForceLogout(ctx, &ssov1.ForceLogoutRequest{Token: userToken, Uid: victimUID})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestAdmin_DisableAndEnable(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	adminToken, _ := registerAdmin(ctx, t, st)
+
+	username := gofakeit.Username() + gofakeit.LetterN(6)
+	pass := randomFakePassword()
+
+	respReg, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	respCreate, err := st.AuthClient.CreateAccessToken(ctx, &ssov1.CreateAccessTokenRequest{
+		Token:  respLogin.GetToken(),
+		Name:   "ci",
+		Scopes: []string{"notes:read"},
+	})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.SetUserDisabled(ctx, &ssov1.SetUserDisabledRequest{
+		Token:    adminToken,
+		Uid:      respReg.GetUid(),
+		Disabled: true,
+	})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.AuthorizeAccessToken(ctx, &ssov1.AuthorizeAccessTokenRequest{
+		AccessToken: respCreate.GetAccessToken(),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// disabling ends all sessions
+	_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: respLogin.GetToken()})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: respLogin.GetRefreshToken()})
+	require.Error(t, err)
+
+	_, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AuthClient.SetUserDisabled(ctx, &ssov1.SetUserDisabledRequest{
+		Token:    adminToken,
+		Uid:      respReg.GetUid(),
+		Disabled: false,
+	})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	// access tokens stay revoked after enabling
+	_, err = st.AuthClient.AuthorizeAccessToken(ctx, &ssov1.AuthorizeAccessTokenRequest{
+		AccessToken: respCreate.GetAccessToken(),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestAdmin_ForceLogout(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	adminToken, _ := registerAdmin(ctx, t, st)
+	userToken, userUID := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+
+	_, err := st.AuthClient.ForceLogout(ctx, &ssov1.ForceLogoutRequest{Token: adminToken, Uid: userUID})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: userToken})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = st.AuthClient.ForceLogout(ctx, &ssov1.ForceLogoutRequest{Token: adminToken, Uid: 1 << 30})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAdmin_SelfActions(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	adminToken, adminUID := registerAdmin(ctx, t, st)
+
+	_, err := st.AuthClient.SetUserDisabled(ctx, &ssov1.SetUserDisabledRequest{
+		Token:    adminToken,
+		Uid:      adminUID,
+		Disabled: true,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = st.AuthClient.SetUserRole(ctx, &ssov1.SetUserRoleRequest{
+		Token: adminToken,
+		Uid:   adminUID,
+		Role:  "user",
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestAdmin_SetRole(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	adminToken, _ := registerAdmin(ctx, t, st)
+
+	username := gofakeit.Username() + gofakeit.LetterN(6)
+	oldToken, uid := registerAndLogin(ctx, t, st, username)
+
+	_, err := st.AuthClient.SetUserRole(ctx, &ssov1.SetUserRoleRequest{
+		Token: adminToken,
+		Uid:   uid,
+		Role:  "superuser",
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = st.AuthClient.SetUserRole(ctx, &ssov1.SetUserRoleRequest{
+		Token: adminToken,
+		Uid:   uid,
+		Role:  "admin",
+	})
+	require.NoError(t, err)
+
+	// tokens with the old role claim are revoked
+	_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: oldToken})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestAdmin_ListUsersAndAuditLog(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	adminToken, adminUID := registerAdmin(ctx, t, st)
+	_, userUID := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+
+	respUsers, err := st.AuthClient.ListUsers(ctx, &ssov1.ListUsersRequest{Token: adminToken, Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, respUsers.GetUsers(), 2)
+	assert.NotEmpty(t, respUsers.GetNextCursor())
+
+	_, err = st.AuthClient.ForceLogout(ctx, &ssov1.ForceLogoutRequest{Token: adminToken, Uid: userUID})
+	require.NoError(t, err)
+
+	// failed actions are recorded too
+	missingUID := int64(1 << 30)
+	_, err = st.AuthClient.ForceLogout(ctx, &ssov1.ForceLogoutRequest{Token: adminToken, Uid: missingUID})
+	require.Error(t, err)
+
+	target := "note:" + strconv.Itoa(gofakeit.Number(1, 1<<30))
+	_, err = st.AuthClient.AuthorizeAdminAction(ctx, &ssov1.AuthorizeAdminActionRequest{
+		Token:   adminToken,
+		Action:  "note.delete",
+		Target:  target,
+		Details: "spam",
+	})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.RecordAdminAction(ctx, &ssov1.RecordAdminActionRequest{
+		Token:   adminToken,
+		Action:  "note.delete",
+		Target:  target,
+		Details: "spam",
+		Result:  "ok",
+	})
+	require.NoError(t, err)
+
+	respLog, err := st.AuthClient.ListAuditLog(ctx, &ssov1.ListAuditLogRequest{Token: adminToken, Limit: 200})
+	require.NoError(t, err)
+
+	var logout, failedLogout, noteRequested, noteDelete bool
+	for _, entry := range respLog.GetEntries() {
+		if entry.GetActorUid() != adminUID {
+			continue
+		}
+		switch {
+		case entry.GetAction() == "user.logout" && entry.GetTarget() == "user:"+strconv.FormatInt(userUID, 10):
+			assert.Equal(t, "ok", entry.GetResult())
+			logout = true
+		case entry.GetAction() == "user.logout" && entry.GetTarget() == "user:"+strconv.FormatInt(missingUID, 10):
+			assert.Equal(t, "user not found", entry.GetResult())
+			failedLogout = true
+		case entry.GetAction() == "note.delete" && entry.GetTarget() == target:
+			assert.Equal(t, "spam", entry.GetDetails())
+			switch entry.GetResult() {
+			case "requested":
+				noteRequested = true
+			case "ok":
+				noteDelete = true
+			}
+		}
+	}
+	assert.True(t, logout)
+	assert.True(t, failedLogout)
+	assert.True(t, noteRequested)
+	assert.True(t, noteDelete)
+}
+
+func TestAdmin_AuthorizeActionRequiresAdmin(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	userToken, _ := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+
+	_, err := st.AuthClient.AuthorizeAdminAction(ctx, &ssov1.AuthorizeAdminActionRequest{
+		Token:  userToken,
+		Action: "note.delete",
+		Target: "note:1",
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	adminToken, _ := registerAdmin(ctx, t, st)
+
+	_, err = st.AuthClient.AuthorizeAdminAction(ctx, &ssov1.AuthorizeAdminActionRequest{
+		Token:  adminToken,
+		Action: "note.delete",
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// registerAdmin registers user, promotes it to admin and logs in,
+// so the token carries admin role.
+func registerAdmin(ctx context.Context, t *testing.T, st *suite.Suite) (string, int64) {
+	t.Helper()
+
+	username := gofakeit.Username() + gofakeit.LetterN(6)
+	pass := randomFakePassword()
+
+	respReg, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	suite.PromoteAdmin(ctx, t, st.Cfg, username)
+
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	return respLogin.GetToken(), respReg.GetUid()
+}
@@ -1,7 +1,9 @@
 package tests
 
 import (
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/liriquew/social-todo/sso_service/tests/suite"
 
@@ -10,6 +12,8 @@ import (
 	ssov1 "github.com/liriquew/todoprotos/gen/go/sso"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -39,15 +43,57 @@ func TestRegisterLogin_Login_HappyPath(t *testing.T) {
 	require.NotEmpty(t, token)
 
 	tokenParsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		return []byte(st.JWTSecret), nil
+		key, err := st.Keys.Key(token.Header["kid"].(string))
+		if err != nil {
+			return nil, err
+		}
+		return key.Public(), nil
 	})
 
 	require.NoError(t, err)
+	assert.Equal(t, st.Cfg.Keys.SigningKID, tokenParsed.Header["kid"])
 
 	claims, ok := tokenParsed.Claims.(jwt.MapClaims)
 	require.True(t, ok)
 
 	assert.Equal(t, respReg.Uid, int64(claims["uid"].(float64)))
+	assert.Equal(t, strconv.FormatInt(respReg.Uid, 10), claims["sub"])
+	assert.Equal(t, st.Cfg.Issuer, claims["iss"])
+	assert.NotEmpty(t, claims["jti"])
+
+	const deltaSeconds = 5
+	iat := int64(claims["iat"].(float64))
+	assert.InDelta(t, time.Now().Unix(), iat, deltaSeconds)
+	assert.InDelta(t, iat, int64(claims["nbf"].(float64)), deltaSeconds)
+	assert.InDelta(t, time.Now().Add(st.Cfg.TokenTTL).Unix(), int64(claims["exp"].(float64)), deltaSeconds)
+
+	respAuth, err := st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: token})
+	require.NoError(t, err)
+	assert.Equal(t, respReg.Uid, respAuth.GetUid())
+}
+
+func TestAuthorize_ExpiredToken(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	now := time.Now()
+	key := st.Keys.Signing()
+	expired := jwt.NewWithClaims(key.Method, jwt.MapClaims{
+		"uid": 1,
+		"sub": "1",
+		"jti": "expired",
+		"iss": st.Cfg.Issuer,
+		"iat": now.Add(-2 * time.Hour).Unix(),
+		"nbf": now.Add(-2 * time.Hour).Unix(),
+		"exp": now.Add(-time.Hour).Unix(),
+	})
+	expired.Header["kid"] = key.ID
+
+	token, err := expired.SignedString(key.Private)
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: token})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
 }
 
 func TestRegisterLogin_DuplicatedRegistration(t *testing.T) {
@@ -166,6 +212,15 @@ func TestLogin_FailCases(t *testing.T) {
 	}
 }
 
+// randomFakePassword has every character class the policy may require,
+// so it never fails the policy by chance.
 func randomFakePassword() string {
-	return gofakeit.Password(true, true, true, true, false, passDefaultLen)
+	pass := []rune(gofakeit.Password(true, false, false, false, false, 1) +
+		gofakeit.Password(false, true, false, false, false, 1) +
+		gofakeit.Password(false, false, true, false, false, 1) +
+		gofakeit.Password(false, false, false, true, false, 1) +
+		gofakeit.Password(true, true, true, true, false, passDefaultLen-4))
+	gofakeit.ShuffleAnySlice(pass)
+
+	return string(pass)
 }
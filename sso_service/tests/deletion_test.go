@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/liriquew/social-todo/sso_service/tests/suite"
+
+	"github.com/brianvoe/gofakeit/v6"
+	ssov1 "github.com/liriquew/todoprotos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDeleteAccount(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	username := gofakeit.Username() + gofakeit.LetterN(6)
+	pass := randomFakePassword()
+
+	respReg, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+	token := respLogin.GetToken()
+
+	_, err = st.AuthClient.DeleteAccount(ctx, &ssov1.DeleteAccountRequest{Token: token, Password: pass + "x"})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	respDel, err := st.AuthClient.DeleteAccount(ctx, &ssov1.DeleteAccountRequest{Token: token, Password: pass})
+	require.NoError(t, err)
+	require.NotEmpty(t, respDel.GetDeletionId())
+
+	// account is gone for everyone right away
+	_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: token})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.Error(t, err)
+
+	_, err = st.AuthClient.GetProfile(ctx, &ssov1.GetProfileRequest{Uid: respReg.GetUid()})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	respStatus, err := st.AuthClient.GetAccountDeletion(ctx, &ssov1.GetAccountDeletionRequest{
+		DeletionId: respDel.GetDeletionId(),
+	})
+	require.NoError(t, err)
+	assert.Contains(t, []string{"pending", "completed"}, respStatus.GetStatus())
+	assert.NotZero(t, respStatus.GetCreatedAt())
+}
+
+func TestGetAccountDeletion_NotFound(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, err := st.AuthClient.GetAccountDeletion(ctx, &ssov1.GetAccountDeletionRequest{
+		DeletionId: gofakeit.UUID(),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
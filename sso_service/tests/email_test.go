@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/liriquew/social-todo/sso_service/tests/suite"
+
+	"github.com/brianvoe/gofakeit/v6"
+	ssov1 "github.com/liriquew/todoprotos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestEmail_RegisterAndVerify(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	username := gofakeit.Username() + gofakeit.LetterN(6)
+	pass := randomFakePassword()
+	email := randomEmail()
+
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass, Email: email})
+	require.NoError(t, err)
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	respEmail, err := st.AuthClient.GetEmail(ctx, &ssov1.GetEmailRequest{Token: respLogin.GetToken()})
+	require.NoError(t, err)
+	assert.Equal(t, email, respEmail.GetEmail())
+	assert.False(t, respEmail.GetVerified())
+
+	respAuth, err := st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: respLogin.GetToken()})
+	require.NoError(t, err)
+	assert.False(t, respAuth.GetEmailVerified())
+
+	token := mailedToken(t, st, email)
+	_, err = st.AuthClient.VerifyEmail(ctx, &ssov1.VerifyEmailRequest{VerificationToken: token})
+	require.NoError(t, err)
+
+	// token is single-use
+	_, err = st.AuthClient.VerifyEmail(ctx, &ssov1.VerifyEmailRequest{VerificationToken: token})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	respEmail, err = st.AuthClient.GetEmail(ctx, &ssov1.GetEmailRequest{Token: respLogin.GetToken()})
+	require.NoError(t, err)
+	assert.True(t, respEmail.GetVerified())
+
+	// tokens carry verification state they were issued with
+	respRefresh, err := st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: respLogin.GetRefreshToken()})
+	require.NoError(t, err)
+	respAuth, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: respRefresh.GetToken()})
+	require.NoError(t, err)
+	assert.True(t, respAuth.GetEmailVerified())
+
+	_, err = st.AuthClient.SendVerification(ctx, &ssov1.SendVerificationRequest{Token: respRefresh.GetToken()})
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestEmail_SetAndResend(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	username := gofakeit.Username() + gofakeit.LetterN(6)
+	token, _ := registerAndLogin(ctx, t, st, username)
+
+	_, err := st.AuthClient.SendVerification(ctx, &ssov1.SendVerificationRequest{Token: token})
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	for _, email := range []string{"not an email", "Name <name@example.com>", strings.Repeat("a", 250) + "@example.com"} {
+		_, err = st.AuthClient.SetEmail(ctx, &ssov1.SetEmailRequest{Token: token, Email: email})
+		require.Error(t, err, email)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), email)
+	}
+
+	email := randomEmail()
+	_, err = st.AuthClient.SetEmail(ctx, &ssov1.SetEmailRequest{Token: token, Email: email})
+	require.NoError(t, err)
+	require.NotEmpty(t, mailedToken(t, st, email))
+
+	_, err = st.AuthClient.SendVerification(ctx, &ssov1.SendVerificationRequest{Token: token})
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// switching addresses doesn't bypass the interval
+	_, err = st.AuthClient.SetEmail(ctx, &ssov1.SetEmailRequest{Token: token, Email: randomEmail()})
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = st.AuthClient.SetEmail(ctx, &ssov1.SetEmailRequest{Token: token, Email: ""})
+	require.NoError(t, err)
+
+	respEmail, err := st.AuthClient.GetEmail(ctx, &ssov1.GetEmailRequest{Token: token})
+	require.NoError(t, err)
+	assert.Empty(t, respEmail.GetEmail())
+	assert.False(t, respEmail.GetVerified())
+}
+
+func TestEmail_VerifiedByAnotherAccount(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email := randomEmail()
+	tokens := make([]string, 2)
+	for i := range tokens {
+		token, _ := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+		_, err := st.AuthClient.SetEmail(ctx, &ssov1.SetEmailRequest{Token: token, Email: email})
+		require.NoError(t, err)
+		tokens[i] = mailedToken(t, st, email)
+	}
+
+	_, err := st.AuthClient.VerifyEmail(ctx, &ssov1.VerifyEmailRequest{VerificationToken: tokens[1]})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.VerifyEmail(ctx, &ssov1.VerifyEmailRequest{VerificationToken: tokens[0]})
+	require.Error(t, err)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+}
+
+func TestEmail_RemoveVerifiedRevokesTokens(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	username := gofakeit.Username() + gofakeit.LetterN(6)
+	pass := randomFakePassword()
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	verifyEmail(ctx, t, st, respLogin.GetToken(), randomEmail())
+	respRefresh, err := st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: respLogin.GetRefreshToken()})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.SetEmail(ctx, &ssov1.SetEmailRequest{Token: respRefresh.GetToken(), Email: ""})
+	require.NoError(t, err)
+
+	// the token carried verification of removed email
+	_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: respRefresh.GetToken()})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	respRefresh, err = st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: respRefresh.GetRefreshToken()})
+	require.NoError(t, err)
+	respAuth, err := st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: respRefresh.GetToken()})
+	require.NoError(t, err)
+	assert.False(t, respAuth.GetEmailVerified())
+}
+
+func TestRequestPasswordReset_UnverifiedEmail(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	username := gofakeit.Username() + gofakeit.LetterN(6)
+	email := randomEmail()
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: randomFakePassword(), Email: email})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.RequestPasswordReset(ctx, &ssov1.RequestPasswordResetRequest{Username: username})
+	require.NoError(t, err)
+
+	// only verification was mailed
+	assert.NotContains(t, suite.LastMail(t, st.Cfg, email), "reset")
+}
+
+// verifyEmail registers email of the token owner and verifies it.
+func verifyEmail(ctx context.Context, t *testing.T, st *suite.Suite, token, email string) {
+	t.Helper()
+
+	_, err := st.AuthClient.SetEmail(ctx, &ssov1.SetEmailRequest{Token: token, Email: email})
+	require.NoError(t, err)
+	_, err = st.AuthClient.VerifyEmail(ctx, &ssov1.VerifyEmailRequest{VerificationToken: mailedToken(t, st, email)})
+	require.NoError(t, err)
+}
+
+// mailedToken returns token from the last mail sent to the address,
+// it ends the first line.
+func mailedToken(t *testing.T, st *suite.Suite, to string) string {
+	t.Helper()
+
+	line, _, _ := strings.Cut(suite.LastMail(t, st.Cfg, to), "\n")
+	return line[strings.LastIndex(line, " ")+1:]
+}
+
+func randomEmail() string {
+	return gofakeit.LetterN(10) + "@" + gofakeit.DomainName()
+}
@@ -0,0 +1,314 @@
+package tests
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/liriquew/social-todo/sso_service/tests/suite"
+
+	"github.com/brianvoe/gofakeit/v6"
+	ssov1 "github.com/liriquew/todoprotos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestExternalLogin_ListConnectors(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	resp, err := st.AuthClient.ListConnectors(ctx, &ssov1.ListConnectorsRequest{})
+	require.NoError(t, err)
+
+	var found bool
+	for _, c := range resp.GetConnectors() {
+		if c.GetId() == suite.FakeConnectorID {
+			found = true
+			assert.Equal(t, "Fake IdP", c.GetName())
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestExternalLogin_LinkAndLogin(t *testing.T) {
+	ctx, st := suite.New(t)
+	idp := suite.FakeProvider(t, st.Cfg)
+
+	username := gofakeit.Username() + gofakeit.LetterN(6)
+	pass := randomFakePassword()
+	respReg, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	identity := fakeIdentity()
+	linked := linkIdentity(ctx, t, st, idp, respLogin.GetToken(), identity)
+	assert.Equal(t, suite.FakeConnectorID, linked.GetConnectorId())
+	assert.Equal(t, identity.Subject, linked.GetSubject())
+	assert.Equal(t, identity.Username, linked.GetUsername())
+
+	respList, err := st.AuthClient.ListIdentities(ctx, &ssov1.ListIdentitiesRequest{Token: respLogin.GetToken()})
+	require.NoError(t, err)
+	require.Len(t, respList.GetIdentities(), 1)
+	assert.Equal(t, identity.Subject, respList.GetIdentities()[0].GetSubject())
+
+	respExt := externalLogin(ctx, t, st, idp, identity)
+	require.NotEmpty(t, respExt.GetToken())
+	require.NotEmpty(t, respExt.GetRefreshToken())
+
+	respAuth, err := st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: respExt.GetToken()})
+	require.NoError(t, err)
+	assert.Equal(t, respReg.GetUid(), respAuth.GetUid())
+
+	// password login keeps working
+	_, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+}
+
+func TestExternalLogin_NotLinked(t *testing.T) {
+	ctx, st := suite.New(t)
+	idp := suite.FakeProvider(t, st.Cfg)
+
+	respStart, err := st.AuthClient.StartExternalLogin(ctx, &ssov1.StartExternalLoginRequest{ConnectorId: suite.FakeConnectorID})
+	require.NoError(t, err)
+
+	code, state := idp.Authorize(t, respStart.GetAuthUrl(), fakeIdentity())
+
+	_, err = st.AuthClient.ExternalLogin(ctx, &ssov1.ExternalLoginRequest{
+		ConnectorId: suite.FakeConnectorID,
+		State:       state,
+		Code:        code,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestExternalLogin_StateChecks(t *testing.T) {
+	ctx, st := suite.New(t)
+	idp := suite.FakeProvider(t, st.Cfg)
+
+	token, _ := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+	identity := fakeIdentity()
+	linkIdentity(ctx, t, st, idp, token, identity)
+
+	respStart, err := st.AuthClient.StartExternalLogin(ctx, &ssov1.StartExternalLoginRequest{ConnectorId: suite.FakeConnectorID})
+	require.NoError(t, err)
+
+	authURL, err := url.Parse(respStart.GetAuthUrl())
+	require.NoError(t, err)
+	assert.Equal(t, "S256", authURL.Query().Get("code_challenge_method"))
+
+	code, state := idp.Authorize(t, respStart.GetAuthUrl(), identity)
+
+	// unknown state
+	_, err = st.AuthClient.ExternalLogin(ctx, &ssov1.ExternalLoginRequest{
+		ConnectorId: suite.FakeConnectorID,
+		State:       gofakeit.LetterN(43),
+		Code:        code,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = st.AuthClient.ExternalLogin(ctx, &ssov1.ExternalLoginRequest{
+		ConnectorId: suite.FakeConnectorID,
+		State:       state,
+		Code:        code,
+	})
+	require.NoError(t, err)
+
+	// state can be used once
+	_, err = st.AuthClient.ExternalLogin(ctx, &ssov1.ExternalLoginRequest{
+		ConnectorId: suite.FakeConnectorID,
+		State:       state,
+		Code:        code,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// state issued for linking can't be used to login
+	respLink, err := st.AuthClient.StartLinkIdentity(ctx, &ssov1.StartLinkIdentityRequest{
+		Token:       token,
+		ConnectorId: suite.FakeConnectorID,
+	})
+	require.NoError(t, err)
+	code, state = idp.Authorize(t, respLink.GetAuthUrl(), identity)
+
+	_, err = st.AuthClient.ExternalLogin(ctx, &ssov1.ExternalLoginRequest{
+		ConnectorId: suite.FakeConnectorID,
+		State:       state,
+		Code:        code,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestExternalLogin_UnknownConnector(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, err := st.AuthClient.StartExternalLogin(ctx, &ssov1.StartExternalLoginRequest{ConnectorId: "unknown"})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestLinkIdentity_AlreadyLinked(t *testing.T) {
+	ctx, st := suite.New(t)
+	idp := suite.FakeProvider(t, st.Cfg)
+
+	firstToken, _ := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+	secondToken, _ := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+
+	identity := fakeIdentity()
+	linkIdentity(ctx, t, st, idp, firstToken, identity)
+
+	// the same identity can't be linked to another account
+	respStart, err := st.AuthClient.StartLinkIdentity(ctx, &ssov1.StartLinkIdentityRequest{
+		Token:       secondToken,
+		ConnectorId: suite.FakeConnectorID,
+	})
+	require.NoError(t, err)
+	code, state := idp.Authorize(t, respStart.GetAuthUrl(), identity)
+
+	_, err = st.AuthClient.LinkIdentity(ctx, &ssov1.LinkIdentityRequest{
+		Token:       secondToken,
+		ConnectorId: suite.FakeConnectorID,
+		State:       state,
+		Code:        code,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	// and the account can't have two identities of one provider
+	respStart, err = st.AuthClient.StartLinkIdentity(ctx, &ssov1.StartLinkIdentityRequest{
+		Token:       firstToken,
+		ConnectorId: suite.FakeConnectorID,
+	})
+	require.NoError(t, err)
+	code, state = idp.Authorize(t, respStart.GetAuthUrl(), fakeIdentity())
+
+	_, err = st.AuthClient.LinkIdentity(ctx, &ssov1.LinkIdentityRequest{
+		Token:       firstToken,
+		ConnectorId: suite.FakeConnectorID,
+		State:       state,
+		Code:        code,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+}
+
+func TestLinkIdentity_StateOfAnotherUser(t *testing.T) {
+	ctx, st := suite.New(t)
+	idp := suite.FakeProvider(t, st.Cfg)
+
+	firstToken, _ := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+	secondToken, _ := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+
+	respStart, err := st.AuthClient.StartLinkIdentity(ctx, &ssov1.StartLinkIdentityRequest{
+		Token:       firstToken,
+		ConnectorId: suite.FakeConnectorID,
+	})
+	require.NoError(t, err)
+	code, state := idp.Authorize(t, respStart.GetAuthUrl(), fakeIdentity())
+
+	_, err = st.AuthClient.LinkIdentity(ctx, &ssov1.LinkIdentityRequest{
+		Token:       secondToken,
+		ConnectorId: suite.FakeConnectorID,
+		State:       state,
+		Code:        code,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestUnlinkIdentity(t *testing.T) {
+	ctx, st := suite.New(t)
+	idp := suite.FakeProvider(t, st.Cfg)
+
+	token, _ := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+	identity := fakeIdentity()
+	linkIdentity(ctx, t, st, idp, token, identity)
+
+	_, err := st.AuthClient.UnlinkIdentity(ctx, &ssov1.UnlinkIdentityRequest{Token: token, ConnectorId: suite.FakeConnectorID})
+	require.NoError(t, err)
+
+	respList, err := st.AuthClient.ListIdentities(ctx, &ssov1.ListIdentitiesRequest{Token: token})
+	require.NoError(t, err)
+	assert.Empty(t, respList.GetIdentities())
+
+	_, err = st.AuthClient.UnlinkIdentity(ctx, &ssov1.UnlinkIdentityRequest{Token: token, ConnectorId: suite.FakeConnectorID})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	respStart, err := st.AuthClient.StartExternalLogin(ctx, &ssov1.StartExternalLoginRequest{ConnectorId: suite.FakeConnectorID})
+	require.NoError(t, err)
+	code, state := idp.Authorize(t, respStart.GetAuthUrl(), identity)
+
+	_, err = st.AuthClient.ExternalLogin(ctx, &ssov1.ExternalLoginRequest{
+		ConnectorId: suite.FakeConnectorID,
+		State:       state,
+		Code:        code,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func fakeIdentity() suite.FakeIdentity {
+	return suite.FakeIdentity{
+		Subject:  gofakeit.UUID(),
+		Username: gofakeit.Username(),
+		Email:    gofakeit.Email(),
+	}
+}
+
+func linkIdentity(
+	ctx context.Context,
+	t *testing.T,
+	st *suite.Suite,
+	idp *suite.FakeIdP,
+	token string,
+	identity suite.FakeIdentity,
+) *ssov1.Identity {
+	t.Helper()
+
+	respStart, err := st.AuthClient.StartLinkIdentity(ctx, &ssov1.StartLinkIdentityRequest{
+		Token:       token,
+		ConnectorId: suite.FakeConnectorID,
+	})
+	require.NoError(t, err)
+
+	code, state := idp.Authorize(t, respStart.GetAuthUrl(), identity)
+
+	resp, err := st.AuthClient.LinkIdentity(ctx, &ssov1.LinkIdentityRequest{
+		Token:       token,
+		ConnectorId: suite.FakeConnectorID,
+		State:       state,
+		Code:        code,
+	})
+	require.NoError(t, err)
+
+	return resp.GetIdentity()
+}
+
+func externalLogin(
+	ctx context.Context,
+	t *testing.T,
+	st *suite.Suite,
+	idp *suite.FakeIdP,
+	identity suite.FakeIdentity,
+) *ssov1.LoginResponse {
+	t.Helper()
+
+	respStart, err := st.AuthClient.StartExternalLogin(ctx, &ssov1.StartExternalLoginRequest{ConnectorId: suite.FakeConnectorID})
+	require.NoError(t, err)
+
+	code, state := idp.Authorize(t, respStart.GetAuthUrl(), identity)
+
+	resp, err := st.AuthClient.ExternalLogin(ctx, &ssov1.ExternalLoginRequest{
+		ConnectorId: suite.FakeConnectorID,
+		State:       state,
+		Code:        code,
+	})
+	require.NoError(t, err)
+
+	return resp
+}
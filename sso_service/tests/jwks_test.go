@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/liriquew/social-todo/sso_service/tests/suite"
+
+	"github.com/golang-jwt/jwt/v5"
+	ssov1 "github.com/liriquew/todoprotos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestJWKS(t *testing.T) {
+	_, st := suite.New(t)
+
+	resp, err := http.Get(suite.JWKSURL(st.Cfg))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var jwks struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&jwks))
+
+	kids := make([]string, 0, len(jwks.Keys))
+	for _, key := range jwks.Keys {
+		kids = append(kids, key["kid"])
+		assert.Equal(t, "sig", key["use"])
+		assert.Empty(t, key["d"], "private part must not be published")
+	}
+	for _, file := range st.Cfg.Keys.Files {
+		assert.Contains(t, kids, file.KID)
+	}
+}
+
+func TestAuthorize_SymmetricTokenRejected(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	now := time.Now()
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"uid": 1,
+		"sub": "1",
+		"jti": "forged",
+		"iss": st.Cfg.Issuer,
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	})
+	forged.Header["kid"] = st.Cfg.Keys.SigningKID
+
+	token, err := forged.SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: token})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
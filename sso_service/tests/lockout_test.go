@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/liriquew/social-todo/sso_service/tests/suite"
+
+	"github.com/brianvoe/gofakeit/v6"
+	ssov1 "github.com/liriquew/todoprotos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLogin_WrongPassword(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	username := gofakeit.Username()
+
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: randomFakePassword()})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: randomFakePassword()})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.ErrorContains(t, err, "invalid username or password")
+}
+
+func TestLogin_UnknownUser(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	// same answer as for wrong password, and unknown names aren't locked
+	for range st.Cfg.Lockout.FreeAttempts + 1 {
+		_, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: gofakeit.Username(), Password: randomFakePassword()})
+		require.Error(t, err)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.ErrorContains(t, err, "invalid username or password")
+	}
+}
+
+func TestLogin_Lockout(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	username := gofakeit.Username()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	// free attempts and the one that locks
+	for range st.Cfg.Lockout.FreeAttempts + 1 {
+		_, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: randomFakePassword()})
+		require.Error(t, err)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+
+	// even correct password is rejected while locked
+	_, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.Error(t, err)
+
+	s := status.Convert(err)
+	require.Equal(t, codes.ResourceExhausted, s.Code())
+
+	var retry *errdetails.RetryInfo
+	for _, d := range s.Details() {
+		if ri, ok := d.(*errdetails.RetryInfo); ok {
+			retry = ri
+		}
+	}
+	require.NotNil(t, retry)
+	assert.Positive(t, retry.GetRetryDelay().AsDuration())
+}
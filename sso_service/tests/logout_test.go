@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/liriquew/social-todo/sso_service/tests/suite"
+
+	"github.com/brianvoe/gofakeit/v6"
+	ssov1 "github.com/liriquew/todoprotos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLogout(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	username := gofakeit.Username()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	first, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+	second, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.Logout(ctx, &ssov1.LogoutRequest{
+		Token:        first.GetToken(),
+		RefreshToken: first.GetRefreshToken(),
+	})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: first.GetToken()})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: first.GetRefreshToken()})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// other sessions are not affected
+	_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: second.GetToken()})
+	require.NoError(t, err)
+}
+
+func TestLogoutAll(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	username := gofakeit.Username()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	first, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+	second, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.LogoutAll(ctx, &ssov1.LogoutAllRequest{Token: first.GetToken()})
+	require.NoError(t, err)
+
+	for _, resp := range []*ssov1.LoginResponse{first, second} {
+		_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: resp.GetToken()})
+		require.Error(t, err)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: resp.GetRefreshToken()})
+		require.Error(t, err)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+
+	// tokens issued right after LogoutAll are fine
+	third, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+	_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: third.GetToken()})
+	require.NoError(t, err)
+}
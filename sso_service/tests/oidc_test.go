@@ -0,0 +1,355 @@
+package tests
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/liriquew/social-todo/sso_service/tests/suite"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/golang-jwt/jwt/v5"
+	ssov1 "github.com/liriquew/todoprotos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const oidcRedirectURI = "http://localhost:9999/callback"
+
+func TestOIDC_Discovery(t *testing.T) {
+	_, st := suite.New(t)
+
+	resp, err := http.Get(st.Cfg.OIDC.Issuer + "/.well-known/openid-configuration")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var doc map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+
+	assert.Equal(t, st.Cfg.OIDC.Issuer, doc["issuer"])
+	assert.Equal(t, st.Cfg.OIDC.Issuer+"/oauth2/authorize", doc["authorization_endpoint"])
+	assert.Equal(t, st.Cfg.OIDC.Issuer+"/oauth2/token", doc["token_endpoint"])
+	assert.Equal(t, st.Cfg.OIDC.Issuer+"/oauth2/userinfo", doc["userinfo_endpoint"])
+	assert.Equal(t, suite.JWKSURL(st.Cfg), doc["jwks_uri"])
+	assert.Equal(t, []any{"S256"}, doc["code_challenge_methods_supported"])
+}
+
+func TestOIDC_CodeFlow(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	clientID, secret := createOIDCClient(ctx, t, st, false)
+
+	username := gofakeit.Username() + gofakeit.LetterN(6)
+	pass := randomFakePassword()
+	respReg, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	verifier := gofakeit.LetterN(64)
+	params := authParams(clientID, verifier)
+
+	// login page
+	resp, err := noRedirects().Get(st.Cfg.OIDC.Issuer + "/oauth2/authorize?" + params.Encode())
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	code := oidcLogin(t, st, params, username, pass)
+
+	// confidential client must authenticate
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {oidcRedirectURI},
+		"code_verifier": {verifier},
+	}
+	tokens, statusCode := exchangeCode(t, st, clientID, secret, form)
+	require.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, "Bearer", tokens["token_type"])
+	assert.Equal(t, "openid profile", tokens["scope"])
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(tokens["id_token"].(string), claims, func(token *jwt.Token) (interface{}, error) {
+		key, err := st.Keys.Key(token.Header["kid"].(string))
+		if err != nil {
+			return nil, err
+		}
+		return key.Public(), nil
+	}, jwt.WithIssuer(st.Cfg.OIDC.Issuer), jwt.WithAudience(clientID), jwt.WithExpirationRequired())
+	require.NoError(t, err)
+	assert.Equal(t, strconv.FormatInt(respReg.GetUid(), 10), claims["sub"])
+	assert.Equal(t, params.Get("nonce"), claims["nonce"])
+	assert.Equal(t, username, claims["preferred_username"])
+
+	// code can be used once
+	_, statusCode = exchangeCode(t, st, clientID, secret, form)
+	assert.Equal(t, http.StatusBadRequest, statusCode)
+
+	req, err := http.NewRequest(http.MethodGet, st.Cfg.OIDC.Issuer+"/oauth2/userinfo", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+tokens["access_token"].(string))
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var info map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+	assert.Equal(t, strconv.FormatInt(respReg.GetUid(), 10), info["sub"])
+	assert.Equal(t, username, info["preferred_username"])
+
+	// access token is good for userinfo only
+	_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: tokens["access_token"].(string)})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestOIDC_BadVerifierAndSecret(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	clientID, secret := createOIDCClient(ctx, t, st, false)
+
+	username := gofakeit.Username() + gofakeit.LetterN(6)
+	pass := randomFakePassword()
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	verifier := gofakeit.LetterN(64)
+	code := oidcLogin(t, st, authParams(clientID, verifier), username, pass)
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {oidcRedirectURI},
+		"code_verifier": {verifier},
+	}
+
+	tokens, status := exchangeCode(t, st, clientID, "wrong"+secret, form)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, "invalid_client", tokens["error"])
+
+	form.Set("code_verifier", gofakeit.LetterN(64))
+	tokens, status = exchangeCode(t, st, clientID, secret, form)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", tokens["error"])
+}
+
+func TestOIDC_CodeOfAnotherClient(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	clientID, secret := createOIDCClient(ctx, t, st, false)
+	otherID, otherSecret := createOIDCClient(ctx, t, st, false)
+
+	username := gofakeit.Username() + gofakeit.LetterN(6)
+	pass := randomFakePassword()
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	verifier := gofakeit.LetterN(64)
+	code := oidcLogin(t, st, authParams(clientID, verifier), username, pass)
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {oidcRedirectURI},
+		"code_verifier": {verifier},
+	}
+
+	tokens, status := exchangeCode(t, st, otherID, otherSecret, form)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", tokens["error"])
+
+	// the attempt doesn't use up the code
+	_, status = exchangeCode(t, st, clientID, secret, form)
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestOIDC_PublicClient(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	clientID, secret := createOIDCClient(ctx, t, st, true)
+	assert.Empty(t, secret)
+
+	username := gofakeit.Username() + gofakeit.LetterN(6)
+	pass := randomFakePassword()
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	verifier := gofakeit.LetterN(64)
+	code := oidcLogin(t, st, authParams(clientID, verifier), username, pass)
+
+	tokens, status := exchangeCode(t, st, "", "", url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {clientID},
+		"code":          {code},
+		"redirect_uri":  {oidcRedirectURI},
+		"code_verifier": {verifier},
+	})
+	require.Equal(t, http.StatusOK, status)
+	assert.NotEmpty(t, tokens["id_token"])
+}
+
+func TestOIDC_AuthorizeErrors(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	clientID, _ := createOIDCClient(ctx, t, st, false)
+
+	// unregistered redirect uri is never redirected to
+	params := authParams(clientID, gofakeit.LetterN(64))
+	params.Set("redirect_uri", "https://evil.example.com/callback")
+	resp, err := noRedirects().Get(st.Cfg.OIDC.Issuer + "/oauth2/authorize?" + params.Encode())
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// PKCE is required
+	params = authParams(clientID, gofakeit.LetterN(64))
+	params.Del("code_challenge")
+	resp, err = noRedirects().Get(st.Cfg.OIDC.Issuer + "/oauth2/authorize?" + params.Encode())
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "invalid_request", location.Query().Get("error"))
+	assert.Equal(t, params.Get("state"), location.Query().Get("state"))
+
+	// wrong password shows the login page again
+	username := gofakeit.Username() + gofakeit.LetterN(6)
+	_, err = st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: randomFakePassword()})
+	require.NoError(t, err)
+
+	params = authParams(clientID, gofakeit.LetterN(64))
+	params.Set("username", username)
+	params.Set("password", "wrong"+randomFakePassword())
+	resp, err = noRedirects().PostForm(st.Cfg.OIDC.Issuer+"/oauth2/authorize", params)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestOIDC_ClientsAdminOnly(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	userToken, _ := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+
+	_, err := st.AuthClient.CreateOIDCClient(ctx, &ssov1.CreateOIDCClientRequest{
+		Token:        userToken,
+		Name:         "tool",
+		RedirectUris: []string{oidcRedirectURI},
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	adminToken, _ := registerAdmin(ctx, t, st)
+
+	_, err = st.AuthClient.CreateOIDCClient(ctx, &ssov1.CreateOIDCClientRequest{
+		Token:        adminToken,
+		Name:         "tool",
+		RedirectUris: []string{"http://example.com/callback"},
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	clientID, _ := createOIDCClient(ctx, t, st, false)
+
+	_, err = st.AuthClient.DeleteOIDCClient(ctx, &ssov1.DeleteOIDCClientRequest{Token: adminToken, ClientId: clientID})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.DeleteOIDCClient(ctx, &ssov1.DeleteOIDCClientRequest{Token: adminToken, ClientId: clientID})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func createOIDCClient(ctx context.Context, t *testing.T, st *suite.Suite, public bool) (string, string) {
+	t.Helper()
+
+	adminToken, _ := registerAdmin(ctx, t, st)
+
+	resp, err := st.AuthClient.CreateOIDCClient(ctx, &ssov1.CreateOIDCClientRequest{
+		Token:        adminToken,
+		Name:         "tool " + gofakeit.LetterN(6),
+		RedirectUris: []string{oidcRedirectURI},
+		Public:       public,
+	})
+	require.NoError(t, err)
+
+	return resp.GetClient().GetClientId(), resp.GetClientSecret()
+}
+
+func authParams(clientID, verifier string) url.Values {
+	sum := sha256.Sum256([]byte(verifier))
+
+	return url.Values{
+		"response_type":         {"code"},
+		"client_id":             {clientID},
+		"redirect_uri":          {oidcRedirectURI},
+		"scope":                 {"openid profile"},
+		"state":                 {gofakeit.LetterN(16)},
+		"nonce":                 {gofakeit.LetterN(16)},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(sum[:])},
+		"code_challenge_method": {"S256"},
+	}
+}
+
+// oidcLogin submits the login page and returns the code from the redirect.
+func oidcLogin(t *testing.T, st *suite.Suite, params url.Values, username, pass string) string {
+	t.Helper()
+
+	form := url.Values{}
+	for k, v := range params {
+		form[k] = v
+	}
+	form.Set("username", username)
+	form.Set("password", pass)
+
+	resp, err := noRedirects().PostForm(st.Cfg.OIDC.Issuer+"/oauth2/authorize", form)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(location.String(), oidcRedirectURI))
+	require.Equal(t, params.Get("state"), location.Query().Get("state"))
+	require.NotEmpty(t, location.Query().Get("code"))
+
+	return location.Query().Get("code")
+}
+
+func exchangeCode(t *testing.T, st *suite.Suite, clientID, secret string, form url.Values) (map[string]any, int) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, st.Cfg.OIDC.Issuer+"/oauth2/token", strings.NewReader(form.Encode()))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if clientID != "" {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(secret))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	return body, resp.StatusCode
+}
+
+func noRedirects() *http.Client {
+	return &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
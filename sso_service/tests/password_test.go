@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/liriquew/social-todo/sso_service/tests/suite"
+
+	"github.com/brianvoe/gofakeit/v6"
+	ssov1 "github.com/liriquew/todoprotos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestChangePassword(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	username := gofakeit.Username()
+	pass := randomFakePassword()
+	newPass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.ChangePassword(ctx, &ssov1.ChangePasswordRequest{
+		Token:       respLogin.GetToken(),
+		OldPassword: randomFakePassword(),
+		NewPassword: newPass,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AuthClient.ChangePassword(ctx, &ssov1.ChangePasswordRequest{
+		Token:       respLogin.GetToken(),
+		OldPassword: pass,
+		NewPassword: newPass,
+	})
+	require.NoError(t, err)
+
+	// sessions are revoked
+	_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: respLogin.GetToken()})
+	require.Error(t, err)
+	_, err = st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: respLogin.GetRefreshToken()})
+	require.Error(t, err)
+
+	_, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.Error(t, err)
+
+	_, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: newPass})
+	require.NoError(t, err)
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	username := gofakeit.Username()
+	pass := randomFakePassword()
+	newPass := randomFakePassword()
+
+	email := randomEmail()
+
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	// reset is mailed to verified email only
+	verifyEmail(ctx, t, st, respLogin.GetToken(), email)
+
+	_, err = st.AuthClient.RequestPasswordReset(ctx, &ssov1.RequestPasswordResetRequest{Username: username})
+	require.NoError(t, err)
+
+	resetToken := mailedToken(t, st, email)
+	require.NotEmpty(t, resetToken)
+
+	_, err = st.AuthClient.ResetPassword(ctx, &ssov1.ResetPasswordRequest{ResetToken: resetToken, NewPassword: newPass})
+	require.NoError(t, err)
+
+	// token is single-use
+	_, err = st.AuthClient.ResetPassword(ctx, &ssov1.ResetPasswordRequest{ResetToken: resetToken, NewPassword: pass})
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: newPass})
+	require.NoError(t, err)
+}
+
+func TestRequestPasswordReset_UnknownUser(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, err := st.AuthClient.RequestPasswordReset(ctx, &ssov1.RequestPasswordResetRequest{Username: gofakeit.Username()})
+	require.NoError(t, err)
+}
+
+func TestRegister_WeakPassword(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{
+		Username: gofakeit.Username(),
+		Password: "password",
+	})
+	require.Error(t, err)
+
+	s := status.Convert(err)
+	require.Equal(t, codes.InvalidArgument, s.Code())
+
+	var fields []string
+	for _, d := range s.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				fields = append(fields, v.GetField())
+			}
+		}
+	}
+	assert.Contains(t, fields, "password.upper")
+	assert.Contains(t, fields, "password.digit")
+	assert.Contains(t, fields, "password.denylist")
+	assert.NotContains(t, fields, "password.min_length")
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/liriquew/social-todo/sso_service/tests/suite"
+
+	"github.com/brianvoe/gofakeit/v6"
+	ssov1 "github.com/liriquew/todoprotos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestProfile_UpdateAndGet(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	username := gofakeit.Username()
+	pass := randomFakePassword()
+
+	respReg, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	// profile exists right after registration, just empty
+	respGet, err := st.AuthClient.GetProfile(ctx, &ssov1.GetProfileRequest{Uid: respReg.GetUid()})
+	require.NoError(t, err)
+	assert.Equal(t, username, respGet.GetProfile().GetUsername())
+	assert.Empty(t, respGet.GetProfile().GetBio())
+
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	displayName := gofakeit.Name()
+	respUpd, err := st.AuthClient.UpdateProfile(ctx, &ssov1.UpdateProfileRequest{
+		Token:       respLogin.GetToken(),
+		DisplayName: proto.String("  " + displayName + " "),
+		Bio:         proto.String("hello"),
+		AvatarUrl:   proto.String("https://example.com/a.png"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, displayName, respUpd.GetProfile().GetDisplayName())
+	assert.NotZero(t, respUpd.GetProfile().GetUpdatedAt())
+
+	// omitted fields are kept
+	respUpd, err = st.AuthClient.UpdateProfile(ctx, &ssov1.UpdateProfileRequest{
+		Token: respLogin.GetToken(),
+		Bio:   proto.String("bye"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, displayName, respUpd.GetProfile().GetDisplayName())
+	assert.Equal(t, "https://example.com/a.png", respUpd.GetProfile().GetAvatarUrl())
+
+	respGet, err = st.AuthClient.GetProfile(ctx, &ssov1.GetProfileRequest{Uid: respReg.GetUid()})
+	require.NoError(t, err)
+	assert.Equal(t, "bye", respGet.GetProfile().GetBio())
+
+	// display name is also returned with other user info
+	respUsers, err := st.AuthClient.GetUsers(ctx, &ssov1.GetUsersRequest{Uids: []int64{respReg.GetUid()}})
+	require.NoError(t, err)
+	require.Len(t, respUsers.GetUsers(), 1)
+	assert.Equal(t, displayName, respUsers.GetUsers()[0].GetDisplayName())
+}
+
+func TestProfile_Invalid(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	username := gofakeit.Username()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		req   *ssov1.UpdateProfileRequest
+		field string
+	}{
+		{
+			name:  "Too Long Display Name",
+			req:   &ssov1.UpdateProfileRequest{DisplayName: proto.String(strings.Repeat("a", 65))},
+			field: "display_name",
+		},
+		{
+			name:  "Too Long Bio",
+			req:   &ssov1.UpdateProfileRequest{Bio: proto.String(strings.Repeat("a", 501))},
+			field: "bio",
+		},
+		{
+			name:  "Not HTTP Avatar",
+			req:   &ssov1.UpdateProfileRequest{AvatarUrl: proto.String("javascript:alert(1)")},
+			field: "avatar_url",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Token = respLogin.GetToken()
+
+			_, err := st.AuthClient.UpdateProfile(ctx, tt.req)
+			require.Error(t, err)
+
+			s := status.Convert(err)
+			assert.Equal(t, codes.InvalidArgument, s.Code())
+
+			var fields []string
+			for _, d := range s.Details() {
+				if br, ok := d.(*errdetails.BadRequest); ok {
+					for _, v := range br.GetFieldViolations() {
+						fields = append(fields, v.GetField())
+					}
+				}
+			}
+			assert.Equal(t, []string{tt.field}, fields)
+		})
+	}
+
+	_, err = st.AuthClient.UpdateProfile(ctx, &ssov1.UpdateProfileRequest{
+		Token: "not a token",
+		Bio:   proto.String("bio"),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestBatchGetProfiles(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	uids := make([]int64, 0, 3)
+	for range 3 {
+		respReg, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{
+			Username: gofakeit.Username(),
+			Password: randomFakePassword(),
+		})
+		require.NoError(t, err)
+		uids = append(uids, respReg.GetUid())
+	}
+
+	// unknown uid is skipped
+	resp, err := st.AuthClient.BatchGetProfiles(ctx, &ssov1.BatchGetProfilesRequest{
+		Uids: append(uids, 1<<30),
+	})
+	require.NoError(t, err)
+	assert.Len(t, resp.GetProfiles(), len(uids))
+
+	_, err = st.AuthClient.GetProfile(ctx, &ssov1.GetProfileRequest{Uid: 1 << 30})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
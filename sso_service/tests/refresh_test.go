@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/liriquew/social-todo/sso_service/tests/suite"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/golang-jwt/jwt/v5"
+	ssov1 "github.com/liriquew/todoprotos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRefresh_Rotation(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	username := gofakeit.Username()
+	pass := randomFakePassword()
+
+	respReg, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{
+		Username: username,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{
+		Username: username,
+		Password: pass,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, respLogin.GetRefreshToken())
+
+	respRefresh, err := st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: respLogin.GetRefreshToken()})
+	require.NoError(t, err)
+	require.NotEmpty(t, respRefresh.GetToken())
+	assert.NotEqual(t, respLogin.GetRefreshToken(), respRefresh.GetRefreshToken())
+
+	respAuth, err := st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: respRefresh.GetToken()})
+	require.NoError(t, err)
+	assert.Equal(t, respReg.GetUid(), respAuth.GetUid())
+
+	// reuse of rotated token revokes the whole family
+	_, err = st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: respLogin.GetRefreshToken()})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: respRefresh.GetRefreshToken()})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestRefresh_ReuseRevokesAccessTokens(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	username := gofakeit.Username() + gofakeit.LetterN(6)
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	respRefresh, err := st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: respLogin.GetRefreshToken()})
+	require.NoError(t, err)
+
+	since := time.Now().Add(-time.Second).UnixMilli()
+
+	_, err = st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: respLogin.GetRefreshToken()})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// access tokens of the family are revoked, not only refresh tokens
+	respRevs, err := st.AuthClient.ListRevocations(ctx, &ssov1.ListRevocationsRequest{Since: since})
+	require.NoError(t, err)
+
+	revoked := make(map[string]bool)
+	for _, token := range respRevs.GetTokens() {
+		revoked[token.GetJti()] = true
+	}
+
+	for _, token := range []string{respLogin.GetToken(), respRefresh.GetToken()} {
+		assert.True(t, revoked[tokenJTI(t, token)])
+
+		_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: token})
+		require.Error(t, err)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+}
+
+func TestRefresh_InvalidToken(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, err := st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: "not-a-token"})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func tokenJTI(t *testing.T, token string) string {
+	t.Helper()
+
+	claims := jwt.MapClaims{}
+	_, _, err := jwt.NewParser().ParseUnverified(token, claims)
+	require.NoError(t, err)
+
+	jti, ok := claims["jti"].(string)
+	require.True(t, ok)
+
+	return jti
+}
@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/liriquew/social-todo/sso_service/tests/suite"
+
+	"github.com/brianvoe/gofakeit/v6"
+	ssov1 "github.com/liriquew/todoprotos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSearchUsers(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	// unique prefix keeps other tests' users out of results
+	prefix := "srch" + gofakeit.LetterN(8)
+
+	token, searcherUID := registerAndLogin(ctx, t, st, prefix+"_searcher")
+
+	uids := make([]int64, 0, 5)
+	for range 5 {
+		respReg, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{
+			Username: prefix + gofakeit.LetterN(6),
+			Password: randomFakePassword(),
+		})
+		require.NoError(t, err)
+		uids = append(uids, respReg.GetUid())
+	}
+
+	var found []int64
+	cursor := ""
+	for {
+		resp, err := st.AuthClient.SearchUsers(ctx, &ssov1.SearchUsersRequest{
+			Token:  token,
+			Query:  prefix,
+			Limit:  2,
+			Cursor: cursor,
+		})
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(resp.GetUsers()), 2)
+
+		for _, user := range resp.GetUsers() {
+			found = append(found, user.GetUid())
+		}
+		if resp.GetNextCursor() == "" {
+			break
+		}
+		cursor = resp.GetNextCursor()
+	}
+
+	// searcher is never in results
+	assert.ElementsMatch(t, uids, found)
+	assert.NotContains(t, found, searcherUID)
+
+	// blocked user is hidden
+	_, err := st.AuthClient.BlockUser(ctx, &ssov1.BlockUserRequest{Token: token, Uid: uids[0]})
+	require.NoError(t, err)
+
+	resp, err := st.AuthClient.SearchUsers(ctx, &ssov1.SearchUsersRequest{Token: token, Query: prefix})
+	require.NoError(t, err)
+	for _, user := range resp.GetUsers() {
+		assert.NotEqual(t, uids[0], user.GetUid())
+	}
+	assert.Len(t, resp.GetUsers(), len(uids)-1)
+
+	_, err = st.AuthClient.UnblockUser(ctx, &ssov1.UnblockUserRequest{Token: token, Uid: uids[0]})
+	require.NoError(t, err)
+
+	resp, err = st.AuthClient.SearchUsers(ctx, &ssov1.SearchUsersRequest{Token: token, Query: prefix})
+	require.NoError(t, err)
+	assert.Len(t, resp.GetUsers(), len(uids))
+}
+
+func TestSearchUsers_DisplayNameAndBlockedBy(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	token, _ := registerAndLogin(ctx, t, st, gofakeit.Username())
+	otherToken, otherUID := registerAndLogin(ctx, t, st, gofakeit.Username())
+
+	displayName := "Zq" + gofakeit.LetterN(10)
+	_, err := st.AuthClient.UpdateProfile(ctx, &ssov1.UpdateProfileRequest{
+		Token:       otherToken,
+		DisplayName: proto.String(displayName),
+	})
+	require.NoError(t, err)
+
+	resp, err := st.AuthClient.SearchUsers(ctx, &ssov1.SearchUsersRequest{Token: token, Query: displayName[:6]})
+	require.NoError(t, err)
+	require.Len(t, resp.GetUsers(), 1)
+	assert.Equal(t, otherUID, resp.GetUsers()[0].GetUid())
+
+	// user who blocked the searcher is hidden too
+	respMe, err := st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: token})
+	require.NoError(t, err)
+	_, err = st.AuthClient.BlockUser(ctx, &ssov1.BlockUserRequest{Token: otherToken, Uid: respMe.GetUid()})
+	require.NoError(t, err)
+
+	resp, err = st.AuthClient.SearchUsers(ctx, &ssov1.SearchUsersRequest{Token: token, Query: displayName[:6]})
+	require.NoError(t, err)
+	assert.Empty(t, resp.GetUsers())
+}
+
+func TestLookupUser(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	token, _ := registerAndLogin(ctx, t, st, gofakeit.Username())
+	username := gofakeit.Username()
+	_, otherUID := registerAndLogin(ctx, t, st, username)
+
+	resp, err := st.AuthClient.LookupUser(ctx, &ssov1.LookupUserRequest{Token: token, Username: username})
+	require.NoError(t, err)
+	assert.Equal(t, otherUID, resp.GetUser().GetUid())
+
+	_, err = st.AuthClient.BlockUser(ctx, &ssov1.BlockUserRequest{Token: token, Uid: otherUID})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.LookupUser(ctx, &ssov1.LookupUserRequest{Token: token, Username: username})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestSearchUsers_FailCases(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	token, uid := registerAndLogin(ctx, t, st, gofakeit.Username())
+
+	_, err := st.AuthClient.SearchUsers(ctx, &ssov1.SearchUsersRequest{Token: token, Query: "a"})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = st.AuthClient.SearchUsers(ctx, &ssov1.SearchUsersRequest{Token: token, Query: "abc", Cursor: "?"})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = st.AuthClient.BlockUser(ctx, &ssov1.BlockUserRequest{Token: token, Uid: uid})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func registerAndLogin(ctx context.Context, t *testing.T, st *suite.Suite, username string) (string, int64) {
+	t.Helper()
+
+	pass := randomFakePassword()
+
+	respReg, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	return respLogin.GetToken(), respReg.GetUid()
+}
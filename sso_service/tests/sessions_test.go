@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/liriquew/social-todo/sso_service/tests/suite"
+
+	"github.com/brianvoe/gofakeit/v6"
+	ssov1 "github.com/liriquew/todoprotos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestSessions_ListAndRevoke(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	username := gofakeit.Username() + gofakeit.LetterN(6)
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	laptopCtx := metadata.AppendToOutgoingContext(ctx, "x-client-user-agent", "laptop-browser/1.0")
+	laptop, err := st.AuthClient.Login(laptopCtx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	phoneCtx := metadata.AppendToOutgoingContext(ctx, "x-client-user-agent", "phone-app/2.0")
+	phone, err := st.AuthClient.Login(phoneCtx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	respList, err := st.AuthClient.ListSessions(ctx, &ssov1.ListSessionsRequest{Token: laptop.GetToken()})
+	require.NoError(t, err)
+	require.Len(t, respList.GetSessions(), 2)
+
+	var current, other *ssov1.Session
+	for _, session := range respList.GetSessions() {
+		if session.GetCurrent() {
+			current = session
+		} else {
+			other = session
+		}
+		assert.NotZero(t, session.GetCreatedAt())
+		assert.NotZero(t, session.GetLastSeenAt())
+	}
+	require.NotNil(t, current)
+	require.NotNil(t, other)
+	assert.Equal(t, "laptop-browser/1.0", current.GetUserAgent())
+	assert.Equal(t, "phone-app/2.0", other.GetUserAgent())
+
+	_, err = st.AuthClient.RevokeSession(ctx, &ssov1.RevokeSessionRequest{
+		Token:     laptop.GetToken(),
+		SessionId: other.GetId(),
+	})
+	require.NoError(t, err)
+
+	// phone's tokens are revoked with its session
+	_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: phone.GetToken()})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: phone.GetRefreshToken()})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// laptop is not affected
+	_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: laptop.GetToken()})
+	require.NoError(t, err)
+
+	respList, err = st.AuthClient.ListSessions(ctx, &ssov1.ListSessionsRequest{Token: laptop.GetToken()})
+	require.NoError(t, err)
+	require.Len(t, respList.GetSessions(), 1)
+	assert.Equal(t, current.GetId(), respList.GetSessions()[0].GetId())
+}
+
+func TestSessions_RefreshKeepsSession(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	username := gofakeit.Username() + gofakeit.LetterN(6)
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	respRefresh, err := st.AuthClient.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: respLogin.GetRefreshToken()})
+	require.NoError(t, err)
+
+	respList, err := st.AuthClient.ListSessions(ctx, &ssov1.ListSessionsRequest{Token: respRefresh.GetToken()})
+	require.NoError(t, err)
+	require.Len(t, respList.GetSessions(), 1)
+	assert.True(t, respList.GetSessions()[0].GetCurrent())
+
+	// revoking the session revokes access tokens issued before refresh too
+	_, err = st.AuthClient.RevokeSession(ctx, &ssov1.RevokeSessionRequest{
+		Token:     respRefresh.GetToken(),
+		SessionId: respList.GetSessions()[0].GetId(),
+	})
+	require.NoError(t, err)
+
+	for _, token := range []string{respLogin.GetToken(), respRefresh.GetToken()} {
+		_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: token})
+		require.Error(t, err)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+}
+
+func TestSessions_RevokeOthersSession(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	token, _ := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+	otherToken, _ := registerAndLogin(ctx, t, st, gofakeit.Username()+gofakeit.LetterN(6))
+
+	respList, err := st.AuthClient.ListSessions(ctx, &ssov1.ListSessionsRequest{Token: otherToken})
+	require.NoError(t, err)
+	require.Len(t, respList.GetSessions(), 1)
+
+	_, err = st.AuthClient.RevokeSession(ctx, &ssov1.RevokeSessionRequest{
+		Token:     token,
+		SessionId: respList.GetSessions()[0].GetId(),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: otherToken})
+	require.NoError(t, err)
+}
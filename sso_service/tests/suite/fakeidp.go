@@ -0,0 +1,223 @@
+package suite
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/liriquew/social-todo/sso_service/internal/lib/config"
+)
+
+// FakeConnectorID is the connector test config points to the fake provider.
+const FakeConnectorID = "fake"
+
+// FakeIdentity is an account at the fake provider.
+type FakeIdentity struct {
+	Subject  string
+	Username string
+	Email    string
+}
+
+// FakeIdP is an OAuth2 provider with authorization code flow, PKCE and
+// userinfo endpoint. There is no login page, Authorize plays the user.
+type FakeIdP struct {
+	cfg config.ConnectorConfig
+
+	mu     sync.Mutex
+	users  map[string]FakeIdentity
+	codes  map[string]fakeGrant
+	tokens map[string]FakeIdentity
+}
+
+type fakeGrant struct {
+	identity    FakeIdentity
+	redirectURI string
+	challenge   string
+}
+
+var (
+	fakeIdPOnce sync.Once
+	fakeIdP     *FakeIdP
+	fakeIdPErr  error
+)
+
+// FakeProvider starts the fake provider on the address test config
+// expects it at, once for all tests.
+func FakeProvider(t *testing.T, cfg *config.Config) *FakeIdP {
+	t.Helper()
+
+	fakeIdPOnce.Do(func() {
+		for _, c := range cfg.ExternalLogin.Connectors {
+			if c.ID == FakeConnectorID {
+				fakeIdP, fakeIdPErr = startFakeIdP(c)
+				return
+			}
+		}
+		fakeIdPErr = errors.New("no fake connector in config")
+	})
+	if fakeIdPErr != nil {
+		t.Fatalf("failed to start fake provider: %v", fakeIdPErr)
+	}
+
+	return fakeIdP
+}
+
+func startFakeIdP(cfg config.ConnectorConfig) (*FakeIdP, error) {
+	u, err := url.Parse(cfg.AuthURL)
+	if err != nil {
+		return nil, err
+	}
+	l, err := net.Listen("tcp", u.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &FakeIdP{
+		cfg:    cfg,
+		users:  make(map[string]FakeIdentity),
+		codes:  make(map[string]fakeGrant),
+		tokens: make(map[string]FakeIdentity),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	mux.HandleFunc("/userinfo", p.userInfo)
+	go http.Serve(l, mux)
+
+	return p, nil
+}
+
+// Authorize signs in at the provider page authURL points to as identity
+// and returns code and state the provider redirects back with.
+func (p *FakeIdP) Authorize(t *testing.T, authURL string, identity FakeIdentity) (string, string) {
+	t.Helper()
+
+	p.mu.Lock()
+	p.users[identity.Subject] = identity
+	p.mu.Unlock()
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get(authURL + "&login_hint=" + url.QueryEscape(identity.Subject))
+	if err != nil {
+		t.Fatalf("failed to authorize: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize: unexpected status %d", resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("authorize: bad redirect: %v", err)
+	}
+
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func (p *FakeIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != p.cfg.ClientID || q.Get("redirect_uri") != p.cfg.RedirectURL ||
+		q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" ||
+		q.Get("code_challenge") == "" || q.Get("state") == "" {
+		http.Error(w, "bad authorization request", http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	identity, ok := p.users[q.Get("login_hint")]
+	p.mu.Unlock()
+	if !ok {
+		http.Error(w, "unknown user", http.StatusUnauthorized)
+		return
+	}
+
+	code := randomString()
+	p.mu.Lock()
+	p.codes[code] = fakeGrant{
+		identity:    identity,
+		redirectURI: q.Get("redirect_uri"),
+		challenge:   q.Get("code_challenge"),
+	}
+	p.mu.Unlock()
+
+	redirect := q.Get("redirect_uri") + "?" + url.Values{"code": {code}, "state": {q.Get("state")}}.Encode()
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+func (p *FakeIdP) token(w http.ResponseWriter, r *http.Request) {
+	id, secret, _ := r.BasicAuth()
+	id, _ = url.QueryUnescape(id)
+	secret, _ = url.QueryUnescape(secret)
+	if id != p.cfg.ClientID || secret != p.cfg.ClientSecret {
+		writeFakeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeFakeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	grant, ok := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || grant.redirectURI != r.PostForm.Get("redirect_uri") ||
+		grant.challenge != base64.RawURLEncoding.EncodeToString(sum[:]) {
+		writeFakeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	token := randomString()
+	p.mu.Lock()
+	p.tokens[token] = grant.identity
+	p.mu.Unlock()
+
+	writeFakeJSON(w, http.StatusOK, map[string]string{"access_token": token, "token_type": "Bearer"})
+}
+
+func (p *FakeIdP) userInfo(w http.ResponseWriter, r *http.Request) {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	p.mu.Lock()
+	identity, ok := p.tokens[token]
+	p.mu.Unlock()
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	writeFakeJSON(w, http.StatusOK, map[string]string{
+		"sub":                identity.Subject,
+		"preferred_username": identity.Username,
+		"email":              identity.Email,
+	})
+}
+
+func writeFakeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -1,7 +1,9 @@
 package suite
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"net"
 	"os"
 	"strconv"
@@ -9,6 +11,8 @@ import (
 	"time"
 
 	"github.com/liriquew/social-todo/sso_service/internal/lib/config"
+	"github.com/liriquew/social-todo/sso_service/internal/lib/jwt"
+	"github.com/liriquew/social-todo/sso_service/internal/storage/postgres"
 
 	ssov1 "github.com/liriquew/todoprotos/gen/go/sso"
 	"google.golang.org/grpc"
@@ -19,7 +23,7 @@ type Suite struct {
 	*testing.T                  // Потребуется для вызова методов *testing.T внутри Suite
 	Cfg        *config.Config   // Конфигурация приложения
 	AuthClient ssov1.AuthClient // Клиент для взаимодействия с gRPC-сервером
-	JWTSecret  string
+	Keys       *jwt.KeySet
 }
 
 const (
@@ -35,6 +39,11 @@ func New(t *testing.T) (context.Context, *Suite) {
 
 	cfg := config.MustLoadPath(configPath())
 
+	keys, err := jwt.LoadKeys(cfg.Keys)
+	if err != nil {
+		t.Fatalf("failed to load keys (generate them with \"task dev_keys\"): %v", err)
+	}
+
 	ctx, cancelCtx := context.WithTimeout(context.Background(), time.Duration(time.Second*3))
 
 	t.Cleanup(func() {
@@ -52,7 +61,7 @@ func New(t *testing.T) (context.Context, *Suite) {
 		T:          t,
 		Cfg:        &cfg,
 		AuthClient: ssov1.NewAuthClient(cc),
-		JWTSecret:  cfg.JWTSecret,
+		Keys:       keys,
 	}
 }
 
@@ -66,6 +75,54 @@ func configPath() string {
 	return "../config/test_config.yaml"
 }
 
+// LastMail returns body of the last message sent to the address
+// by the file mailer.
+func LastMail(t *testing.T, cfg *config.Config, to string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(cfg.Mailer.Path)
+	if err != nil {
+		t.Fatalf("failed to read mail: %v", err)
+	}
+
+	var body string
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var msg struct {
+			To   string `json:"to"`
+			Body string `json:"body"`
+		}
+		if json.Unmarshal(line, &msg) == nil && msg.To == to {
+			body = msg.Body
+		}
+	}
+	if body == "" {
+		t.Fatalf("no mail sent to %s", to)
+	}
+
+	return body
+}
+
+// PromoteAdmin gives user admin role directly in the database,
+// the way configured admins are bootstrapped.
+func PromoteAdmin(ctx context.Context, t *testing.T, cfg *config.Config, username string) {
+	t.Helper()
+
+	storage, err := postgres.New(cfg.Postgres)
+	if err != nil {
+		t.Fatalf("failed to connect to postgres: %v", err)
+	}
+	defer storage.Close()
+
+	if err := storage.PromoteAdmins(ctx, []string{username}); err != nil {
+		t.Fatalf("failed to promote admin: %v", err)
+	}
+}
+
+// JWKSURL returns address of the JWKS document.
+func JWKSURL(cfg *config.Config) string {
+	return "http://" + net.JoinHostPort(grpcHost, strconv.Itoa(cfg.JWKSPort)) + "/.well-known/jwks.json"
+}
+
 func grpcAddress(cfg *config.Config) string {
 	return net.JoinHostPort(grpcHost, strconv.Itoa(cfg.Port))
 }
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/liriquew/social-todo/sso_service/internal/lib/totp"
+	"github.com/liriquew/social-todo/sso_service/tests/suite"
+
+	"github.com/brianvoe/gofakeit/v6"
+	ssov1 "github.com/liriquew/todoprotos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTOTP(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	username := gofakeit.Username()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+	token := respLogin.GetToken()
+
+	respEnroll, err := st.AuthClient.EnrollTOTP(ctx, &ssov1.EnrollTOTPRequest{Token: token})
+	require.NoError(t, err)
+	require.NotEmpty(t, respEnroll.GetSecret())
+	assert.Contains(t, respEnroll.GetUri(), "otpauth://totp/")
+
+	step := totp.Step(time.Now())
+	code, err := totp.Code(respEnroll.GetSecret(), step)
+	require.NoError(t, err)
+
+	respConfirm, err := st.AuthClient.ConfirmTOTP(ctx, &ssov1.ConfirmTOTPRequest{Token: token, Code: code})
+	require.NoError(t, err)
+	require.Len(t, respConfirm.GetRecoveryCodes(), st.Cfg.TwoFactor.RecoveryCodes)
+
+	// login now requires the second step
+	respLogin, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+	require.True(t, respLogin.GetTwoFactorRequired())
+	require.Empty(t, respLogin.GetToken())
+	challenge := respLogin.GetChallengeToken()
+
+	// challenge token is not an access token
+	_, err = st.AuthClient.Authorize(ctx, &ssov1.AuthorizeRequest{Token: challenge})
+	require.Error(t, err)
+
+	// code of already used step is rejected
+	_, err = st.AuthClient.CompleteLogin(ctx, &ssov1.CompleteLoginRequest{ChallengeToken: challenge, Code: code})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	next, err := totp.Code(respEnroll.GetSecret(), step+1)
+	require.NoError(t, err)
+
+	respComplete, err := st.AuthClient.CompleteLogin(ctx, &ssov1.CompleteLoginRequest{ChallengeToken: challenge, Code: next})
+	require.NoError(t, err)
+	require.NotEmpty(t, respComplete.GetToken())
+	require.NotEmpty(t, respComplete.GetRefreshToken())
+
+	// recovery codes are single-use
+	respLogin, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+
+	recovery := respConfirm.GetRecoveryCodes()[0]
+	_, err = st.AuthClient.CompleteLogin(ctx, &ssov1.CompleteLoginRequest{ChallengeToken: respLogin.GetChallengeToken(), Code: recovery})
+	require.NoError(t, err)
+
+	respLogin, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+	_, err = st.AuthClient.CompleteLogin(ctx, &ssov1.CompleteLoginRequest{ChallengeToken: respLogin.GetChallengeToken(), Code: recovery})
+	require.Error(t, err)
+
+	_, err = st.AuthClient.DisableTOTP(ctx, &ssov1.DisableTOTPRequest{Token: respComplete.GetToken(), Password: pass})
+	require.NoError(t, err)
+
+	respLogin, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Username: username, Password: pass})
+	require.NoError(t, err)
+	assert.False(t, respLogin.GetTwoFactorRequired())
+	assert.NotEmpty(t, respLogin.GetToken())
+}
@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/liriquew/social-todo/sso_service/tests/suite"
+
+	"github.com/brianvoe/gofakeit/v6"
+	ssov1 "github.com/liriquew/todoprotos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUsers_HappyPath(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	usernames := map[int64]string{}
+	for range 3 {
+		username := gofakeit.Username()
+
+		respReg, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{
+			Username: username,
+			Password: randomFakePassword(),
+		})
+		require.NoError(t, err)
+
+		usernames[respReg.GetUid()] = username
+	}
+
+	uids := make([]int64, 0, len(usernames))
+	for uid := range usernames {
+		uids = append(uids, uid)
+	}
+
+	resp, err := st.AuthClient.GetUsers(ctx, &ssov1.GetUsersRequest{Uids: uids})
+	require.NoError(t, err)
+	require.Len(t, resp.GetUsers(), len(usernames))
+
+	for _, user := range resp.GetUsers() {
+		assert.Equal(t, usernames[user.GetUid()], user.GetUsername())
+	}
+}
+
+func TestGetUsers_EmptyList(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, err := st.AuthClient.GetUsers(ctx, &ssov1.GetUsersRequest{})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "uids list is empty")
+}
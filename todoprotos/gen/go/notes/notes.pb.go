@@ -0,0 +1,1261 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: notes/notes.proto
+
+package notes
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Note struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Title     string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Content   string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	Duration  *durationpb.Duration   `protobuf:"bytes,3,opt,name=duration,proto3" json:"duration,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	Public    *bool                  `protobuf:"varint,5,opt,name=public,proto3,oneof" json:"public,omitempty"`
+}
+
+func (x *Note) Reset() {
+	*x = Note{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notes_notes_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Note) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Note) ProtoMessage() {}
+
+func (x *Note) ProtoReflect() protoreflect.Message {
+	mi := &file_notes_notes_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Note.ProtoReflect.Descriptor instead.
+func (*Note) Descriptor() ([]byte, []int) {
+	return file_notes_notes_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Note) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Note) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Note) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *Note) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Note) GetPublic() bool {
+	if x != nil && x.Public != nil {
+		return *x.Public
+	}
+	return false
+}
+
+type CreateNoteRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UID  int64 `protobuf:"varint,1,opt,name=UID,proto3" json:"UID,omitempty"`
+	Note *Note `protobuf:"bytes,2,opt,name=note,proto3" json:"note,omitempty"`
+}
+
+func (x *CreateNoteRequest) Reset() {
+	*x = CreateNoteRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notes_notes_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateNoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateNoteRequest) ProtoMessage() {}
+
+func (x *CreateNoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notes_notes_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateNoteRequest.ProtoReflect.Descriptor instead.
+func (*CreateNoteRequest) Descriptor() ([]byte, []int) {
+	return file_notes_notes_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateNoteRequest) GetUID() int64 {
+	if x != nil {
+		return x.UID
+	}
+	return 0
+}
+
+func (x *CreateNoteRequest) GetNote() *Note {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+type UpdateNoteRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UID  int64 `protobuf:"varint,1,opt,name=UID,proto3" json:"UID,omitempty"`
+	NID  int64 `protobuf:"varint,2,opt,name=NID,proto3" json:"NID,omitempty"`
+	Note *Note `protobuf:"bytes,3,opt,name=note,proto3" json:"note,omitempty"`
+}
+
+func (x *UpdateNoteRequest) Reset() {
+	*x = UpdateNoteRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notes_notes_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateNoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateNoteRequest) ProtoMessage() {}
+
+func (x *UpdateNoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notes_notes_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateNoteRequest.ProtoReflect.Descriptor instead.
+func (*UpdateNoteRequest) Descriptor() ([]byte, []int) {
+	return file_notes_notes_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *UpdateNoteRequest) GetUID() int64 {
+	if x != nil {
+		return x.UID
+	}
+	return 0
+}
+
+func (x *UpdateNoteRequest) GetNID() int64 {
+	if x != nil {
+		return x.NID
+	}
+	return 0
+}
+
+func (x *UpdateNoteRequest) GetNote() *Note {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+type NoteIDRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UID int64 `protobuf:"varint,1,opt,name=UID,proto3" json:"UID,omitempty"`
+	NID int64 `protobuf:"varint,2,opt,name=NID,proto3" json:"NID,omitempty"`
+}
+
+func (x *NoteIDRequest) Reset() {
+	*x = NoteIDRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notes_notes_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NoteIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NoteIDRequest) ProtoMessage() {}
+
+func (x *NoteIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notes_notes_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NoteIDRequest.ProtoReflect.Descriptor instead.
+func (*NoteIDRequest) Descriptor() ([]byte, []int) {
+	return file_notes_notes_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *NoteIDRequest) GetUID() int64 {
+	if x != nil {
+		return x.UID
+	}
+	return 0
+}
+
+func (x *NoteIDRequest) GetNID() int64 {
+	if x != nil {
+		return x.NID
+	}
+	return 0
+}
+
+type NoteResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NID int64 `protobuf:"varint,1,opt,name=NID,proto3" json:"NID,omitempty"`
+}
+
+func (x *NoteResponse) Reset() {
+	*x = NoteResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notes_notes_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NoteResponse) ProtoMessage() {}
+
+func (x *NoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_notes_notes_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NoteResponse.ProtoReflect.Descriptor instead.
+func (*NoteResponse) Descriptor() ([]byte, []int) {
+	return file_notes_notes_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *NoteResponse) GetNID() int64 {
+	if x != nil {
+		return x.NID
+	}
+	return 0
+}
+
+type UserIDRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UID int64 `protobuf:"varint,1,opt,name=UID,proto3" json:"UID,omitempty"`
+}
+
+func (x *UserIDRequest) Reset() {
+	*x = UserIDRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notes_notes_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UserIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserIDRequest) ProtoMessage() {}
+
+func (x *UserIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notes_notes_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserIDRequest.ProtoReflect.Descriptor instead.
+func (*UserIDRequest) Descriptor() ([]byte, []int) {
+	return file_notes_notes_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UserIDRequest) GetUID() int64 {
+	if x != nil {
+		return x.UID
+	}
+	return 0
+}
+
+type NoteIDList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UID     int64   `protobuf:"varint,1,opt,name=UID,proto3" json:"UID,omitempty"`
+	NoteIDs []int64 `protobuf:"varint,2,rep,packed,name=noteIDs,proto3" json:"noteIDs,omitempty"`
+}
+
+func (x *NoteIDList) Reset() {
+	*x = NoteIDList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notes_notes_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NoteIDList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NoteIDList) ProtoMessage() {}
+
+func (x *NoteIDList) ProtoReflect() protoreflect.Message {
+	mi := &file_notes_notes_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NoteIDList.ProtoReflect.Descriptor instead.
+func (*NoteIDList) Descriptor() ([]byte, []int) {
+	return file_notes_notes_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *NoteIDList) GetUID() int64 {
+	if x != nil {
+		return x.UID
+	}
+	return 0
+}
+
+func (x *NoteIDList) GetNoteIDs() []int64 {
+	if x != nil {
+		return x.NoteIDs
+	}
+	return nil
+}
+
+type NoteListItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NID  int64 `protobuf:"varint,1,opt,name=NID,proto3" json:"NID,omitempty"`
+	Note *Note `protobuf:"bytes,2,opt,name=note,proto3" json:"note,omitempty"`
+}
+
+func (x *NoteListItem) Reset() {
+	*x = NoteListItem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notes_notes_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NoteListItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NoteListItem) ProtoMessage() {}
+
+func (x *NoteListItem) ProtoReflect() protoreflect.Message {
+	mi := &file_notes_notes_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NoteListItem.ProtoReflect.Descriptor instead.
+func (*NoteListItem) Descriptor() ([]byte, []int) {
+	return file_notes_notes_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *NoteListItem) GetNID() int64 {
+	if x != nil {
+		return x.NID
+	}
+	return 0
+}
+
+func (x *NoteListItem) GetNote() *Note {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+type NoteList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Notes []*NoteListItem `protobuf:"bytes,1,rep,name=notes,proto3" json:"notes,omitempty"`
+}
+
+func (x *NoteList) Reset() {
+	*x = NoteList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notes_notes_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NoteList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NoteList) ProtoMessage() {}
+
+func (x *NoteList) ProtoReflect() protoreflect.Message {
+	mi := &file_notes_notes_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NoteList.ProtoReflect.Descriptor instead.
+func (*NoteList) Descriptor() ([]byte, []int) {
+	return file_notes_notes_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *NoteList) GetNotes() []*NoteListItem {
+	if x != nil {
+		return x.Notes
+	}
+	return nil
+}
+
+type UsersNotesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UIDs []int64 `protobuf:"varint,1,rep,packed,name=UIDs,proto3" json:"UIDs,omitempty"`
+	// notes of these users are listed only if public
+	PublicUIDs []int64 `protobuf:"varint,2,rep,packed,name=publicUIDs,proto3" json:"publicUIDs,omitempty"`
+	Offset     int64   `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	Limit      int64   `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (x *UsersNotesRequest) Reset() {
+	*x = UsersNotesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notes_notes_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UsersNotesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsersNotesRequest) ProtoMessage() {}
+
+func (x *UsersNotesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notes_notes_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsersNotesRequest.ProtoReflect.Descriptor instead.
+func (*UsersNotesRequest) Descriptor() ([]byte, []int) {
+	return file_notes_notes_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UsersNotesRequest) GetUIDs() []int64 {
+	if x != nil {
+		return x.UIDs
+	}
+	return nil
+}
+
+func (x *UsersNotesRequest) GetPublicUIDs() []int64 {
+	if x != nil {
+		return x.PublicUIDs
+	}
+	return nil
+}
+
+func (x *UsersNotesRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *UsersNotesRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type UsersNotesListItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NID  int64 `protobuf:"varint,1,opt,name=NID,proto3" json:"NID,omitempty"`
+	UID  int64 `protobuf:"varint,2,opt,name=UID,proto3" json:"UID,omitempty"`
+	Note *Note `protobuf:"bytes,3,opt,name=note,proto3" json:"note,omitempty"`
+}
+
+func (x *UsersNotesListItem) Reset() {
+	*x = UsersNotesListItem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notes_notes_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UsersNotesListItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsersNotesListItem) ProtoMessage() {}
+
+func (x *UsersNotesListItem) ProtoReflect() protoreflect.Message {
+	mi := &file_notes_notes_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsersNotesListItem.ProtoReflect.Descriptor instead.
+func (*UsersNotesListItem) Descriptor() ([]byte, []int) {
+	return file_notes_notes_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UsersNotesListItem) GetNID() int64 {
+	if x != nil {
+		return x.NID
+	}
+	return 0
+}
+
+func (x *UsersNotesListItem) GetUID() int64 {
+	if x != nil {
+		return x.UID
+	}
+	return 0
+}
+
+func (x *UsersNotesListItem) GetNote() *Note {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+type UsersNotesList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Notes []*UsersNotesListItem `protobuf:"bytes,1,rep,name=notes,proto3" json:"notes,omitempty"`
+}
+
+func (x *UsersNotesList) Reset() {
+	*x = UsersNotesList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notes_notes_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UsersNotesList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsersNotesList) ProtoMessage() {}
+
+func (x *UsersNotesList) ProtoReflect() protoreflect.Message {
+	mi := &file_notes_notes_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsersNotesList.ProtoReflect.Descriptor instead.
+func (*UsersNotesList) Descriptor() ([]byte, []int) {
+	return file_notes_notes_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UsersNotesList) GetNotes() []*UsersNotesListItem {
+	if x != nil {
+		return x.Notes
+	}
+	return nil
+}
+
+type DeleteUserNotesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Deleted int64 `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`
+}
+
+func (x *DeleteUserNotesResponse) Reset() {
+	*x = DeleteUserNotesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notes_notes_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteUserNotesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteUserNotesResponse) ProtoMessage() {}
+
+func (x *DeleteUserNotesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_notes_notes_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteUserNotesResponse.ProtoReflect.Descriptor instead.
+func (*DeleteUserNotesResponse) Descriptor() ([]byte, []int) {
+	return file_notes_notes_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DeleteUserNotesResponse) GetDeleted() int64 {
+	if x != nil {
+		return x.Deleted
+	}
+	return 0
+}
+
+type AdminDeleteNoteRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ActorID int64 `protobuf:"varint,1,opt,name=actorID,proto3" json:"actorID,omitempty"`
+	NID     int64 `protobuf:"varint,2,opt,name=NID,proto3" json:"NID,omitempty"`
+}
+
+func (x *AdminDeleteNoteRequest) Reset() {
+	*x = AdminDeleteNoteRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notes_notes_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AdminDeleteNoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminDeleteNoteRequest) ProtoMessage() {}
+
+func (x *AdminDeleteNoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notes_notes_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminDeleteNoteRequest.ProtoReflect.Descriptor instead.
+func (*AdminDeleteNoteRequest) Descriptor() ([]byte, []int) {
+	return file_notes_notes_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *AdminDeleteNoteRequest) GetActorID() int64 {
+	if x != nil {
+		return x.ActorID
+	}
+	return 0
+}
+
+func (x *AdminDeleteNoteRequest) GetNID() int64 {
+	if x != nil {
+		return x.NID
+	}
+	return 0
+}
+
+type AdminDeleteNoteResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NID int64 `protobuf:"varint,1,opt,name=NID,proto3" json:"NID,omitempty"`
+	// owner of the deleted note
+	UID int64 `protobuf:"varint,2,opt,name=UID,proto3" json:"UID,omitempty"`
+}
+
+func (x *AdminDeleteNoteResponse) Reset() {
+	*x = AdminDeleteNoteResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notes_notes_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AdminDeleteNoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminDeleteNoteResponse) ProtoMessage() {}
+
+func (x *AdminDeleteNoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_notes_notes_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminDeleteNoteResponse.ProtoReflect.Descriptor instead.
+func (*AdminDeleteNoteResponse) Descriptor() ([]byte, []int) {
+	return file_notes_notes_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *AdminDeleteNoteResponse) GetNID() int64 {
+	if x != nil {
+		return x.NID
+	}
+	return 0
+}
+
+func (x *AdminDeleteNoteResponse) GetUID() int64 {
+	if x != nil {
+		return x.UID
+	}
+	return 0
+}
+
+var File_notes_notes_proto protoreflect.FileDescriptor
+
+var file_notes_notes_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2f, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x05, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xcf, 0x01, 0x0a, 0x04,
+	0x4e, 0x6f, 0x74, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f,
+	0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6e,
+	0x74, 0x65, 0x6e, 0x74, 0x12, 0x35, 0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x38, 0x0a, 0x09, 0x63,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1b, 0x0a, 0x06, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x06, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x88,
+	0x01, 0x01, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x22, 0x46, 0x0a,
+	0x11, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4e, 0x6f, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x55, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x03, 0x55, 0x49, 0x44, 0x12, 0x1f, 0x0a, 0x04, 0x6e, 0x6f, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2e, 0x4e, 0x6f, 0x74, 0x65, 0x52,
+	0x04, 0x6e, 0x6f, 0x74, 0x65, 0x22, 0x58, 0x0a, 0x11, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4e,
+	0x6f, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x55, 0x49,
+	0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x55, 0x49, 0x44, 0x12, 0x10, 0x0a, 0x03,
+	0x4e, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x4e, 0x49, 0x44, 0x12, 0x1f,
+	0x0a, 0x04, 0x6e, 0x6f, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x6e,
+	0x6f, 0x74, 0x65, 0x73, 0x2e, 0x4e, 0x6f, 0x74, 0x65, 0x52, 0x04, 0x6e, 0x6f, 0x74, 0x65, 0x22,
+	0x33, 0x0a, 0x0d, 0x4e, 0x6f, 0x74, 0x65, 0x49, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x10, 0x0a, 0x03, 0x55, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x55,
+	0x49, 0x44, 0x12, 0x10, 0x0a, 0x03, 0x4e, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x03, 0x4e, 0x49, 0x44, 0x22, 0x20, 0x0a, 0x0c, 0x4e, 0x6f, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x4e, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x03, 0x4e, 0x49, 0x44, 0x22, 0x21, 0x0a, 0x0d, 0x55, 0x73, 0x65, 0x72, 0x49, 0x44,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x55, 0x49, 0x44, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x55, 0x49, 0x44, 0x22, 0x38, 0x0a, 0x0a, 0x4e, 0x6f, 0x74,
+	0x65, 0x49, 0x44, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x55, 0x49, 0x44, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x55, 0x49, 0x44, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x6f, 0x74,
+	0x65, 0x49, 0x44, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x03, 0x52, 0x07, 0x6e, 0x6f, 0x74, 0x65,
+	0x49, 0x44, 0x73, 0x22, 0x41, 0x0a, 0x0c, 0x4e, 0x6f, 0x74, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x49,
+	0x74, 0x65, 0x6d, 0x12, 0x10, 0x0a, 0x03, 0x4e, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x03, 0x4e, 0x49, 0x44, 0x12, 0x1f, 0x0a, 0x04, 0x6e, 0x6f, 0x74, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2e, 0x4e, 0x6f, 0x74, 0x65,
+	0x52, 0x04, 0x6e, 0x6f, 0x74, 0x65, 0x22, 0x35, 0x0a, 0x08, 0x4e, 0x6f, 0x74, 0x65, 0x4c, 0x69,
+	0x73, 0x74, 0x12, 0x29, 0x0a, 0x05, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x13, 0x2e, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2e, 0x4e, 0x6f, 0x74, 0x65, 0x4c, 0x69,
+	0x73, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x22, 0x75, 0x0a,
+	0x11, 0x55, 0x73, 0x65, 0x72, 0x73, 0x4e, 0x6f, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x55, 0x49, 0x44, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x03,
+	0x52, 0x04, 0x55, 0x49, 0x44, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63,
+	0x55, 0x49, 0x44, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x03, 0x52, 0x0a, 0x70, 0x75, 0x62, 0x6c,
+	0x69, 0x63, 0x55, 0x49, 0x44, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x14,
+	0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c,
+	0x69, 0x6d, 0x69, 0x74, 0x22, 0x59, 0x0a, 0x12, 0x55, 0x73, 0x65, 0x72, 0x73, 0x4e, 0x6f, 0x74,
+	0x65, 0x73, 0x4c, 0x69, 0x73, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x10, 0x0a, 0x03, 0x4e, 0x49,
+	0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x4e, 0x49, 0x44, 0x12, 0x10, 0x0a, 0x03,
+	0x55, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x55, 0x49, 0x44, 0x12, 0x1f,
+	0x0a, 0x04, 0x6e, 0x6f, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x6e,
+	0x6f, 0x74, 0x65, 0x73, 0x2e, 0x4e, 0x6f, 0x74, 0x65, 0x52, 0x04, 0x6e, 0x6f, 0x74, 0x65, 0x22,
+	0x41, 0x0a, 0x0e, 0x55, 0x73, 0x65, 0x72, 0x73, 0x4e, 0x6f, 0x74, 0x65, 0x73, 0x4c, 0x69, 0x73,
+	0x74, 0x12, 0x2f, 0x0a, 0x05, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x73, 0x4e, 0x6f,
+	0x74, 0x65, 0x73, 0x4c, 0x69, 0x73, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x6e, 0x6f, 0x74,
+	0x65, 0x73, 0x22, 0x33, 0x0a, 0x17, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72,
+	0x4e, 0x6f, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07,
+	0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x22, 0x44, 0x0a, 0x16, 0x41, 0x64, 0x6d, 0x69, 0x6e,
+	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4e, 0x6f, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x07, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x49, 0x44, 0x12, 0x10, 0x0a, 0x03, 0x4e,
+	0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x4e, 0x49, 0x44, 0x22, 0x3d, 0x0a,
+	0x17, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4e, 0x6f, 0x74, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x4e, 0x49, 0x44, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x4e, 0x49, 0x44, 0x12, 0x10, 0x0a, 0x03, 0x55, 0x49,
+	0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x55, 0x49, 0x44, 0x32, 0xb7, 0x04, 0x0a,
+	0x05, 0x4e, 0x6f, 0x74, 0x65, 0x73, 0x12, 0x3b, 0x0a, 0x0a, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x4e, 0x6f, 0x74, 0x65, 0x12, 0x18, 0x2e, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2e, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x4e, 0x6f, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13,
+	0x2e, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2e, 0x4e, 0x6f, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x2c, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x4e, 0x6f, 0x74, 0x65, 0x12, 0x14,
+	0x2e, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2e, 0x4e, 0x6f, 0x74, 0x65, 0x49, 0x44, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x0b, 0x2e, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2e, 0x4e, 0x6f, 0x74,
+	0x65, 0x12, 0x3b, 0x0a, 0x0a, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4e, 0x6f, 0x74, 0x65, 0x12,
+	0x18, 0x2e, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4e, 0x6f,
+	0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x6e, 0x6f, 0x74, 0x65,
+	0x73, 0x2e, 0x4e, 0x6f, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37,
+	0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4e, 0x6f, 0x74, 0x65, 0x12, 0x14, 0x2e, 0x6e,
+	0x6f, 0x74, 0x65, 0x73, 0x2e, 0x4e, 0x6f, 0x74, 0x65, 0x49, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x13, 0x2e, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2e, 0x4e, 0x6f, 0x74, 0x65, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3a, 0x0a, 0x0f, 0x4c, 0x69, 0x73, 0x74, 0x55,
+	0x73, 0x65, 0x72, 0x4e, 0x6f, 0x74, 0x65, 0x73, 0x49, 0x44, 0x12, 0x14, 0x2e, 0x6e, 0x6f, 0x74,
+	0x65, 0x73, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x49, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x11, 0x2e, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2e, 0x4e, 0x6f, 0x74, 0x65, 0x49, 0x44, 0x4c,
+	0x69, 0x73, 0x74, 0x12, 0x33, 0x0a, 0x0d, 0x4c, 0x69, 0x73, 0x74, 0x55, 0x73, 0x65, 0x72, 0x4e,
+	0x6f, 0x74, 0x65, 0x73, 0x12, 0x11, 0x2e, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2e, 0x4e, 0x6f, 0x74,
+	0x65, 0x49, 0x44, 0x4c, 0x69, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2e,
+	0x4e, 0x6f, 0x74, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x41, 0x0a, 0x0e, 0x4c, 0x69, 0x73, 0x74,
+	0x55, 0x73, 0x65, 0x72, 0x73, 0x4e, 0x6f, 0x74, 0x65, 0x73, 0x12, 0x18, 0x2e, 0x6e, 0x6f, 0x74,
+	0x65, 0x73, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x73, 0x4e, 0x6f, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2e, 0x55, 0x73, 0x65,
+	0x72, 0x73, 0x4e, 0x6f, 0x74, 0x65, 0x73, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x47, 0x0a, 0x0f, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x4e, 0x6f, 0x74, 0x65, 0x73, 0x12, 0x14,
+	0x2e, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x49, 0x44, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2e, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x4e, 0x6f, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x50, 0x0a, 0x0f, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x4e, 0x6f, 0x74, 0x65, 0x12, 0x1d, 0x2e, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2e,
+	0x41, 0x64, 0x6d, 0x69, 0x6e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4e, 0x6f, 0x74, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x2e, 0x41,
+	0x64, 0x6d, 0x69, 0x6e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4e, 0x6f, 0x74, 0x65, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x69, 0x72, 0x69, 0x71, 0x75, 0x65, 0x77, 0x2f, 0x74, 0x6f,
+	0x64, 0x6f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x67, 0x6f, 0x2f,
+	0x6e, 0x6f, 0x74, 0x65, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_notes_notes_proto_rawDescOnce sync.Once
+	file_notes_notes_proto_rawDescData = file_notes_notes_proto_rawDesc
+)
+
+func file_notes_notes_proto_rawDescGZIP() []byte {
+	file_notes_notes_proto_rawDescOnce.Do(func() {
+		file_notes_notes_proto_rawDescData = protoimpl.X.CompressGZIP(file_notes_notes_proto_rawDescData)
+	})
+	return file_notes_notes_proto_rawDescData
+}
+
+var file_notes_notes_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_notes_notes_proto_goTypes = []any{
+	(*Note)(nil),                    // 0: notes.Note
+	(*CreateNoteRequest)(nil),       // 1: notes.CreateNoteRequest
+	(*UpdateNoteRequest)(nil),       // 2: notes.UpdateNoteRequest
+	(*NoteIDRequest)(nil),           // 3: notes.NoteIDRequest
+	(*NoteResponse)(nil),            // 4: notes.NoteResponse
+	(*UserIDRequest)(nil),           // 5: notes.UserIDRequest
+	(*NoteIDList)(nil),              // 6: notes.NoteIDList
+	(*NoteListItem)(nil),            // 7: notes.NoteListItem
+	(*NoteList)(nil),                // 8: notes.NoteList
+	(*UsersNotesRequest)(nil),       // 9: notes.UsersNotesRequest
+	(*UsersNotesListItem)(nil),      // 10: notes.UsersNotesListItem
+	(*UsersNotesList)(nil),          // 11: notes.UsersNotesList
+	(*DeleteUserNotesResponse)(nil), // 12: notes.DeleteUserNotesResponse
+	(*AdminDeleteNoteRequest)(nil),  // 13: notes.AdminDeleteNoteRequest
+	(*AdminDeleteNoteResponse)(nil), // 14: notes.AdminDeleteNoteResponse
+	(*durationpb.Duration)(nil),     // 15: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil),   // 16: google.protobuf.Timestamp
+}
+var file_notes_notes_proto_depIdxs = []int32{
+	15, // 0: notes.Note.duration:type_name -> google.protobuf.Duration
+	16, // 1: notes.Note.createdAt:type_name -> google.protobuf.Timestamp
+	0,  // 2: notes.CreateNoteRequest.note:type_name -> notes.Note
+	0,  // 3: notes.UpdateNoteRequest.note:type_name -> notes.Note
+	0,  // 4: notes.NoteListItem.note:type_name -> notes.Note
+	7,  // 5: notes.NoteList.notes:type_name -> notes.NoteListItem
+	0,  // 6: notes.UsersNotesListItem.note:type_name -> notes.Note
+	10, // 7: notes.UsersNotesList.notes:type_name -> notes.UsersNotesListItem
+	1,  // 8: notes.Notes.CreateNote:input_type -> notes.CreateNoteRequest
+	3,  // 9: notes.Notes.GetNote:input_type -> notes.NoteIDRequest
+	2,  // 10: notes.Notes.UpdateNote:input_type -> notes.UpdateNoteRequest
+	3,  // 11: notes.Notes.DeleteNote:input_type -> notes.NoteIDRequest
+	5,  // 12: notes.Notes.ListUserNotesID:input_type -> notes.UserIDRequest
+	6,  // 13: notes.Notes.ListUserNotes:input_type -> notes.NoteIDList
+	9,  // 14: notes.Notes.ListUsersNotes:input_type -> notes.UsersNotesRequest
+	5,  // 15: notes.Notes.DeleteUserNotes:input_type -> notes.UserIDRequest
+	13, // 16: notes.Notes.AdminDeleteNote:input_type -> notes.AdminDeleteNoteRequest
+	4,  // 17: notes.Notes.CreateNote:output_type -> notes.NoteResponse
+	0,  // 18: notes.Notes.GetNote:output_type -> notes.Note
+	4,  // 19: notes.Notes.UpdateNote:output_type -> notes.NoteResponse
+	4,  // 20: notes.Notes.DeleteNote:output_type -> notes.NoteResponse
+	6,  // 21: notes.Notes.ListUserNotesID:output_type -> notes.NoteIDList
+	8,  // 22: notes.Notes.ListUserNotes:output_type -> notes.NoteList
+	11, // 23: notes.Notes.ListUsersNotes:output_type -> notes.UsersNotesList
+	12, // 24: notes.Notes.DeleteUserNotes:output_type -> notes.DeleteUserNotesResponse
+	14, // 25: notes.Notes.AdminDeleteNote:output_type -> notes.AdminDeleteNoteResponse
+	17, // [17:26] is the sub-list for method output_type
+	8,  // [8:17] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_notes_notes_proto_init() }
+func file_notes_notes_proto_init() {
+	if File_notes_notes_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_notes_notes_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*Note); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_notes_notes_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*CreateNoteRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_notes_notes_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*UpdateNoteRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_notes_notes_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*NoteIDRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_notes_notes_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*NoteResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_notes_notes_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*UserIDRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_notes_notes_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*NoteIDList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_notes_notes_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*NoteListItem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_notes_notes_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*NoteList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_notes_notes_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*UsersNotesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_notes_notes_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*UsersNotesListItem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_notes_notes_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*UsersNotesList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_notes_notes_proto_msgTypes[12].Exporter = func(v any, i int) any {
+			switch v := v.(*DeleteUserNotesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_notes_notes_proto_msgTypes[13].Exporter = func(v any, i int) any {
+			switch v := v.(*AdminDeleteNoteRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_notes_notes_proto_msgTypes[14].Exporter = func(v any, i int) any {
+			switch v := v.(*AdminDeleteNoteResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_notes_notes_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_notes_notes_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_notes_notes_proto_goTypes,
+		DependencyIndexes: file_notes_notes_proto_depIdxs,
+		MessageInfos:      file_notes_notes_proto_msgTypes,
+	}.Build()
+	File_notes_notes_proto = out.File
+	file_notes_notes_proto_rawDesc = nil
+	file_notes_notes_proto_goTypes = nil
+	file_notes_notes_proto_depIdxs = nil
+}